@@ -0,0 +1,173 @@
+// Package main CLI修改写回命令
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"code-context-generator/internal/apply"
+	"code-context-generator/internal/unpack"
+	"code-context-generator/internal/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// applyCmd 修改写回命令
+var applyCmd = &cobra.Command{
+	Use:   "apply <回复文件>",
+	Short: "将模型回复中的文件块或统一diff写回工作区",
+	Long: `读取模型回复中的 XML/Markdown 文件块（与生成的上下文格式相同）或统一diff，
+逐个文件显示diff预览，然后写入项目根目录
+
+所有路径必须位于项目根目录之内，越出根目录或经过符号链接的路径会使整个应用失败。
+文件内容与生成上下文时记录的哈希不一致时视为冲突（生成后文件已被修改），
+哈希取自 --context 指定的上下文文件，其次取自回复中文件块的哈希。
+哈希只在生成时使用 --hash 记录，没有记录哈希的文件不检测冲突。
+存在冲突或无法应用的修改块时不写入任何文件，冲突可以使用 --force 强制覆盖。`,
+	Args: cobra.ExactArgs(1),
+	RunE: runApply,
+}
+
+// initApplyCommands 初始化修改写回命令
+func initApplyCommands() {
+	rootCmd.AddCommand(applyCmd)
+
+	applyCmd.Flags().String("root", ".", "项目根目录")
+	applyCmd.Flags().String("context", "", "生成的上下文文件或分块清单，用于冲突检测")
+	applyCmd.Flags().String("format", "", "回复格式 (diff, xml, markdown 等，默认自动识别)")
+	applyCmd.Flags().Bool("dry-run", false, "只显示diff预览，不写入文件")
+	applyCmd.Flags().Bool("force", false, "覆盖生成上下文后已被修改的文件")
+}
+
+// runApply 执行修改写回
+func runApply(cmd *cobra.Command, args []string) error {
+	root, _ := cmd.Flags().GetString("root")
+	contextFile, _ := cmd.Flags().GetString("context")
+	format, _ := cmd.Flags().GetString("format")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	force, _ := cmd.Flags().GetBool("force")
+
+	content, err := os.ReadFile(args[0])
+	if err != nil {
+		return fmt.Errorf("读取回复文件失败: %w", err)
+	}
+	if format == "" {
+		format = apply.DetectFormat(args[0], content)
+		if format == "" {
+			return fmt.Errorf("无法识别回复格式，请使用 --format 指定")
+		}
+	}
+
+	var customNames map[string]string
+	if cfg != nil {
+		customNames = cfg.Fields.CustomNames
+	}
+	response, err := apply.ParseResponse(content, format, customNames)
+	if err != nil {
+		return err
+	}
+
+	opts := apply.Options{Root: root}
+	if contextFile != "" {
+		opts.Context, err = unpack.DecodeFile(contextFile, "", customNames)
+		if err != nil {
+			return err
+		}
+	}
+	entries, err := apply.Plan(response, opts)
+	if err != nil {
+		return err
+	}
+	if !hasRecordedHash(response, opts.Context) {
+		fmt.Println(utils.WarningColor("⚠ 上下文和回复中都没有记录哈希（生成时未使用 --hash），无法检测生成后被修改的文件"))
+	}
+
+	conflicts, failed := 0, 0
+	for _, entry := range entries {
+		printApplyEntry(entry)
+		switch {
+		case entry.Action == apply.ActionFailed:
+			failed++
+		case entry.Action != apply.ActionSkip && entry.Conflict != "":
+			conflicts++
+		}
+	}
+
+	if dryRun {
+		fmt.Println(utils.InfoColor(fmt.Sprintf("试运行: %d 个文件，%d 个冲突，%d 个无法应用", len(entries), conflicts, failed)))
+		return nil
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d 个文件的修改无法应用，未写入任何文件", failed)
+	}
+	if conflicts > 0 && !force {
+		return fmt.Errorf("%d 个文件在生成上下文后已被修改，未写入任何文件 (使用 --force 覆盖)", conflicts)
+	}
+
+	written, err := apply.Apply(entries, force)
+	if err != nil {
+		return err
+	}
+	fmt.Println(utils.SuccessColor(fmt.Sprintf("已写入 %d 个文件", written)))
+	return nil
+}
+
+// applyActionNames 操作的显示名称
+var applyActionNames = map[string]string{
+	apply.ActionCreate:    "新建",
+	apply.ActionModify:    "修改",
+	apply.ActionDelete:    "删除",
+	apply.ActionUnchanged: "无变化",
+	apply.ActionSkip:      "跳过",
+	apply.ActionFailed:    "无法应用",
+}
+
+// printApplyEntry 输出单个文件的操作、冲突和带颜色的diff预览
+func printApplyEntry(entry apply.Entry) {
+	header := fmt.Sprintf("%s %s", applyActionNames[entry.Action], entry.Path)
+	switch {
+	case entry.Action == apply.ActionFailed:
+		fmt.Println(utils.ErrorColor(header + " (" + entry.Conflict + ")"))
+	case entry.Conflict != "":
+		fmt.Println(utils.WarningColor(header + " (" + entry.Conflict + ")"))
+	default:
+		fmt.Println(utils.InfoColor(header))
+	}
+
+	for _, line := range strings.SplitAfter(entry.Diff, "\n") {
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "+++ "), strings.HasPrefix(line, "--- "):
+			fmt.Print(line)
+		case strings.HasPrefix(line, "+"):
+			fmt.Print(utils.SuccessColor(strings.TrimSuffix(line, "\n")) + "\n")
+		case strings.HasPrefix(line, "-"):
+			fmt.Print(utils.ErrorColor(strings.TrimSuffix(line, "\n")) + "\n")
+		default:
+			fmt.Print(line)
+		}
+	}
+}
+
+// init 初始化函数 - 添加修改写回命令
+func init() {
+	initApplyCommands()
+}
+
+// hasRecordedHash 判断回复或上下文中是否有生成时记录的哈希
+func hasRecordedHash(response *apply.Response, context *unpack.Snapshot) bool {
+	for _, change := range response.Changes {
+		if change.Hash != "" {
+			return true
+		}
+	}
+	if context != nil {
+		for _, file := range context.Files {
+			if file.Hash != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+// Package main CLI许可证检测命令
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"code-context-generator/internal/filesystem"
+	"code-context-generator/internal/license"
+	"code-context-generator/internal/utils"
+	"code-context-generator/pkg/types"
+
+	"github.com/spf13/cobra"
+)
+
+// licenseCmd 许可证检测命令
+var licenseCmd = &cobra.Command{
+	Use:   "license [路径]",
+	Short: "检测许可证并生成合规摘要",
+	Long: `检测 LICENSE/COPYING 文件、源文件中的 SPDX-License-Identifier 头以及包清单中的许可证字段，
+按内置SPDX许可证列表分类，报告各目录的许可证、缺少SPDX头的源文件和许可证冲突
+
+支持的清单：package.json, composer.json, Cargo.toml, pyproject.toml, setup.cfg, *.gemspec
+冲突指比所在目录（或项目）许可证更严格的copyleft声明，例如MIT项目中的GPL代码。
+项目许可证默认从根目录识别，可通过配置 license.project_license 指定。`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runLicense,
+}
+
+// initLicenseCommands 初始化许可证检测命令
+func initLicenseCommands() {
+	rootCmd.AddCommand(licenseCmd)
+
+	licenseCmd.Flags().String("format", "text", "报告格式 (text, json)")
+	licenseCmd.Flags().String("output-file", "", "输出报告文件路径")
+	licenseCmd.Flags().String("project-license", "", "项目许可证的SPDX表达式 (默认从根目录识别)")
+	licenseCmd.Flags().StringSliceP("exclude", "e", []string{}, "排除的文件/目录模式")
+	licenseCmd.Flags().Bool("fail-on-conflict", false, "存在许可证冲突时退出码为非零")
+}
+
+// runLicense 执行许可证检测
+func runLicense(cmd *cobra.Command, args []string) error {
+	format, _ := cmd.Flags().GetString("format")
+	outputFile, _ := cmd.Flags().GetString("output-file")
+	projectLicense, _ := cmd.Flags().GetString("project-license")
+	exclude, _ := cmd.Flags().GetStringSlice("exclude")
+	failOnConflict, _ := cmd.Flags().GetBool("fail-on-conflict")
+
+	path := "."
+	if len(args) > 0 {
+		path = args[0]
+	}
+	if format != "text" && format != "json" {
+		return fmt.Errorf("无效的报告格式: %s", format)
+	}
+	if len(exclude) == 0 {
+		exclude = cfg.Filters.ExcludePatterns
+	}
+
+	licenseConfig := cfg.License
+	if projectLicense != "" {
+		licenseConfig.ProjectLicense = projectLicense
+	}
+
+	walker := filesystem.NewFileSystemWalker(types.WalkOptions{})
+	result, err := walker.Walk(path, &types.WalkOptions{
+		MaxDepth:        -1,
+		ExcludePatterns: exclude,
+		ExcludeBinary:   true,
+	})
+	if err != nil {
+		return fmt.Errorf("扫描失败: %w", err)
+	}
+
+	report := license.NewAnalyzer(&licenseConfig).Analyze(license.CollectFiles(result))
+
+	var reportContent []byte
+	if format == "json" {
+		reportContent, err = json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("生成报告失败: %v", err)
+		}
+	} else {
+		reportContent = []byte(license.FormatReport(report))
+	}
+
+	if outputFile != "" {
+		if err := os.WriteFile(outputFile, reportContent, 0644); err != nil {
+			return fmt.Errorf("写入报告文件失败: %v", err)
+		}
+		fmt.Printf("许可证报告已保存到: %s\n", outputFile)
+	} else {
+		fmt.Println(string(reportContent))
+	}
+
+	if failOnConflict && len(report.Conflicts) > 0 {
+		fmt.Println(utils.ErrorColor(fmt.Sprintf("发现 %d 处许可证冲突，退出码为1", len(report.Conflicts))))
+		os.Exit(1)
+	}
+
+	return nil
+}
+
+// init 初始化函数 - 添加许可证检测命令
+func init() {
+	initLicenseCommands()
+}
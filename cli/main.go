@@ -7,11 +7,15 @@ import (
 	"path/filepath"
 	"strings"
 
+	"code-context-generator/internal/chunk"
 	"code-context-generator/internal/config"
 	"code-context-generator/internal/env"
 	"code-context-generator/internal/filesystem"
 	"code-context-generator/internal/formatter"
 	"code-context-generator/internal/git"
+	"code-context-generator/internal/license"
+	"code-context-generator/internal/pipeline"
+	"code-context-generator/internal/sbom"
 	"code-context-generator/internal/utils"
 	"code-context-generator/pkg/security"
 	"code-context-generator/pkg/types"
@@ -33,7 +37,7 @@ var rootCmd = &cobra.Command{
 	Short: "代码上下文生成器",
 	Long: `代码上下文生成器 - 智能生成代码项目结构文档
 
-支持多种输出格式（JSON、XML、TOML、Markdown），提供自动文件扫描，
+支持多种输出格式（JSON、XML、TOML、Markdown、YAML、JSON Lines、纯文本），提供自动文件扫描，
 自动补全功能，以及丰富的配置选项。`,
 	Version: version,
 	Args:    cobra.MaximumNArgs(1), // 接受一个可选的路径参数
@@ -111,7 +115,7 @@ func init() {
 
 	// 根命令的生成标志（与generate命令相同）
 	rootCmd.Flags().StringP("output", "o", "", "输出文件路径")
-	rootCmd.Flags().StringP("format", "f", "json", "输出格式 (json, xml, toml, markdown)")
+	rootCmd.Flags().StringP("format", "f", "json", "输出格式 (json, xml, toml, markdown, yaml, ndjson, plain, template:模板文件)")
 	rootCmd.Flags().StringSliceP("exclude", "e", []string{}, "排除的文件/目录模式")
 	rootCmd.Flags().StringSliceP("include", "i", []string{}, "包含的文件/目录模式")
 	// 注意：recursive 参数已被移除，使用 max-depth 控制递归行为
@@ -125,12 +129,22 @@ func init() {
 	rootCmd.Flags().BoolP("hash", "H", false, "包含文件哈希")
 	rootCmd.Flags().Bool("exclude-binary", true, "排除二进制文件")
 	rootCmd.Flags().String("encoding", "utf-8", "输出文件编码格式")
+	rootCmd.Flags().Bool("bom", false, "在输出文件开头写入字节顺序标记 (仅UTF-8、UTF-16、GB18030)")
+	rootCmd.Flags().String("encoding-errors", "", "无法编码的字符的处理方式 (lossy: 替换为?并提示, strict: 报错)")
 	rootCmd.Flags().StringSliceP("multiple-files", "m", []string{}, "多个文件路径（可多次使用）")
 	rootCmd.Flags().StringP("pattern-file", "p", "", "从文件读取模式（支持.gitignore格式，兼容Windows/Linux路径分隔符）")
+	rootCmd.Flags().Bool("allow-sensitive", false, "允许打包 .env、私钥、kubeconfig 等敏感文件")
+	rootCmd.Flags().Bool("mask-pii", false, "在输出中遮蔽邮箱、电话、银行卡号等个人信息")
+	rootCmd.Flags().Bool("license", false, "检测许可证并在输出中包含合规摘要")
+	rootCmd.Flags().String("sbom", "", "同时生成SBOM到指定文件 (*.spdx.json 为SPDX，其余为CycloneDX)")
+	rootCmd.Flags().Bool("line-numbers", false, "在文件内容的每行前添加行号")
+	rootCmd.Flags().Bool("split-output", false, "将输出拆分为多个分块文件 (如 context-001.xml)，并写入清单")
+	rootCmd.Flags().Int("chunk-tokens", 0, "每个分块的最大token数，与 --split-output 一起使用")
+	rootCmd.Flags().String("chunk-size", "", "每个分块的最大大小 (如 2MB)，与 --split-output 一起使用")
 
 	// generate命令标志（保持向后兼容）
 	generateCmd.Flags().StringP("output", "o", "", "输出文件路径")
-	generateCmd.Flags().StringP("format", "f", "json", "输出格式 (json, xml, toml, markdown)")
+	generateCmd.Flags().StringP("format", "f", "json", "输出格式 (json, xml, toml, markdown, yaml, ndjson, plain, template:模板文件)")
 	generateCmd.Flags().StringSliceP("exclude", "e", []string{}, "排除的文件/目录模式")
 	generateCmd.Flags().StringSliceP("include", "i", []string{}, "包含的文件/目录模式")
 	// 注意：recursive 参数已被移除，使用 max-depth 控制递归行为
@@ -141,8 +155,18 @@ func init() {
 	generateCmd.Flags().BoolP("hash", "H", false, "包含文件哈希")
 	generateCmd.Flags().Bool("exclude-binary", true, "排除二进制文件")
 	generateCmd.Flags().String("encoding", "utf-8", "输出文件编码格式")
+	generateCmd.Flags().Bool("bom", false, "在输出文件开头写入字节顺序标记 (仅UTF-8、UTF-16、GB18030)")
+	generateCmd.Flags().String("encoding-errors", "", "无法编码的字符的处理方式 (lossy: 替换为?并提示, strict: 报错)")
 	generateCmd.Flags().StringSliceP("multiple-files", "m", []string{}, "多个文件路径（可多次使用）")
 	generateCmd.Flags().StringP("pattern-file", "p", "", "从文件读取模式（支持.gitignore格式，兼容Windows/Linux路径分隔符）")
+	generateCmd.Flags().Bool("allow-sensitive", false, "允许打包 .env、私钥、kubeconfig 等敏感文件")
+	generateCmd.Flags().Bool("mask-pii", false, "在输出中遮蔽邮箱、电话、银行卡号等个人信息")
+	generateCmd.Flags().Bool("license", false, "检测许可证并在输出中包含合规摘要")
+	generateCmd.Flags().String("sbom", "", "同时生成SBOM到指定文件 (*.spdx.json 为SPDX，其余为CycloneDX)")
+	generateCmd.Flags().Bool("line-numbers", false, "在文件内容的每行前添加行号")
+	generateCmd.Flags().Bool("split-output", false, "将输出拆分为多个分块文件 (如 context-001.xml)，并写入清单")
+	generateCmd.Flags().Int("chunk-tokens", 0, "每个分块的最大token数，与 --split-output 一起使用")
+	generateCmd.Flags().String("chunk-size", "", "每个分块的最大大小 (如 2MB)，与 --split-output 一起使用")
 
 	// Git集成相关标志
 	generateCmd.Flags().Bool("git-enabled", false, "启用Git集成功能")
@@ -184,8 +208,18 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 	hash, _ := cmd.Flags().GetBool("hash")
 	excludeBinary, _ := cmd.Flags().GetBool("exclude-binary")
 	encoding, _ := cmd.Flags().GetString("encoding")
+	bom, _ := cmd.Flags().GetBool("bom")
+	encodingErrors, _ := cmd.Flags().GetString("encoding-errors")
 	multipleFiles, _ := cmd.Flags().GetStringSlice("multiple-files")
 	patternFile, _ := cmd.Flags().GetString("pattern-file")
+	allowSensitive, _ := cmd.Flags().GetBool("allow-sensitive")
+	maskPII, _ := cmd.Flags().GetBool("mask-pii")
+	licenseEnabled, _ := cmd.Flags().GetBool("license")
+	sbomOutput, _ := cmd.Flags().GetString("sbom")
+	lineNumbers, _ := cmd.Flags().GetBool("line-numbers")
+	splitOutput, _ := cmd.Flags().GetBool("split-output")
+	chunkTokens, _ := cmd.Flags().GetInt("chunk-tokens")
+	chunkSize, _ := cmd.Flags().GetString("chunk-size")
 
 	// Git集成相关标志
 	gitEnabled, _ := cmd.Flags().GetBool("git-enabled")
@@ -245,14 +279,29 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 	if !hidden && cfg.FileProcessing.IncludeHidden {
 		hidden = cfg.FileProcessing.IncludeHidden
 	}
+	if hash {
+		cfg.FileProcessing.IncludeHash = true
+	}
 	if !excludeBinary && cfg.Filters.ExcludeBinary {
 		excludeBinary = cfg.Filters.ExcludeBinary
 	}
+	if !allowSensitive && cfg.Filters.AllowSensitive {
+		allowSensitive = cfg.Filters.AllowSensitive
+	}
+	if licenseEnabled {
+		cfg.License.Enabled = true
+	}
 
 	// 应用编码设置（命令行参数优先）
 	if encoding != "" && encoding != "utf-8" {
 		cfg.Output.Encoding = encoding
 	}
+	if bom {
+		cfg.Output.BOM = true
+	}
+	if encodingErrors != "" {
+		cfg.Output.EncodingErrors = encodingErrors
+	}
 
 	// 合并Git配置（命令行参数优先）
 	if gitEnabled {
@@ -294,10 +343,40 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 		cfg.Output.IncludeMetadata = true
 	}
 
+	// 合并个人信息处理配置
+	if maskPII {
+		cfg.Security.PII.Mode = security.PIIModeMask
+	}
+	if err := security.ValidatePIIConfig(&cfg.Security.PII); err != nil {
+		return err
+	}
+
+	// 合并内容处理配置并创建处理管道
+	if lineNumbers {
+		cfg.Fields.Processing.AddLineNumbers = true
+	}
+	contentPipeline, err := pipeline.New(&cfg.Fields.Processing)
+	if err != nil {
+		return fmt.Errorf("内容处理配置无效: %w", err)
+	}
+
 	// 验证格式
 	if !isValidFormat(format) {
 		return fmt.Errorf("无效的输出格式: %s", format)
 	}
+	if err := formatter.EncodingOptions(cfg, format).Validate(); err != nil {
+		return fmt.Errorf("无效的输出编码设置: %w", err)
+	}
+
+	// 验证分块限制
+	var chunkOptions chunk.Options
+	if splitOutput {
+		opts, err := parseChunkOptions(chunkTokens, chunkSize)
+		if err != nil {
+			return err
+		}
+		chunkOptions = opts
+	}
 
 	// 创建文件系统遍历器
 	walker := filesystem.NewFileSystemWalker(types.WalkOptions{})
@@ -338,7 +417,6 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 	}
 
 	var result *types.ContextData
-	var err error
 
 	if len(multipleFiles) > 0 {
 		// 处理多个指定文件
@@ -352,6 +430,13 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("扫描失败: %w", err)
 	}
 
+	// 排除不应发送给LLM的敏感文件，包含模式也不能重新加入
+	if !allowSensitive {
+		for _, file := range filesystem.RemoveSensitiveFiles(result) {
+			fmt.Println(utils.WarningColor(fmt.Sprintf("⚠ 已排除敏感文件 %s (%s)，使用 --allow-sensitive 可强制包含", file.Path, file.Reason)))
+		}
+	}
+
 	if verbose {
 		fmt.Printf("扫描完成: %d 个文件, %d 个目录\n", result.FileCount, result.FolderCount)
 	}
@@ -361,23 +446,30 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 		fmt.Println(utils.InfoColor("🔍 开始安全扫描..."))
 		securityIntegration := security.NewSecurityIntegration(&cfg.Security)
 
-		// 收集要扫描的文件路径
-		var filesToScan []string
-		for _, file := range result.Files {
-			filesToScan = append(filesToScan, file.Path)
-		}
-		for _, folder := range result.Folders {
-			for _, file := range folder.Files {
-				filesToScan = append(filesToScan, file.Path)
-			}
-		}
-
-		securityReport, err := securityIntegration.ScanFiles(filesToScan)
+		// 直接复用遍历时读取的内容，目录树中的文件只有元信息，不需要扫描
+		securityReport, err := securityIntegration.ScanFiles(result.Files)
 		if err != nil {
 			fmt.Printf("安全扫描失败: %v\n", err)
 		} else {
+			securityReport.Root = path
 			securityIntegration.PrintSummary(securityReport)
 
+			// 将发现的问题嵌入生成的上下文，便于LLM结合代码理解风险
+			securityIntegration.AttachFindings(result, securityReport)
+
+			// 推送扫描结果，推送失败不影响生成；配置了历史目录时与 security 命令一样只推送新增问题
+			if cfg.Security.Reporting.Webhook.URL != "" {
+				var previous *types.SecurityReport
+				if cfg.Security.HistoryDir != "" {
+					previous, err = recordSecurityHistory(cfg.Security.HistoryDir, securityReport)
+				}
+				if err != nil {
+					fmt.Println(utils.WarningColor(fmt.Sprintf("⚠ 读取历史报告失败，跳过Webhook推送: %v", err)))
+				} else if err := sendSecurityWebhook(&cfg.Security.Reporting.Webhook, securityReport, previous); err != nil {
+					fmt.Println(utils.WarningColor(fmt.Sprintf("⚠ Webhook推送失败: %v", err)))
+				}
+			}
+
 			// 如果启用了失败选项且有关键问题，则退出
 			if cfg.Security.FailOnCritical && securityIntegration.HasCriticalIssues(securityReport) {
 				return fmt.Errorf("发现严重安全问题，扫描终止")
@@ -401,6 +493,26 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// 复用遍历结果生成SBOM
+	if sbomOutput != "" {
+		content, err := buildSBOM(path, result, sbom.FormatFromFilename(sbomOutput))
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(sbomOutput, content, 0644); err != nil {
+			return fmt.Errorf("写入SBOM失败: %v", err)
+		}
+		fmt.Println(utils.SuccessColor(fmt.Sprintf("✓ SBOM已生成: %s", sbomOutput)))
+	}
+
+	// 检测许可证
+	if cfg.License.Enabled {
+		result.License = license.NewAnalyzer(&cfg.License).Analyze(license.CollectFiles(result))
+		if len(result.License.Conflicts) > 0 {
+			fmt.Println(utils.WarningColor(fmt.Sprintf("⚠ 发现 %d 处许可证冲突，详见输出中的许可证部分", len(result.License.Conflicts))))
+		}
+	}
+
 	// 执行Git集成
 	if cfg.Git.Enabled {
 		fmt.Println(utils.InfoColor("🔍 开始Git集成分析..."))
@@ -437,12 +549,29 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// 在格式化前统一处理文件内容，对所有输出格式生效
+	contentPipeline.ProcessFiles(result.Files)
+	if verbose && len(contentPipeline.Stages()) > 0 {
+		fmt.Printf("内容处理阶段: %s\n", strings.Join(contentPipeline.Stages(), ", "))
+	}
+
 	// 创建格式化器
-	formatter, err := formatter.NewFormatter(format, cfg)
+	outputFormatter, err := formatter.NewFormatter(format, cfg)
 	if err != nil {
 		return fmt.Errorf("创建格式化器失败: %w", err)
 	}
 
+	// 遮蔽模式下在格式化前替换文件内容中的个人信息
+	var maskingFormatter *formatter.MaskingFormatter
+	if cfg.Security.PII.Mode == security.PIIModeMask {
+		masker, err := security.NewPIIMasker(&cfg.Security.PII)
+		if err != nil {
+			return err
+		}
+		maskingFormatter = formatter.NewMaskingFormatter(outputFormatter, masker)
+		outputFormatter = maskingFormatter
+	}
+
 	// ContextData 已经包含了所有需要的信息
 	// 初始化metadata map并添加根路径
 	if result.Metadata == nil {
@@ -451,14 +580,38 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 	result.Metadata["root_path"] = path
 	contextData := *result
 
+	// 分块输出：每个分块单独格式化并写入文件
+	if splitOutput {
+		target := output
+		if target == "" {
+			target = defaultOutputPath(path, multipleFiles, format)
+		}
+		manifest, err := writeSplitOutput(outputFormatter, contextData, chunkOptions, target, format)
+		if err != nil {
+			return err
+		}
+		if maskingFormatter != nil && maskingFormatter.Masked() > 0 {
+			fmt.Println(utils.WarningColor(fmt.Sprintf("⚠ 已遮蔽 %d 处个人信息", maskingFormatter.Masked())))
+		}
+		fmt.Println(utils.SuccessColor(fmt.Sprintf("✅ 成功生成 %d 个分块文件，清单:", len(manifest.Chunks))), chunk.ManifestName(target))
+		for _, entry := range manifest.Chunks {
+			fmt.Printf("  %s: %d 个文件，约 %d tokens\n", entry.File, len(entry.Files), entry.Tokens)
+		}
+		return nil
+	}
+
 	// 格式化输出
-	outputData, err := formatter.Format(contextData)
+	outputData, err := outputFormatter.Format(contextData)
 	if err != nil {
 		return fmt.Errorf("格式化输出失败: %w", err)
 	}
+	if maskingFormatter != nil && maskingFormatter.Masked() > 0 {
+		fmt.Println(utils.WarningColor(fmt.Sprintf("⚠ 已遮蔽 %d 处个人信息", maskingFormatter.Masked())))
+	}
 
 	// 添加额外信息
-	if content || hash {
+	// 哈希在遍历时写入文件信息，这里只处理文件内容
+	if content {
 		// 创建 WalkResult 用于 addFileContent
 		walkResult := &types.WalkResult{
 			Files:       result.Files,
@@ -468,15 +621,14 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 			TotalSize:   result.TotalSize,
 			RootPath:    path,
 		}
-		outputData = addFileContent(outputData, walkResult, content, hash)
+		outputData = addFileContent(outputData, walkResult, content, false)
 	}
 
 	// 输出结果 - 默认写入文件，控制台输出仅在明确指定时
 	if output != "" {
 		// 使用指定的输出文件
-		// 标准化换行符为当前操作系统格式
-		normalizedData := utils.NormalizeLineEndings(outputData)
-		if err := os.WriteFile(output, []byte(normalizedData), 0644); err != nil {
+		// 标准化换行符为当前操作系统格式，并转换为输出编码
+		if err := writeOutputFile(output, outputData, format); err != nil {
 			return fmt.Errorf("写入输出文件失败: %w", err)
 		}
 		if verbose {
@@ -484,26 +636,10 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 		}
 	} else {
 		// 自动生成默认输出文件名
-		var defaultOutput string
-		if len(multipleFiles) > 0 {
-			// 使用第一个文件名作为基础名称
-			baseName := filepath.Base(multipleFiles[0])
-			ext := filepath.Ext(baseName)
-			baseName = strings.TrimSuffix(baseName, ext)
-			defaultOutput = fmt.Sprintf("context_%s.%s", baseName, format)
-			if format == "markdown" {
-				defaultOutput = fmt.Sprintf("context_%s.md", baseName)
-			}
-		} else {
-			defaultOutput = fmt.Sprintf("context_%s.%s", filepath.Base(path), format)
-			if format == "markdown" {
-				defaultOutput = fmt.Sprintf("context_%s.md", filepath.Base(path))
-			}
-		}
+		defaultOutput := defaultOutputPath(path, multipleFiles, format)
 
-		// 标准化换行符为当前操作系统格式
-		normalizedData := utils.NormalizeLineEndings(outputData)
-		if err := os.WriteFile(defaultOutput, []byte(normalizedData), 0644); err != nil {
+		// 标准化换行符为当前操作系统格式，并转换为输出编码
+		if err := writeOutputFile(defaultOutput, outputData, format); err != nil {
 			return fmt.Errorf("写入默认输出文件失败: %w", err)
 		}
 		fmt.Println(utils.SuccessColor("✅ 成功生成代码上下文文件:"), defaultOutput)
@@ -564,7 +700,10 @@ func runConfigShow(cmd *cobra.Command, args []string) error {
 
 // isValidFormat 检查格式是否有效
 func isValidFormat(format string) bool {
-	validFormats := []string{"json", "xml", "toml", "markdown", "md"}
+	if strings.HasPrefix(format, formatter.TemplateFormatPrefix) {
+		return len(format) > len(formatter.TemplateFormatPrefix)
+	}
+	validFormats := []string{"json", "xml", "toml", "markdown", "md", "yaml", "yml", "ndjson", "jsonl", "plain", "txt"}
 	for _, valid := range validFormats {
 		if format == valid {
 			return true
@@ -573,6 +712,25 @@ func isValidFormat(format string) bool {
 	return false
 }
 
+// outputExtension 返回格式对应的默认输出文件扩展名，模板格式使用模板文件名中 .tmpl 之前的扩展名
+func outputExtension(format string) string {
+	if strings.HasPrefix(format, formatter.TemplateFormatPrefix) {
+		ext := filepath.Ext(strings.TrimSuffix(filepath.Base(format), ".tmpl"))
+		if ext == "" {
+			return "txt"
+		}
+		return ext[1:]
+	}
+	switch format {
+	case "markdown":
+		return "md"
+	case "plain":
+		return "txt"
+	default:
+		return format
+	}
+}
+
 // addFileContent 添加文件内容
 func addFileContent(outputData string, _ *types.WalkResult, includeContent, includeHash bool) string {
 	// 如果不需要包含内容和哈希，直接返回原始数据
@@ -640,6 +798,7 @@ func generateConfigOutput(cfg *types.Config) string {
 	output.WriteString(fmt.Sprintf("  最大深度: %d\n", cfg.Filters.MaxDepth))
 	output.WriteString(fmt.Sprintf("  跟随符号链接: %v\n", cfg.Filters.FollowSymlinks))
 	output.WriteString(fmt.Sprintf("  排除二进制文件: %v\n", cfg.Filters.ExcludeBinary))
+	output.WriteString(fmt.Sprintf("  允许敏感文件: %v\n", cfg.Filters.AllowSensitive))
 
 	if len(cfg.Filters.ExcludePatterns) > 0 {
 		output.WriteString("  排除模式:\n")
@@ -0,0 +1,92 @@
+// Package main CLI软件物料清单命令
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"code-context-generator/internal/filesystem"
+	"code-context-generator/internal/sbom"
+	"code-context-generator/pkg/types"
+
+	"github.com/spf13/cobra"
+)
+
+// sbomCmd SBOM生成命令
+var sbomCmd = &cobra.Command{
+	Use:   "sbom [路径]",
+	Short: "生成CycloneDX或SPDX格式的软件物料清单",
+	Long: `遍历项目中的依赖清单和锁文件，生成包含组件名称、版本、purl、哈希和依赖关系图的SBOM
+
+支持的文件：go.mod（哈希取自 go.sum）, package-lock.json, poetry.lock（项目信息取自 pyproject.toml）,
+Cargo.lock, requirements.txt
+输出格式：CycloneDX 1.5 JSON（默认）、SPDX 2.3 JSON`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runSBOM,
+}
+
+// initSBOMCommands 初始化SBOM命令
+func initSBOMCommands() {
+	rootCmd.AddCommand(sbomCmd)
+
+	sbomCmd.Flags().String("format", sbom.FormatCycloneDX, "SBOM格式 (cyclonedx, spdx)")
+	sbomCmd.Flags().StringP("output", "o", "", "输出文件路径 (默认输出到标准输出)")
+	sbomCmd.Flags().StringSliceP("exclude", "e", []string{}, "排除的文件/目录模式")
+}
+
+// runSBOM 执行SBOM生成
+func runSBOM(cmd *cobra.Command, args []string) error {
+	format, _ := cmd.Flags().GetString("format")
+	output, _ := cmd.Flags().GetString("output")
+	exclude, _ := cmd.Flags().GetStringSlice("exclude")
+
+	path := "."
+	if len(args) > 0 {
+		path = args[0]
+	}
+	if len(exclude) == 0 {
+		exclude = cfg.Filters.ExcludePatterns
+	}
+
+	walker := filesystem.NewFileSystemWalker(types.WalkOptions{})
+	result, err := walker.Walk(path, &types.WalkOptions{
+		MaxDepth:        -1,
+		ExcludePatterns: exclude,
+		ExcludeBinary:   true,
+	})
+	if err != nil {
+		return fmt.Errorf("扫描失败: %w", err)
+	}
+
+	content, err := buildSBOM(path, result, format)
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		fmt.Println(string(content))
+		return nil
+	}
+	if err := os.WriteFile(output, content, 0644); err != nil {
+		return fmt.Errorf("写入SBOM失败: %v", err)
+	}
+	fmt.Printf("SBOM已保存到: %s\n", output)
+	return nil
+}
+
+// buildSBOM 根据遍历结果生成指定格式的SBOM
+func buildSBOM(root string, result *types.ContextData, format string) ([]byte, error) {
+	bom, err := sbom.Build(root, result.Files)
+	if err != nil {
+		return nil, fmt.Errorf("生成SBOM失败: %v", err)
+	}
+	if verbose {
+		fmt.Printf("SBOM包含 %d 个组件\n", len(bom.Components))
+	}
+	return sbom.Format(bom, format)
+}
+
+// init 初始化函数 - 添加SBOM命令
+func init() {
+	initSBOMCommands()
+}
@@ -4,9 +4,11 @@ package main
 import (
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 
 	"code-context-generator/internal/config"
+	"code-context-generator/internal/utils"
 	"code-context-generator/pkg/security"
 	"code-context-generator/pkg/types"
 
@@ -24,9 +26,19 @@ var securityCmd = &cobra.Command{
 - SQL注入漏洞
 - XSS漏洞
 - 路径遍历漏洞
+- Dockerfile、Kubernetes、docker-compose、Terraform 配置问题
+- 个人信息（邮箱、电话、银行卡号、IBAN、IP地址、身份证号）
 - 代码质量问题
 
-支持多种编程语言：Go, Python, JavaScript, Java, PHP, Ruby等。`,
+扫描级别：
+- basic: 硬编码凭证及高置信度的Go漏洞检测
+- standard: 增加SQL注入、XSS、路径遍历、弱加密、IaC配置、个人信息等检测（默认）
+- comprehensive: 增加代码质量检测
+
+支持多种编程语言：Go, Python, JavaScript, Java, PHP, Ruby等。
+
+使用 --report-format html 生成可直接在浏览器中打开的单文件报告，
+包含严重性筛选、按文件分组的问题及其附近源码。`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runSecurityScan,
 }
@@ -54,6 +66,46 @@ var securityConfigInitCmd = &cobra.Command{
 	RunE:  runSecurityConfigInit,
 }
 
+// securityDepsCmd 依赖漏洞审计命令
+var securityDepsCmd = &cobra.Command{
+	Use:   "deps [路径]",
+	Short: "审计依赖中的已知漏洞",
+	Long: `解析依赖清单并与本地OSV公告库比对，报告受影响的版本和修复版本
+
+支持的清单：go.mod, go.sum, package-lock.json, requirements.txt, Cargo.lock, pom.xml
+公告库可以是OSV JSON目录、.zip 或 .tar.gz 归档，审计过程不访问网络。`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runSecurityDeps,
+}
+
+// securityCompareCmd 安全报告对比命令
+var securityCompareCmd = &cobra.Command{
+	Use:   "compare <旧报告> <新报告>",
+	Short: "对比两次安全扫描报告",
+	Long: `按稳定指纹（规则 + 文件 + 归一化代码片段）对比两份JSON格式的安全报告，
+将问题分为新增、已修复和未变化，并显示各严重级别数量的变化
+
+报告可通过 --report-format json 生成，或使用 --history-dir 自动保存。`,
+	Args: cobra.ExactArgs(2),
+	RunE: runSecurityCompare,
+}
+
+// securityFixCmd 硬编码凭证修复命令
+var securityFixCmd = &cobra.Command{
+	Use:   "fix [路径]",
+	Short: "将硬编码凭证改写为读取环境变量",
+	Long: `扫描硬编码凭证，将字符串字面量替换为对应语言的环境变量读取：
+- Go: os.Getenv("KEY")
+- Python: os.environ["KEY"]
+- JavaScript/TypeScript: process.env.KEY
+- Java: System.getenv("KEY")，PHP: getenv('KEY')，Ruby: ENV["KEY"]
+
+新的变量以占位值追加到项目根目录的 .env.example，所有修改以统一diff输出。
+默认只预览（--dry-run），使用 --write 写入文件。`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runSecurityFix,
+}
+
 // initSecurityCommands 初始化安全扫描命令
 func initSecurityCommands() {
 	// 添加安全扫描命令
@@ -63,6 +115,23 @@ func initSecurityCommands() {
 	securityCmd.AddCommand(securityConfigCmd)
 	securityConfigCmd.AddCommand(securityConfigShowCmd)
 	securityConfigCmd.AddCommand(securityConfigInitCmd)
+	securityCmd.AddCommand(securityDepsCmd)
+	securityCmd.AddCommand(securityCompareCmd)
+	securityCmd.AddCommand(securityFixCmd)
+
+	// 凭证修复命令标志
+	securityFixCmd.Flags().Bool("dry-run", false, "只输出diff，不修改文件（默认）")
+	securityFixCmd.Flags().Bool("write", false, "将修复写入文件")
+	securityFixCmd.Flags().StringSlice("exclude-patterns", []string{}, "排除的文件模式")
+
+	// 报告对比命令标志
+	securityCompareCmd.Flags().Bool("show-unchanged", false, "列出未变化的问题")
+	securityCompareCmd.Flags().Bool("fail-on-new", false, "存在新增问题时退出码为非零")
+
+	// 依赖审计命令标志
+	securityDepsCmd.Flags().String("advisory-db", "", "OSV公告库目录或归档 (默认使用配置中的 advisory_db)")
+	securityDepsCmd.Flags().String("output-file", "", "输出报告文件路径")
+	securityDepsCmd.Flags().Bool("fail-on-critical", false, "发现严重问题时退出码为非零")
 
 	// 安全扫描命令标志
 	securityCmd.Flags().Bool("enabled", true, "启用安全扫描")
@@ -70,8 +139,15 @@ func initSecurityCommands() {
 	securityCmd.Flags().String("scan-level", "standard", "扫描级别 (basic, standard, comprehensive)")
 	securityCmd.Flags().String("report-format", "text", "报告格式 (text, json, xml, html)")
 	securityCmd.Flags().String("output-file", "", "输出报告文件路径")
+	securityCmd.Flags().Int("context-lines", 3, "HTML报告中问题前后显示的源码行数")
 	securityCmd.Flags().Bool("include-details", true, "包含详细问题信息")
 	securityCmd.Flags().Bool("show-statistics", true, "显示扫描统计信息")
+	securityCmd.Flags().Int("workers", 0, "并发扫描worker数量 (0表示使用CPU核数)")
+	securityCmd.Flags().Bool("history", false, "扫描Git历史中新增的凭证（包括已删除的凭证）")
+	securityCmd.Flags().String("since-ref", "", "历史扫描只检查该引用之后的提交")
+	securityCmd.Flags().String("history-dir", "", "保存报告的历史目录，并与上一次扫描对比 (默认使用配置中的 history_dir)")
+	securityCmd.Flags().String("webhook-url", "", "扫描完成后推送结果的Webhook地址 (默认使用配置中的 reporting.webhook.url)")
+	securityCmd.Flags().Bool("webhook-dry-run", false, "只输出Webhook请求内容，不实际发送")
 
 	// 检测器配置标志
 	securityCmd.Flags().Bool("detect-credentials", true, "检测硬编码凭证")
@@ -79,6 +155,8 @@ func initSecurityCommands() {
 	securityCmd.Flags().Bool("detect-xss", true, "检测XSS漏洞")
 	securityCmd.Flags().Bool("detect-path-traversal", true, "检测路径遍历漏洞")
 	securityCmd.Flags().Bool("detect-quality", true, "检测代码质量问题")
+	securityCmd.Flags().Bool("detect-pii", true, "检测个人信息（邮箱、电话、银行卡号、IBAN、IP地址、身份证号）")
+	securityCmd.Flags().StringSlice("pii-types", []string{}, "只检测指定的个人信息类型 (email, phone, credit_card, iban, ip_address, cn_id)")
 
 	// 排除配置标志
 	securityCmd.Flags().StringSlice("exclude-files", []string{}, "排除的文件列表")
@@ -93,14 +171,23 @@ func runSecurityScan(cmd *cobra.Command, args []string) error {
 	scanLevelStr, _ := cmd.Flags().GetString("scan-level")
 	reportFormat, _ := cmd.Flags().GetString("report-format")
 	outputFile, _ := cmd.Flags().GetString("output-file")
+	contextLines, _ := cmd.Flags().GetInt("context-lines")
 	includeDetails, _ := cmd.Flags().GetBool("include-details")
 	showStatistics, _ := cmd.Flags().GetBool("show-statistics")
+	workers, _ := cmd.Flags().GetInt("workers")
+	history, _ := cmd.Flags().GetBool("history")
+	sinceRef, _ := cmd.Flags().GetString("since-ref")
+	historyDir, _ := cmd.Flags().GetString("history-dir")
+	webhookURL, _ := cmd.Flags().GetString("webhook-url")
+	webhookDryRun, _ := cmd.Flags().GetBool("webhook-dry-run")
 
 	detectCredentials, _ := cmd.Flags().GetBool("detect-credentials")
 	detectSQLInjection, _ := cmd.Flags().GetBool("detect-sql-injection")
 	detectXSS, _ := cmd.Flags().GetBool("detect-xss")
 	detectPathTraversal, _ := cmd.Flags().GetBool("detect-path-traversal")
 	detectQuality, _ := cmd.Flags().GetBool("detect-quality")
+	detectPII, _ := cmd.Flags().GetBool("detect-pii")
+	piiTypes, _ := cmd.Flags().GetStringSlice("pii-types")
 
 	excludeFiles, _ := cmd.Flags().GetStringSlice("exclude-files")
 	excludePatterns, _ := cmd.Flags().GetStringSlice("exclude-patterns")
@@ -116,21 +203,63 @@ func runSecurityScan(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("路径不存在: %v", err)
 	}
 
-	// 创建安全配置
+	if sinceRef != "" && !history {
+		return fmt.Errorf("--since-ref 需要与 --history 一起使用")
+	}
+
+	// 未在命令行指定的选项使用配置文件中的值
+	flags := cmd.Flags()
+	if !flags.Changed("scan-level") && cfg.Security.ScanLevel != "" {
+		scanLevelStr = cfg.Security.ScanLevel
+	}
+	if !flags.Changed("report-format") && cfg.Security.Reporting.Format != "" {
+		reportFormat = cfg.Security.Reporting.Format
+	}
+	if !flags.Changed("output-file") && cfg.Security.Reporting.OutputFile != "" {
+		outputFile = cfg.Security.Reporting.OutputFile
+	}
+	if !flags.Changed("context-lines") && cfg.Security.Reporting.ContextLines > 0 {
+		contextLines = cfg.Security.Reporting.ContextLines
+	}
+	if !flags.Changed("workers") && cfg.Security.MaxWorkers > 0 {
+		workers = cfg.Security.MaxWorkers
+	}
+	if !flags.Changed("pii-types") && len(cfg.Security.PII.Types) > 0 {
+		piiTypes = cfg.Security.PII.Types
+	}
+	failOnCritical = failOnCritical || cfg.Security.FailOnCritical
+
+	// 创建安全配置，保留配置文件中的最低严重性、个人信息模式和排除规则
 	securityConfig := createSecurityConfig(
-		enabled, failOnCritical, scanLevelStr, reportFormat, outputFile,
+		cfg.Security, enabled, failOnCritical, scanLevelStr, reportFormat, outputFile,
 		includeDetails, showStatistics, detectCredentials, detectSQLInjection,
-		detectXSS, detectPathTraversal, detectQuality, excludeFiles, excludePatterns,
+		detectXSS, detectPathTraversal, detectQuality, detectPII, excludeFiles, excludePatterns,
 	)
+	securityConfig.MaxWorkers = workers
+	securityConfig.Reporting.ContextLines = contextLines
+	securityConfig.PII.Types = piiTypes
 
 	// 创建安全管理器
 	manager := security.NewSecurityManager(securityConfig)
 
-	// 执行扫描
-	fmt.Println("开始安全扫描...")
-	report, err := manager.RunScan(path)
-	if err != nil {
-		return fmt.Errorf("安全扫描失败: %v", err)
+	var report *types.SecurityReport
+	var err error
+	if history {
+		// 扫描Git历史
+		fmt.Println("开始扫描Git历史...")
+		result, err := security.NewHistoryScanner(securityConfig).Scan(path, sinceRef)
+		if err != nil {
+			return fmt.Errorf("历史扫描失败: %v", err)
+		}
+		fmt.Printf("已扫描 %d 个提交\n", result.Commits)
+		report = result.Report
+	} else {
+		// 执行扫描
+		fmt.Println("开始安全扫描...")
+		report, err = manager.RunScan(path)
+		if err != nil {
+			return fmt.Errorf("安全扫描失败: %v", err)
+		}
 	}
 
 	// 生成报告
@@ -152,6 +281,32 @@ func runSecurityScan(cmd *cobra.Command, args []string) error {
 		fmt.Println(string(reportContent))
 	}
 
+	// 保存到历史目录并与上一次扫描对比
+	if historyDir == "" {
+		historyDir = cfg.Security.HistoryDir
+	}
+	var previous *types.SecurityReport
+	if historyDir != "" {
+		previous, err = recordSecurityHistory(historyDir, report)
+		if err != nil {
+			return err
+		}
+	}
+
+	// 推送扫描结果
+	webhook := cfg.Security.Reporting.Webhook
+	if webhookURL != "" {
+		webhook.URL = webhookURL
+	}
+	if webhookDryRun {
+		webhook.DryRun = true
+	}
+	if webhook.URL != "" {
+		if err := sendSecurityWebhook(&webhook, report, previous); err != nil {
+			return err
+		}
+	}
+
 	// 检查是否需要因严重问题而退出
 	if failOnCritical && report.Summary.CriticalIssues > 0 {
 		fmt.Printf("发现 %d 个严重问题，退出码为1\n", report.Summary.CriticalIssues)
@@ -161,48 +316,226 @@ func runSecurityScan(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-// createSecurityConfig 创建安全配置
+// recordSecurityHistory 保存报告，输出与上一次扫描的对比和问题趋势，返回上一次扫描的报告
+func recordSecurityHistory(historyDir string, report *types.SecurityReport) (*types.SecurityReport, error) {
+	history := security.NewReportHistory(historyDir)
+	previous, err := history.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	savedPath, err := history.Save(report)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("报告已保存到历史目录: %s\n", savedPath)
+
+	var last *types.SecurityReport
+	if len(previous) > 0 {
+		last = previous[len(previous)-1]
+		comparison := security.CompareReports(last, report)
+		fmt.Printf("与上一次扫描相比: 新增 %d，已修复 %d，未变化 %d\n",
+			len(comparison.Added), len(comparison.Fixed), len(comparison.Unchanged))
+	}
+
+	fmt.Println()
+	fmt.Print(string(security.FormatTrend(security.BuildTrend(append(previous, report)))))
+	return last, nil
+}
+
+// sendSecurityWebhook 推送新增的严重和高危问题，试运行时输出请求内容
+func sendSecurityWebhook(config *types.WebhookConfig, report, previous *types.SecurityReport) error {
+	payload := security.BuildWebhookPayload(report, previous)
+	result, err := security.NewWebhookNotifier(config).Send(payload)
+	if err != nil {
+		return err
+	}
+
+	if result.DryRun {
+		fmt.Printf("Webhook试运行，将向 %s 发送:\n", config.URL)
+		keys := make([]string, 0, len(result.Headers))
+		for key := range result.Headers {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Printf("%s: %s\n", key, strings.Join(result.Headers[key], ", "))
+		}
+		fmt.Println(string(result.Body))
+		return nil
+	}
+	fmt.Printf("已推送 %d 个新增问题到Webhook (状态 %d)\n", len(payload.NewIssues), result.StatusCode)
+	return nil
+}
+
+// runSecurityCompare 对比两次安全扫描报告
+func runSecurityCompare(cmd *cobra.Command, args []string) error {
+	showUnchanged, _ := cmd.Flags().GetBool("show-unchanged")
+	failOnNew, _ := cmd.Flags().GetBool("fail-on-new")
+
+	oldReport, err := security.LoadReport(args[0])
+	if err != nil {
+		return err
+	}
+	newReport, err := security.LoadReport(args[1])
+	if err != nil {
+		return err
+	}
+
+	comparison := security.CompareReports(oldReport, newReport)
+	fmt.Print(string(security.FormatComparison(comparison, showUnchanged)))
+
+	if failOnNew && len(comparison.Added) > 0 {
+		fmt.Printf("发现 %d 个新增问题，退出码为1\n", len(comparison.Added))
+		os.Exit(1)
+	}
+
+	return nil
+}
+
+// runSecurityFix 修复硬编码凭证
+func runSecurityFix(cmd *cobra.Command, args []string) error {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	write, _ := cmd.Flags().GetBool("write")
+	excludePatterns, _ := cmd.Flags().GetStringSlice("exclude-patterns")
+
+	if dryRun && write {
+		return fmt.Errorf("--dry-run 和 --write 不能同时使用")
+	}
+
+	path := "."
+	if len(args) > 0 {
+		path = args[0]
+	}
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("路径不存在: %v", err)
+	}
+
+	// 只运行凭证检测器
+	securityConfig := &types.SecurityConfig{
+		Enabled:    true,
+		ScanLevel:  "basic",
+		Detectors:  types.DetectorConfig{Credentials: true},
+		Exclusions: types.ExclusionConfig{Patterns: excludePatterns},
+	}
+	report, err := security.NewSecurityManager(securityConfig).RunScan(path)
+	if err != nil {
+		return fmt.Errorf("安全扫描失败: %v", err)
+	}
+
+	result, err := security.NewCredentialFixer(path).Plan(report.Issues)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(result.Diff)
+	for _, skipped := range result.Skipped {
+		fmt.Println(utils.WarningColor(fmt.Sprintf("⚠ 跳过 %s:%d (%s)", skipped.File, skipped.Line, skipped.Reason)))
+	}
+	if len(result.Fixes) == 0 {
+		fmt.Println("没有可自动修复的硬编码凭证")
+		return nil
+	}
+
+	if !write {
+		fmt.Printf("\n可修复 %d 处硬编码凭证，使用 --write 写入文件\n", len(result.Fixes))
+		return nil
+	}
+	if err := result.Apply(); err != nil {
+		return err
+	}
+	fmt.Println(utils.SuccessColor(fmt.Sprintf("已修复 %d 处硬编码凭证，新的环境变量已添加到 %s", len(result.Fixes), result.EnvExample)))
+	return nil
+}
+
+// runSecurityDeps 运行依赖漏洞审计
+func runSecurityDeps(cmd *cobra.Command, args []string) error {
+	advisoryDB, _ := cmd.Flags().GetString("advisory-db")
+	outputFile, _ := cmd.Flags().GetString("output-file")
+	failOnCritical, _ := cmd.Flags().GetBool("fail-on-critical")
+
+	if advisoryDB == "" {
+		advisoryDB = cfg.Security.AdvisoryDB
+	}
+	if advisoryDB == "" {
+		return fmt.Errorf("未指定公告库，请使用 --advisory-db 或在配置中设置 security.advisory_db")
+	}
+
+	path := "."
+	if len(args) > 0 {
+		path = args[0]
+	}
+
+	db, err := security.LoadAdvisoryDatabase(advisoryDB)
+	if err != nil {
+		return err
+	}
+	if verbose {
+		fmt.Printf("已加载 %d 条公告\n", db.Count())
+	}
+
+	fmt.Println("开始依赖审计...")
+	report, err := security.NewDependencyAuditor(db, &cfg.Security).Audit(path)
+	if err != nil {
+		return fmt.Errorf("依赖审计失败: %v", err)
+	}
+
+	reportContent, err := security.NewSecurityReporter().Generate(report)
+	if err != nil {
+		return fmt.Errorf("生成报告失败: %v", err)
+	}
+
+	if outputFile != "" {
+		if err := os.WriteFile(outputFile, reportContent, 0644); err != nil {
+			return fmt.Errorf("写入报告文件失败: %v", err)
+		}
+		fmt.Printf("依赖审计报告已保存到: %s\n", outputFile)
+	} else {
+		fmt.Println(string(reportContent))
+	}
+
+	if failOnCritical && report.Summary.CriticalIssues > 0 {
+		fmt.Printf("发现 %d 个严重问题，退出码为1\n", report.Summary.CriticalIssues)
+		os.Exit(1)
+	}
+
+	return nil
+}
+
+// createSecurityConfig 以配置文件中的安全配置为基础，用命令行选项覆盖
 func createSecurityConfig(
+	base types.SecurityConfig,
 	enabled, failOnCritical bool,
 	scanLevelStr, reportFormat, outputFile string,
 	includeDetails, showStatistics bool,
-	detectCredentials, detectSQLInjection, detectXSS, detectPathTraversal, detectQuality bool,
+	detectCredentials, detectSQLInjection, detectXSS, detectPathTraversal, detectQuality, detectPII bool,
 	excludeFiles, excludePatterns []string,
 ) *types.SecurityConfig {
-	// 扫描级别字符串直接使用
+	config := base
+	config.Enabled = enabled
+	config.FailOnCritical = failOnCritical
+	config.ScanLevel = scanLevelStr
 
-	// 创建检测器配置
-	detectorConfig := types.DetectorConfig{
+	// 检测器开关以命令行为准，security 命令默认启用全部检测器
+	config.Detectors = types.DetectorConfig{
 		Credentials:   detectCredentials,
 		SQLInjection:  detectSQLInjection,
 		XSS:           detectXSS,
 		PathTraversal: detectPathTraversal,
 		Quality:       detectQuality,
+		PII:           detectPII,
 	}
 
-	// 创建排除配置
-	exclusionConfig := types.ExclusionConfig{
-		Files:    excludeFiles,
-		Patterns: excludePatterns,
-		Rules:    []types.ExclusionRule{},
-	}
+	// 命令行的排除项追加到配置文件的排除项之后
+	config.Exclusions.Files = append(append([]string{}, base.Exclusions.Files...), excludeFiles...)
+	config.Exclusions.Patterns = append(append([]string{}, base.Exclusions.Patterns...), excludePatterns...)
 
-	// 创建报告配置
-	reportingConfig := types.ReportingConfig{
-		Format:         reportFormat,
-		OutputFile:     outputFile,
-		IncludeDetails: includeDetails,
-		ShowStatistics: showStatistics,
-	}
+	config.Reporting.Format = reportFormat
+	config.Reporting.OutputFile = outputFile
+	config.Reporting.IncludeDetails = includeDetails
+	config.Reporting.ShowStatistics = showStatistics
 
-	return &types.SecurityConfig{
-		Enabled:        enabled,
-		FailOnCritical: failOnCritical,
-		ScanLevel:      scanLevelStr,
-		Detectors:      detectorConfig,
-		Exclusions:     exclusionConfig,
-		Reporting:      reportingConfig,
-	}
+	return &config
 }
 
 // runSecurityConfigShow 显示安全配置
@@ -211,6 +544,7 @@ func runSecurityConfigShow(cmd *cobra.Command, args []string) error {
 	fmt.Printf("启用安全扫描: %v\n", cfg.Security.Enabled)
 	fmt.Printf("发现严重问题时退出: %v\n", cfg.Security.FailOnCritical)
 	fmt.Printf("扫描级别: %v\n", cfg.Security.ScanLevel)
+	fmt.Printf("历史报告目录: %s\n", cfg.Security.HistoryDir)
 
 	fmt.Println("\n检测器配置:")
 	fmt.Printf("硬编码凭证检测: %v\n", cfg.Security.Detectors.Credentials)
@@ -218,12 +552,27 @@ func runSecurityConfigShow(cmd *cobra.Command, args []string) error {
 	fmt.Printf("XSS漏洞检测: %v\n", cfg.Security.Detectors.XSS)
 	fmt.Printf("路径遍历检测: %v\n", cfg.Security.Detectors.PathTraversal)
 	fmt.Printf("代码质量检测: %v\n", cfg.Security.Detectors.Quality)
+	fmt.Printf("个人信息检测: %v\n", cfg.Security.Detectors.PII)
+
+	fmt.Println("\n最低严重性:")
+	fmt.Printf("硬编码凭证: %s\n", cfg.Security.Credentials.SeverityThreshold)
+	fmt.Printf("安全漏洞: %s\n", cfg.Security.Vulnerability.SeverityThreshold)
+	fmt.Printf("代码质量: %s\n", cfg.Security.Quality.SeverityThreshold)
+	fmt.Printf("个人信息: %s\n", cfg.Security.PII.SeverityThreshold)
+
+	fmt.Println("\n个人信息配置:")
+	fmt.Printf("处理模式: %s\n", cfg.Security.PII.Mode)
+	fmt.Printf("检测类型: %v\n", cfg.Security.PII.Types)
 
 	fmt.Println("\n报告配置:")
 	fmt.Printf("报告格式: %s\n", cfg.Security.Reporting.Format)
 	fmt.Printf("输出文件: %s\n", cfg.Security.Reporting.OutputFile)
 	fmt.Printf("包含详细信息: %v\n", cfg.Security.Reporting.IncludeDetails)
 	fmt.Printf("显示统计信息: %v\n", cfg.Security.Reporting.ShowStatistics)
+	fmt.Printf("源码上下文行数: %d\n", cfg.Security.Reporting.ContextLines)
+	fmt.Printf("Webhook地址: %s\n", cfg.Security.Reporting.Webhook.URL)
+	fmt.Printf("Webhook签名密钥环境变量: %s\n", cfg.Security.Reporting.Webhook.SecretEnv)
+	fmt.Printf("Webhook试运行: %v\n", cfg.Security.Reporting.Webhook.DryRun)
 
 	return nil
 }
@@ -241,6 +590,10 @@ func runSecurityConfigInit(cmd *cobra.Command, args []string) error {
 			XSS:           true,
 			PathTraversal: true,
 			Quality:       true,
+			PII:           true,
+		},
+		PII: types.PIIConfig{
+			Mode: "report",
 		},
 		Exclusions: types.ExclusionConfig{
 			Files:    []string{},
@@ -0,0 +1,132 @@
+// Package main CLI分块输出
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"code-context-generator/internal/chunk"
+	"code-context-generator/internal/env"
+	"code-context-generator/internal/formatter"
+	"code-context-generator/internal/formatter/encoding"
+	"code-context-generator/internal/utils"
+	"code-context-generator/pkg/types"
+)
+
+// parseChunkOptions 解析分块限制，--chunk-size 支持 2MB、512KB 等写法
+func parseChunkOptions(chunkTokens int, chunkSize string) (chunk.Options, error) {
+	opts := chunk.Options{MaxTokens: chunkTokens}
+	if chunkTokens < 0 {
+		return opts, fmt.Errorf("无效的分块token数: %d", chunkTokens)
+	}
+	if chunkSize != "" {
+		opts.MaxBytes = env.ParseFileSize(chunkSize)
+		if opts.MaxBytes <= 0 {
+			return opts, fmt.Errorf("无效的分块大小: %s", chunkSize)
+		}
+	}
+	if opts.Limit() <= 0 {
+		return opts, fmt.Errorf("使用 --split-output 时需要指定 --chunk-tokens 或 --chunk-size")
+	}
+	return opts, nil
+}
+
+// writeSplitOutput 将上下文拆分为多个分块分别格式化写出，并在旁边写入清单
+func writeSplitOutput(outputFormatter formatter.Formatter, data types.ContextData, opts chunk.Options, output, format string) (*types.ChunkManifest, error) {
+	// 按实际写出的字节数测量分块，遮蔽格式转换器使用单独计数的副本，避免重复统计
+	measureFormatter := outputFormatter
+	if masking, ok := outputFormatter.(*formatter.MaskingFormatter); ok {
+		measureFormatter = masking.Fork()
+	}
+	opts.Measure = func(chunkData types.ContextData) (int64, error) {
+		outputData, err := measureFormatter.Format(chunkData)
+		if err != nil {
+			return 0, err
+		}
+		result, err := encodeOutput(outputData, format)
+		if err != nil {
+			return 0, err
+		}
+		return int64(len(result.Data)), nil
+	}
+
+	chunks, manifest, err := chunk.Split(data, opts, output)
+	if err != nil {
+		return nil, err
+	}
+	manifest.Format = format
+
+	for i, chunkData := range chunks {
+		outputData, err := outputFormatter.Format(chunkData)
+		if err != nil {
+			return nil, fmt.Errorf("格式化分块 %d 失败: %w", i+1, err)
+		}
+		chunkFile := chunk.FileName(output, i+1)
+		if err := writeOutputFile(chunkFile, outputData, format); err != nil {
+			return nil, fmt.Errorf("写入分块文件失败: %w", err)
+		}
+	}
+
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("序列化分块清单失败: %w", err)
+	}
+	if err := os.WriteFile(chunk.ManifestName(output), append(content, '\n'), 0644); err != nil {
+		return nil, fmt.Errorf("写入分块清单失败: %w", err)
+	}
+	return manifest, nil
+}
+
+// defaultOutputPath 返回未指定输出路径时的默认文件名
+func defaultOutputPath(path string, multipleFiles []string, format string) string {
+	if len(multipleFiles) > 0 {
+		// 使用第一个文件名作为基础名称
+		baseName := filepath.Base(multipleFiles[0])
+		baseName = strings.TrimSuffix(baseName, filepath.Ext(baseName))
+		return fmt.Sprintf("context_%s.%s", baseName, outputExtension(format))
+	}
+	return fmt.Sprintf("context_%s.%s", filepath.Base(path), outputExtension(format))
+}
+
+// writeOutputFile 标准化换行符并按配置转换编码后写入输出文件，提示被替换的字符
+func writeOutputFile(path, data, format string) error {
+	result, err := encodeOutput(data, format)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, result.Data, 0644); err != nil {
+		return err
+	}
+
+	if result.Replaced > 0 {
+		fmt.Println(utils.WarningColor(fmt.Sprintf("⚠ %s: %d 个字符无法使用 %s 编码，已替换为 ? (使用 --encoding-errors strict 时报错)", path, result.Replaced, result.Encoding)))
+		for _, u := range result.Samples {
+			fmt.Printf("  行:列 %s\n", u)
+		}
+		if more := result.Replaced - len(result.Samples); more > 0 {
+			fmt.Printf("  ... 另有 %d 个\n", more)
+		}
+	}
+	return nil
+}
+
+// encodeOutput 标准化换行符并按配置转换编码，返回实际写出的内容
+func encodeOutput(data, format string) (*encoding.Result, error) {
+	opts := formatter.EncodingOptions(cfg, format)
+	data = utils.NormalizeLineEndings(data)
+	if !encoding.IsUTF8(opts.Encoding) {
+		// XML声明需要与实际编码一致，否则解析器会按UTF-8读取
+		if _, name, err := encoding.Lookup(opts.Encoding); err == nil {
+			data = encoding.SetXMLDeclaration(data, name)
+		}
+	}
+
+	result, err := encoding.Encode(data, opts)
+	if err != nil {
+		return nil, fmt.Errorf("编码转换失败: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,87 @@
+// Package main CLI上下文还原命令
+package main
+
+import (
+	"fmt"
+
+	"code-context-generator/internal/unpack"
+	"code-context-generator/internal/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// unpackCmd 上下文还原命令
+var unpackCmd = &cobra.Command{
+	Use:   "unpack <上下文文件>",
+	Short: "从生成的上下文文件还原目录结构和文件内容",
+	Long: `解析本工具生成的 JSON/XML/TOML/Markdown/YAML/NDJSON/纯文本 输出，在目标目录中重建目录结构和文件内容
+
+传入分块清单（*-manifest.json）时会读取所有分块并拼接被拆分的文件。
+所有路径通过安全路径拼接计算，越出目标目录或经过符号链接的路径会被拒绝。
+二进制文件、被截断的内容以及已存在的文件（未指定 --overwrite 时）会被跳过。`,
+	Args: cobra.ExactArgs(1),
+	RunE: runUnpack,
+}
+
+// initUnpackCommands 初始化上下文还原命令
+func initUnpackCommands() {
+	rootCmd.AddCommand(unpackCmd)
+
+	unpackCmd.Flags().String("into", "", "还原到的目标目录")
+	unpackCmd.Flags().String("format", "", "上下文文件格式 (默认根据扩展名和内容识别)")
+	unpackCmd.Flags().Bool("dry-run", false, "只列出将要写入的文件，不写入磁盘")
+	unpackCmd.Flags().Bool("overwrite", false, "覆盖已存在的文件")
+	unpackCmd.MarkFlagRequired("into")
+}
+
+// runUnpack 执行上下文还原
+func runUnpack(cmd *cobra.Command, args []string) error {
+	into, _ := cmd.Flags().GetString("into")
+	format, _ := cmd.Flags().GetString("format")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	overwrite, _ := cmd.Flags().GetBool("overwrite")
+
+	var customNames map[string]string
+	if cfg != nil {
+		customNames = cfg.Fields.CustomNames
+	}
+	snapshot, err := unpack.ReadFile(args[0], format, customNames)
+	if err != nil {
+		return err
+	}
+
+	entries, err := unpack.Plan(snapshot, into, overwrite)
+	if err != nil {
+		return err
+	}
+
+	skipped := 0
+	for _, entry := range entries {
+		switch entry.Action {
+		case unpack.ActionSkip:
+			skipped++
+			fmt.Println(utils.WarningColor(fmt.Sprintf("跳过 %s (%s)", entry.Path, entry.Reason)))
+		case unpack.ActionOverwrite:
+			fmt.Printf("覆盖 %s (%d 字节)\n", entry.Path, len(entry.Content))
+		default:
+			fmt.Printf("创建 %s (%d 字节)\n", entry.Path, len(entry.Content))
+		}
+	}
+
+	if dryRun {
+		fmt.Println(utils.InfoColor(fmt.Sprintf("试运行: 将写入 %d 个文件，跳过 %d 个，目标目录: %s", len(entries)-skipped, skipped, into)))
+		return nil
+	}
+
+	written, err := unpack.Apply(entries)
+	if err != nil {
+		return err
+	}
+	fmt.Println(utils.SuccessColor(fmt.Sprintf("已还原 %d 个文件到 %s，跳过 %d 个", written, into, skipped)))
+	return nil
+}
+
+// init 初始化函数 - 添加上下文还原命令
+func init() {
+	initUnpackCommands()
+}
@@ -0,0 +1,263 @@
+// Package apply 将模型回复中的文件块或统一diff写回工作区，写入前检测冲突
+package apply
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"code-context-generator/internal/unpack"
+	"code-context-generator/internal/utils"
+)
+
+// FormatDiff 统一diff格式
+const FormatDiff = "diff"
+
+// 应用操作
+const (
+	ActionCreate    = "create"
+	ActionModify    = "modify"
+	ActionDelete    = "delete"
+	ActionUnchanged = "unchanged"
+	ActionSkip      = "skip"   // 文件块无法写回，如二进制文件或被截断的内容
+	ActionFailed    = "failed" // 修改块无法应用到当前文件
+)
+
+// xmlFileBlockPattern 回复中包含AI优化XML格式的文件块
+var xmlFileBlockPattern = regexp.MustCompile(`<file path="`)
+
+// markdownFileBlockPattern 回复中包含Markdown格式的文件块
+var markdownFileBlockPattern = regexp.MustCompile(`(?m)^- \*\*路径\*\*: `)
+
+// Change 回复中对单个文件的修改
+type Change struct {
+	Path    string     // 回复中的原始路径
+	Content string     // 文件块给出的完整内容
+	Hash    string     // 文件块中记录的生成时哈希
+	Skip    string     // 文件块无法写回的原因
+	Patch   *FilePatch // 统一diff给出的修改，为nil时使用 Content
+}
+
+// Response 解析后的模型回复
+type Response struct {
+	RootPath string // 文件块中记录的生成时根目录
+	Changes  []Change
+}
+
+// DetectFormat 根据内容判断回复格式，无法判断时根据文件名判断
+func DetectFormat(filename string, content []byte) string {
+	text := string(content)
+	switch {
+	case IsUnifiedDiff(text):
+		return FormatDiff
+	case xmlFileBlockPattern.MatchString(text):
+		return unpack.FormatXML
+	case markdownFileBlockPattern.MatchString(text):
+		return unpack.FormatMarkdown
+	}
+	return unpack.DetectFormat(filename, content)
+}
+
+// ParseResponse 解析模型回复，format 为 diff 或 unpack 支持的上下文格式
+func ParseResponse(content []byte, format string, customNames map[string]string) (*Response, error) {
+	if format == FormatDiff {
+		patches, err := ParsePatch(string(content))
+		if err != nil {
+			return nil, err
+		}
+		response := &Response{}
+		for i := range patches {
+			response.Changes = append(response.Changes, Change{Path: patches[i].Path(), Patch: &patches[i]})
+		}
+		return response, nil
+	}
+
+	snapshot, err := unpack.Decode(content, format, customNames)
+	if err != nil {
+		return nil, err
+	}
+	response := &Response{RootPath: snapshot.RootPath}
+	for _, file := range snapshot.Files {
+		response.Changes = append(response.Changes, Change{Path: file.Path, Content: file.Content, Hash: file.Hash, Skip: file.Skip})
+	}
+	return response, nil
+}
+
+// Options 应用选项
+type Options struct {
+	Root    string           // 项目根目录
+	Context *unpack.Snapshot // 生成的上下文，提供生成时的哈希和根目录，可以为nil
+}
+
+// Entry 单个文件的应用计划
+type Entry struct {
+	Path     string // 相对于项目根目录的路径
+	Target   string
+	Action   string
+	Old      string // 当前内容
+	New      string // 修改后的内容
+	Diff     string // 从当前内容到修改后内容的统一diff
+	Conflict string // 冲突或失败原因，非空时默认不写入
+}
+
+// Plan 计算每个文件的修改和diff预览，不写入任何文件
+//
+// 路径必须位于项目根目录之内，越出根目录或经过符号链接时整体返回错误。
+// 生成时记录了哈希的文件，当前内容与哈希不一致时标记为冲突；
+// 哈希优先取自生成的上下文，其次取自回复中的文件块，都没有记录时不检测冲突。
+func Plan(response *Response, opts Options) ([]Entry, error) {
+	root, err := filepath.Abs(opts.Root)
+	if err != nil {
+		return nil, fmt.Errorf("解析项目根目录失败: %w", err)
+	}
+	roots := []string{root, response.RootPath}
+	hashes := make(map[string]string)
+	if opts.Context != nil {
+		roots = append(roots, opts.Context.RootPath)
+		for _, file := range opts.Context.Files {
+			rel, ok := relativePath(file.Path, root, opts.Context.RootPath)
+			if !ok {
+				continue
+			}
+			// 上下文中的内容经过了内容处理，只使用遍历时按原始内容记录的哈希
+			if file.Hash != "" {
+				hashes[rel] = file.Hash
+			}
+		}
+	}
+
+	entries := make([]Entry, 0, len(response.Changes))
+	for _, change := range response.Changes {
+		rel, ok := relativePath(change.Path, roots...)
+		if !ok {
+			return nil, fmt.Errorf("拒绝应用 %s: 路径不在项目根目录内", change.Path)
+		}
+		target, err := unpack.SafeTarget(root, rel)
+		if err != nil {
+			return nil, fmt.Errorf("拒绝应用 %s: %w", change.Path, err)
+		}
+
+		expected := hashes[rel]
+		if expected == "" {
+			expected = change.Hash
+		}
+		entries = append(entries, planEntry(change, rel, target, expected))
+	}
+	return entries, nil
+}
+
+// relativePath 将路径转换为相对于第一个包含它的根目录的路径
+func relativePath(p string, roots ...string) (string, bool) {
+	for _, root := range roots {
+		if rel, ok := unpack.RelativeTo(p, root); ok {
+			return rel, true
+		}
+	}
+	return p, false
+}
+
+// planEntry 读取当前文件并计算单个文件的修改
+func planEntry(change Change, rel, target, expectedHash string) Entry {
+	entry := Entry{Path: rel, Target: target}
+	if change.Skip != "" {
+		entry.Action, entry.Conflict = ActionSkip, change.Skip
+		return entry
+	}
+
+	exists := false
+	if info, err := os.Stat(target); err == nil {
+		if info.IsDir() {
+			entry.Action, entry.Conflict = ActionFailed, "目标是目录"
+			return entry
+		}
+		content, _, err := utils.ReadFileContent(target, 0)
+		if err != nil {
+			entry.Action, entry.Conflict = ActionFailed, err.Error()
+			return entry
+		}
+		entry.Old, exists = content, true
+	}
+
+	switch {
+	case change.Patch == nil:
+		entry.New = change.Content
+		// 代码块无法表达末尾换行，缺少时沿用当前文件的习惯，新文件以换行结尾
+		if entry.New != "" && !strings.HasSuffix(entry.New, "\n") && (!exists || strings.HasSuffix(entry.Old, "\n")) {
+			entry.New += "\n"
+		}
+	case change.Patch.OldPath == "" && exists:
+		entry.Action, entry.Conflict = ActionFailed, "diff新建的文件已存在"
+		return entry
+	case change.Patch.OldPath != "" && !exists:
+		entry.Action, entry.Conflict = ActionFailed, "diff修改的文件不存在"
+		return entry
+	default:
+		content, err := applyHunks(entry.Old, change.Patch.Hunks)
+		if err != nil {
+			entry.Action, entry.Conflict = ActionFailed, err.Error()
+			return entry
+		}
+		entry.New = content
+	}
+
+	oldName, newName := rel, rel
+	switch {
+	case change.Patch != nil && change.Patch.NewPath == "":
+		entry.Action, entry.New, newName = ActionDelete, "", ""
+	case !exists:
+		entry.Action, oldName = ActionCreate, ""
+	case entry.New == entry.Old:
+		entry.Action = ActionUnchanged
+	default:
+		entry.Action = ActionModify
+	}
+	entry.Diff = Unified(oldName, newName, entry.Old, entry.New)
+
+	if expectedHash != "" && entry.Action != ActionUnchanged {
+		if !exists {
+			entry.Conflict = "文件在生成上下文后已被删除"
+		} else if !strings.EqualFold(utils.ContentHash(entry.Old), expectedHash) {
+			entry.Conflict = "文件在生成上下文后已被修改"
+		}
+	}
+	return entry
+}
+
+// Apply 写入计划中的修改，force 为 true 时也写入与生成时哈希冲突的文件，返回写入的文件数
+//
+// 无变化、被跳过和无法应用的条目不做处理。
+func Apply(entries []Entry, force bool) (int, error) {
+	written := 0
+	for _, entry := range entries {
+		switch entry.Action {
+		case ActionUnchanged, ActionSkip, ActionFailed:
+			continue
+		}
+		if entry.Conflict != "" && !force {
+			continue
+		}
+
+		if entry.Action == ActionDelete {
+			if err := os.Remove(entry.Target); err != nil {
+				return written, fmt.Errorf("删除文件失败: %w", err)
+			}
+			written++
+			continue
+		}
+
+		mode := os.FileMode(0644)
+		if info, err := os.Stat(entry.Target); err == nil {
+			mode = info.Mode().Perm()
+		}
+		if err := os.MkdirAll(filepath.Dir(entry.Target), 0755); err != nil {
+			return written, fmt.Errorf("创建目录失败: %w", err)
+		}
+		if err := os.WriteFile(entry.Target, []byte(entry.New), mode); err != nil {
+			return written, fmt.Errorf("写入文件失败: %w", err)
+		}
+		written++
+	}
+	return written, nil
+}
@@ -0,0 +1,375 @@
+// Package apply 提供修改写回的单元测试
+package apply
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"code-context-generator/internal/formatter"
+	"code-context-generator/internal/pipeline"
+	"code-context-generator/internal/unpack"
+	"code-context-generator/internal/utils"
+	"code-context-generator/pkg/types"
+)
+
+// TestApplyPatch 测试解析并应用统一diff
+func TestApplyPatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		original string
+		patch    string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "修改中间行",
+			original: "a\nb\nc\nd\n",
+			patch:    "--- a/f.txt\n+++ b/f.txt\n@@ -2,2 +2,2 @@\n b\n-c\n+C\n",
+			expected: "a\nb\nC\nd\n",
+		},
+		{
+			name:     "行号偏移时查找上下文",
+			original: "x\ny\na\nb\nc\n",
+			patch:    "--- a/f.txt\n+++ b/f.txt\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+			expected: "x\ny\na\nB\nc\n",
+		},
+		{
+			name:     "多个修改块",
+			original: "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			patch:    "--- f.txt\n+++ f.txt\n@@ -1,2 +1,2 @@\n-1\n+one\n 2\n@@ -8,2 +8,3 @@\n 8\n 9\n+10\n",
+			expected: "one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+		},
+		{
+			name:     "纯插入",
+			original: "a\nb\n",
+			patch:    "--- a/f.txt\n+++ b/f.txt\n@@ -1,0 +2 @@\n+inserted\n",
+			expected: "a\ninserted\nb\n",
+		},
+		{
+			name:     "去掉末尾换行",
+			original: "a\nb\n",
+			patch:    "--- a/f.txt\n+++ b/f.txt\n@@ -2 +2 @@\n-b\n+b\n\\ No newline at end of file\n",
+			expected: "a\nb",
+		},
+		{
+			name:     "补上末尾换行",
+			original: "a\nb",
+			patch:    "--- a/f.txt\n+++ b/f.txt\n@@ -2 +2 @@\n-b\n\\ No newline at end of file\n+b\n",
+			expected: "a\nb\n",
+		},
+		{
+			name:     "CRLF文件",
+			original: "a\r\nb\r\n",
+			patch:    "--- a/f.txt\n+++ b/f.txt\n@@ -1,2 +1,2 @@\n a\n-b\n+c\n",
+			expected: "a\r\nc\r\n",
+		},
+		{
+			name:     "上下文不一致",
+			original: "a\nb\n",
+			patch:    "--- a/f.txt\n+++ b/f.txt\n@@ -1,2 +1,2 @@\n a\n-x\n+y\n",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patches, err := ParsePatch(tt.patch)
+			if err != nil {
+				t.Fatalf("ParsePatch() error = %v", err)
+			}
+			if len(patches) != 1 || patches[0].Path() != "f.txt" {
+				t.Fatalf("ParsePatch() = %+v", patches)
+			}
+			got, err := applyHunks(tt.original, patches[0].Hunks)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyHunks() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.expected {
+				t.Errorf("applyHunks() = %q, 期望 %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestParsePatchFiles 测试新建、删除和多文件diff
+func TestParsePatchFiles(t *testing.T) {
+	patch := "说明文字\n" +
+		"diff --git a/new.go b/new.go\nnew file mode 100644\n--- /dev/null\n+++ b/new.go\n@@ -0,0 +1,2 @@\n+package x\n+\n" +
+		"diff --git a/old.go b/old.go\n--- a/old.go\n+++ /dev/null\n@@ -1 +0,0 @@\n-package x\n" +
+		"--- a/sql/q.sql\n+++ b/sql/q.sql\n@@ -1,2 +1 @@\n--- 注释\n select 1;\n"
+	if !IsUnifiedDiff(patch) {
+		t.Fatal("IsUnifiedDiff() = false")
+	}
+	patches, err := ParsePatch(patch)
+	if err != nil {
+		t.Fatalf("ParsePatch() error = %v", err)
+	}
+	if len(patches) != 3 {
+		t.Fatalf("期望3个文件，实际 %d 个", len(patches))
+	}
+	if patches[0].OldPath != "" || patches[0].NewPath != "new.go" {
+		t.Errorf("新建文件路径 = %q, %q", patches[0].OldPath, patches[0].NewPath)
+	}
+	if patches[1].OldPath != "old.go" || patches[1].NewPath != "" {
+		t.Errorf("删除文件路径 = %q, %q", patches[1].OldPath, patches[1].NewPath)
+	}
+	if got := patches[2].Hunks[0].OldLines[0]; got != "-- 注释\n" {
+		t.Errorf("以 -- 开头的删除行 = %q", got)
+	}
+
+	if _, err := ParsePatch("--- a/f\n+++ b/f\n@@ -1,2 +1,2 @@\n a\n"); err == nil {
+		t.Error("不完整的修改块应该返回错误")
+	}
+}
+
+// TestUnified 测试diff预览
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name     string
+		oldName  string
+		newName  string
+		old      string
+		new      string
+		expected string
+	}{
+		{"无变化", "f", "f", "a\n", "a\n", ""},
+		{
+			"修改一行", "f", "f", "1\n2\n3\n4\n5\n6\n7\n8\n", "1\n2\n3\n4\nfive\n6\n7\n8\n",
+			"--- a/f\n+++ b/f\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+		{"新建文件", "", "f", "", "a\n", "--- /dev/null\n+++ b/f\n@@ -0,0 +1,1 @@\n+a\n"},
+		{"删除文件", "f", "", "a\n", "", "--- a/f\n+++ /dev/null\n@@ -1,1 +0,0 @@\n-a\n"},
+		{"末尾换行", "f", "f", "a", "a\n", "--- a/f\n+++ b/f\n@@ -1,1 +1,1 @@\n-a\n\\ No newline at end of file\n+a\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Unified(tt.oldName, tt.newName, tt.old, tt.new); got != tt.expected {
+				t.Errorf("Unified() = %q, 期望 %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestUnifiedRoundTrip 测试生成的diff能够应用回原内容
+func TestUnifiedRoundTrip(t *testing.T) {
+	old := "package a\n\nimport \"fmt\"\n\nfunc A() {\n\tfmt.Println(1)\n}\n\nfunc B() {}\n\nfunc C() {}\n\nfunc D() {}\n"
+	updated := "package a\n\nimport \"os\"\n\nfunc A() {\n\tos.Exit(1)\n}\n\nfunc B() {}\n\nfunc C() {}\n\nfunc E() {}\n"
+	patches, err := ParsePatch(Unified("a.go", "a.go", old, updated))
+	if err != nil {
+		t.Fatalf("ParsePatch() error = %v", err)
+	}
+	got, err := applyHunks(old, patches[0].Hunks)
+	if err != nil {
+		t.Fatalf("applyHunks() error = %v", err)
+	}
+	if got != updated {
+		t.Errorf("applyHunks() = %q, 期望 %q", got, updated)
+	}
+}
+
+// TestPlan 测试应用计划和冲突检测
+func TestPlan(t *testing.T) {
+	root := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("a.go", "package a\n")
+	write("b.go", "package b\n")
+	write("c.go", "package c\n// 生成后的本地修改\n")
+
+	// 生成时的上下文：c.go 在生成后被修改
+	context := &unpack.Snapshot{RootPath: root, Files: []unpack.File{
+		{Path: filepath.Join(root, "a.go"), Content: "package a\n"},
+		{Path: filepath.Join(root, "c.go"), Hash: utils.ContentHash("package c\n")},
+	}}
+	response := &Response{Changes: []Change{
+		{Path: filepath.Join(root, "a.go"), Content: "package a\n\nfunc A() {}"},
+		{Path: "b.go", Content: "package b\n"},
+		{Path: "c.go", Content: "package c\n\nfunc C() {}\n"},
+		{Path: "sub/new.go", Content: "package sub"},
+		{Path: "logo.png", Skip: "二进制文件"},
+	}}
+
+	entries, err := Plan(response, Options{Root: root, Context: context})
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	expected := []struct {
+		path     string
+		action   string
+		conflict bool
+	}{
+		{"a.go", ActionModify, false},
+		{"b.go", ActionUnchanged, false},
+		{"c.go", ActionModify, true},
+		{"sub/new.go", ActionCreate, false},
+		{"logo.png", ActionSkip, true},
+	}
+	for i, want := range expected {
+		entry := entries[i]
+		if entry.Path != want.path || entry.Action != want.action || (entry.Conflict != "") != want.conflict {
+			t.Errorf("entries[%d] = %s %s %q, 期望 %s %s 冲突=%v", i, entry.Path, entry.Action, entry.Conflict, want.path, want.action, want.conflict)
+		}
+	}
+	if !strings.Contains(entries[0].Diff, "+func A() {}\n") {
+		t.Errorf("diff预览缺少新增行: %q", entries[0].Diff)
+	}
+
+	written, err := Apply(entries, false)
+	if err != nil || written != 2 {
+		t.Fatalf("Apply() = %d, %v", written, err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(root, "a.go")); string(content) != "package a\n\nfunc A() {}\n" {
+		t.Errorf("a.go = %q", content)
+	}
+	if content, _ := os.ReadFile(filepath.Join(root, "sub", "new.go")); string(content) != "package sub\n" {
+		t.Errorf("sub/new.go = %q", content)
+	}
+	if content, _ := os.ReadFile(filepath.Join(root, "c.go")); string(content) != "package c\n// 生成后的本地修改\n" {
+		t.Errorf("冲突的文件不应该被写入: %q", content)
+	}
+
+	if written, err := Apply(entries, true); err != nil || written != 3 {
+		t.Fatalf("Apply(force) = %d, %v", written, err)
+	}
+}
+
+// TestPlanProcessedContext 测试回复中带行号的文件去掉行号后应用，截断的文件跳过
+func TestPlanProcessedContext(t *testing.T) {
+	root := t.TempDir()
+	files := []types.FileInfo{
+		{Path: filepath.Join(root, "main.go"), Name: "main.go", Content: "package main\n\nfunc main() {}\n"},
+		{Path: filepath.Join(root, "long.txt"), Name: "long.txt", Content: strings.Repeat("0123456789\n", 20)},
+	}
+	for _, file := range files {
+		if err := os.WriteFile(file.Path, []byte(file.Content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 模型原样返回了生成时添加行号并截断的内容
+	p, err := pipeline.New(&types.ProcessingConfig{AddLineNumbers: true, MaxLength: 60})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.ProcessFiles(files)
+	f, _ := formatter.NewFormatter(unpack.FormatMarkdown, &types.Config{})
+	output, err := f.Format(types.ContextData{Files: files, Metadata: map[string]interface{}{"root_path": root}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := ParseResponse([]byte(output), unpack.FormatMarkdown, nil)
+	if err != nil {
+		t.Fatalf("ParseResponse() error = %v", err)
+	}
+	entries, err := Plan(response, Options{Root: root})
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	if len(entries) != 2 || entries[0].Action != ActionUnchanged || entries[1].Action != ActionSkip {
+		t.Fatalf("期望 main.go 无变化、long.txt 跳过，实际 %+v", entries)
+	}
+	if written, err := Apply(entries, true); err != nil || written != 0 {
+		t.Errorf("Apply() = %d, %v，不应该写入任何文件", written, err)
+	}
+}
+
+// TestPlanContextWithoutHash 测试上下文未记录哈希时不按处理后的内容检测冲突
+func TestPlanContextWithoutHash(t *testing.T) {
+	root := t.TempDir()
+	original := "package main  \n\nfunc main() {}\t\n"
+	path := filepath.Join(root, "main.go")
+	if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// 生成时未使用 --hash，默认去除行尾空白
+	files := []types.FileInfo{{Path: path, Name: "main.go", Content: original}}
+	p, err := pipeline.New(&types.ProcessingConfig{TrimWhitespace: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.ProcessFiles(files)
+	f, _ := formatter.NewFormatter(unpack.FormatXML, &types.Config{})
+	output, err := f.Format(types.ContextData{Files: files, Metadata: map[string]interface{}{"root_path": root}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	context, err := unpack.Decode([]byte(output), unpack.FormatXML, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response := &Response{Changes: []Change{{Path: "main.go", Content: "package main\n\nfunc main() { run() }\n"}}}
+	entries, err := Plan(response, Options{Root: root, Context: context})
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	if len(entries) != 1 || entries[0].Action != ActionModify || entries[0].Conflict != "" {
+		t.Errorf("未修改的文件不应该视为冲突，实际 %+v", entries)
+	}
+}
+
+// TestPlanPatch 测试统一diff的计划
+func TestPlanPatch(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "a.txt"), []byte("a\nb\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	diff := "--- a/a.txt\n+++ b/a.txt\n@@ -1,2 +1,2 @@\n a\n-b\n+c\n" +
+		"--- a/missing.txt\n+++ b/missing.txt\n@@ -1 +1 @@\n-x\n+y\n" +
+		"--- a/a.txt\n+++ /dev/null\n@@ -1,2 +0,0 @@\n-a\n-x\n"
+	response, err := ParseResponse([]byte(diff), FormatDiff, nil)
+	if err != nil {
+		t.Fatalf("ParseResponse() error = %v", err)
+	}
+	entries, err := Plan(response, Options{Root: root})
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	if entries[0].Action != ActionModify || entries[0].New != "a\nc\n" {
+		t.Errorf("修改 = %s %q", entries[0].Action, entries[0].New)
+	}
+	if entries[1].Action != ActionFailed || entries[2].Action != ActionFailed {
+		t.Errorf("不存在的文件和内容不一致的删除应该无法应用: %s, %s", entries[1].Action, entries[2].Action)
+	}
+}
+
+// TestPlanRejectsEscapes 测试拒绝项目根目录之外的路径
+func TestPlanRejectsEscapes(t *testing.T) {
+	root := t.TempDir()
+	tests := []string{"../escape.go", "/etc/passwd", "a/../../escape.go"}
+	for _, path := range tests {
+		response := &Response{Changes: []Change{{Path: path, Content: "x"}}}
+		if _, err := Plan(response, Options{Root: root}); err == nil {
+			t.Errorf("Plan(%q) 应该返回错误", path)
+		}
+	}
+}
+
+// TestDetectFormat 测试回复格式识别
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected string
+	}{
+		{"修改如下:\n--- a/x\n+++ b/x\n@@ -1 +1 @@\n-a\n+b\n", FormatDiff},
+		{"<files>\n<file path=\"x.go\">", unpack.FormatXML},
+		{"说明\n\n- **路径**: `x.go`\n\n```go\n```\n", unpack.FormatMarkdown},
+	}
+	for _, tt := range tests {
+		if got := DetectFormat("response.txt", []byte(tt.content)); got != tt.expected {
+			t.Errorf("DetectFormat(%q) = %q, 期望 %q", tt.content, got, tt.expected)
+		}
+	}
+}
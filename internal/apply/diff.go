@@ -0,0 +1,178 @@
+package apply
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContextLines 预览diff中每个修改块前后的上下文行数
+const diffContextLines = 3
+
+// maxDiffCells 逐行比较的规模上限，超出时把中间不同的部分整体显示为替换
+const maxDiffCells = 4 << 20
+
+// lineOp diff中的一行，kind 为 ' '、'-' 或 '+'，text 包含换行符
+type lineOp struct {
+	kind byte
+	text string
+}
+
+// Unified 生成从旧内容到新内容的统一diff，oldName 为空表示新建文件，newName 为空表示删除文件
+func Unified(oldName, newName, oldContent, newContent string) string {
+	if oldContent == newContent && oldName != "" && newName != "" {
+		return ""
+	}
+	ops := diffLines(splitLinesKeep(oldContent), splitLinesKeep(newContent))
+
+	var builder strings.Builder
+	builder.WriteString(diffHeader("---", "a/", oldName))
+	builder.WriteString(diffHeader("+++", "b/", newName))
+
+	// 相距不超过两倍上下文行数的修改合并到同一个块中
+	var changes []int
+	for i, op := range ops {
+		if op.kind != ' ' {
+			changes = append(changes, i)
+		}
+	}
+	for start := 0; start < len(changes); {
+		end := start
+		for end+1 < len(changes) && changes[end+1]-changes[end]-1 <= 2*diffContextLines {
+			end++
+		}
+		first := changes[start] - diffContextLines
+		if first < 0 {
+			first = 0
+		}
+		last := changes[end] + diffContextLines + 1
+		if last > len(ops) {
+			last = len(ops)
+		}
+		writeHunk(&builder, ops, first, last)
+		start = end + 1
+	}
+	return builder.String()
+}
+
+// diffHeader 生成diff文件头，名称为空时使用 /dev/null
+func diffHeader(marker, prefix, name string) string {
+	if name == "" {
+		return marker + " /dev/null\n"
+	}
+	return fmt.Sprintf("%s %s%s\n", marker, prefix, name)
+}
+
+// writeHunk 写出 ops[first:last] 组成的修改块
+func writeHunk(builder *strings.Builder, ops []lineOp, first, last int) {
+	oldStart, newStart := 0, 0
+	for _, op := range ops[:first] {
+		if op.kind != '+' {
+			oldStart++
+		}
+		if op.kind != '-' {
+			newStart++
+		}
+	}
+
+	var body strings.Builder
+	oldCount, newCount := 0, 0
+	for _, op := range ops[first:last] {
+		if op.kind != '+' {
+			oldCount++
+		}
+		if op.kind != '-' {
+			newCount++
+		}
+		body.WriteByte(op.kind)
+		body.WriteString(op.text)
+		if !strings.HasSuffix(op.text, "\n") {
+			body.WriteString("\n" + noNewlineMarker + "\n")
+		}
+	}
+	builder.WriteString(fmt.Sprintf("@@ -%s +%s @@\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount)))
+	builder.WriteString(body.String())
+}
+
+// hunkRange 格式化diff块的行范围，空范围按惯例使用前一行的行号
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// diffLines 计算两组行之间的编辑，去掉公共前后缀后按最长公共子序列比较
+func diffLines(a, b []string) []lineOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var ops []lineOp
+	for _, line := range a[:prefix] {
+		ops = append(ops, lineOp{' ', line})
+	}
+	ops = append(ops, diffMiddle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, lineOp{' ', line})
+	}
+	return ops
+}
+
+// diffMiddle 使用最长公共子序列比较两组行，规模过大时整体替换
+func diffMiddle(a, b []string) []lineOp {
+	var ops []lineOp
+	if len(a)*len(b) > maxDiffCells {
+		for _, line := range a {
+			ops = append(ops, lineOp{'-', line})
+		}
+		for _, line := range b {
+			ops = append(ops, lineOp{'+', line})
+		}
+		return ops
+	}
+
+	// lcs[i][j] 为 a[i:] 与 b[j:] 的最长公共子序列长度
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, lineOp{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, lineOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, lineOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, lineOp{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, lineOp{'+', b[j]})
+	}
+	return ops
+}
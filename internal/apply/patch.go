@@ -0,0 +1,286 @@
+package apply
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// noNewlineMarker 统一diff中表示上一行没有换行符的标记
+const noNewlineMarker = `\ No newline at end of file`
+
+// hunkHeaderPattern 匹配修改块头，如 @@ -1,3 +1,4 @@
+var hunkHeaderPattern = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// FilePatch 统一diff中单个文件的修改
+type FilePatch struct {
+	OldPath string // 为空表示新建文件（/dev/null）
+	NewPath string // 为空表示删除文件（/dev/null）
+	Hunks   []Hunk
+}
+
+// Path 返回修改的文件路径
+func (p *FilePatch) Path() string {
+	if p.NewPath != "" {
+		return p.NewPath
+	}
+	return p.OldPath
+}
+
+// Hunk 修改块，行内容包含换行符，没有换行符的最后一行由 noNewlineMarker 标记
+type Hunk struct {
+	OldStart int
+	OldCount int
+	NewStart int
+	NewCount int
+	OldLines []string // 上下文行和删除的行
+	NewLines []string // 上下文行和新增的行
+}
+
+// IsUnifiedDiff 判断内容是否为统一diff
+func IsUnifiedDiff(content string) bool {
+	lines := strings.Split(content, "\n")
+	for i := 0; i+2 < len(lines); i++ {
+		if strings.HasPrefix(lines[i], "--- ") && strings.HasPrefix(lines[i+1], "+++ ") && hunkHeaderPattern.MatchString(lines[i+2]) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParsePatch 解析统一diff，支持 git diff 和 diff -u 的输出，忽略文件头之外的说明文字
+func ParsePatch(content string) ([]FilePatch, error) {
+	lines := strings.SplitAfter(content, "\n")
+	var patches []FilePatch
+	for i := 0; i < len(lines); i++ {
+		if !strings.HasPrefix(lines[i], "--- ") || i+1 >= len(lines) || !strings.HasPrefix(lines[i+1], "+++ ") {
+			continue
+		}
+		patch := FilePatch{
+			OldPath: patchPath(lines[i][4:]),
+			NewPath: patchPath(lines[i+1][4:]),
+		}
+		stripPrefixes(&patch)
+		if patch.OldPath == "" && patch.NewPath == "" {
+			return nil, fmt.Errorf("第 %d 行: diff文件头缺少文件路径", i+1)
+		}
+		i += 2
+
+		for i < len(lines) && hunkHeaderPattern.MatchString(lines[i]) {
+			hunk, next, err := parseHunk(lines, i)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", patch.Path(), err)
+			}
+			patch.Hunks = append(patch.Hunks, hunk)
+			i = next
+		}
+		if len(patch.Hunks) == 0 {
+			return nil, fmt.Errorf("%s: 没有修改块", patch.Path())
+		}
+		patches = append(patches, patch)
+		i--
+	}
+	if len(patches) == 0 {
+		return nil, fmt.Errorf("没有找到统一diff格式的修改")
+	}
+	return patches, nil
+}
+
+// parseHunk 解析从 start 行开始的修改块，返回下一个未处理的行号
+func parseHunk(lines []string, start int) (Hunk, int, error) {
+	match := hunkHeaderPattern.FindStringSubmatch(lines[start])
+	hunk := Hunk{
+		OldStart: atoiDefault(match[1], 0),
+		OldCount: atoiDefault(match[2], 1),
+		NewStart: atoiDefault(match[3], 0),
+		NewCount: atoiDefault(match[4], 1),
+	}
+
+	oldSeen, newSeen := 0, 0
+	i := start + 1
+	var last byte
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if strings.HasPrefix(line, noNewlineMarker) {
+			// 标记作用于前一行，上下文行同时作用于新旧两侧
+			if last == ' ' || last == '-' {
+				trimLastNewline(hunk.OldLines)
+			}
+			if last == ' ' || last == '+' {
+				trimLastNewline(hunk.NewLines)
+			}
+			continue
+		}
+		if oldSeen == hunk.OldCount && newSeen == hunk.NewCount {
+			break
+		}
+
+		// 部分工具会去掉空上下文行的前导空格
+		kind, text := byte(' '), "\n"
+		if line != "\n" && line != "\r\n" {
+			if line == "" {
+				break
+			}
+			kind, text = line[0], line[1:]
+		}
+		if !strings.HasSuffix(text, "\n") {
+			text += "\n"
+		}
+		switch kind {
+		case ' ':
+			hunk.OldLines = append(hunk.OldLines, text)
+			hunk.NewLines = append(hunk.NewLines, text)
+			oldSeen++
+			newSeen++
+		case '-':
+			hunk.OldLines = append(hunk.OldLines, text)
+			oldSeen++
+		case '+':
+			hunk.NewLines = append(hunk.NewLines, text)
+			newSeen++
+		default:
+			return hunk, i, fmt.Errorf("第 %d 行: 无效的修改块内容", i+1)
+		}
+		last = kind
+		if oldSeen > hunk.OldCount || newSeen > hunk.NewCount {
+			return hunk, i, fmt.Errorf("第 %d 行: 修改块行数与块头不一致", i+1)
+		}
+	}
+	if oldSeen != hunk.OldCount || newSeen != hunk.NewCount {
+		return hunk, i, fmt.Errorf("第 %d 行: 修改块不完整", start+1)
+	}
+	return hunk, i, nil
+}
+
+// trimLastNewline 去掉最后一行的换行符
+func trimLastNewline(lines []string) {
+	if n := len(lines); n > 0 {
+		lines[n-1] = strings.TrimSuffix(lines[n-1], "\n")
+	}
+}
+
+// atoiDefault 解析整数，为空时返回默认值
+func atoiDefault(s string, defaultValue int) int {
+	if s == "" {
+		return defaultValue
+	}
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// patchPath 解析文件头中的路径，去掉时间戳和引号，/dev/null 返回空字符串
+func patchPath(header string) string {
+	header = strings.TrimRight(header, "\r\n")
+	if i := strings.Index(header, "\t"); i >= 0 {
+		header = header[:i]
+	}
+	header = strings.TrimSpace(header)
+	if unquoted, err := strconv.Unquote(header); err == nil {
+		header = unquoted
+	}
+	if header == "/dev/null" {
+		return ""
+	}
+	return header
+}
+
+// stripPrefixes 去掉 git diff 的 a/ 和 b/ 前缀，只有两侧前缀一致时才去掉
+func stripPrefixes(patch *FilePatch) {
+	oldOK := patch.OldPath == "" || strings.HasPrefix(patch.OldPath, "a/")
+	newOK := patch.NewPath == "" || strings.HasPrefix(patch.NewPath, "b/")
+	if !oldOK || !newOK {
+		return
+	}
+	patch.OldPath = strings.TrimPrefix(patch.OldPath, "a/")
+	patch.NewPath = strings.TrimPrefix(patch.NewPath, "b/")
+}
+
+// applyHunks 将修改块依次应用到内容上
+//
+// 修改块优先在块头给出的位置匹配，不一致时在之后的内容中查找最近的匹配位置。
+// 比较时忽略行尾的 \r，文件使用CRLF时新增的行也使用CRLF。
+func applyHunks(content string, hunks []Hunk) (string, error) {
+	lines := splitLinesKeep(content)
+	crlf := len(lines) > 0 && strings.HasSuffix(lines[0], "\r\n")
+
+	var result []string
+	next := 0
+	for i, hunk := range hunks {
+		at := hunk.OldStart - 1
+		if hunk.OldCount == 0 {
+			// 纯插入的块头给出插入位置之前的行号
+			at = hunk.OldStart
+		}
+		pos := findLines(lines, hunk.OldLines, at, next)
+		if pos < 0 {
+			return "", fmt.Errorf("第 %d 个修改块无法应用: 上下文与当前文件不一致", i+1)
+		}
+		result = append(result, lines[next:pos]...)
+		for _, line := range hunk.NewLines {
+			if crlf && strings.HasSuffix(line, "\n") && !strings.HasSuffix(line, "\r\n") {
+				line = strings.TrimSuffix(line, "\n") + "\r\n"
+			}
+			result = append(result, line)
+		}
+		next = pos + len(hunk.OldLines)
+	}
+	result = append(result, lines[next:]...)
+	return strings.Join(result, ""), nil
+}
+
+// findLines 查找 want 在 lines 中的位置，从 at 开始向两侧搜索，不早于 min
+func findLines(lines, want []string, at, min int) int {
+	if at < min {
+		at = min
+	}
+	maxPos := len(lines) - len(want)
+	if maxPos < min {
+		return -1
+	}
+	if at > maxPos {
+		at = maxPos
+	}
+	for offset := 0; ; offset++ {
+		before, after := at-offset, at+offset
+		if before < min && after > maxPos {
+			return -1
+		}
+		if after <= maxPos && linesMatch(lines[after:after+len(want)], want) {
+			return after
+		}
+		if offset > 0 && before >= min && linesMatch(lines[before:before+len(want)], want) {
+			return before
+		}
+	}
+}
+
+// linesMatch 逐行比较，忽略行尾 \r 的差异
+func linesMatch(lines, want []string) bool {
+	for i := range want {
+		if normalizeEOL(lines[i]) != normalizeEOL(want[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeEOL 将行尾的CRLF视为LF
+func normalizeEOL(line string) string {
+	if strings.HasSuffix(line, "\r\n") {
+		return line[:len(line)-2] + "\n"
+	}
+	return line
+}
+
+// splitLinesKeep 按行拆分内容，每行保留换行符
+func splitLinesKeep(content string) []string {
+	if content == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
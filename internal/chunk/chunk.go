@@ -0,0 +1,290 @@
+// Package chunk 按token数或文件大小将上下文拆分为多个分块
+package chunk
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"code-context-generator/pkg/types"
+)
+
+// bytesPerToken 每个token对应的字节数，与格式化输出中的token估算一致
+const bytesPerToken = 4
+
+// maxAttempts 按格式化后的大小重新拆分的最多次数
+const maxAttempts = 10
+
+// Options 分块限制，同时设置时取较小者
+type Options struct {
+	MaxTokens int
+	MaxBytes  int64
+	// Measure 返回分块格式化后的字节数，设置后每个分块的完整输出都不超过限制
+	Measure func(types.ContextData) (int64, error)
+}
+
+// Limit 返回每个分块可容纳的文件内容字节数
+func (o Options) Limit() int64 {
+	limit := o.MaxBytes
+	if o.MaxTokens > 0 {
+		if tokenLimit := int64(o.MaxTokens) * bytesPerToken; limit <= 0 || tokenLimit < limit {
+			limit = tokenLimit
+		}
+	}
+	return limit
+}
+
+// FileName 返回第 index 个分块的文件名，如 context.xml 的第1块为 context-001.xml
+func FileName(output string, index int) string {
+	ext := filepath.Ext(output)
+	return fmt.Sprintf("%s-%03d%s", strings.TrimSuffix(output, ext), index, ext)
+}
+
+// ManifestName 返回分块清单的文件名，如 context.xml 的清单为 context-manifest.json
+func ManifestName(output string) string {
+	return strings.TrimSuffix(output, filepath.Ext(output)) + "-manifest.json"
+}
+
+// piece 分块中的文件或文件片段
+type piece struct {
+	file  types.FileInfo
+	part  int
+	parts int
+}
+
+// Split 将上下文数据拆分为多个分块，output 为未分块时的输出文件路径
+//
+// 文件按顺序装入分块，只有单个文件超出限制时才按行拆分为多个片段。
+// 未设置 Measure 时限制只计算文件内容；设置后逐个测量格式化的分块，
+// 超出限制的分块扣除格式、分块索引和项目级信息占用的空间，
+// 再按内容格式化前后的大小比例缩减预算后重新拆分。
+// 文件夹、安全扫描、许可证和Git等项目级信息只写入第一个分块。
+func Split(data types.ContextData, opts Options, output string) ([]types.ContextData, *types.ChunkManifest, error) {
+	limit := opts.Limit()
+	if limit <= 0 {
+		return nil, nil, fmt.Errorf("分块限制必须大于0")
+	}
+
+	// 每个分块可容纳的文件内容字节数，按分块序号记录
+	budgets := make(map[int]int64)
+	budget := func(index int) int64 {
+		if b, ok := budgets[index]; ok {
+			return b
+		}
+		return limit
+	}
+
+	for attempt := 1; ; attempt++ {
+		groups := pack(data.Files, budget)
+		chunks, entries := buildChunks(data, groups, output)
+		if opts.Measure == nil {
+			return chunks, newManifest(data, opts, entries), nil
+		}
+
+		fits := true
+		for i, chunk := range chunks {
+			size, err := opts.Measure(chunk)
+			if err != nil {
+				return nil, nil, fmt.Errorf("测量分块 %d 失败: %w", i+1, err)
+			}
+			if size <= limit {
+				continue
+			}
+			fits = false
+			overhead, err := opts.Measure(withoutContent(chunk))
+			if err != nil {
+				return nil, nil, fmt.Errorf("测量分块 %d 失败: %w", i+1, err)
+			}
+			if overhead >= limit {
+				return nil, nil, fmt.Errorf("分块限制 %d 字节不足以容纳分块 %d 的格式和项目级信息 (%d 字节)", limit, i+1, overhead)
+			}
+			// 转义使格式化后的内容大于原始内容，按两者的比例缩减预算
+			reduced := entries[i].Size * (limit - overhead) / (size - overhead)
+			if reduced <= 0 {
+				return nil, nil, fmt.Errorf("分块限制 %d 字节不足以容纳分块 %d 的格式和项目级信息 (%d 字节)", limit, i+1, overhead)
+			}
+			budgets[i] = min(budget(i), reduced)
+		}
+		if fits {
+			return chunks, newManifest(data, opts, entries), nil
+		}
+		if attempt == maxAttempts {
+			return nil, nil, fmt.Errorf("重新拆分 %d 次后分块仍超出限制 %d 字节，请增大分块限制", maxAttempts, limit)
+		}
+	}
+}
+
+// withoutContent 返回清空文件内容的分块，用于测量格式和项目级信息的开销
+func withoutContent(chunk types.ContextData) types.ContextData {
+	files := make([]types.FileInfo, len(chunk.Files))
+	for i, file := range chunk.Files {
+		file.Content = ""
+		files[i] = file
+	}
+	chunk.Files = files
+	return chunk
+}
+
+// pack 按每个分块的内容预算将文件装入分块
+func pack(files []types.FileInfo, budget func(index int) int64) [][]piece {
+	var groups [][]piece
+	var current []piece
+	var used int64
+	flush := func() {
+		if len(current) > 0 {
+			groups = append(groups, current)
+			current = nil
+			used = 0
+		}
+	}
+
+	for _, file := range files {
+		size := int64(len(file.Content))
+		if used+size > budget(len(groups)) {
+			flush()
+		}
+		if size <= budget(len(groups)) {
+			current = append(current, piece{file: file})
+			used += size
+			continue
+		}
+
+		// 单个文件超出限制，拆分后每个片段独占一个分块，最后一个片段可与后续文件共用
+		start := len(groups)
+		var parts []string
+		for rest := file.Content; rest != ""; {
+			var part string
+			part, rest = nextPart(rest, budget(start+len(parts)))
+			parts = append(parts, part)
+		}
+		for i, content := range parts {
+			part := file
+			part.Content = content
+			part.Size = int64(len(content))
+			current = append(current, piece{file: part, part: i + 1, parts: len(parts)})
+			used = part.Size
+			if i < len(parts)-1 {
+				flush()
+			}
+		}
+	}
+	flush()
+	if len(groups) == 0 {
+		groups = append(groups, nil)
+	}
+	return groups
+}
+
+// buildChunks 根据装箱结果生成分块数据和分块索引
+func buildChunks(data types.ContextData, groups [][]piece, output string) ([]types.ContextData, []types.ChunkEntry) {
+	entries := make([]types.ChunkEntry, len(groups))
+	for i, group := range groups {
+		entry := types.ChunkEntry{
+			Index: i + 1,
+			File:  filepath.Base(FileName(output, i+1)),
+			Files: make([]types.ChunkFile, 0, len(group)),
+		}
+		for _, p := range group {
+			chunkFile := types.ChunkFile{
+				Path:   p.file.Path,
+				Part:   p.part,
+				Parts:  p.parts,
+				Size:   int64(len(p.file.Content)),
+				Tokens: len(p.file.Content) / bytesPerToken,
+			}
+			entry.Files = append(entry.Files, chunkFile)
+			entry.Size += chunkFile.Size
+			entry.Tokens += chunkFile.Tokens
+		}
+		entries[i] = entry
+	}
+
+	chunks := make([]types.ContextData, len(groups))
+	for i, group := range groups {
+		chunk := types.ContextData{
+			Files: make([]types.FileInfo, 0, len(group)),
+			Chunk: &types.ChunkInfo{
+				Index:    i + 1,
+				Total:    len(groups),
+				Manifest: filepath.Base(ManifestName(output)),
+				Chunks:   entries,
+			},
+		}
+		for _, p := range group {
+			chunk.Files = append(chunk.Files, p.file)
+			chunk.TotalSize += p.file.Size
+		}
+		chunk.FileCount = len(chunk.Files)
+
+		if i == 0 {
+			chunk.Folders = data.Folders
+			chunk.FolderCount = data.FolderCount
+			chunk.Security = data.Security
+			chunk.License = data.License
+			chunk.Metadata = data.Metadata
+		} else if rootPath, ok := data.Metadata["root_path"]; ok {
+			chunk.Metadata = map[string]interface{}{"root_path": rootPath}
+		}
+		chunks[i] = chunk
+	}
+	return chunks, entries
+}
+
+// newManifest 生成分块清单
+func newManifest(data types.ContextData, opts Options, entries []types.ChunkEntry) *types.ChunkManifest {
+	return &types.ChunkManifest{
+		GeneratedAt: time.Now(),
+		MaxTokens:   opts.MaxTokens,
+		MaxBytes:    opts.MaxBytes,
+		FileCount:   data.FileCount,
+		TotalSize:   data.TotalSize,
+		Chunks:      entries,
+	}
+}
+
+// splitContent 按行拆分内容，每个片段不超过 limit 字节，单行超出限制时在字符边界处截断
+func splitContent(content string, limit int64) []string {
+	var parts []string
+	for rest := content; rest != ""; {
+		var part string
+		part, rest = nextPart(rest, limit)
+		parts = append(parts, part)
+	}
+	return parts
+}
+
+// nextPart 从内容开头取出不超过 limit 字节的完整行，首行超出限制时在字符边界处截断
+func nextPart(content string, limit int64) (string, string) {
+	if int64(len(content)) <= limit {
+		return content, ""
+	}
+	end := 0
+	for end < len(content) {
+		lineEnd := len(content)
+		if i := strings.IndexByte(content[end:], '\n'); i >= 0 {
+			lineEnd = end + i + 1
+		}
+		if int64(lineEnd) > limit {
+			break
+		}
+		end = lineEnd
+	}
+	if end == 0 {
+		end = runeBoundary(content, int(limit))
+	}
+	return content[:end], content[end:]
+}
+
+// runeBoundary 返回不超过 limit 的最近字符边界
+func runeBoundary(s string, limit int) int {
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	if cut == 0 {
+		return limit
+	}
+	return cut
+}
@@ -0,0 +1,224 @@
+// Package chunk 提供分块输出的单元测试
+package chunk
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"code-context-generator/pkg/types"
+)
+
+// chunkTestData 返回包含一个超大文件的上下文数据
+func chunkTestData() types.ContextData {
+	large := strings.Repeat("0123456789\n", 10) // 110字节
+	files := []types.FileInfo{
+		{Path: "a.go", Content: strings.Repeat("a", 30)},
+		{Path: "b.go", Content: strings.Repeat("b", 30)},
+		{Path: "large.txt", Content: large},
+		{Path: "c.go", Content: strings.Repeat("c", 5)},
+	}
+	data := types.ContextData{
+		Files:       files,
+		Folders:     []types.FolderInfo{{Path: "sub"}},
+		FileCount:   len(files),
+		FolderCount: 1,
+		License:     &types.LicenseReport{ProjectLicense: "MIT"},
+		Metadata:    map[string]interface{}{"root_path": "/project", "git": "data"},
+	}
+	for _, file := range files {
+		data.TotalSize += int64(len(file.Content))
+	}
+	return data
+}
+
+// TestOptionsLimit 测试token和字节限制的换算
+func TestOptionsLimit(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     Options
+		expected int64
+	}{
+		{"只限制token", Options{MaxTokens: 100}, 400},
+		{"只限制字节", Options{MaxBytes: 1000}, 1000},
+		{"同时限制取较小者", Options{MaxTokens: 100, MaxBytes: 300}, 300},
+		{"未设置", Options{}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.opts.Limit(); got != tt.expected {
+				t.Errorf("Limit() = %d, 期望 %d", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestFileName 测试分块和清单文件名
+func TestFileName(t *testing.T) {
+	if got := FileName("out/context.xml", 2); got != "out/context-002.xml" {
+		t.Errorf("FileName() = %s", got)
+	}
+	if got := ManifestName("out/context.xml"); got != "out/context-manifest.json" {
+		t.Errorf("ManifestName() = %s", got)
+	}
+}
+
+// TestSplit 测试文件不被拆分、超大文件按行拆分以及分块索引
+func TestSplit(t *testing.T) {
+	data := chunkTestData()
+	chunks, manifest, err := Split(data, Options{MaxBytes: 64}, "context.md")
+	if err != nil {
+		t.Fatalf("Split() error = %v", err)
+	}
+
+	// a+b 共60字节，large 拆为2个片段，最后一个片段与 c 共用分块
+	var layout []string
+	for _, chunk := range chunks {
+		var paths []string
+		for _, file := range chunk.Files {
+			paths = append(paths, file.Path)
+		}
+		layout = append(layout, strings.Join(paths, ","))
+	}
+	expected := []string{"a.go,b.go", "large.txt", "large.txt,c.go"}
+	if strings.Join(layout, "|") != strings.Join(expected, "|") {
+		t.Fatalf("分块布局 = %v, 期望 %v", layout, expected)
+	}
+
+	// 片段按行拆分，拼接后与原内容一致
+	var rebuilt strings.Builder
+	for _, chunk := range chunks[1:] {
+		file := chunk.Files[0]
+		if !strings.HasSuffix(file.Content, "\n") || len(file.Content) > 64 {
+			t.Errorf("片段应在行尾拆分且不超过限制: %q", file.Content)
+		}
+		rebuilt.WriteString(file.Content)
+	}
+	if rebuilt.String() != data.Files[2].Content {
+		t.Error("片段拼接后与原内容不一致")
+	}
+
+	// 每个分块都携带完整索引，项目级信息只在第一个分块
+	for i, chunk := range chunks {
+		if chunk.Chunk == nil || chunk.Chunk.Index != i+1 || chunk.Chunk.Total != 3 || len(chunk.Chunk.Chunks) != 3 {
+			t.Fatalf("分块 %d 的头信息错误: %+v", i+1, chunk.Chunk)
+		}
+		if chunk.Chunk.Manifest != "context-manifest.json" {
+			t.Errorf("清单文件名错误: %s", chunk.Chunk.Manifest)
+		}
+		if chunk.Metadata["root_path"] != "/project" {
+			t.Errorf("分块 %d 缺少根路径", i+1)
+		}
+		if (i == 0) != (chunk.License != nil) || (i == 0) != (chunk.Metadata["git"] != nil) {
+			t.Errorf("分块 %d 的项目级信息错误", i+1)
+		}
+	}
+
+	last := manifest.Chunks[2]
+	if last.File != "context-003.md" || last.Files[0].Part != 2 || last.Files[0].Parts != 2 || last.Files[1].Part != 0 {
+		t.Errorf("清单索引错误: %+v", last)
+	}
+	if manifest.FileCount != 4 || manifest.MaxBytes != 64 {
+		t.Errorf("清单统计错误: %+v", manifest)
+	}
+}
+
+// TestSplitMeasure 测试按格式化后的大小拆分，格式、分块索引和项目级信息都计入限制
+func TestSplitMeasure(t *testing.T) {
+	data := chunkTestData()
+	measure := func(chunk types.ContextData) (int64, error) {
+		out, err := json.Marshal(chunk)
+		return int64(len(out)), err
+	}
+
+	const limit = 1200
+	chunks, _, err := Split(data, Options{MaxBytes: limit, Measure: measure}, "context.json")
+	if err != nil {
+		t.Fatalf("Split() error = %v", err)
+	}
+	if len(chunks) < 2 {
+		t.Fatalf("期望按格式化大小拆分为多个分块，实际 %d 个", len(chunks))
+	}
+
+	var rebuilt strings.Builder
+	for i, chunk := range chunks {
+		if size, _ := measure(chunk); size > limit {
+			t.Errorf("分块 %d 格式化后 %d 字节，超出限制 %d", i+1, size, limit)
+		}
+		for _, file := range chunk.Files {
+			rebuilt.WriteString(file.Content)
+		}
+	}
+	var original strings.Builder
+	for _, file := range data.Files {
+		original.WriteString(file.Content)
+	}
+	if rebuilt.String() != original.String() {
+		t.Error("拆分后内容丢失")
+	}
+
+	if _, _, err := Split(data, Options{MaxBytes: 900, Measure: measure}, "context.json"); err == nil {
+		t.Error("限制小于格式开销时应返回错误")
+	}
+}
+
+// TestSplitContent 测试超长行在字符边界处截断
+func TestSplitContent(t *testing.T) {
+	content := strings.Repeat("中", 10) + "\nend\n"
+	parts := splitContent(content, 8)
+	if strings.Join(parts, "") != content {
+		t.Fatal("拆分后内容丢失")
+	}
+	for _, part := range parts {
+		if len(part) > 8 || !utf8.ValidString(part) {
+			t.Errorf("片段超出限制或未在字符边界处截断: %q", part)
+		}
+	}
+}
+
+// TestSplitErrors 测试无效限制和空项目
+func TestSplitErrors(t *testing.T) {
+	if _, _, err := Split(chunkTestData(), Options{}, "context.json"); err == nil {
+		t.Error("未设置限制时应返回错误")
+	}
+
+	chunks, _, err := Split(types.ContextData{}, Options{MaxTokens: 10}, "context.json")
+	if err != nil || len(chunks) != 1 {
+		t.Errorf("空项目应生成一个分块: %d, %v", len(chunks), err)
+	}
+}
+
+// TestSplitMeasureEscaped 测试转义使内容成倍膨胀时按比例缩减预算
+func TestSplitMeasureEscaped(t *testing.T) {
+	content := strings.Repeat("<<<<<<<<<\n", 60) // JSON中每个 < 转义为6字节
+	data := types.ContextData{
+		Files:     []types.FileInfo{{Path: "escaped.html", Content: content}},
+		FileCount: 1,
+		TotalSize: int64(len(content)),
+	}
+	measure := func(chunk types.ContextData) (int64, error) {
+		out, err := json.Marshal(chunk)
+		return int64(len(out)), err
+	}
+
+	const limit = 2500
+	chunks, _, err := Split(data, Options{MaxBytes: limit, Measure: measure}, "context.json")
+	if err != nil {
+		t.Fatalf("Split() error = %v", err)
+	}
+
+	var rebuilt strings.Builder
+	for i, chunk := range chunks {
+		if size, _ := measure(chunk); size > limit {
+			t.Errorf("分块 %d 格式化后 %d 字节，超出限制 %d", i+1, size, limit)
+		}
+		for _, file := range chunk.Files {
+			rebuilt.WriteString(file.Content)
+		}
+	}
+	if rebuilt.String() != content {
+		t.Error("拆分后内容丢失")
+	}
+}
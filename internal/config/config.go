@@ -100,7 +100,7 @@ func (cm *ConfigManager) Validate() error {
 	}
 
 	// 验证格式配置
-	formats := []string{constants.FormatXML, constants.FormatJSON, constants.FormatTOML, constants.FormatMarkdown}
+	formats := []string{constants.FormatXML, constants.FormatJSON, constants.FormatTOML, constants.FormatMarkdown, constants.FormatYAML}
 	hasEnabled := false
 	for _, format := range formats {
 		if cm.isFormatEnabled(format) {
@@ -181,6 +181,7 @@ func (cm *ConfigManager) GetEnvOverrides() map[string]string {
 		env.EnvSecurityDetectXSS:           "security_detect_xss",
 		env.EnvSecurityDetectPathTraversal: "security_detect_path_traversal",
 		env.EnvSecurityDetectQuality:       "security_detect_quality",
+		env.EnvSecurityDetectPII:           "security_detect_pii",
 	}
 
 	for envKey, fieldName := range mapping {
@@ -214,6 +215,7 @@ func (cm *ConfigManager) applyEnvOverrides(config *types.Config) {
 	config.Security.Detectors.XSS = env.GetSecurityDetectXSS()
 	config.Security.Detectors.PathTraversal = env.GetSecurityDetectPathTraversal()
 	config.Security.Detectors.Quality = env.GetSecurityDetectQuality()
+	config.Security.Detectors.PII = env.GetSecurityDetectPII()
 
 	// 应用文件名模板覆盖
 	if filenameTemplate := env.GetFilenameTemplate(); filenameTemplate != "" {
@@ -269,6 +271,8 @@ func (cm *ConfigManager) GenerateOutput(data types.ContextData, format string) (
 		return cm.generateTOML(data)
 	case constants.FormatMarkdown:
 		return cm.generateMarkdown(data)
+	case constants.FormatYAML:
+		return cm.generateYAML(data)
 	default:
 		return "", fmt.Errorf("不支持的格式: %s", format)
 	}
@@ -325,6 +329,8 @@ func (cm *ConfigManager) isFormatEnabled(format string) bool {
 		return cm.config.Formats.TOML.Enabled
 	case constants.FormatMarkdown:
 		return cm.config.Formats.Markdown.Enabled
+	case constants.FormatYAML:
+		return cm.config.Formats.YAML.Enabled
 	default:
 		return false
 	}
@@ -547,6 +553,15 @@ func (cm *ConfigManager) generateTOML(data types.ContextData) (string, error) {
 	return buf.String(), nil
 }
 
+// generateYAML 将上下文数据序列化为YAML
+func (cm *ConfigManager) generateYAML(data types.ContextData) (string, error) {
+	output, err := yaml.Marshal(data)
+	if err != nil {
+		return "", fmt.Errorf("YAML生成失败: %w", err)
+	}
+	return string(output), nil
+}
+
 // LoadConfig 从文件加载配置（辅助函数）
 func LoadConfig(configPath string) (*types.Config, error) {
 	data, err := os.ReadFile(configPath)
@@ -620,6 +635,7 @@ func GetDefaultConfig() *types.Config {
 				XSS:           false, // 默认禁用
 				PathTraversal: false, // 默认禁用
 				Quality:       false, // 默认禁用
+				PII:           false, // 默认禁用
 			},
 		},
 		Formats: types.FormatsConfig{
@@ -679,6 +695,9 @@ func GetDefaultConfig() *types.Config {
 					"code_language": true,
 				},
 			},
+			YAML: types.FormatConfig{
+				Enabled: true,
+			},
 		},
 		Fields: types.FieldsConfig{
 			CustomNames: map[string]string{
@@ -693,12 +712,7 @@ func GetDefaultConfig() *types.Config {
 				Include: []string{},
 				Exclude: []string{},
 			},
-			Processing: struct {
-				MaxLength      int  `yaml:"max_length"`
-				AddLineNumbers bool `yaml:"add_line_numbers"`
-				TrimWhitespace bool `yaml:"trim_whitespace"`
-				CodeHighlight  bool `yaml:"code_highlight"`
-			}{
+			Processing: types.ProcessingConfig{
 				MaxLength:      0,
 				AddLineNumbers: false,
 				TrimWhitespace: true,
@@ -750,5 +764,8 @@ func GetDefaultConfig() *types.Config {
 				Until:   "",
 			},
 		},
+		License: types.LicenseConfig{
+			Enabled: false,
+		},
 	}
 }
@@ -44,6 +44,7 @@ const (
 	EnvSecurityDetectXSS         = constants.EnvPrefix + "SECURITY_DETECT_XSS"
 	EnvSecurityDetectPathTraversal = constants.EnvPrefix + "SECURITY_DETECT_PATH_TRAVERSAL"
 	EnvSecurityDetectQuality     = constants.EnvPrefix + "SECURITY_DETECT_QUALITY"
+	EnvSecurityDetectPII         = constants.EnvPrefix + "SECURITY_DETECT_PII"
 )
 
 // LoadEnv 加载.env文件到环境变量中
@@ -183,6 +184,7 @@ func GetAllEnvVars() map[string]string {
 	envVars[EnvSecurityDetectXSS] = strconv.FormatBool(GetEnvBool(EnvSecurityDetectXSS, false))
 	envVars[EnvSecurityDetectPathTraversal] = strconv.FormatBool(GetEnvBool(EnvSecurityDetectPathTraversal, false))
 	envVars[EnvSecurityDetectQuality] = strconv.FormatBool(GetEnvBool(EnvSecurityDetectQuality, false))
+	envVars[EnvSecurityDetectPII] = strconv.FormatBool(GetEnvBool(EnvSecurityDetectPII, false))
 	
 	return envVars
 }
@@ -288,6 +290,10 @@ func GetSecurityDetectPathTraversal() bool {
 func GetSecurityDetectQuality() bool {
 	return GetEnvBool(EnvSecurityDetectQuality, false)
 }
+
+func GetSecurityDetectPII() bool {
+	return GetEnvBool(EnvSecurityDetectPII, false)
+}
 func ApplyEnvOverrides(config map[string]interface{}) {
 	envVars := GetAllEnvVars()
 	
@@ -39,6 +39,10 @@ func (w *FileSystemWalker) GetFileInfo(path string) (*types.FileInfo, error) {
 		Name:     info.Name(),
 		Content:  content,
 	}
+	// 只在要求包含哈希时计算，哈希用于 apply 写回时检测冲突
+	if !isBinary && w.config != nil && w.config.FileProcessing.IncludeHash {
+		fileInfo.Hash = utils.ContentHash(content)
+	}
 
 	// 总是填充文件大小信息，无论是否包含元信息
 	fileInfo.Size = info.Size()
@@ -67,22 +71,30 @@ func (w *FileSystemWalker) GetFolderInfo(path string) (*types.FolderInfo, error)
 		return nil, fmt.Errorf("读取文件夹内容失败: %w", err)
 	}
 
-	var files []types.FileInfo
+	// 只读取文件的元信息，不读取文件内容
+	folder := &types.FolderInfo{
+		Path:     path,
+		Name:     info.Name(),
+		ModTime:  info.ModTime(),
+		IsHidden: strings.HasPrefix(info.Name(), "."),
+	}
 	for _, entry := range entries {
-		if !entry.IsDir() {
-			filePath := filepath.Join(path, entry.Name())
-			fileInfo, err := w.GetFileInfo(filePath)
-			if err != nil {
-				continue // 跳过无法读取的文件
-			}
-			files = append(files, *fileInfo)
+		if entry.IsDir() {
+			continue
 		}
+		entryInfo, err := entry.Info()
+		if err != nil {
+			continue // 跳过无法读取的文件
+		}
+		folder.Files = append(folder.Files, types.FileInfo{
+			Name:     entry.Name(),
+			Path:     filepath.Join(path, entry.Name()),
+			Size:     entryInfo.Size(),
+			ModTime:  entryInfo.ModTime(),
+			IsHidden: strings.HasPrefix(entry.Name(), "."),
+		})
+		folder.Size += entryInfo.Size()
+		folder.Count++
 	}
-
-	return &types.FolderInfo{
-		Path:    path,
-		Name:    info.Name(),
-		ModTime: info.ModTime(),
-		Files:   files,
-	}, nil
+	return folder, nil
 }
\ No newline at end of file
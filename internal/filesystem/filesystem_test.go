@@ -3,9 +3,11 @@ package filesystem
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"code-context-generator/internal/utils"
 	"code-context-generator/pkg/types"
 )
 
@@ -428,6 +430,19 @@ func TestFileSystemWalker_GetFileInfo(t *testing.T) {
 	if fileInfo.IsDir {
 		t.Error("GetFileInfo() IsDir should be false for file")
 	}
+
+	// 只有要求包含哈希时才计算
+	if fileInfo.Hash != "" {
+		t.Errorf("GetFileInfo() Hash = %v, 未启用 include_hash 时应为空", fileInfo.Hash)
+	}
+	config.FileProcessing.IncludeHash = true
+	fileInfo, err = fsWalker.GetFileInfo(tempFile.Name())
+	if err != nil {
+		t.Fatalf("GetFileInfo() error = %v", err)
+	}
+	if fileInfo.Hash != utils.ContentHash(string(testData)) {
+		t.Errorf("GetFileInfo() Hash = %v, want %v", fileInfo.Hash, utils.ContentHash(string(testData)))
+	}
 }
 
 func TestFileSystemWalker_GetFolderInfo(t *testing.T) {
@@ -582,9 +597,16 @@ func TestFileSystemWalker_Walk(t *testing.T) {
 			t.Errorf("Walk() found %d files, want %d", len(contextData.Files), len(testStructure))
 		}
 
-		// 验证文件夹数量
-		if len(contextData.Folders) != 2 { // subdir 和 subdir/nested
-			t.Errorf("Walk() found %d folders, want 2", len(contextData.Folders))
+		// 验证文件夹数量和目录树：subdir/nested 嵌套在 subdir 下
+		if contextData.FolderCount != 2 || len(contextData.Folders) != 1 {
+			t.Fatalf("Walk() found %d folders (%d top-level), want 2 (1 top-level)", contextData.FolderCount, len(contextData.Folders))
+		}
+		subdir := contextData.Folders[0]
+		if subdir.Name != "subdir" || len(subdir.Folders) != 1 || subdir.Folders[0].Name != "nested" {
+			t.Errorf("目录树错误: %+v", subdir)
+		}
+		if subdir.Count != 2 || subdir.Size != 16 || len(subdir.Files) != 1 || subdir.Files[0].Content != "" {
+			t.Errorf("subdir 汇总错误: count=%d size=%d files=%d", subdir.Count, subdir.Size, len(subdir.Files))
 		}
 	})
 
@@ -786,3 +808,39 @@ func BenchmarkFilterFiles(b *testing.B) {
 		walker.FilterFiles(files, patterns)
 	}
 }
+
+// TestBuildFolderTree 测试目录树组装和统计值汇总
+func TestBuildFolderTree(t *testing.T) {
+	folders := []types.FolderInfo{
+		{Name: "util", Path: "src/util"},
+		{Name: "src", Path: "src"},
+		{Name: "docs", Path: "docs"},
+	}
+	files := []types.FileInfo{
+		{Name: "main.go", Path: "src/main.go", Size: 40, Content: strings.Repeat("m", 40)},
+		{Name: "strings.go", Path: "src/util/strings.go", Size: 20, Content: strings.Repeat("s", 20)},
+		{Name: "README.md", Path: "README.md", Size: 8, Content: "# readme"},
+	}
+
+	tree := BuildFolderTree(folders, files)
+	if len(tree) != 2 || tree[0].Name != "docs" || tree[1].Name != "src" {
+		t.Fatalf("顶层文件夹错误: %+v", tree)
+	}
+
+	src := tree[1]
+	if src.Size != 60 || src.Count != 2 || src.Tokens != 15 {
+		t.Errorf("src 汇总 = size %d, count %d, tokens %d, 期望 60, 2, 15", src.Size, src.Count, src.Tokens)
+	}
+	if len(src.Files) != 1 || src.Files[0].Name != "main.go" || src.Files[0].Content != "" {
+		t.Errorf("src 的文件应只包含元信息: %+v", src.Files)
+	}
+	if len(src.Folders) != 1 || src.Folders[0].Path != "src/util" || src.Folders[0].Count != 1 || src.Folders[0].Tokens != 5 {
+		t.Errorf("子文件夹错误: %+v", src.Folders)
+	}
+	if tree[0].Count != 0 || len(tree[0].Files) != 0 {
+		t.Errorf("空文件夹不应包含文件: %+v", tree[0])
+	}
+	if files[0].Content == "" {
+		t.Error("不应修改传入的文件内容")
+	}
+}
@@ -0,0 +1,205 @@
+// Package filesystem 提供文件系统遍历和过滤功能
+package filesystem
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"code-context-generator/pkg/types"
+)
+
+// SensitiveFile 被识别为敏感而排除的文件
+type SensitiveFile struct {
+	Path   string
+	Reason string
+}
+
+// sensitiveFileNames 按文件名识别的敏感文件
+var sensitiveFileNames = map[string]string{
+	"id_rsa":            "SSH私钥",
+	"id_dsa":            "SSH私钥",
+	"id_ecdsa":          "SSH私钥",
+	"id_ed25519":        "SSH私钥",
+	"credentials.json":  "云服务凭证",
+	"credentials":       "云服务凭证",
+	".git-credentials":  "Git凭证",
+	".netrc":            "网络登录凭证",
+	".pgpass":           "数据库密码文件",
+	".htpasswd":         "HTTP认证密码文件",
+	"kubeconfig":        "Kubernetes集群凭证",
+	"terraform.tfstate": "Terraform状态文件",
+}
+
+// sensitiveExtensions 按扩展名识别的敏感文件
+var sensitiveExtensions = map[string]string{
+	".pem":        "证书或私钥",
+	".key":        "私钥",
+	".p12":        "PKCS#12证书包",
+	".pfx":        "PKCS#12证书包",
+	".jks":        "Java密钥库",
+	".keystore":   "密钥库",
+	".kdbx":       "KeePass密码库",
+	".ppk":        "PuTTY私钥",
+	".kubeconfig": "Kubernetes集群凭证",
+}
+
+// envTemplateSuffixes 不包含真实值的 .env 模板后缀
+var envTemplateSuffixes = []string{".example", ".sample", ".template", ".dist"}
+
+// registryConfigNames 可能包含令牌的包管理器配置
+var registryConfigNames = map[string]bool{
+	".npmrc":      true,
+	".yarnrc":     true,
+	".yarnrc.yml": true,
+	".pypirc":     true,
+}
+
+var (
+	// privateKeyPattern 以PEM私钥开头的文件
+	privateKeyPattern = regexp.MustCompile(`^\s*-----BEGIN [A-Z0-9 ]*PRIVATE KEY( BLOCK)?-----`)
+	// registryTokenPattern 包管理器配置中的认证字段
+	registryTokenPattern = regexp.MustCompile(`(?im)(_authToken|_auth|_password|npmAuthToken|npmAuthIdent|^\s*password)\s*[=:]\s*\S+`)
+	// kubeconfigPatterns kubeconfig 必须同时包含的字段
+	kubeconfigPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`(?m)^kind:\s*["']?Config["']?\s*$`),
+		regexp.MustCompile(`(?m)^clusters:`),
+		regexp.MustCompile(`(?m)^users:`),
+	}
+)
+
+// DetectSensitiveFile 根据文件名和内容判断文件是否不应发送给LLM，返回原因
+func DetectSensitiveFile(path, content string) (string, bool) {
+	name := strings.ToLower(filepath.Base(path))
+	slashPath := strings.ToLower(filepath.ToSlash(path))
+
+	// .env 及其变体，如 .env.production、.envrc、.env_local
+	if strings.HasPrefix(name, ".env") {
+		for _, suffix := range envTemplateSuffixes {
+			if strings.HasSuffix(name, suffix) {
+				return "", false
+			}
+		}
+		return "环境变量文件", true
+	}
+
+	if reason, ok := sensitiveFileNames[name]; ok {
+		return reason, true
+	}
+	if reason, ok := sensitiveExtensions[filepath.Ext(name)]; ok {
+		return reason, true
+	}
+	if strings.HasSuffix(slashPath, ".kube/config") {
+		return "Kubernetes集群凭证", true
+	}
+
+	if registryConfigNames[name] && registryTokenPattern.MatchString(content) {
+		return "包含认证令牌的包管理器配置", true
+	}
+
+	// 内容特征：扩展名无法识别的私钥和kubeconfig
+	if privateKeyPattern.MatchString(content) {
+		return "私钥", true
+	}
+	if isKubeconfig(content) {
+		return "Kubernetes集群凭证", true
+	}
+
+	return "", false
+}
+
+// isKubeconfig 检查内容是否为kubeconfig
+func isKubeconfig(content string) bool {
+	if content == "" {
+		return false
+	}
+	for _, pattern := range kubeconfigPatterns {
+		if !pattern.MatchString(content) {
+			return false
+		}
+	}
+	return true
+}
+
+// RemoveSensitiveFiles 从上下文数据中移除敏感文件，返回被移除的文件
+//
+// 目录树中的文件只有元信息，按路径匹配文件列表中检测出的敏感文件后移除，
+// 并从所在文件夹及所有上级文件夹的大小、文件数和token数中扣除。
+func RemoveSensitiveFiles(data *types.ContextData) []SensitiveFile {
+	var removed []removedFile
+
+	data.Files = filterSensitive(data.Files, nil, &removed)
+	byPath := make(map[string]removedFile, len(removed))
+	for _, file := range removed {
+		data.FileCount--
+		data.TotalSize -= file.size
+		byPath[filepath.Clean(file.Path)] = file
+	}
+
+	// 文件夹中的文件同样会被输出
+	for i := range data.Folders {
+		removeFromFolder(&data.Folders[i], byPath, &removed)
+	}
+
+	// 同一文件可能同时出现在文件列表和所在文件夹中
+	var result []SensitiveFile
+	seen := make(map[string]bool)
+	for _, file := range removed {
+		if !seen[file.Path] {
+			seen[file.Path] = true
+			result = append(result, file.SensitiveFile)
+		}
+	}
+	return result
+}
+
+// removedFile 被移除的文件及其大小和token数
+type removedFile struct {
+	SensitiveFile
+	size   int64
+	tokens int
+}
+
+// removeFromFolder 递归移除文件夹中的敏感文件，更新并返回文件夹中被移除文件的汇总
+func removeFromFolder(folder *types.FolderInfo, byPath map[string]removedFile, removed *[]removedFile) (size int64, count, tokens int) {
+	before := len(*removed)
+	folder.Files = filterSensitive(folder.Files, byPath, removed)
+	for _, file := range (*removed)[before:] {
+		size += file.size
+		tokens += file.tokens
+		count++
+	}
+
+	for i := range folder.Folders {
+		s, c, t := removeFromFolder(&folder.Folders[i], byPath, removed)
+		size += s
+		count += c
+		tokens += t
+	}
+
+	folder.Size -= size
+	folder.Count -= count
+	folder.Tokens -= tokens
+	return size, count, tokens
+}
+
+// filterSensitive 过滤文件列表中的敏感文件，已知的敏感文件按路径匹配，其余按文件名和内容检测
+func filterSensitive(files []types.FileInfo, known map[string]removedFile, removed *[]removedFile) []types.FileInfo {
+	kept := files[:0]
+	for _, file := range files {
+		if match, ok := known[filepath.Clean(file.Path)]; ok {
+			*removed = append(*removed, match)
+			continue
+		}
+		if reason, ok := DetectSensitiveFile(file.Path, file.Content); ok {
+			*removed = append(*removed, removedFile{
+				SensitiveFile: SensitiveFile{Path: file.Path, Reason: reason},
+				size:          file.Size,
+				tokens:        len(file.Content) / bytesPerToken,
+			})
+			continue
+		}
+		kept = append(kept, file)
+	}
+	return kept
+}
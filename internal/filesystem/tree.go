@@ -0,0 +1,86 @@
+package filesystem
+
+import (
+	"path/filepath"
+	"sort"
+
+	"code-context-generator/pkg/types"
+)
+
+// bytesPerToken 估算token数时每个token对应的字节数，与格式化输出一致
+const bytesPerToken = 4
+
+// BuildFolderTree 将扁平的文件夹列表和文件组装为目录树
+//
+// 父文件夹不在列表中的文件夹作为顶层节点返回。文件挂到所在文件夹下，只保留元信息；
+// 文件夹的大小、文件数和token数汇总到所有上级文件夹。子节点按名称排序。
+func BuildFolderTree(folders []types.FolderInfo, files []types.FileInfo) []types.FolderInfo {
+	type node struct {
+		folder   types.FolderInfo
+		children []*node
+	}
+
+	nodes := make(map[string]*node, len(folders))
+	order := make([]string, 0, len(folders))
+	for _, folder := range folders {
+		key := filepath.Clean(folder.Path)
+		if _, ok := nodes[key]; ok {
+			continue
+		}
+		folder.Files = nil
+		folder.Folders = nil
+		folder.Size = 0
+		folder.Count = 0
+		folder.Tokens = 0
+		nodes[key] = &node{folder: folder}
+		order = append(order, key)
+	}
+
+	// 文件挂到所在文件夹，并把统计值累加到所有上级文件夹
+	for _, file := range files {
+		tokens := len(file.Content) / bytesPerToken
+		entry := file
+		entry.Content = ""
+		entry.Issues = nil
+
+		attached := false
+		for dir := filepath.Dir(filepath.Clean(file.Path)); ; dir = filepath.Dir(dir) {
+			if n, ok := nodes[dir]; ok {
+				if !attached {
+					n.folder.Files = append(n.folder.Files, entry)
+					attached = true
+				}
+				n.folder.Size += file.Size
+				n.folder.Count++
+				n.folder.Tokens += tokens
+			}
+			if parent := filepath.Dir(dir); parent == dir {
+				break
+			}
+		}
+	}
+
+	var roots []*node
+	for _, key := range order {
+		n := nodes[key]
+		if parent, ok := nodes[filepath.Dir(key)]; ok && filepath.Dir(key) != key {
+			parent.children = append(parent.children, n)
+		} else {
+			roots = append(roots, n)
+		}
+	}
+
+	var build func(list []*node) []types.FolderInfo
+	build = func(list []*node) []types.FolderInfo {
+		sort.Slice(list, func(i, j int) bool { return list[i].folder.Name < list[j].folder.Name })
+		result := make([]types.FolderInfo, len(list))
+		for i, n := range list {
+			folder := n.folder
+			sort.Slice(folder.Files, func(a, b int) bool { return folder.Files[a].Name < folder.Files[b].Name })
+			folder.Folders = build(n.children)
+			result[i] = folder
+		}
+		return result
+	}
+	return build(roots)
+}
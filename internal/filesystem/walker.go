@@ -237,19 +237,15 @@ func (w *FileSystemWalker) WalkWithProgress(rootPath string, options *types.Walk
 				}
 				
 				if shouldInclude {
-					folderInfo, err := w.GetFolderInfo(path)
-					if err != nil {
-						mu.Lock()
-						walkErrors = append(walkErrors, fmt.Errorf("获取文件夹信息失败 %s: %w", path, err))
-						mu.Unlock()
-						return nil
-					}
-
+					// 只记录文件夹本身，文件和统计值在遍历结束后组装目录树时填充
 					mu.Lock()
-					contextData.Folders = append(contextData.Folders, *folderInfo)
+					contextData.Folders = append(contextData.Folders, types.FolderInfo{
+						Name:     info.Name(),
+						Path:     path,
+						ModTime:  info.ModTime(),
+						IsHidden: strings.HasPrefix(info.Name(), "."),
+					})
 					contextData.FolderCount++
-					// 总是累加文件夹大小，无论是否包含元信息
-					contextData.TotalSize += folderInfo.Size
 					mu.Unlock()
 				}
 			}
@@ -260,6 +256,9 @@ func (w *FileSystemWalker) WalkWithProgress(rootPath string, options *types.Walk
 
 	wg.Wait()
 
+	// 组装目录树
+	contextData.Folders = BuildFolderTree(contextData.Folders, contextData.Files)
+
 	// 最终进度更新
 	if progressCallback != nil {
 		progressCallback(totalFiles, totalFiles, "完成")
@@ -490,26 +489,15 @@ func (w *FileSystemWalker) processMultipleFiles(files []string, options *types.W
 			// 获取目录信息
 			dirInfo, err := os.Stat(dirPath)
 			if err == nil && dirInfo.IsDir() {
-				// 获取目录中的文件列表（用于计算文件数量）
-				filesInDir, _ := os.ReadDir(dirPath)
-				fileCount := 0
-				for _, entry := range filesInDir {
-					if !entry.IsDir() {
-						fileCount++
-					}
-				}
-
+				// 文件和统计值在处理结束后组装目录树时填充
 				folderInfo := types.FolderInfo{
 					Name:     filepath.Base(dirPath),
 					Path:     dirPath,
-					Files:    []types.FileInfo{}, // 这里不填充具体文件，保持简洁
-					Folders:  nil,
 					ModTime:  dirInfo.ModTime(),
 					IsHidden: strings.HasPrefix(filepath.Base(dirPath), "."),
-					Size:     0, // 目录大小计算复杂，这里设为0
-					Count:    fileCount,
 				}
-				
+
+
 				contextData.Folders = append(contextData.Folders, folderInfo)
 				contextData.FolderCount++
 				processedFolders[dirPath] = true
@@ -522,6 +510,9 @@ func (w *FileSystemWalker) processMultipleFiles(files []string, options *types.W
 		}
 	}
 
+	// 组装目录树
+	contextData.Folders = BuildFolderTree(contextData.Folders, contextData.Files)
+
 	// 最终进度更新
 	if progressCallback != nil {
 		progressCallback(processedFiles, totalFiles, "完成")
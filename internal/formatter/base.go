@@ -68,18 +68,22 @@ func (r RawText) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 
 // SimplifiedFileInfo 简化的文件信息结构（不包含元信息）
 type SimplifiedFileInfo struct {
-	Path    string `json:"path"`
-	Name    string `json:"name"`
-	Size    int64  `json:"size"`
-	Content string `json:"content"`
+	Path    string              `json:"path"`
+	Name    string              `json:"name"`
+	Size    int64               `json:"size"`
+	Hash    string              `json:"hash,omitempty" xml:"Hash,omitempty" toml:"Hash,omitempty"`
+	Content string              `json:"content"`
+	Issues  SimplifiedIssueList `json:"issues,omitempty" xml:"issues,omitempty" toml:"issues,omitempty"`
 }
 
 // SimplifiedFolderInfo 简化的文件夹信息结构（不包含元信息）
 type SimplifiedFolderInfo struct {
-	Path  string `json:"path"`
-	Name  string `json:"name"`
-	Size  int64  `json:"size"`
-	Count int    `json:"count"`
+	Path    string                 `json:"path"`
+	Name    string                 `json:"name"`
+	Size    int64                  `json:"size"`
+	Count   int                    `json:"count"`
+	Tokens  int                    `json:"tokens"`
+	Folders []SimplifiedFolderInfo `json:"folders,omitempty" xml:"folders>folder,omitempty" toml:"folders,omitempty"`
 }
 
 // simplifyFiles 简化文件信息，移除元信息字段
@@ -90,7 +94,9 @@ func (f *BaseFormatter) simplifyFiles(files []types.FileInfo) []SimplifiedFileIn
 			Path:    file.Path,
 			Name:    file.Name,
 			Size:    file.Size,
+			Hash:    file.Hash,
 			Content: file.Content,
+			Issues:  simplifyIssues(file.Issues),
 		}
 	}
 	return simplified
@@ -101,10 +107,12 @@ func (f *BaseFormatter) simplifyFolders(folders []types.FolderInfo) []Simplified
 	simplified := make([]SimplifiedFolderInfo, len(folders))
 	for i, folder := range folders {
 		simplified[i] = SimplifiedFolderInfo{
-			Path:  folder.Path,
-			Name:  folder.Name,
-			Size:  folder.Size,
-			Count: folder.Count,
+			Path:    folder.Path,
+			Name:    folder.Name,
+			Size:    folder.Size,
+			Count:   folder.Count,
+			Tokens:  folder.Tokens,
+			Folders: f.simplifyFolders(folder.Folders),
 		}
 	}
 	return simplified
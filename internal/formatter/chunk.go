@@ -0,0 +1,71 @@
+package formatter
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"code-context-generator/pkg/types"
+)
+
+// chunkFileLabel 返回索引中的文件名，拆分的文件附带片段序号
+func chunkFileLabel(file types.ChunkFile) string {
+	if file.Parts > 0 {
+		return fmt.Sprintf("%s (%d/%d)", file.Path, file.Part, file.Parts)
+	}
+	return file.Path
+}
+
+// writeChunkMarkdown 写入Markdown格式的分块头和文件索引
+func writeChunkMarkdown(result *strings.Builder, chunk *types.ChunkInfo) {
+	if chunk == nil {
+		return
+	}
+	result.WriteString(fmt.Sprintf("## 分块 %d/%d\n\n", chunk.Index, chunk.Total))
+	result.WriteString(fmt.Sprintf("本文件是分块输出的第 %d 部分，清单文件: `%s`\n\n", chunk.Index, chunk.Manifest))
+	result.WriteString("| 分块 | 文件 |\n")
+	result.WriteString("|------|------|\n")
+	for _, entry := range chunk.Chunks {
+		name := fmt.Sprintf("`%s`", entry.File)
+		if entry.Index == chunk.Index {
+			name = fmt.Sprintf("**`%s`** (当前)", entry.File)
+		}
+		labels := make([]string, len(entry.Files))
+		for i, file := range entry.Files {
+			labels[i] = fmt.Sprintf("`%s`", chunkFileLabel(file))
+		}
+		result.WriteString(fmt.Sprintf("| %s | %s |\n", name, strings.Join(labels, ", ")))
+	}
+	result.WriteString("\n")
+}
+
+// formatChunkXML 生成AI优化XML的分块头和文件索引
+func formatChunkXML(chunk *types.ChunkInfo) string {
+	if chunk == nil {
+		return ""
+	}
+	output, err := xml.MarshalIndent(struct {
+		XMLName xml.Name `xml:"chunk_info"`
+		*types.ChunkInfo
+	}{ChunkInfo: chunk}, "", "  ")
+	if err != nil {
+		return ""
+	}
+	return string(output) + "\n"
+}
+
+// chunkNotes 生成纯文本格式的分块说明和文件索引
+func chunkNotes(chunk *types.ChunkInfo) []string {
+	if chunk == nil {
+		return nil
+	}
+	notes := []string{fmt.Sprintf("Part %d of %d, manifest: %s", chunk.Index, chunk.Total, chunk.Manifest)}
+	for _, entry := range chunk.Chunks {
+		labels := make([]string, len(entry.Files))
+		for i, file := range entry.Files {
+			labels[i] = chunkFileLabel(file)
+		}
+		notes = append(notes, fmt.Sprintf("%s: %s", entry.File, strings.Join(labels, ", ")))
+	}
+	return notes
+}
@@ -2,104 +2,240 @@
 package encoding
 
 import (
+	"bytes"
 	"fmt"
 	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+	"golang.org/x/text/encoding/unicode"
 )
 
-// ConvertEncoding converts string encoding
-func ConvertEncoding(input string, targetEncoding string) (string, error) {
-	// This implements encoding conversion logic
-	// Since Go standard library mainly supports UTF-8, we can use third-party libraries like golang.org/x/text/encoding
-	// or simple character mapping tables to implement basic encoding conversion
-
-	switch strings.ToLower(targetEncoding) {
-	case "gbk", "gb2312", "gb18030":
-		// Simplified GBK conversion example (professional libraries should be used in actual projects)
-		return toGBK(input), nil
-	case "big5":
-		// Simplified Big5 conversion example
-		return toBig5(input), nil
-	case "shift_jis", "sjis":
-		// Simplified Shift-JIS conversion example
-		return toShiftJIS(input), nil
-	case "euc-jp":
-		// Simplified EUC-JP conversion example
-		return toEUCJP(input), nil
-	case "iso-8859-1", "latin1":
-		// Simplified ISO-8859-1 conversion example
-		return toISO88591(input), nil
-	case "utf-8", "utf8":
-		return input, nil
-	default:
-		return "", fmt.Errorf("unsupported encoding format: %s", targetEncoding)
-	}
+// Error policies for characters that cannot be represented in the target encoding
+const (
+	PolicyLossy  = "lossy"  // replace them with '?' and report them
+	PolicyStrict = "strict" // fail on the first one
+)
+
+// maxSamples limits how many unencodable characters a Result keeps
+const maxSamples = 20
+
+// charset is a supported target encoding and its canonical (IANA) name
+type charset struct {
+	name     string
+	encoding encoding.Encoding
 }
 
-// Simplified encoding conversion functions (professional libraries should be used in actual projects)
-func toGBK(input string) string {
-	// Professional GBK encoding libraries should be used here
-	// Simplified implementation: only handles ASCII characters, other characters are replaced with ?
-	var result strings.Builder
-	for _, r := range input {
-		if r < 128 {
-			result.WriteRune(r)
-		} else {
-			result.WriteRune('?')
-		}
+// charsets maps lower-case aliases with '_' replaced by '-' to target encodings
+var charsets = map[string]charset{
+	"utf-8":        {"UTF-8", unicode.UTF8},
+	"utf8":         {"UTF-8", unicode.UTF8},
+	"gbk":          {"GBK", simplifiedchinese.GBK},
+	"gb2312":       {"GBK", simplifiedchinese.GBK},
+	"cp936":        {"GBK", simplifiedchinese.GBK},
+	"gb18030":      {"GB18030", simplifiedchinese.GB18030},
+	"big5":         {"Big5", traditionalchinese.Big5},
+	"shift-jis":    {"Shift_JIS", japanese.ShiftJIS},
+	"sjis":         {"Shift_JIS", japanese.ShiftJIS},
+	"euc-jp":       {"EUC-JP", japanese.EUCJP},
+	"euc-kr":       {"EUC-KR", korean.EUCKR},
+	"iso-8859-1":   {"ISO-8859-1", charmap.ISO8859_1},
+	"latin1":       {"ISO-8859-1", charmap.ISO8859_1},
+	"windows-1252": {"windows-1252", charmap.Windows1252},
+	"cp1252":       {"windows-1252", charmap.Windows1252},
+	"utf-16le":     {"UTF-16LE", unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)},
+	"utf-16be":     {"UTF-16BE", unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM)},
+}
+
+// byteOrderMarks holds the BOM of each encoding that has one
+var byteOrderMarks = map[string][]byte{
+	"UTF-8":    {0xEF, 0xBB, 0xBF},
+	"UTF-16LE": {0xFF, 0xFE},
+	"UTF-16BE": {0xFE, 0xFF},
+	"GB18030":  {0x84, 0x31, 0x95, 0x33},
+}
+
+// Options controls how output text is encoded
+type Options struct {
+	Encoding string // target encoding, empty means UTF-8
+	BOM      bool   // write a byte order mark (UTF-8, UTF-16 and GB18030 only)
+	Policy   string // PolicyLossy (default) or PolicyStrict
+}
+
+// Unencodable is a character that the target encoding cannot represent
+type Unencodable struct {
+	Rune   rune
+	Line   int // 1-based line in the output
+	Column int // 1-based column in runes
+}
+
+// String formats the character and its position
+func (u Unencodable) String() string {
+	return fmt.Sprintf("%d:%d %q (U+%04X)", u.Line, u.Column, u.Rune, u.Rune)
+}
+
+// Result is the encoded output together with a report of replaced characters
+type Result struct {
+	Data     []byte
+	Encoding string        // canonical name of the target encoding
+	Replaced int           // number of characters replaced with '?'
+	Samples  []Unencodable // the first replaced characters, at most maxSamples
+}
+
+// Lookup returns the encoding for name and its canonical name
+func Lookup(name string) (encoding.Encoding, string, error) {
+	key := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), "_", "-")
+	if key == "" {
+		key = "utf-8"
+	}
+	cs, ok := charsets[key]
+	if !ok {
+		return nil, "", fmt.Errorf("unsupported encoding format: %s", name)
 	}
-	return result.String()
+	return cs.encoding, cs.name, nil
 }
 
-func toBig5(input string) string {
-	// Simplified implementation: only handles ASCII characters
-	var result strings.Builder
-	for _, r := range input {
-		if r < 128 {
-			result.WriteRune(r)
-		} else {
-			result.WriteRune('?')
-		}
+// IsUTF8 reports whether name refers to UTF-8 (an empty name does too)
+func IsUTF8(name string) bool {
+	_, canonical, err := Lookup(name)
+	return err == nil && canonical == "UTF-8"
+}
+
+// Validate checks the encoding name, error policy and BOM option
+func (o Options) Validate() error {
+	_, _, err := o.resolve()
+	return err
+}
+
+// resolve looks up the target encoding and validates the other options
+func (o Options) resolve() (encoding.Encoding, string, error) {
+	enc, name, err := Lookup(o.Encoding)
+	if err != nil {
+		return nil, "", err
+	}
+	switch strings.ToLower(o.Policy) {
+	case "", PolicyLossy, PolicyStrict:
+	default:
+		return nil, "", fmt.Errorf("unsupported encoding error policy: %s (expected %s or %s)", o.Policy, PolicyLossy, PolicyStrict)
 	}
-	return result.String()
+	if _, ok := byteOrderMarks[name]; o.BOM && !ok {
+		return nil, "", fmt.Errorf("encoding %s has no byte order mark", name)
+	}
+	return enc, name, nil
 }
 
-func toShiftJIS(input string) string {
-	// Simplified implementation: only handles ASCII characters
-	var result strings.Builder
-	for _, r := range input {
-		if r < 128 {
-			result.WriteRune(r)
+// Encode converts UTF-8 text to the target encoding.
+//
+// Characters the target encoding cannot represent are replaced with '?' and
+// reported in the Result, or cause an error naming their position when the
+// policy is PolicyStrict.
+func Encode(input string, opts Options) (*Result, error) {
+	enc, name, err := opts.resolve()
+	if err != nil {
+		return nil, err
+	}
+	strict := strings.EqualFold(opts.Policy, PolicyStrict)
+
+	result := &Result{Encoding: name}
+	var buf bytes.Buffer
+	if opts.BOM {
+		buf.Write(byteOrderMarks[name])
+	}
+	if name == "UTF-8" {
+		buf.WriteString(input)
+		result.Data = buf.Bytes()
+		return result, nil
+	}
+
+	encoder := enc.NewEncoder()
+	if out, err := encoder.String(input); err == nil {
+		buf.WriteString(out)
+		result.Data = buf.Bytes()
+		return result, nil
+	}
+
+	// Slow path: re-encode failing lines rune by rune to locate the characters
+	replacement, err := encoder.String("?")
+	if err != nil {
+		return nil, fmt.Errorf("encoding %s cannot represent the replacement character: %w", name, err)
+	}
+	line := 1
+	for start := 0; start < len(input); line++ {
+		end := strings.IndexByte(input[start:], '\n')
+		if end < 0 {
+			end = len(input)
 		} else {
-			result.WriteRune('?')
+			end += start + 1
+		}
+		text := input[start:end]
+		start = end
+
+		if out, err := encoder.String(text); err == nil && utf8.ValidString(text) {
+			buf.WriteString(out)
+			continue
+		}
+		column := 0
+		for i := 0; i < len(text); {
+			r, size := utf8.DecodeRuneInString(text[i:])
+			column++
+			out, err := encoder.String(text[i : i+size])
+			i += size
+			if err == nil && (r != utf8.RuneError || size > 1) {
+				buf.WriteString(out)
+				continue
+			}
+
+			u := Unencodable{Rune: r, Line: line, Column: column}
+			if strict {
+				return nil, fmt.Errorf("line %d, column %d: character %q (U+%04X) cannot be encoded as %s", u.Line, u.Column, u.Rune, u.Rune, name)
+			}
+			result.Replaced++
+			if len(result.Samples) < maxSamples {
+				result.Samples = append(result.Samples, u)
+			}
+			buf.WriteString(replacement)
 		}
 	}
-	return result.String()
+	result.Data = buf.Bytes()
+	return result, nil
 }
 
-func toEUCJP(input string) string {
-	// Simplified implementation: only handles ASCII characters
-	var result strings.Builder
-	for _, r := range input {
-		if r < 128 {
-			result.WriteRune(r)
-		} else {
-			result.WriteRune('?')
-		}
+// ConvertEncoding converts string encoding, replacing unencodable characters with '?'
+func ConvertEncoding(input string, targetEncoding string) (string, error) {
+	result, err := Encode(input, Options{Encoding: targetEncoding})
+	if err != nil {
+		return "", err
 	}
-	return result.String()
+	return string(result.Data), nil
 }
 
-func toISO88591(input string) string {
-	// Simplified implementation: only handles ASCII characters
-	var result strings.Builder
-	for _, r := range input {
-		if r < 256 {
-			result.WriteRune(r)
-		} else {
-			result.WriteRune('?')
-		}
+// SetXMLDeclaration rewrites the encoding attribute of a leading XML declaration.
+// It leaves input without a declaration unchanged.
+func SetXMLDeclaration(input string, encodingName string) string {
+	if !strings.HasPrefix(input, "<?xml") {
+		return input
+	}
+	end := strings.Index(input, "?>")
+	if end < 0 {
+		return input
 	}
-	return result.String()
+	declaration := input[:end]
+	attr := strings.Index(declaration, "encoding=")
+	if attr < 0 || attr+len("encoding=") >= len(declaration) {
+		return input
+	}
+	valueStart := attr + len("encoding=") + 1
+	quote := declaration[valueStart-1]
+	valueEnd := strings.IndexByte(declaration[valueStart:], quote)
+	if valueEnd < 0 {
+		return input
+	}
+	return input[:valueStart] + encodingName + input[valueStart+valueEnd:]
 }
 
 // EscapeTOMLString escapes TOML strings
@@ -111,4 +247,4 @@ func EscapeTOMLString(s string) string {
 	s = strings.ReplaceAll(s, "\r", "\\r")
 	s = strings.ReplaceAll(s, "\t", "\\t")
 	return s
-}
\ No newline at end of file
+}
@@ -0,0 +1,125 @@
+// Package encoding 提供编码转换的单元测试
+package encoding
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestEncode 测试转换为各目标编码
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		opts     Options
+		expected []byte
+	}{
+		{"UTF-8原样输出", "中文", Options{}, []byte("中文")},
+		{"UTF-8 BOM", "a", Options{Encoding: "utf8", BOM: true}, []byte{0xEF, 0xBB, 0xBF, 'a'}},
+		{"GBK", "a中文", Options{Encoding: "GBK"}, []byte{'a', 0xD6, 0xD0, 0xCE, 0xC4}},
+		{"GB2312别名", "中", Options{Encoding: "gb2312"}, []byte{0xD6, 0xD0}},
+		{"GB18030表示全部字符", "😀", Options{Encoding: "gb18030"}, []byte{0x94, 0x39, 0xFC, 0x36}},
+		{"Big5", "中文", Options{Encoding: "big5"}, []byte{0xA4, 0xA4, 0xA4, 0xE5}},
+		{"Shift_JIS", "日本", Options{Encoding: "shift_jis"}, []byte{0x93, 0xFA, 0x96, 0x7B}},
+		{"EUC-JP", "日本", Options{Encoding: "EUC-JP"}, []byte{0xC6, 0xFC, 0xCB, 0xDC}},
+		{"Latin-1", "café", Options{Encoding: "latin1"}, []byte{'c', 'a', 'f', 0xE9}},
+		{"UTF-16LE BOM", "a中", Options{Encoding: "utf-16le", BOM: true}, []byte{0xFF, 0xFE, 'a', 0x00, 0x2D, 0x4E}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Encode(tt.input, tt.opts)
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if !bytes.Equal(result.Data, tt.expected) {
+				t.Errorf("Encode() = % X, 期望 % X", result.Data, tt.expected)
+			}
+			if result.Replaced != 0 {
+				t.Errorf("不应该替换字符，实际替换了 %d 个", result.Replaced)
+			}
+		})
+	}
+}
+
+// TestEncodeUnencodable 测试无法编码的字符的替换、报告和严格模式
+func TestEncodeUnencodable(t *testing.T) {
+	input := "// 中文注释\nx := \"😀\" // é\n"
+
+	result, err := Encode(input, Options{Encoding: "gbk"})
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if result.Encoding != "GBK" || result.Replaced != 1 {
+		t.Fatalf("Encode() 编码 = %s, 替换 = %d", result.Encoding, result.Replaced)
+	}
+	expectedSamples := []Unencodable{{Rune: '😀', Line: 2, Column: 7}}
+	if !reflect.DeepEqual(result.Samples, expectedSamples) {
+		t.Errorf("Samples = %v, 期望 %v", result.Samples, expectedSamples)
+	}
+	// 可以编码的中文和 é 保留，只有 😀 被替换
+	if !bytes.Contains(result.Data, []byte("x := \"?\" // \xA8\xA6\n")) || !bytes.HasPrefix(result.Data, []byte("// \xD6\xD0\xCE\xC4")) {
+		t.Errorf("Encode() = %q", result.Data)
+	}
+
+	result, err = Encode("a\xffb", Options{Encoding: "latin1"})
+	if err != nil || string(result.Data) != "a?b" || result.Replaced != 1 {
+		t.Errorf("无效的UTF-8应该被替换: %q, %v", result.Data, err)
+	}
+
+	_, err = Encode(input, Options{Encoding: "gbk", Policy: PolicyStrict})
+	if err == nil || !strings.Contains(err.Error(), "line 2, column 7") {
+		t.Errorf("严格模式应该返回包含位置的错误，实际: %v", err)
+	}
+
+	many := strings.Repeat("😀", maxSamples+5)
+	result, err = Encode(many, Options{Encoding: "big5"})
+	if err != nil || result.Replaced != maxSamples+5 || len(result.Samples) != maxSamples {
+		t.Errorf("替换 = %d, 样例 = %d, error = %v", result.Replaced, len(result.Samples), err)
+	}
+}
+
+// TestOptionsValidate 测试编码选项校验
+func TestOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+	}{
+		{"默认", Options{}, false},
+		{"严格模式", Options{Encoding: "gbk", Policy: "STRICT"}, false},
+		{"GB18030 BOM", Options{Encoding: "gb18030", BOM: true}, false},
+		{"不支持的编码", Options{Encoding: "ebcdic"}, true},
+		{"无效的策略", Options{Policy: "ignore"}, true},
+		{"GBK没有BOM", Options{Encoding: "gbk", BOM: true}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.opts.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestSetXMLDeclaration 测试改写XML声明中的编码
+func TestSetXMLDeclaration(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`<?xml version="1.0" encoding="UTF-8"?>` + "\n<a/>", `<?xml version="1.0" encoding="GBK"?>` + "\n<a/>"},
+		{`<?xml version='1.0' encoding='utf-8'?><a/>`, `<?xml version='1.0' encoding='GBK'?><a/>`},
+		{`<?xml version="1.0"?><a/>`, `<?xml version="1.0"?><a/>`},
+		{"# 标题\nencoding=\"UTF-8\"", "# 标题\nencoding=\"UTF-8\""},
+	}
+
+	for _, tt := range tests {
+		if got := SetXMLDeclaration(tt.input, "GBK"); got != tt.expected {
+			t.Errorf("SetXMLDeclaration(%q) = %q, 期望 %q", tt.input, got, tt.expected)
+		}
+	}
+}
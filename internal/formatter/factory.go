@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"code-context-generator/internal/formatter/encoding"
 	"code-context-generator/pkg/types"
 )
 
@@ -37,6 +38,15 @@ func (f *FormatterFactory) RegisterFormatters() {
 	
 	// 注册Markdown格式转换器
 	f.Register("markdown", NewMarkdownFormatter(nil))
+	
+	// 注册YAML格式转换器
+	f.Register("yaml", NewYAMLFormatter(nil))
+	
+	// 注册JSON Lines格式转换器
+	f.Register("ndjson", NewNDJSONFormatter(nil))
+	
+	// 注册纯文本格式转换器
+	f.Register("plain", NewPlainFormatter(nil))
 }
 
 // Register 注册格式转换器
@@ -51,9 +61,19 @@ func (f *FormatterFactory) Get(name string) (Formatter, error) {
 
 // GetFormatter 获取格式转换器
 func (f *FormatterFactory) GetFormatter(name string, config *types.Config) (Formatter, error) {
+	// 自定义模板文件，路径保持原样
+	if len(name) > len(TemplateFormatPrefix) && strings.EqualFold(name[:len(TemplateFormatPrefix)], TemplateFormatPrefix) {
+		return newTemplateFormatterFromSpec(config, name[len(TemplateFormatPrefix):])
+	}
+
 	// 大小写不敏感处理
 	name = strings.ToLower(name)
 	
+	// 格式配置了模板时使用模板渲染
+	if spec := configuredTemplate(config, name); spec != "" {
+		return newTemplateFormatterFromSpec(config, spec)
+	}
+	
 	// 根据名称创建对应的格式转换器实例
 	switch name {
 	case "json":
@@ -64,6 +84,12 @@ func (f *FormatterFactory) GetFormatter(name string, config *types.Config) (Form
 		return NewTOMLFormatter(config), nil
 	case "markdown", "md":
 		return NewMarkdownFormatter(config), nil
+	case "yaml", "yml":
+		return NewYAMLFormatter(config), nil
+	case "ndjson", "jsonl":
+		return NewNDJSONFormatter(config), nil
+	case "plain", "txt":
+		return NewPlainFormatter(config), nil
 	default:
 		return nil, fmt.Errorf("不支持的格式: %s", name)
 	}
@@ -102,4 +128,47 @@ func NewFormatter(format string, config *types.Config) (Formatter, error) {
 func CreateDefaultFactory(config *types.Config) *FormatterFactory {
 	factory := NewFormatterFactory()
 	return factory
-}
\ No newline at end of file
+}
+
+// EncodingOptions 返回写出该格式输出时使用的编码选项
+//
+// 格式化器始终输出UTF-8，由调用方在写入文件时转换编码。
+// output.encoding（或 --encoding）指定非UTF-8编码时优先，否则使用 formats.<格式>.encoding。
+func EncodingOptions(config *types.Config, format string) encoding.Options {
+	if config == nil {
+		return encoding.Options{}
+	}
+	opts := encoding.Options{
+		Encoding: config.Output.Encoding,
+		BOM:      config.Output.BOM,
+		Policy:   config.Output.EncodingErrors,
+	}
+	if encoding.IsUTF8(opts.Encoding) {
+		if formatEncoding := configuredEncoding(config, strings.ToLower(format)); formatEncoding != "" {
+			opts.Encoding = formatEncoding
+		}
+	}
+	return opts
+}
+
+// configuredEncoding 返回格式配置中的编码
+func configuredEncoding(config *types.Config, format string) string {
+	switch format {
+	case "json":
+		return config.Formats.JSON.Encoding
+	case "xml":
+		return config.Formats.XML.FormatConfig.Encoding
+	case "toml":
+		return config.Formats.TOML.Encoding
+	case "markdown", "md":
+		return config.Formats.Markdown.Encoding
+	case "yaml", "yml":
+		return config.Formats.YAML.Encoding
+	case "ndjson", "jsonl":
+		return config.Formats.NDJSON.Encoding
+	case "plain", "txt":
+		return config.Formats.Plain.Encoding
+	default:
+		return ""
+	}
+}
@@ -1,14 +1,20 @@
 package formatter
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	"code-context-generator/internal/formatter/encoding"
 	"code-context-generator/pkg/types"
+	"github.com/goccy/go-yaml"
 )
 
 // 测试辅助函数
@@ -387,10 +393,10 @@ func TestFormatterFactory(t *testing.T) {
 		t.Error("Expected error for nonexistent format")
 	}
 
-	// 测试获取支持的格式（工厂默认注册了4种格式）
+	// 测试获取支持的格式（工厂默认注册了7种格式）
 	supportedFormats := factory.GetSupportedFormats()
-	if len(supportedFormats) != 4 { // json, xml, toml, markdown
-		t.Errorf("Expected 4 supported formats, got %d", len(supportedFormats))
+	if len(supportedFormats) != 7 { // json, xml, toml, markdown, yaml, ndjson, plain
+		t.Errorf("Expected 7 supported formats, got %d", len(supportedFormats))
 	}
 }
 
@@ -732,3 +738,607 @@ func TestEscapeTOMLString(t *testing.T) {
 		}
 	}
 }
+
+// upperMasker 测试用遮蔽器，将 secret 替换为 ***
+type upperMasker struct{}
+
+func (upperMasker) Mask(content string) (string, int) {
+	return strings.ReplaceAll(content, "secret", "***"), strings.Count(content, "secret")
+}
+
+func TestMaskingFormatter_Format(t *testing.T) {
+	data := types.ContextData{
+		Files: []types.FileInfo{{Path: "a.txt", Name: "a.txt", Content: "secret one"}},
+		Folders: []types.FolderInfo{{
+			Path:  "dir",
+			Name:  "dir",
+			Files: []types.FileInfo{{Path: "dir/b.txt", Name: "b.txt", Content: "secret two secret"}},
+		}},
+	}
+
+	formatter := NewMaskingFormatter(NewJSONFormatter(nil), upperMasker{})
+	result, err := formatter.Format(data)
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	if strings.Contains(result, "secret") {
+		t.Error("Format() 输出不应包含被遮蔽的内容")
+	}
+	if formatter.Masked() != 3 {
+		t.Errorf("Masked() = %d, want 3", formatter.Masked())
+	}
+	if data.Files[0].Content != "secret one" || data.Folders[0].Files[0].Content != "secret two secret" {
+		t.Error("Format() 不应修改调用方的数据")
+	}
+}
+
+// TestMaskingFormatter_GitMetadata 测试遮蔽Git元数据中的作者、邮箱和提交信息
+func TestMaskingFormatter_GitMetadata(t *testing.T) {
+	git := &types.GitIntegrationData{
+		GitInfo: &types.GitInfo{
+			IsGitRepo:  true,
+			LastCommit: &types.CommitInfo{Hash: "abc", Author: "secret", Email: "secret@example.com", Message: "fix secret"},
+		},
+		GitHistory: &types.GitHistory{
+			Commits:      []types.CommitInfo{{Hash: "def", Author: "secret", Message: "add secret"}},
+			Contributors: []string{"secret <secret@example.com>"},
+		},
+		GitDiffs: []types.CommitDiff{{CommitHash: "def", Files: []types.FileDiff{{FilePath: "a.txt", Diff: "+secret"}}}},
+		GitStats: &types.GitStats{AuthorStats: []types.AuthorStat{{Name: "secret", Commits: 1}}},
+	}
+	data := types.ContextData{Metadata: map[string]interface{}{"git": git, "tool": "c-gen"}}
+
+	formatter := NewMaskingFormatter(NewNDJSONFormatter(nil), upperMasker{})
+	result, err := formatter.Format(data)
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	if strings.Contains(result, "secret") || !strings.Contains(result, "fix ***") {
+		t.Errorf("Format() 输出不应包含Git元数据中被遮蔽的内容: %s", result)
+	}
+	if formatter.Masked() != 9 {
+		t.Errorf("Masked() = %d, want 9", formatter.Masked())
+	}
+	if git.GitInfo.LastCommit.Author != "secret" || git.GitHistory.Contributors[0] != "secret <secret@example.com>" ||
+		git.GitDiffs[0].Files[0].Diff != "+secret" || data.Metadata["git"] != git {
+		t.Error("Format() 不应修改调用方的Git元数据")
+	}
+}
+
+func TestFormatters_SecurityFindings(t *testing.T) {
+	data := types.ContextData{
+		Security: &types.ScanSummary{ScannedFiles: 1, IssuesFound: 1, HighIssues: 1},
+		Files: []types.FileInfo{{
+			Path:    "config.py",
+			Name:    "config.py",
+			Content: "password = 'x'",
+			Issues: []types.SecurityIssue{{
+				Type:     "HardcodedCredentials",
+				Severity: types.SeverityHigh,
+				Line:     1,
+				Message:  "发现硬编码密码",
+			}},
+		}},
+		FileCount: 1,
+	}
+
+	tests := []struct {
+		name      string
+		formatter Formatter
+		expected  []string
+	}{
+		{"JSON", NewJSONFormatter(nil), []string{`"security"`, `"issues_found": 1`, `"issues"`, `"severity": "high"`}},
+		{"XML", NewXMLFormatter(nil), []string{"<security>", "<issues_found>1</issues_found>", `<issue severity="high" type="HardcodedCredentials" line="1">`}},
+		{"TOML", NewTOMLFormatter(nil), []string{"[security]", "issues_found = 1", "[[files.issues]]"}},
+		{"Markdown", NewMarkdownFormatter(nil), []string{"## 安全扫描", "> [!WARNING]", "第 1 行: 发现硬编码密码"}},
+		{"YAML", NewYAMLFormatter(nil), []string{"security:", "issues_found: 1", "severity: high"}},
+		{"NDJSON", NewNDJSONFormatter(nil), []string{`"type":"security"`, `"issues_found":1`, `"severity":"high"`}},
+		{"Plain", NewPlainFormatter(nil), []string{"Security:\n", "1 issues found", "config.py:1 [high] 发现硬编码密码"}},
+		{"XML AI优化", NewXMLFormatter(&types.Config{Output: types.OutputConfig{AIOptimized: true}}), []string{"<security_summary", "<issues>"}},
+		{"Markdown AI优化", NewMarkdownFormatter(&types.Config{Output: types.OutputConfig{AIOptimized: true}}), []string{"## 安全扫描", "> [!WARNING]"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.formatter.Format(data)
+			if err != nil {
+				t.Fatalf("Format() error = %v", err)
+			}
+			for _, expected := range tt.expected {
+				if !strings.Contains(result, expected) {
+					t.Errorf("Format() 输出应该包含 %q", expected)
+				}
+			}
+		})
+	}
+
+	// 未执行安全扫描时不输出安全相关内容
+	data.Security = nil
+	data.Files[0].Issues = nil
+	result, err := NewJSONFormatter(nil).Format(data)
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if strings.Contains(result, "security") || strings.Contains(result, "issues") {
+		t.Error("未扫描时输出不应包含安全信息")
+	}
+}
+
+// TestFormatters_LicenseReport 测试各格式输出许可证合规摘要
+func TestFormatters_LicenseReport(t *testing.T) {
+	data := types.ContextData{
+		License: &types.LicenseReport{
+			ProjectLicense: "MIT",
+			Directories:    []types.DirectoryLicense{{Path: ".", Licenses: []string{"MIT"}}},
+			Conflicts: []types.LicenseConflict{{
+				Path:      "vendor/COPYING",
+				License:   "GPL-3.0-only",
+				Governing: "MIT",
+				Message:   "GPL-3.0-only 比 MIT 更严格",
+			}},
+			FilesWithoutHeader: []string{"main.go"},
+		},
+		Files:     []types.FileInfo{{Path: "main.go", Name: "main.go", Content: "package main"}},
+		FileCount: 1,
+	}
+
+	tests := []struct {
+		name      string
+		formatter Formatter
+		expected  []string
+	}{
+		{"JSON", NewJSONFormatter(nil), []string{`"license"`, `"project_license": "MIT"`, `"governing_license": "MIT"`}},
+		{"XML", NewXMLFormatter(nil), []string{"<license>", "<project_license>MIT</project_license>", `<conflict path="vendor/COPYING"`}},
+		{"TOML", NewTOMLFormatter(nil), []string{"[license]", "project_license = \"MIT\"", "[[license.conflicts]]"}},
+		{"Markdown", NewMarkdownFormatter(nil), []string{"## 许可证", "> [!CAUTION]", "`main.go`"}},
+		{"YAML", NewYAMLFormatter(nil), []string{"license:", "project_license: MIT", "governing_license: MIT"}},
+		{"NDJSON", NewNDJSONFormatter(nil), []string{`"type":"license"`, `"project_license":"MIT"`}},
+		{"Plain", NewPlainFormatter(nil), []string{"License:\n", "- Project license: MIT", "- Conflict in vendor/COPYING"}},
+		{"XML AI优化", NewXMLFormatter(&types.Config{Output: types.OutputConfig{AIOptimized: true}}), []string{`<license_summary project_license="MIT"`, "<conflict"}},
+		{"Markdown AI优化", NewMarkdownFormatter(&types.Config{Output: types.OutputConfig{AIOptimized: true}}), []string{"## 许可证", "- **项目许可证**: MIT"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.formatter.Format(data)
+			if err != nil {
+				t.Fatalf("Format() error = %v", err)
+			}
+			for _, expected := range tt.expected {
+				if !strings.Contains(result, expected) {
+					t.Errorf("Format() 输出应该包含 %q", expected)
+				}
+			}
+		})
+	}
+
+	// 未启用许可证检测时不输出许可证信息
+	data.License = nil
+	result, err := NewJSONFormatter(nil).Format(data)
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if strings.Contains(result, "license") {
+		t.Error("未检测时输出不应包含许可证信息")
+	}
+}
+
+// TestFormatters_ChunkInfo 测试各格式输出分块头和文件索引
+func TestFormatters_ChunkInfo(t *testing.T) {
+	entries := []types.ChunkEntry{
+		{Index: 1, File: "context-001.out", Files: []types.ChunkFile{{Path: "a.go", Size: 12, Tokens: 3}, {Path: "big.go", Part: 1, Parts: 2, Size: 40, Tokens: 10}}},
+		{Index: 2, File: "context-002.out", Files: []types.ChunkFile{{Path: "big.go", Part: 2, Parts: 2, Size: 20, Tokens: 5}}},
+	}
+	data := types.ContextData{
+		Chunk:     &types.ChunkInfo{Index: 2, Total: 2, Manifest: "context-manifest.json", Chunks: entries},
+		Files:     []types.FileInfo{{Path: "big.go", Name: "big.go", Content: "func b() {}\n"}},
+		FileCount: 1,
+	}
+
+	tests := []struct {
+		name      string
+		formatter Formatter
+		expected  []string
+	}{
+		{"JSON", NewJSONFormatter(nil), []string{`"chunk"`, `"manifest": "context-manifest.json"`, `"part": 2`}},
+		{"XML", NewXMLFormatter(nil), []string{`<chunk_info index="2" total="2" manifest="context-manifest.json">`, `<entry path="big.go" part="2" parts="2"`}},
+		{"TOML", NewTOMLFormatter(nil), []string{"[chunk]", "[[chunk.chunks]]", "manifest = \"context-manifest.json\""}},
+		{"Markdown", NewMarkdownFormatter(nil), []string{"## 分块 2/2", "**`context-002.out`** (当前)", "`big.go (1/2)`"}},
+		{"YAML", NewYAMLFormatter(nil), []string{"chunk:", "manifest: context-manifest.json"}},
+		{"NDJSON", NewNDJSONFormatter(nil), []string{`"chunk":{"index":2,"total":2`}},
+		{"Plain", NewPlainFormatter(nil), []string{"Chunk:\n", "- Part 2 of 2, manifest: context-manifest.json", "- context-001.out: a.go, big.go (1/2)"}},
+		{"XML AI优化", NewXMLFormatter(&types.Config{Output: types.OutputConfig{AIOptimized: true}}), []string{`<chunk_info index="2"`, `<chunk index="1" file="context-001.out"`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.formatter.Format(data)
+			if err != nil {
+				t.Fatalf("Format() error = %v", err)
+			}
+			for _, expected := range tt.expected {
+				if !strings.Contains(result, expected) {
+					t.Errorf("Format() 输出应该包含 %q", expected)
+				}
+			}
+		})
+	}
+}
+
+// TestYAMLFormatter_Format 测试YAML输出的字面量块、内容往返和自定义字段名
+func TestYAMLFormatter_Format(t *testing.T) {
+	contents := []string{
+		"package main\n\nfunc main() {\n\tprintln(\"Hello World\")\n}\n",
+		"no trailing newline\nsecond line",
+		"  indented first line\nnext\n",
+		"\n\n  after blank lines\n",
+		"windows\r\nline endings\r\n",
+		"trailing blank lines\n\n\n",
+		"key: value # not a comment\n- not a list\n",
+		"single line",
+		"",
+	}
+	data := types.ContextData{FileCount: len(contents)}
+	for i, content := range contents {
+		data.Files = append(data.Files, types.FileInfo{Path: fmt.Sprintf("f%d.txt", i), Name: fmt.Sprintf("f%d.txt", i), Content: content})
+	}
+
+	result, err := NewYAMLFormatter(nil).Format(data)
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if !strings.Contains(result, "content: |\n      package main\n") {
+		t.Errorf("多行内容应使用字面量块:\n%s", result)
+	}
+
+	var parsed struct {
+		Files []struct {
+			Path    string `yaml:"path"`
+			Content string `yaml:"content"`
+		} `yaml:"files"`
+		FileCount int `yaml:"file_count"`
+	}
+	if err := yaml.Unmarshal([]byte(result), &parsed); err != nil {
+		t.Fatalf("输出不是有效的YAML: %v\n%s", err, result)
+	}
+	if len(parsed.Files) != len(contents) || parsed.FileCount != len(contents) {
+		t.Fatalf("期望 %d 个文件，实际 %d 个", len(contents), len(parsed.Files))
+	}
+	for i, content := range contents {
+		if parsed.Files[i].Content != content {
+			t.Errorf("文件 %d 内容往返后不一致: got %q, want %q", i, parsed.Files[i].Content, content)
+		}
+	}
+
+	// 自定义字段名
+	config := &types.Config{Fields: types.FieldsConfig{CustomNames: map[string]string{
+		"filepath":    "relative_path",
+		"filecontent": "source",
+	}}}
+	result, err = NewYAMLFormatter(config).Format(createTestContextData())
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	for _, expected := range []string{"relative_path: test/file.go", "name: file.go", "source: |"} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("自定义字段名输出应该包含 %q:\n%s", expected, result)
+		}
+	}
+
+	// AI优化
+	config.Output.AIOptimized = true
+	result, err = NewYAMLFormatter(config).Format(createTestContextData())
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	for _, expected := range []string{"file_summary:", "directory_structure:", "language: go", "source: |"} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("AI优化输出应该包含 %q:\n%s", expected, result)
+		}
+	}
+}
+
+// TestYAMLFormatter_Metadata 测试包含元信息时输出元数据
+func TestYAMLFormatter_Metadata(t *testing.T) {
+	data := createTestContextData()
+	data.Metadata["root_path"] = "test"
+
+	result, err := NewYAMLFormatter(nil).Format(data)
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if strings.Contains(result, "metadata:") || strings.Contains(result, "mod_time") {
+		t.Error("默认不应包含元信息")
+	}
+
+	config := &types.Config{Output: types.OutputConfig{IncludeMetadata: true}}
+	result, err = NewYAMLFormatter(config).Format(data)
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	for _, expected := range []string{"metadata:", "root_path: test", "mod_time:"} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("输出应该包含 %q", expected)
+		}
+	}
+
+	fileOutput, err := NewYAMLFormatter(nil).FormatFile(createTestFileInfo())
+	if err != nil || !strings.HasPrefix(fileOutput, "path: test/file.go") {
+		t.Errorf("FormatFile() = %q, %v", fileOutput, err)
+	}
+}
+
+// TestNDJSONFormatter_Format 测试每行是独立的JSON记录以及记录顺序
+func TestNDJSONFormatter_Format(t *testing.T) {
+	data := createTestContextData()
+	data.Files = append(data.Files, types.FileInfo{Path: "web/index.html", Name: "index.html", Size: 20, Content: "<p>\n  a & b\n</p>\n"})
+	data.FileCount = 2
+	data.Security = &types.ScanSummary{ScannedFiles: 2}
+	data.License = &types.LicenseReport{ProjectLicense: "MIT"}
+	data.Metadata["root_path"] = "project"
+	data.Metadata["git"] = &types.GitIntegrationData{GitInfo: &types.GitInfo{IsGitRepo: true, CurrentBranch: "main"}}
+
+	result, err := NewNDJSONFormatter(nil).Format(data)
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(result, "\n"), "\n")
+	expectedTypes := []string{"header", "file", "file", "git", "security", "license"}
+	if len(lines) != len(expectedTypes) {
+		t.Fatalf("期望 %d 行，实际 %d 行:\n%s", len(expectedTypes), len(lines), result)
+	}
+
+	records := make([]map[string]interface{}, len(lines))
+	for i, line := range lines {
+		if err := json.Unmarshal([]byte(line), &records[i]); err != nil {
+			t.Fatalf("第 %d 行不是有效JSON: %v", i+1, err)
+		}
+		if records[i]["type"] != expectedTypes[i] {
+			t.Errorf("第 %d 行类型 = %v, 期望 %s", i+1, records[i]["type"], expectedTypes[i])
+		}
+	}
+
+	if records[0]["root_path"] != "project" || records[0]["file_count"] != float64(2) {
+		t.Errorf("头记录错误: %v", records[0])
+	}
+	html := records[2]
+	sum := sha256.Sum256([]byte("<p>\n  a & b\n</p>\n"))
+	if html["path"] != "web/index.html" || html["language"] != "html" || html["content"] != "<p>\n  a & b\n</p>\n" {
+		t.Errorf("文件记录错误: %v", html)
+	}
+	if html["hash"] != hex.EncodeToString(sum[:]) {
+		t.Errorf("哈希 = %v, 期望内容的SHA-256", html["hash"])
+	}
+	if !strings.Contains(lines[2], "<p>") {
+		t.Error("不应转义HTML字符")
+	}
+	if git, ok := records[3]["git"].(map[string]interface{}); !ok || git["git_info"] == nil {
+		t.Errorf("Git记录错误: %v", records[3])
+	}
+	if records[4]["scanned_files"] != float64(2) || records[5]["project_license"] != "MIT" {
+		t.Errorf("安全或许可证记录错误: %v %v", records[4], records[5])
+	}
+
+	// 没有Git、安全和许可证信息时只输出头记录和文件记录
+	result, err = NewNDJSONFormatter(nil).Format(createTestContextData())
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if count := strings.Count(result, "\n"); count != 2 {
+		t.Errorf("期望 2 行，实际 %d 行", count)
+	}
+}
+
+// TestPlainFormatter_Format 测试纯文本输出的章节、目录树和文件分隔
+func TestPlainFormatter_Format(t *testing.T) {
+	data := types.ContextData{
+		Files: []types.FileInfo{
+			{Path: "src/main.go", Name: "main.go", Content: "package main\n"},
+			{Path: "README.md", Name: "README.md", Content: "# Demo"},
+			{Path: "src/util/strings.go", Name: "strings.go", Content: "package util\n"},
+		},
+		Folders:   []types.FolderInfo{{Path: "docs", Name: "docs"}},
+		FileCount: 3,
+	}
+	config := &types.Config{Output: types.OutputConfig{
+		AIInstructions: types.AIInstructionsConfig{Enabled: true, Content: "Review the error handling."},
+	}}
+
+	result, err := NewPlainFormatter(config).Format(data)
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	sections := []string{
+		plainSectionSeparator + "\nFile Summary\n" + plainSectionSeparator,
+		"Purpose:\n--------\n",
+		"Usage Guidelines:\n",
+		"Instruction:\n------------\nReview the error handling.\n",
+		plainSectionSeparator + "\nDirectory Structure\n" + plainSectionSeparator,
+		plainSectionSeparator + "\nFiles\n" + plainSectionSeparator,
+	}
+	last := -1
+	for _, section := range sections {
+		index := strings.Index(result, section)
+		if index < 0 {
+			t.Fatalf("输出应该包含 %q:\n%s", section, result)
+		}
+		if index < last {
+			t.Errorf("%q 的位置不正确", section)
+		}
+		last = index
+	}
+
+	tree := "├── docs/\n├── src/\n│   ├── util/\n│   │   └── strings.go\n│   └── main.go\n└── README.md\n"
+	if !strings.Contains(result, tree) {
+		t.Errorf("目录树错误，期望:\n%s\n实际输出:\n%s", tree, result)
+	}
+
+	files := "\n================\nFile: src/main.go\n================\npackage main\n\n" +
+		"================\nFile: README.md\n================\n# Demo\n\n"
+	if !strings.Contains(result, files) {
+		t.Errorf("文件分隔格式错误:\n%s", result)
+	}
+
+	// 未启用自定义指令时不输出指令
+	result, err = NewPlainFormatter(nil).Format(data)
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if strings.Contains(result, "Instruction:") {
+		t.Error("未启用时不应输出指令")
+	}
+}
+
+// TestRenderTree 测试根据目录树渲染ASCII树
+func TestRenderTree(t *testing.T) {
+	data := types.ContextData{
+		Folders: []types.FolderInfo{
+			{Name: "docs", Path: "/repo/docs"},
+			{Name: "src", Path: "/repo/src", Count: 2, Files: []types.FileInfo{{Name: "main.go", Path: "/repo/src/main.go"}},
+				Folders: []types.FolderInfo{{Name: "util", Path: "/repo/src/util", Files: []types.FileInfo{{Name: "io.go", Path: "/repo/src/util/io.go"}}}}},
+		},
+		Files:    []types.FileInfo{{Name: "go.mod", Path: "/repo/go.mod"}, {Name: "main.go", Path: "/repo/src/main.go"}},
+		Metadata: map[string]interface{}{"root_path": "/repo"},
+	}
+
+	expected := "├── docs/\n├── src/\n│   ├── util/\n│   │   └── io.go\n│   └── main.go\n└── go.mod\n"
+	if got := RenderTree(data); got != expected {
+		t.Errorf("RenderTree() =\n%s\n期望:\n%s", got, expected)
+	}
+
+	// 各格式共用同一个目录树
+	config := &types.Config{Output: types.OutputConfig{AIOptimized: true}}
+	for _, f := range []Formatter{NewXMLFormatter(config), NewMarkdownFormatter(config), NewPlainFormatter(config)} {
+		result, err := f.Format(data)
+		if err != nil {
+			t.Fatalf("%s Format() error = %v", f.GetName(), err)
+		}
+		if !strings.Contains(result, "│   │   └── io.go\n") {
+			t.Errorf("%s 输出应包含ASCII目录树", f.GetName())
+		}
+	}
+}
+
+// TestTemplateFormatter 测试自定义模板格式和模板函数
+func TestTemplateFormatter(t *testing.T) {
+	data := types.ContextData{
+		Security: &types.ScanSummary{IssuesFound: 1},
+		Files: []types.FileInfo{
+			{Path: "app/main.go", Name: "main.go", Size: 12, Content: "package main"},
+			{Path: "app/README.md", Name: "README.md", Size: 16, Content: "```sh\nmake\n```\n"},
+			{Path: "app/db.py", Name: "db.py", Size: 20, Content: "query = 'x' + id\n", Issues: []types.SecurityIssue{
+				{Type: "SQLInjection", Severity: types.SeverityHigh, Line: 1, Message: "SQL拼接"},
+			}},
+		},
+		FileCount: 3,
+		TotalSize: 48,
+		Metadata: map[string]interface{}{
+			"root_path": "app",
+			"git":       &types.GitIntegrationData{GitInfo: &types.GitInfo{CurrentBranch: "main"}},
+		},
+	}
+
+	templatePath := filepath.Join(t.TempDir(), "prompt.md.tmpl")
+	templateText := `# {{.Project.Name}} ({{.Git.GitInfo.CurrentBranch}}) tokens={{.Statistics.TotalTokens}}
+{{.Tree}}{{range .Files}}## {{relpath "app" .Path}} [{{lang .Path}}, {{.Lines}} lines]
+{{fence .Language .Content}}
+{{end}}{{range .Issues}}- {{.Path}}:{{.Line}} {{.Severity}} {{.Message}}
+{{end}}{{indent 2 "a\nb"}}`
+	if err := os.WriteFile(templatePath, []byte(templateText), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := NewFormatter(TemplateFormatPrefix+templatePath, nil)
+	if err != nil {
+		t.Fatalf("NewFormatter() error = %v", err)
+	}
+	result, err := f.Format(data)
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	expected := []string{
+		"# app (main) tokens=10\n",
+		"├── README.md\n├── db.py\n└── main.go\n",
+		"## main.go [go, 1 lines]\n```go\npackage main\n```\n",
+		"## README.md [markdown, 4 lines]\n````markdown\n```sh\nmake\n```\n````\n",
+		"- app/db.py:1 high SQL拼接\n",
+		"  a\n  b",
+	}
+	for _, e := range expected {
+		if !strings.Contains(result, e) {
+			t.Errorf("输出应该包含 %q，实际:\n%s", e, result)
+		}
+	}
+
+	// 格式配置中的内联模板
+	config := &types.Config{Formats: types.FormatsConfig{JSON: types.FormatConfig{Template: `{{len .Files}} files, {{.Security.IssuesFound}} issues`}}}
+	f, err = NewFormatter("json", config)
+	if err != nil {
+		t.Fatalf("NewFormatter() error = %v", err)
+	}
+	if result, err := f.Format(data); err != nil || result != "3 files, 1 issues" {
+		t.Errorf("Format() = %q, %v", result, err)
+	}
+
+	// JSON Lines和纯文本格式同样使用配置的模板
+	config.Formats.NDJSON.Template = `ndjson {{len .Files}}`
+	config.Formats.Plain.Template = `plain {{len .Files}}`
+	for name, want := range map[string]string{"jsonl": "ndjson 3", "plain": "plain 3"} {
+		f, err := NewFormatter(name, config)
+		if err != nil {
+			t.Fatalf("NewFormatter(%s) error = %v", name, err)
+		}
+		if result, err := f.Format(data); err != nil || result != want {
+			t.Errorf("%s Format() = %q, %v，期望 %q", name, result, err, want)
+		}
+	}
+
+	// 未配置模板的格式不受影响
+	if f, err := NewFormatter("xml", config); err != nil || f.GetName() != "XML" {
+		t.Errorf("未配置模板时应使用内置格式: %v", err)
+	}
+
+	if _, err := NewFormatter(TemplateFormatPrefix+filepath.Join(t.TempDir(), "missing.tmpl"), nil); err == nil {
+		t.Error("模板文件不存在时应返回错误")
+	}
+	if _, err := NewTemplateFormatter(nil, "bad", "{{.Files"); err == nil {
+		t.Error("模板语法错误时应返回错误")
+	}
+}
+
+// TestEncodingOptions 测试输出编码选项的优先级
+func TestEncodingOptions(t *testing.T) {
+	config := &types.Config{
+		Output:  types.OutputConfig{Encoding: "utf-8", BOM: true, EncodingErrors: encoding.PolicyStrict},
+		Formats: types.FormatsConfig{JSON: types.FormatConfig{Encoding: "gbk"}},
+	}
+	tests := []struct {
+		name     string
+		output   string
+		format   string
+		expected string
+	}{
+		{"使用格式配置", "utf-8", "json", "gbk"},
+		{"输出编码优先", "big5", "json", "big5"},
+		{"未配置的格式", "", "yml", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.Output.Encoding = tt.output
+			opts := EncodingOptions(config, tt.format)
+			if opts.Encoding != tt.expected || !opts.BOM || opts.Policy != encoding.PolicyStrict {
+				t.Errorf("EncodingOptions() = %+v, 期望编码 %q", opts, tt.expected)
+			}
+		})
+	}
+
+	if opts := EncodingOptions(nil, "json"); opts != (encoding.Options{}) {
+		t.Errorf("EncodingOptions(nil) = %+v", opts)
+	}
+}
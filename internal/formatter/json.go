@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 
-	"code-context-generator/internal/formatter/encoding"
 	"code-context-generator/pkg/types"
 )
 
@@ -76,12 +75,18 @@ func (f *JSONFormatter) Format(data types.ContextData) (string, error) {
 			simplifiedFolders := f.simplifyFolders(data.Folders)
 			
 			outputData = struct {
+				Chunk       *types.ChunkInfo       `json:"chunk,omitempty"`
+				Security    *SecuritySummary       `json:"security,omitempty"`
+				License     *types.LicenseReport   `json:"license,omitempty"`
 				Files       []SimplifiedFileInfo   `json:"files"`
 				Folders     []SimplifiedFolderInfo `json:"folders"`
 				FileCount   int                    `json:"file_count"`
 				FolderCount int                    `json:"folder_count"`
 				TotalSize   int64                  `json:"total_size"`
 			}{
+				Chunk:       data.Chunk,
+				Security:    simplifySecurity(data.Security),
+				License:     data.License,
 				Files:       simplifiedFiles,
 				Folders:     simplifiedFolders,
 				FileCount:   data.FileCount,
@@ -108,16 +113,6 @@ func (f *JSONFormatter) Format(data types.ContextData) (string, error) {
 		return "", fmt.Errorf("JSON格式化失败: %w", err)
 	}
 
-	// 检查配置中是否指定了编码格式
-	if f.config != nil && f.config.Formats.JSON.Encoding != "" && f.config.Formats.JSON.Encoding != "utf-8" {
-		// 转换编码
-		encodedOutput, err := encoding.ConvertEncoding(string(output), f.config.Formats.JSON.Encoding)
-		if err != nil {
-			return "", fmt.Errorf("编码转换失败: %w", err)
-		}
-		return encodedOutput, nil
-	}
-
 	return string(output), nil
 }
 
@@ -147,6 +142,7 @@ func (f *JSONFormatter) FormatFile(file types.FileInfo) (string, error) {
 			Path:    file.Path,
 			Name:    file.Name,
 			Size:    file.Size,
+			Hash:    file.Hash,
 			Content: file.Content,
 		}
 		output, err = json.MarshalIndent(simplifiedFile, "", "  ")
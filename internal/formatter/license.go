@@ -0,0 +1,66 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+
+	"code-context-generator/pkg/types"
+)
+
+// maxListedFilesWithoutHeader Markdown中列出的缺少SPDX头文件的最大数量
+const maxListedFilesWithoutHeader = 20
+
+// writeLicenseMarkdown 写入Markdown格式的许可证合规摘要
+func writeLicenseMarkdown(result *strings.Builder, report *types.LicenseReport) {
+	if report == nil {
+		return
+	}
+	result.WriteString("## 许可证\n\n")
+	result.WriteString(fmt.Sprintf("- **项目许可证**: %s\n", report.ProjectLicense))
+	for _, dir := range report.Directories {
+		result.WriteString(fmt.Sprintf("- `%s`: %s\n", dir.Path, strings.Join(dir.Licenses, ", ")))
+	}
+	result.WriteString("\n")
+
+	if len(report.Conflicts) > 0 {
+		result.WriteString("> [!CAUTION]\n")
+		result.WriteString(fmt.Sprintf("> 发现 %d 处许可证冲突:\n", len(report.Conflicts)))
+		for _, conflict := range report.Conflicts {
+			result.WriteString(fmt.Sprintf("> - `%s`: %s\n", conflict.Path, conflict.Message))
+		}
+		result.WriteString("\n")
+	}
+
+	if len(report.FilesWithoutHeader) > 0 {
+		result.WriteString(fmt.Sprintf("缺少SPDX头的源文件: %d\n\n", len(report.FilesWithoutHeader)))
+		for i, file := range report.FilesWithoutHeader {
+			if i == maxListedFilesWithoutHeader {
+				result.WriteString(fmt.Sprintf("- ……另有 %d 个文件\n", len(report.FilesWithoutHeader)-i))
+				break
+			}
+			result.WriteString(fmt.Sprintf("- `%s`\n", file))
+		}
+		result.WriteString("\n")
+	}
+}
+
+// formatLicenseXML 生成AI优化XML的许可证摘要
+func formatLicenseXML(report *types.LicenseReport) string {
+	if report == nil {
+		return ""
+	}
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("<license_summary project_license=\"%s\" files_without_header=\"%d\">\n",
+		escapeXMLAttribute(report.ProjectLicense), len(report.FilesWithoutHeader)))
+	for _, dir := range report.Directories {
+		result.WriteString(fmt.Sprintf("  <directory path=\"%s\">%s</directory>\n",
+			escapeXMLAttribute(dir.Path), escapeXMLAttribute(strings.Join(dir.Licenses, ", "))))
+	}
+	for _, conflict := range report.Conflicts {
+		result.WriteString(fmt.Sprintf("  <conflict path=\"%s\" license=\"%s\" governing_license=\"%s\">%s</conflict>\n",
+			escapeXMLAttribute(conflict.Path), escapeXMLAttribute(conflict.License),
+			escapeXMLAttribute(conflict.Governing), escapeXMLAttribute(conflict.Message)))
+	}
+	result.WriteString("</license_summary>\n")
+	return result.String()
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"strings"
 
-	"code-context-generator/internal/formatter/encoding"
 	"code-context-generator/pkg/types"
 )
 
@@ -58,6 +57,15 @@ func (f *MarkdownFormatter) Format(data types.ContextData) (string, error) {
 	result.WriteString(fmt.Sprintf("- **总大小**: %d 字节\n", data.TotalSize))
 	result.WriteString("\n")
 
+	// 分块头（如果分块输出）
+	writeChunkMarkdown(&result, data.Chunk)
+
+	// 安全扫描摘要（如果生成时执行了扫描）
+	writeSecuritySummaryMarkdown(&result, data.Security)
+
+	// 许可证摘要（如果启用了许可证检测）
+	writeLicenseMarkdown(&result, data.License)
+
 	// 元信息（如果包含）
 	if includeMetadata && len(data.Metadata) > 0 {
 		result.WriteString("## 元信息\n\n")
@@ -89,6 +97,9 @@ func (f *MarkdownFormatter) Format(data types.ContextData) (string, error) {
 			result.WriteString(fmt.Sprintf("### %s\n\n", file.Name))
 			result.WriteString(fmt.Sprintf("- **路径**: %s\n", file.Path))
 			result.WriteString(fmt.Sprintf("- **大小**: %d 字节\n", file.Size))
+			if file.Hash != "" {
+				result.WriteString(fmt.Sprintf("- **SHA-256**: `%s`\n", file.Hash))
+			}
 			result.WriteString(fmt.Sprintf("- **修改时间**: %s\n", file.ModTime.Format("2006-01-02 15:04:05")))
 			if file.IsBinary {
 				result.WriteString("- **类型**: 二进制文件\n")
@@ -98,10 +109,12 @@ func (f *MarkdownFormatter) Format(data types.ContextData) (string, error) {
 			if file.IsHidden {
 				result.WriteString("- **隐藏**: 是\n")
 			}
+			result.WriteString("\n")
+			writeIssuesMarkdown(&result, file.Issues)
 
 			// 文件内容
 			if !file.IsBinary {
-				result.WriteString("\n#### 内容\n\n")
+				result.WriteString("#### 内容\n\n")
 				result.WriteString("```\n")
 				// 限制内容长度以避免Markdown文件过大
 				content := file.Content
@@ -117,16 +130,6 @@ func (f *MarkdownFormatter) Format(data types.ContextData) (string, error) {
 
 	resultStr := result.String()
 
-	// 检查配置中是否指定了编码格式
-	if f.config != nil && f.config.Formats.Markdown.Encoding != "" && f.config.Formats.Markdown.Encoding != "utf-8" {
-		// 转换编码
-		encodedOutput, err := encoding.ConvertEncoding(resultStr, f.config.Formats.Markdown.Encoding)
-		if err != nil {
-			return "", fmt.Errorf("编码转换失败: %w", err)
-		}
-		return encodedOutput, nil
-	}
-
 	return resultStr, nil
 }
 
@@ -165,16 +168,6 @@ func (f *MarkdownFormatter) FormatFile(file types.FileInfo) (string, error) {
 
 	resultStr := result.String()
 
-	// 检查配置中是否指定了编码格式
-	if f.config != nil && f.config.Formats.Markdown.Encoding != "" && f.config.Formats.Markdown.Encoding != "utf-8" {
-		// 转换编码
-		encodedOutput, err := encoding.ConvertEncoding(resultStr, f.config.Formats.Markdown.Encoding)
-		if err != nil {
-			return "", fmt.Errorf("编码转换失败: %w", err)
-		}
-		return encodedOutput, nil
-	}
-
 	return resultStr, nil
 }
 
@@ -228,10 +221,12 @@ func (f *MarkdownFormatter) FormatFolder(folder types.FolderInfo) (string, error
 			if file.IsHidden {
 				result.WriteString("- **隐藏**: 是\n")
 			}
+			result.WriteString("\n")
+			writeIssuesMarkdown(&result, file.Issues)
 
 			// 文件内容
 			if !file.IsBinary {
-				result.WriteString("\n#### 内容\n\n")
+				result.WriteString("#### 内容\n\n")
 				result.WriteString("```\n")
 				// 限制内容长度以避免Markdown文件过大
 				content := file.Content
@@ -247,16 +242,6 @@ func (f *MarkdownFormatter) FormatFolder(folder types.FolderInfo) (string, error
 
 	resultStr := result.String()
 
-	// 检查配置中是否指定了编码格式
-	if f.config != nil && f.config.Formats.Markdown.Encoding != "" && f.config.Formats.Markdown.Encoding != "utf-8" {
-		// 转换编码
-		encodedOutput, err := encoding.ConvertEncoding(resultStr, f.config.Formats.Markdown.Encoding)
-		if err != nil {
-			return "", fmt.Errorf("编码转换失败: %w", err)
-		}
-		return encodedOutput, nil
-	}
-
 	return resultStr, nil
 }
 
@@ -306,6 +291,15 @@ func (f *MarkdownFormatter) formatAIOptimized(data types.ContextData) (string, e
 	result.WriteString(fmt.Sprintf("%v", aiSummary))
 	result.WriteString("\n\n")
 
+	// 分块头
+	writeChunkMarkdown(&result, data.Chunk)
+
+	// 安全扫描摘要
+	writeSecuritySummaryMarkdown(&result, data.Security)
+
+	// 许可证摘要
+	writeLicenseMarkdown(&result, data.License)
+
 	// 生成目录结构
 	result.WriteString("## 项目结构\n\n")
 	directoryStructure := f.generateDirectoryStructure(data)
@@ -333,16 +327,6 @@ func (f *MarkdownFormatter) formatAIOptimized(data types.ContextData) (string, e
 
 	resultStr := result.String()
 
-	// 检查配置中是否指定了编码格式
-	if f.config != nil && f.config.Formats.Markdown.Encoding != "" && f.config.Formats.Markdown.Encoding != "utf-8" {
-		// 转换编码
-		encodedOutput, err := encoding.ConvertEncoding(resultStr, f.config.Formats.Markdown.Encoding)
-		if err != nil {
-			return "", fmt.Errorf("编码转换失败: %w", err)
-		}
-		return encodedOutput, nil
-	}
-
 	return resultStr, nil
 }
 
@@ -354,6 +338,9 @@ func (f *MarkdownFormatter) formatFileAIOptimized(file types.FileInfo) string {
 	result.WriteString(fmt.Sprintf("### %s\n\n", file.Name))
 	result.WriteString(fmt.Sprintf("- **路径**: `%s`\n", file.Path))
 	result.WriteString(fmt.Sprintf("- **大小**: %d 字节\n", file.Size))
+	if file.Hash != "" {
+		result.WriteString(fmt.Sprintf("- **SHA-256**: `%s`\n", file.Hash))
+	}
 	result.WriteString(fmt.Sprintf("- **修改时间**: %s\n", file.ModTime.Format("2006-01-02 15:04:05")))
 
 	// 检测语言类型
@@ -373,6 +360,7 @@ func (f *MarkdownFormatter) formatFileAIOptimized(file types.FileInfo) string {
 		result.WriteString("- **隐藏**: 是\n")
 	}
 	result.WriteString("\n")
+	writeIssuesMarkdown(&result, file.Issues)
 
 	// 文件内容
 	if !file.IsBinary {
@@ -390,19 +378,7 @@ func (f *MarkdownFormatter) formatFileAIOptimized(file types.FileInfo) string {
 
 // generateDirectoryStructure 生成目录结构
 func (f *MarkdownFormatter) generateDirectoryStructure(data types.ContextData) string {
-	var result strings.Builder
-	result.WriteString("```\n")
-
-	// 简化的目录结构表示
-	for _, folder := range data.Folders {
-		result.WriteString(fmt.Sprintf("%s/\n", folder.Name))
-	}
-	for _, file := range data.Files {
-		result.WriteString(fmt.Sprintf("  %s\n", file.Name))
-	}
-
-	result.WriteString("```\n")
-	return result.String()
+	return "```\n" + RenderTree(data) + "```\n"
 }
 
 // getFileTypes 获取文件类型统计
@@ -0,0 +1,161 @@
+package formatter
+
+import (
+	"code-context-generator/pkg/types"
+)
+
+// ContentMasker 内容遮蔽器接口
+type ContentMasker interface {
+	Mask(content string) (string, int)
+}
+
+// MaskingFormatter 在格式化前遮蔽文件内容的格式转换器
+type MaskingFormatter struct {
+	Formatter
+	masker ContentMasker
+	masked int
+}
+
+// NewMaskingFormatter 创建遮蔽格式转换器，包装已有的格式转换器
+func NewMaskingFormatter(inner Formatter, masker ContentMasker) *MaskingFormatter {
+	return &MaskingFormatter{
+		Formatter: inner,
+		masker:    masker,
+	}
+}
+
+// Format 遮蔽所有文件内容和Git元数据后格式化
+func (f *MaskingFormatter) Format(data types.ContextData) (string, error) {
+	data.Files = f.maskFiles(data.Files)
+	data.Folders = f.maskFolders(data.Folders)
+	data.Metadata = f.maskMetadata(data.Metadata)
+	return f.Formatter.Format(data)
+}
+
+// FormatFile 遮蔽文件内容后格式化
+func (f *MaskingFormatter) FormatFile(file types.FileInfo) (string, error) {
+	file.Content = f.mask(file.Content)
+	return f.Formatter.FormatFile(file)
+}
+
+// FormatFolder 遮蔽文件夹中的文件内容后格式化
+func (f *MaskingFormatter) FormatFolder(folder types.FolderInfo) (string, error) {
+	folder.Files = f.maskFiles(folder.Files)
+	folder.Folders = f.maskFolders(folder.Folders)
+	return f.Formatter.FormatFolder(folder)
+}
+
+// Fork 返回共用遮蔽器但单独计数的副本，用于不计入统计的试格式化
+func (f *MaskingFormatter) Fork() *MaskingFormatter {
+	return NewMaskingFormatter(f.Formatter, f.masker)
+}
+
+// Masked 返回已遮蔽的数量
+func (f *MaskingFormatter) Masked() int {
+	return f.masked
+}
+
+// mask 遮蔽单个内容并累计数量
+func (f *MaskingFormatter) mask(content string) string {
+	masked, count := f.masker.Mask(content)
+	f.masked += count
+	return masked
+}
+
+// maskFiles 复制文件列表并遮蔽内容，不修改调用方的数据
+func (f *MaskingFormatter) maskFiles(files []types.FileInfo) []types.FileInfo {
+	if files == nil {
+		return nil
+	}
+	masked := make([]types.FileInfo, len(files))
+	for i, file := range files {
+		file.Content = f.mask(file.Content)
+		masked[i] = file
+	}
+	return masked
+}
+
+// maskFolders 递归复制文件夹并遮蔽内容
+func (f *MaskingFormatter) maskFolders(folders []types.FolderInfo) []types.FolderInfo {
+	if folders == nil {
+		return nil
+	}
+	masked := make([]types.FolderInfo, len(folders))
+	for i, folder := range folders {
+		folder.Files = f.maskFiles(folder.Files)
+		folder.Folders = f.maskFolders(folder.Folders)
+		masked[i] = folder
+	}
+	return masked
+}
+
+// maskMetadata 复制元数据并遮蔽其中的Git信息，作者、邮箱、提交信息和差异都可能包含个人信息
+func (f *MaskingFormatter) maskMetadata(metadata map[string]interface{}) map[string]interface{} {
+	git, ok := metadata["git"].(*types.GitIntegrationData)
+	if !ok || git == nil {
+		return metadata
+	}
+
+	masked := make(map[string]interface{}, len(metadata))
+	for key, value := range metadata {
+		masked[key] = value
+	}
+	masked["git"] = f.maskGit(git)
+	return masked
+}
+
+// maskGit 复制Git集成数据并遮蔽其中的文本字段
+func (f *MaskingFormatter) maskGit(git *types.GitIntegrationData) *types.GitIntegrationData {
+	masked := *git
+	if git.GitInfo != nil {
+		info := *git.GitInfo
+		info.RemoteURL = f.mask(info.RemoteURL)
+		if info.LastCommit != nil {
+			commit := f.maskCommit(*info.LastCommit)
+			info.LastCommit = &commit
+		}
+		masked.GitInfo = &info
+	}
+	if git.GitHistory != nil {
+		history := *git.GitHistory
+		history.Commits = make([]types.CommitInfo, len(git.GitHistory.Commits))
+		for i, commit := range git.GitHistory.Commits {
+			history.Commits[i] = f.maskCommit(commit)
+		}
+		history.Contributors = make([]string, len(git.GitHistory.Contributors))
+		for i, contributor := range git.GitHistory.Contributors {
+			history.Contributors[i] = f.mask(contributor)
+		}
+		masked.GitHistory = &history
+	}
+	if git.GitDiffs != nil {
+		masked.GitDiffs = make([]types.CommitDiff, len(git.GitDiffs))
+		for i, commitDiff := range git.GitDiffs {
+			files := make([]types.FileDiff, len(commitDiff.Files))
+			for j, fileDiff := range commitDiff.Files {
+				fileDiff.Diff = f.mask(fileDiff.Diff)
+				files[j] = fileDiff
+			}
+			commitDiff.Files = files
+			masked.GitDiffs[i] = commitDiff
+		}
+	}
+	if git.GitStats != nil {
+		stats := *git.GitStats
+		stats.AuthorStats = make([]types.AuthorStat, len(git.GitStats.AuthorStats))
+		for i, author := range git.GitStats.AuthorStats {
+			author.Name = f.mask(author.Name)
+			stats.AuthorStats[i] = author
+		}
+		masked.GitStats = &stats
+	}
+	return &masked
+}
+
+// maskCommit 遮蔽提交的作者、邮箱和提交信息
+func (f *MaskingFormatter) maskCommit(commit types.CommitInfo) types.CommitInfo {
+	commit.Author = f.mask(commit.Author)
+	commit.Email = f.mask(commit.Email)
+	commit.Message = f.mask(commit.Message)
+	return commit
+}
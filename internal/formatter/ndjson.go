@@ -0,0 +1,185 @@
+package formatter
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"code-context-generator/pkg/types"
+)
+
+// NDJSON记录类型
+const (
+	NDJSONRecordHeader   = "header"
+	NDJSONRecordFile     = "file"
+	NDJSONRecordGit      = "git"
+	NDJSONRecordSecurity = "security"
+	NDJSONRecordLicense  = "license"
+)
+
+// NDJSONFormatter JSON Lines格式转换器，每行是一条独立的JSON记录
+type NDJSONFormatter struct {
+	BaseFormatter
+	config *types.Config
+}
+
+// NewNDJSONFormatter 创建JSON Lines格式转换器
+func NewNDJSONFormatter(config *types.Config) Formatter {
+	return &NDJSONFormatter{
+		BaseFormatter: BaseFormatter{
+			name:        "NDJSON",
+			description: "Newline delimited JSON, one record per line",
+			config:      nil,
+		},
+		config: config,
+	}
+}
+
+// NDJSONHeader 第一行的项目信息记录
+type NDJSONHeader struct {
+	Type        string    `json:"type"`
+	RootPath    string    `json:"root_path,omitempty"`
+	GeneratedAt time.Time `json:"generated_at"`
+	FileCount   int       `json:"file_count"`
+	FolderCount int       `json:"folder_count"`
+	TotalSize   int64     `json:"total_size"`
+	Languages   []string  `json:"languages"`
+	// Chunk 分块输出时的分块头和文件索引
+	Chunk *types.ChunkInfo `json:"chunk,omitempty"`
+}
+
+// NDJSONFile 单个文件记录
+type NDJSONFile struct {
+	Type     string              `json:"type"`
+	Path     string              `json:"path"`
+	Language string              `json:"language"`
+	Size     int64               `json:"size"`
+	Tokens   int                 `json:"tokens"`
+	Hash     string              `json:"hash"` // 读取时内容的SHA-256，未记录时根据输出内容计算
+	IsBinary bool                `json:"is_binary,omitempty"`
+	Issues   SimplifiedIssueList `json:"issues,omitempty"`
+	Content  string              `json:"content"`
+}
+
+// Format 格式化上下文数据：头记录、文件记录，然后是可选的Git、安全和许可证记录
+func (f *NDJSONFormatter) Format(data types.ContextData) (string, error) {
+	xmlFormatter := &XMLFormatter{config: f.config}
+	languages := xmlFormatter.detectLanguages(data.Files)
+	sort.Strings(languages)
+
+	header := NDJSONHeader{
+		Type:        NDJSONRecordHeader,
+		GeneratedAt: time.Now(),
+		FileCount:   data.FileCount,
+		FolderCount: data.FolderCount,
+		TotalSize:   data.TotalSize,
+		Languages:   languages,
+		Chunk:       data.Chunk,
+	}
+	if rootPath, ok := data.Metadata["root_path"].(string); ok {
+		header.RootPath = rootPath
+	}
+
+	records := []interface{}{header}
+	for _, file := range data.Files {
+		records = append(records, f.fileRecord(file))
+	}
+	if git, ok := data.Metadata["git"]; ok && git != nil {
+		records = append(records, struct {
+			Type string      `json:"type"`
+			Git  interface{} `json:"git"`
+		}{NDJSONRecordGit, git})
+	}
+	if security := simplifySecurity(data.Security); security != nil {
+		records = append(records, struct {
+			Type string `json:"type"`
+			*SecuritySummary
+		}{NDJSONRecordSecurity, security})
+	}
+	if data.License != nil {
+		records = append(records, struct {
+			Type string `json:"type"`
+			*types.LicenseReport
+		}{NDJSONRecordLicense, data.License})
+	}
+
+	var result strings.Builder
+	for _, record := range records {
+		line, err := f.marshalLine(record)
+		if err != nil {
+			return "", fmt.Errorf("NDJSON格式化失败: %w", err)
+		}
+		result.WriteString(line)
+	}
+	return result.String(), nil
+}
+
+// FormatFile 格式化单个文件为一条记录
+func (f *NDJSONFormatter) FormatFile(file types.FileInfo) (string, error) {
+	output, err := f.marshalLine(f.fileRecord(file))
+	if err != nil {
+		return "", fmt.Errorf("NDJSON文件格式化失败: %w", err)
+	}
+	return output, nil
+}
+
+// FormatFolder 格式化文件夹中的文件，每个文件一条记录
+func (f *NDJSONFormatter) FormatFolder(folder types.FolderInfo) (string, error) {
+	var result strings.Builder
+	for _, file := range folder.Files {
+		line, err := f.FormatFile(file)
+		if err != nil {
+			return "", err
+		}
+		result.WriteString(line)
+	}
+	for _, subFolder := range folder.Folders {
+		lines, err := f.FormatFolder(subFolder)
+		if err != nil {
+			return "", err
+		}
+		result.WriteString(lines)
+	}
+	return result.String(), nil
+}
+
+// fileRecord 生成文件记录
+func (f *NDJSONFormatter) fileRecord(file types.FileInfo) NDJSONFile {
+	xmlFormatter := &XMLFormatter{config: f.config}
+	content := file.Content
+	if file.IsBinary {
+		content = "[二进制文件 - 内容未显示]"
+	}
+	hash := file.Hash
+	if hash == "" {
+		sum := sha256.Sum256([]byte(file.Content))
+		hash = hex.EncodeToString(sum[:])
+	}
+	return NDJSONFile{
+		Type:     NDJSONRecordFile,
+		Path:     file.Path,
+		Language: xmlFormatter.detectLanguage(file.Path, content),
+		Size:     file.Size,
+		Tokens:   xmlFormatter.estimateTokens(content),
+		Hash:     hash,
+		IsBinary: file.IsBinary,
+		Issues:   simplifyIssues(file.Issues),
+		Content:  content,
+	}
+}
+
+// marshalLine 序列化一条记录，结尾带换行
+func (f *NDJSONFormatter) marshalLine(record interface{}) (string, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(record); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,208 @@
+package formatter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"code-context-generator/pkg/types"
+)
+
+// 纯文本格式的分隔线，与常见的打包仓库文本布局一致
+const (
+	plainSectionSeparator = "================================================================"
+	plainFileSeparator    = "================"
+)
+
+// PlainFormatter 纯文本格式转换器，输出打包仓库常用的文本布局
+type PlainFormatter struct {
+	BaseFormatter
+	config *types.Config
+}
+
+// NewPlainFormatter 创建纯文本格式转换器
+func NewPlainFormatter(config *types.Config) Formatter {
+	return &PlainFormatter{
+		BaseFormatter: BaseFormatter{
+			name:        "Plain",
+			description: "Plain text packed repository layout",
+			config:      nil,
+		},
+		config: config,
+	}
+}
+
+// Format 格式化上下文数据：摘要、目录树、文件内容
+func (f *PlainFormatter) Format(data types.ContextData) (string, error) {
+	var result strings.Builder
+
+	if err := f.writeSummary(&result, data); err != nil {
+		return "", err
+	}
+
+	f.writeSection(&result, "Directory Structure")
+	result.WriteString(RenderTree(data))
+	result.WriteString("\n")
+
+	f.writeSection(&result, "Files")
+	result.WriteString("\n")
+	for _, file := range data.Files {
+		fileText, err := f.FormatFile(file)
+		if err != nil {
+			return "", err
+		}
+		result.WriteString(fileText)
+		result.WriteString("\n")
+	}
+
+	return result.String(), nil
+}
+
+// FormatFile 格式化单个文件
+func (f *PlainFormatter) FormatFile(file types.FileInfo) (string, error) {
+	content := file.Content
+	if file.IsBinary {
+		content = "[二进制文件 - 内容未显示]"
+	}
+
+	var result strings.Builder
+	result.WriteString(plainFileSeparator + "\n")
+	result.WriteString(fmt.Sprintf("File: %s\n", file.Path))
+	result.WriteString(plainFileSeparator + "\n")
+	result.WriteString(content)
+	if !strings.HasSuffix(content, "\n") {
+		result.WriteString("\n")
+	}
+	return result.String(), nil
+}
+
+// FormatFolder 格式化文件夹中的所有文件
+func (f *PlainFormatter) FormatFolder(folder types.FolderInfo) (string, error) {
+	var result strings.Builder
+	for _, file := range folder.Files {
+		fileText, err := f.FormatFile(file)
+		if err != nil {
+			return "", err
+		}
+		result.WriteString(fileText)
+		result.WriteString("\n")
+	}
+	for _, subFolder := range folder.Folders {
+		folderText, err := f.FormatFolder(subFolder)
+		if err != nil {
+			return "", err
+		}
+		result.WriteString(folderText)
+	}
+	return result.String(), nil
+}
+
+// writeSection 写入带分隔线的章节标题
+func (f *PlainFormatter) writeSection(result *strings.Builder, title string) {
+	result.WriteString(plainSectionSeparator + "\n")
+	result.WriteString(title + "\n")
+	result.WriteString(plainSectionSeparator + "\n")
+}
+
+// writeSubsection 写入带下划线的小节标题
+func (f *PlainFormatter) writeSubsection(result *strings.Builder, title string) {
+	result.WriteString(title + ":\n")
+	result.WriteString(strings.Repeat("-", len(title)+1) + "\n")
+}
+
+// writeSummary 写入由AI摘要和自定义指令生成的文件头
+func (f *PlainFormatter) writeSummary(result *strings.Builder, data types.ContextData) error {
+	xmlFormatter := &XMLFormatter{config: f.config}
+	languages := xmlFormatter.detectLanguages(data.Files)
+	sort.Strings(languages)
+	summary := NewAISummaryGenerator(f.config).GenerateSummary(data.FileCount, data.TotalSize, languages)
+
+	result.WriteString(summary.GenerationHeader + "\n")
+	result.WriteString("This file is a merged representation of the codebase, combined into a single document.\n\n")
+
+	f.writeSection(result, "File Summary")
+	result.WriteString("\n")
+
+	f.writeSubsection(result, "Purpose")
+	result.WriteString(summary.Purpose + "\n\n")
+
+	f.writeSubsection(result, "File Format")
+	result.WriteString(`The content is organized as follows:
+1. This summary section
+2. Directory structure
+3. Multiple file entries, each consisting of:
+  a. A separator line (================)
+  b. The file path (File: path/to/file)
+  c. Another separator line
+  d. The full contents of the file
+  e. A blank line
+`)
+	result.WriteString("\n")
+
+	f.writeSubsection(result, "Usage Guidelines")
+	for _, guideline := range summary.UsageGuidelines {
+		result.WriteString(fmt.Sprintf("- %s\n", guideline))
+	}
+	result.WriteString("\n")
+
+	f.writeSubsection(result, "Notes")
+	for _, note := range summary.Notes {
+		result.WriteString(fmt.Sprintf("- %s\n", note))
+	}
+	result.WriteString("\n")
+
+	if chunk := chunkNotes(data.Chunk); len(chunk) > 0 {
+		f.writeSubsection(result, "Chunk")
+		for _, line := range chunk {
+			result.WriteString(fmt.Sprintf("- %s\n", line))
+		}
+		result.WriteString("\n")
+	}
+
+	if security := f.securityNotes(data); len(security) > 0 {
+		f.writeSubsection(result, "Security")
+		for _, line := range security {
+			result.WriteString(fmt.Sprintf("- %s\n", line))
+		}
+		result.WriteString("\n")
+	}
+
+	if data.License != nil {
+		f.writeSubsection(result, "License")
+		result.WriteString(fmt.Sprintf("- Project license: %s\n", data.License.ProjectLicense))
+		for _, conflict := range data.License.Conflicts {
+			result.WriteString(fmt.Sprintf("- Conflict in %s: %s\n", conflict.Path, conflict.Message))
+		}
+		result.WriteString("\n")
+	}
+
+	// 添加自定义指令
+	if f.config != nil && f.config.Output.AIInstructions.Enabled {
+		instructions, err := NewInstructionLoader(f.config).LoadInstructions()
+		if err != nil {
+			return fmt.Errorf("加载AI指令失败: %w", err)
+		}
+		if instructions != "" {
+			f.writeSubsection(result, "Instruction")
+			result.WriteString(strings.TrimRight(instructions, "\n") + "\n\n")
+		}
+	}
+
+	return nil
+}
+
+// securityNotes 生成安全扫描摘要和问题列表，问题不写入文件内容块以免破坏布局
+func (f *PlainFormatter) securityNotes(data types.ContextData) []string {
+	if data.Security == nil {
+		return nil
+	}
+	s := data.Security
+	notes := []string{fmt.Sprintf("Scanned %d files, %d issues found (critical %d, high %d, medium %d, low %d)",
+		s.ScannedFiles, s.IssuesFound, s.CriticalIssues, s.HighIssues, s.MediumIssues, s.LowIssues)}
+	for _, file := range data.Files {
+		for _, issue := range file.Issues {
+			notes = append(notes, fmt.Sprintf("%s:%d [%s] %s", file.Path, issue.Line, issue.Severity, issue.Message))
+		}
+	}
+	return notes
+}
@@ -0,0 +1,126 @@
+package formatter
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"code-context-generator/pkg/types"
+)
+
+// SimplifiedIssue 简化的安全问题结构，随文件一起输出
+type SimplifiedIssue struct {
+	Severity       string `json:"severity" xml:"severity,attr" toml:"severity"`
+	Type           string `json:"type" xml:"type,attr" toml:"type"`
+	Line           int    `json:"line" xml:"line,attr" toml:"line"`
+	Message        string `json:"message" xml:"message" toml:"message"`
+	Recommendation string `json:"recommendation,omitempty" xml:"recommendation,omitempty" toml:"recommendation,omitempty"`
+}
+
+// SimplifiedIssueList 简化的安全问题列表
+type SimplifiedIssueList []SimplifiedIssue
+
+// MarshalXML 将问题包装在<issues>元素中，列表为空时由omitempty省略
+func (l SimplifiedIssueList) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(struct {
+		Issues []SimplifiedIssue `xml:"issue"`
+	}{l}, start)
+}
+
+// SecuritySummary 输出顶部的安全扫描摘要
+type SecuritySummary struct {
+	ScannedFiles   int `json:"scanned_files" xml:"scanned_files" toml:"scanned_files"`
+	IssuesFound    int `json:"issues_found" xml:"issues_found" toml:"issues_found"`
+	CriticalIssues int `json:"critical_issues" xml:"critical_issues" toml:"critical_issues"`
+	HighIssues     int `json:"high_issues" xml:"high_issues" toml:"high_issues"`
+	MediumIssues   int `json:"medium_issues" xml:"medium_issues" toml:"medium_issues"`
+	LowIssues      int `json:"low_issues" xml:"low_issues" toml:"low_issues"`
+}
+
+// simplifyIssues 转换安全问题为简化结构
+func simplifyIssues(issues []types.SecurityIssue) SimplifiedIssueList {
+	if len(issues) == 0 {
+		return nil
+	}
+	simplified := make(SimplifiedIssueList, len(issues))
+	for i, issue := range issues {
+		simplified[i] = SimplifiedIssue{
+			Severity:       issue.Severity.String(),
+			Type:           issue.Type,
+			Line:           issue.Line,
+			Message:        issue.Message,
+			Recommendation: issue.Recommendation,
+		}
+	}
+	return simplified
+}
+
+// simplifySecurity 转换扫描摘要，未扫描时返回nil
+func simplifySecurity(summary *types.ScanSummary) *SecuritySummary {
+	if summary == nil {
+		return nil
+	}
+	return &SecuritySummary{
+		ScannedFiles:   summary.ScannedFiles,
+		IssuesFound:    summary.IssuesFound,
+		CriticalIssues: summary.CriticalIssues,
+		HighIssues:     summary.HighIssues,
+		MediumIssues:   summary.MediumIssues,
+		LowIssues:      summary.LowIssues,
+	}
+}
+
+// writeSecuritySummaryMarkdown 写入Markdown格式的安全扫描摘要
+func writeSecuritySummaryMarkdown(result *strings.Builder, summary *types.ScanSummary) {
+	if summary == nil {
+		return
+	}
+	result.WriteString("## 安全扫描\n\n")
+	result.WriteString(fmt.Sprintf("- **扫描文件**: %d\n", summary.ScannedFiles))
+	result.WriteString(fmt.Sprintf("- **发现问题**: %d\n", summary.IssuesFound))
+	result.WriteString(fmt.Sprintf("- **严重/高危/中危/低危**: %d / %d / %d / %d\n",
+		summary.CriticalIssues, summary.HighIssues, summary.MediumIssues, summary.LowIssues))
+	result.WriteString("\n")
+}
+
+// writeIssuesMarkdown 写入Markdown格式的文件安全问题提示块
+func writeIssuesMarkdown(result *strings.Builder, issues []types.SecurityIssue) {
+	if len(issues) == 0 {
+		return
+	}
+	result.WriteString("> [!WARNING]\n")
+	result.WriteString(fmt.Sprintf("> 安全扫描在此文件中发现 %d 个问题:\n", len(issues)))
+	for _, issue := range issues {
+		result.WriteString(fmt.Sprintf("> - **%s** 第 %d 行: %s\n", issue.Severity.String(), issue.Line, issue.Message))
+	}
+	result.WriteString("\n")
+}
+
+// formatSecuritySummaryXML 生成AI优化XML的安全扫描摘要
+func formatSecuritySummaryXML(summary *types.ScanSummary) string {
+	if summary == nil {
+		return ""
+	}
+	return fmt.Sprintf(`<security_summary scanned_files="%d" issues_found="%d" critical="%d" high="%d" medium="%d" low="%d" />
+`,
+		summary.ScannedFiles, summary.IssuesFound,
+		summary.CriticalIssues, summary.HighIssues, summary.MediumIssues, summary.LowIssues)
+}
+
+// formatIssuesXML 生成AI优化XML中文件的问题列表
+func formatIssuesXML(issues []types.SecurityIssue) string {
+	if len(issues) == 0 {
+		return ""
+	}
+	var result strings.Builder
+	result.WriteString("    <issues>\n")
+	for _, issue := range issues {
+		result.WriteString(fmt.Sprintf("      <issue severity=\"%s\" type=\"%s\" line=\"%d\">%s</issue>\n",
+			escapeXMLAttribute(issue.Severity.String()),
+			escapeXMLAttribute(issue.Type),
+			issue.Line,
+			escapeXMLAttribute(issue.Message)))
+	}
+	result.WriteString("    </issues>\n")
+	return result.String()
+}
@@ -0,0 +1,122 @@
+package formatter
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"code-context-generator/pkg/types"
+)
+
+// TemplateFormatPrefix 使用自定义模板文件的格式前缀，如 template:prompt.tmpl
+const TemplateFormatPrefix = "template:"
+
+// TemplateFormatter 使用用户模板渲染输出的格式转换器
+type TemplateFormatter struct {
+	BaseFormatter
+	config   *types.Config
+	system   *TemplateSystem
+	template *template.Template
+}
+
+// NewTemplateFormatter 创建模板格式转换器，模板在创建时解析
+func NewTemplateFormatter(config *types.Config, name, text string) (Formatter, error) {
+	system := NewTemplateSystem(config)
+	tmpl, err := template.New(name).Funcs(system.getTemplateFuncs()).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("解析模板 %s 失败: %w", name, err)
+	}
+	return &TemplateFormatter{
+		BaseFormatter: BaseFormatter{
+			name:        "Template",
+			description: "User-defined text/template output",
+			config:      nil,
+		},
+		config:   config,
+		system:   system,
+		template: tmpl,
+	}, nil
+}
+
+// LoadTemplate 加载模板：包含 {{ 时视为内联模板，否则从文件读取
+func LoadTemplate(spec string) (name, text string, err error) {
+	if strings.Contains(spec, "{{") {
+		return "inline", spec, nil
+	}
+	content, err := os.ReadFile(spec)
+	if err != nil {
+		return "", "", fmt.Errorf("读取模板文件失败: %w", err)
+	}
+	return filepath.Base(spec), string(content), nil
+}
+
+// newTemplateFormatterFromSpec 根据模板文件路径或内联模板创建格式转换器
+func newTemplateFormatterFromSpec(config *types.Config, spec string) (Formatter, error) {
+	name, text, err := LoadTemplate(spec)
+	if err != nil {
+		return nil, err
+	}
+	return NewTemplateFormatter(config, name, text)
+}
+
+// configuredTemplate 返回格式配置中的模板，未配置时返回空
+func configuredTemplate(config *types.Config, format string) string {
+	if config == nil {
+		return ""
+	}
+	switch format {
+	case "json":
+		return config.Formats.JSON.Template
+	case "xml":
+		return config.Formats.XML.Template
+	case "toml":
+		return config.Formats.TOML.Template
+	case "markdown", "md":
+		return config.Formats.Markdown.Template
+	case "yaml", "yml":
+		return config.Formats.YAML.Template
+	case "ndjson", "jsonl":
+		return config.Formats.NDJSON.Template
+	case "plain", "txt":
+		return config.Formats.Plain.Template
+	default:
+		return ""
+	}
+}
+
+// Format 格式化上下文数据
+func (f *TemplateFormatter) Format(data types.ContextData) (string, error) {
+	return f.render(data)
+}
+
+// FormatFile 使用只包含该文件的数据渲染模板
+func (f *TemplateFormatter) FormatFile(file types.FileInfo) (string, error) {
+	return f.render(types.ContextData{
+		Files:     []types.FileInfo{file},
+		FileCount: 1,
+		TotalSize: file.Size,
+	})
+}
+
+// FormatFolder 使用文件夹中的文件渲染模板
+func (f *TemplateFormatter) FormatFolder(folder types.FolderInfo) (string, error) {
+	return f.render(types.ContextData{
+		Files:       folder.Files,
+		Folders:     folder.Folders,
+		FileCount:   len(folder.Files),
+		FolderCount: len(folder.Folders),
+		TotalSize:   folder.Size,
+	})
+}
+
+// render 执行模板
+func (f *TemplateFormatter) render(data types.ContextData) (string, error) {
+	var buf bytes.Buffer
+	if err := f.template.Execute(&buf, f.system.CreateContextTemplateData(data)); err != nil {
+		return "", fmt.Errorf("执行模板失败: %w", err)
+	}
+	return buf.String(), nil
+}
@@ -3,6 +3,8 @@ package formatter
 import (
 	"bytes"
 	"fmt"
+	"path"
+	"path/filepath"
 	"strings"
 	"text/template"
 	"time"
@@ -21,6 +23,38 @@ type TemplateData struct {
 	Generation  GenerationData
 	Statistics  StatisticsData
 	Custom      map[string]interface{}
+
+	// 以下字段由 CreateContextTemplateData 填充，供用户自定义输出模板使用
+	Files    []TemplateFile
+	Folders  []types.FolderInfo
+	Tree     string                    // 缩进的目录树
+	Git      *types.GitIntegrationData // 未启用Git集成时为nil
+	Security *types.ScanSummary        // 未扫描时为nil
+	Issues   []TemplateIssue           // 所有文件的安全问题
+	License  *types.LicenseReport      // 未检测时为nil
+	Chunk    *types.ChunkInfo          // 未分块时为nil
+	Metadata map[string]interface{}
+}
+
+// TemplateFile 模板中的文件信息
+type TemplateFile struct {
+	Path     string
+	Name     string
+	Size     int64
+	ModTime  time.Time
+	Content  string
+	Language string
+	Lines    int
+	Tokens   int
+	IsBinary bool
+	IsHidden bool
+	Issues   []types.SecurityIssue
+}
+
+// TemplateIssue 带文件路径的安全问题
+type TemplateIssue struct {
+	types.SecurityIssue
+	Path string
 }
 
 // ProjectData 项目数据
@@ -90,6 +124,10 @@ func (t *TemplateSystem) getTemplateFuncs() template.FuncMap {
 		"upper":          strings.ToUpper,
 		"title":          strings.Title,
 		"trim":           strings.TrimSpace,
+		"indent":         indent,
+		"fence":          fence,
+		"lang":           t.lang,
+		"relpath":        relpath,
 	}
 }
 
@@ -118,6 +156,68 @@ func (t *TemplateSystem) CreateDefaultTemplateData(fileCount, folderCount int, t
 	}
 }
 
+// CreateContextTemplateData 根据上下文数据创建完整的模板数据
+func (t *TemplateSystem) CreateContextTemplateData(data types.ContextData) TemplateData {
+	files := make([]TemplateFile, len(data.Files))
+	var issues []TemplateIssue
+	totalTokens := 0
+	languageSet := make(map[string]bool)
+	var languages []string
+	for i, file := range data.Files {
+		content := file.Content
+		if file.IsBinary {
+			content = "[二进制文件 - 内容未显示]"
+		}
+		language := t.lang(file.Path)
+		if language != "unknown" && !languageSet[language] {
+			languageSet[language] = true
+			languages = append(languages, language)
+		}
+		tokens := len(content) / 4
+		totalTokens += tokens
+		files[i] = TemplateFile{
+			Path:     file.Path,
+			Name:     file.Name,
+			Size:     file.Size,
+			ModTime:  file.ModTime,
+			Content:  content,
+			Language: language,
+			Lines:    lineCount(content),
+			Tokens:   tokens,
+			IsBinary: file.IsBinary,
+			IsHidden: file.IsHidden,
+			Issues:   file.Issues,
+		}
+		for _, issue := range file.Issues {
+			issues = append(issues, TemplateIssue{SecurityIssue: issue, Path: file.Path})
+		}
+	}
+
+	templateData := t.CreateDefaultTemplateData(data.FileCount, data.FolderCount, data.TotalSize, languages)
+	templateData.Statistics.TotalTokens = totalTokens
+	if rootPath, ok := data.Metadata["root_path"].(string); ok {
+		templateData.Project.Name = filepath.Base(rootPath)
+		templateData.Project.Path = rootPath
+	}
+	templateData.Files = files
+	templateData.Folders = data.Folders
+	templateData.Tree = RenderTree(data)
+	templateData.Security = data.Security
+	templateData.Issues = issues
+	templateData.License = data.License
+	templateData.Chunk = data.Chunk
+	templateData.Metadata = data.Metadata
+	if git, ok := data.Metadata["git"].(*types.GitIntegrationData); ok {
+		templateData.Git = git
+	}
+	return templateData
+}
+
+// lang 根据文件路径返回语言名称
+func (t *TemplateSystem) lang(filePath string) string {
+	return (&XMLFormatter{config: t.config}).detectLanguage(filePath, "")
+}
+
 // getProjectName 获取项目名称
 func (t *TemplateSystem) getProjectName() string {
 	// 可以从配置或环境变量获取
@@ -210,4 +310,45 @@ func wordCount(s string) int {
 // lineCount 计算行数
 func lineCount(s string) int {
 	return strings.Count(s, "\n") + 1
-}
\ No newline at end of file
+}
+
+// indent 为每个非空行添加指定数量的空格
+func indent(spaces int, s string) string {
+	prefix := strings.Repeat(" ", spaces)
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// fence 用Markdown代码块包裹内容，围栏比内容中最长的连续反引号更长
+func fence(language, content string) string {
+	longest, run := 0, 0
+	for _, r := range content {
+		if r == '`' {
+			run++
+			if run > longest {
+				longest = run
+			}
+		} else {
+			run = 0
+		}
+	}
+	marker := strings.Repeat("`", max(3, longest+1))
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return marker + language + "\n" + content + marker
+}
+
+// relpath 返回 target 相对于 base 的路径，无法计算时返回 target
+func relpath(base, target string) string {
+	rel, err := filepath.Rel(filepath.FromSlash(base), filepath.FromSlash(target))
+	if err != nil {
+		return target
+	}
+	return path.Clean(filepath.ToSlash(rel))
+}
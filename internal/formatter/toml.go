@@ -3,7 +3,6 @@ package formatter
 import (
 	"fmt"
 
-	"code-context-generator/internal/formatter/encoding"
 	"code-context-generator/pkg/types"
 	"github.com/BurntSushi/toml"
 )
@@ -51,6 +50,9 @@ func (f *TOMLFormatter) Format(data types.ContextData) (string, error) {
 	if includeMetadata {
 		// 包含元信息的默认结构
 		type SerializableContextData struct {
+			Chunk       *types.ChunkInfo   `toml:"chunk,omitempty"`
+			Security    *SecuritySummary   `toml:"security,omitempty"`
+			License     *types.LicenseReport `toml:"license,omitempty"`
 			Files       []types.FileInfo   `toml:"files"`
 			Folders     []types.FolderInfo `toml:"folders"`
 			FileCount   int                `toml:"file_count"`
@@ -60,6 +62,9 @@ func (f *TOMLFormatter) Format(data types.ContextData) (string, error) {
 		}
 
 		serializableData := SerializableContextData{
+			Chunk:       data.Chunk,
+			Security:    simplifySecurity(data.Security),
+			License:     data.License,
 			Files:       data.Files,
 			Folders:     data.Folders,
 			FileCount:   data.FileCount,
@@ -71,6 +76,9 @@ func (f *TOMLFormatter) Format(data types.ContextData) (string, error) {
 	} else {
 		// 不包含元信息的简化结构
 		type SimplifiedContextData struct {
+			Chunk       *types.ChunkInfo       `toml:"chunk,omitempty"`
+			Security    *SecuritySummary       `toml:"security,omitempty"`
+			License     *types.LicenseReport   `toml:"license,omitempty"`
 			Files       []SimplifiedFileInfo   `toml:"files"`
 			Folders     []SimplifiedFolderInfo `toml:"folders"`
 			FileCount   int                    `toml:"file_count"`
@@ -79,6 +87,9 @@ func (f *TOMLFormatter) Format(data types.ContextData) (string, error) {
 		}
 		
 		simplifiedData := SimplifiedContextData{
+			Chunk:       data.Chunk,
+			Security:    simplifySecurity(data.Security),
+			License:     data.License,
 			Files:       f.simplifyFiles(data.Files),
 			Folders:     f.simplifyFolders(data.Folders),
 			FileCount:   data.FileCount,
@@ -94,16 +105,6 @@ func (f *TOMLFormatter) Format(data types.ContextData) (string, error) {
 
 	result := string(output)
 
-	// 检查配置中是否指定了编码格式
-	if f.config != nil && f.config.Formats.TOML.Encoding != "" && f.config.Formats.TOML.Encoding != "utf-8" {
-		// 转换编码
-		encodedOutput, err := encoding.ConvertEncoding(result, f.config.Formats.TOML.Encoding)
-		if err != nil {
-			return "", fmt.Errorf("编码转换失败: %w", err)
-		}
-		return encodedOutput, nil
-	}
-
 	return result, nil
 }
 
@@ -122,16 +123,6 @@ func (f *TOMLFormatter) FormatFile(file types.FileInfo) (string, error) {
 
 	result := string(output)
 
-	// 检查配置中是否指定了编码格式
-	if f.config != nil && f.config.Formats.TOML.Encoding != "" && f.config.Formats.TOML.Encoding != "utf-8" {
-		// 转换编码
-		encodedOutput, err := encoding.ConvertEncoding(result, f.config.Formats.TOML.Encoding)
-		if err != nil {
-			return "", fmt.Errorf("编码转换失败: %w", err)
-		}
-		return encodedOutput, nil
-	}
-
 	return result, nil
 }
 
@@ -145,15 +136,5 @@ func (f *TOMLFormatter) FormatFolder(folder types.FolderInfo) (string, error) {
 
 	result := string(output)
 
-	// 检查配置中是否指定了编码格式
-	if f.config != nil && f.config.Formats.TOML.Encoding != "" && f.config.Formats.TOML.Encoding != "utf-8" {
-		// 转换编码
-		encodedOutput, err := encoding.ConvertEncoding(result, f.config.Formats.TOML.Encoding)
-		if err != nil {
-			return "", fmt.Errorf("编码转换失败: %w", err)
-		}
-		return encodedOutput, nil
-	}
-
 	return result, nil
 }
\ No newline at end of file
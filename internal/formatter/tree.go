@@ -0,0 +1,99 @@
+package formatter
+
+import (
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"code-context-generator/pkg/types"
+)
+
+// treeNode ASCII目录树节点
+type treeNode struct {
+	children map[string]*treeNode
+	isDir    bool
+}
+
+// RenderTree 将目录树和文件渲染为ASCII树，目录在前并按名称排序，各格式共用
+//
+// 路径相对于 Metadata 中的 root_path 显示。除目录树中的文件夹和文件外，
+// 不在目录树中的文件（如根目录下的文件或分块输出的后续分块）也会显示。
+func RenderTree(data types.ContextData) string {
+	rootPath, _ := data.Metadata["root_path"].(string)
+	root := &treeNode{children: make(map[string]*treeNode), isDir: true}
+	insert := func(p string, isDir bool) {
+		node := root
+		parts := strings.Split(treeRelativePath(rootPath, p), "/")
+		for i, part := range parts {
+			if part == "" || part == "." {
+				continue
+			}
+			child, ok := node.children[part]
+			if !ok {
+				child = &treeNode{children: make(map[string]*treeNode)}
+				node.children[part] = child
+			}
+			if isDir || i < len(parts)-1 {
+				child.isDir = true
+			}
+			node = child
+		}
+	}
+
+	var walk func(folders []types.FolderInfo)
+	walk = func(folders []types.FolderInfo) {
+		for _, folder := range folders {
+			insert(folder.Path, true)
+			for _, file := range folder.Files {
+				insert(file.Path, false)
+			}
+			walk(folder.Folders)
+		}
+	}
+	walk(data.Folders)
+	for _, file := range data.Files {
+		insert(file.Path, false)
+	}
+
+	var result strings.Builder
+	var write func(node *treeNode, prefix string)
+	write = func(node *treeNode, prefix string) {
+		names := make([]string, 0, len(node.children))
+		for name := range node.children {
+			names = append(names, name)
+		}
+		sort.Slice(names, func(i, j int) bool {
+			a, b := node.children[names[i]], node.children[names[j]]
+			if a.isDir != b.isDir {
+				return a.isDir
+			}
+			return names[i] < names[j]
+		})
+		for i, name := range names {
+			child := node.children[name]
+			connector, childPrefix := "├── ", "│   "
+			if i == len(names)-1 {
+				connector, childPrefix = "└── ", "    "
+			}
+			result.WriteString(prefix + connector + name)
+			if child.isDir {
+				result.WriteString("/")
+			}
+			result.WriteString("\n")
+			write(child, prefix+childPrefix)
+		}
+	}
+	write(root, "")
+	return result.String()
+}
+
+// treeRelativePath 返回相对于根路径的斜杠分隔路径，无法计算时使用原路径
+func treeRelativePath(rootPath, p string) string {
+	if rootPath != "" {
+		if rel, err := filepath.Rel(rootPath, p); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			p = rel
+		}
+	}
+	return path.Clean(strings.ReplaceAll(p, "\\", "/"))
+}
@@ -6,7 +6,6 @@ import (
 	"strings"
 	"time"
 
-	"code-context-generator/internal/formatter/encoding"
 	"code-context-generator/pkg/types"
 )
 
@@ -69,6 +68,9 @@ func (f *XMLFormatter) Format(data types.ContextData) (string, error) {
 		// 包含元信息的默认结构
 		type SerializableContextData struct {
 			XMLName     xml.Name           `xml:"context"`
+			Chunk       *types.ChunkInfo   `xml:"chunk_info,omitempty"`
+			Security    *SecuritySummary   `xml:"security,omitempty"`
+			License     *types.LicenseReport `xml:"license,omitempty"`
 			Files       []types.FileInfo   `xml:"files>file"`
 			Folders     []types.FolderInfo `xml:"folders>folder"`
 			FileCount   int                `xml:"file_count"`
@@ -87,6 +89,9 @@ func (f *XMLFormatter) Format(data types.ContextData) (string, error) {
 		}
 
 		serializableData := SerializableContextData{
+			Chunk:       data.Chunk,
+			Security:    simplifySecurity(data.Security),
+			License:     data.License,
 			Files:       data.Files,
 			Folders:     data.Folders,
 			FileCount:   data.FileCount,
@@ -99,6 +104,9 @@ func (f *XMLFormatter) Format(data types.ContextData) (string, error) {
 		// 不包含元信息的简化结构
 		type SimplifiedContextData struct {
 			XMLName     xml.Name               `xml:"context"`
+			Chunk       *types.ChunkInfo       `xml:"chunk_info,omitempty"`
+			Security    *SecuritySummary       `xml:"security,omitempty"`
+			License     *types.LicenseReport   `xml:"license,omitempty"`
 			Files       []SimplifiedFileInfo   `xml:"files>file"`
 			Folders     []SimplifiedFolderInfo `xml:"folders>folder"`
 			FileCount   int                    `xml:"file_count"`
@@ -107,6 +115,9 @@ func (f *XMLFormatter) Format(data types.ContextData) (string, error) {
 		}
 
 		simplifiedData := SimplifiedContextData{
+			Chunk:       data.Chunk,
+			Security:    simplifySecurity(data.Security),
+			License:     data.License,
 			Files:       f.simplifyFiles(data.Files),
 			Folders:     f.simplifyFolders(data.Folders),
 			FileCount:   data.FileCount,
@@ -122,16 +133,6 @@ func (f *XMLFormatter) Format(data types.ContextData) (string, error) {
 
 	result := xml.Header + string(output)
 
-	// 检查配置中是否指定了编码格式
-	if f.config != nil && f.config.Formats.XML.FormatConfig.Encoding != "" && f.config.Formats.XML.FormatConfig.Encoding != "utf-8" {
-		// 转换编码
-		encodedOutput, err := encoding.ConvertEncoding(result, f.config.Formats.XML.FormatConfig.Encoding)
-		if err != nil {
-			return "", fmt.Errorf("编码转换失败: %w", err)
-		}
-		return encodedOutput, nil
-	}
-
 	return result, nil
 }
 
@@ -168,8 +169,17 @@ func (f *XMLFormatter) formatAIOptimized(data types.ContextData) (string, error)
 	result.WriteString(summary.FormatAsXML())
 	result.WriteString("\n")
 
+	// 写入分块头
+	result.WriteString(formatChunkXML(data.Chunk))
+
+	// 写入安全扫描摘要
+	result.WriteString(formatSecuritySummaryXML(data.Security))
+
+	// 写入许可证摘要
+	result.WriteString(formatLicenseXML(data.License))
+
 	// 生成目录结构
-	directoryStructure := f.generateDirectoryStructure(data.Folders)
+	directoryStructure := f.generateDirectoryStructure(data)
 	result.WriteString(directoryStructure)
 	result.WriteString("\n")
 
@@ -221,8 +231,8 @@ func (f *XMLFormatter) formatFileAIOptimized(file types.FileInfo) (string, error
       <lines>%d</lines>
       <tokens>%d</tokens>
       <language>%s</language>
-    </metadata>
-    <content>
+%s    </metadata>
+%s    <content>
       <![CDATA[%s]]>
     </content>
   </file>`,
@@ -231,39 +241,25 @@ func (f *XMLFormatter) formatFileAIOptimized(file types.FileInfo) (string, error
 		lines,
 		tokens,
 		language,
+		formatHashXML(file.Hash),
+		formatIssuesXML(file.Issues),
 		content,
 	)
 
 	return fileXML, nil
 }
 
-// generateDirectoryStructure 生成目录结构
-func (f *XMLFormatter) generateDirectoryStructure(folders []types.FolderInfo) string {
-	var result strings.Builder
-	result.WriteString("<directory_structure>\n")
-	
-	for _, folder := range folders {
-		f.formatFolderStructure(&result, folder, 1)
+// formatHashXML 生成AI优化XML文件元数据中的内容哈希，未记录时为空
+func formatHashXML(hash string) string {
+	if hash == "" {
+		return ""
 	}
-	
-	result.WriteString("</directory_structure>")
-	return result.String()
+	return fmt.Sprintf("      <hash>%s</hash>\n", hash)
 }
 
-// formatFolderStructure 递归格式化文件夹结构
-func (f *XMLFormatter) formatFolderStructure(result *strings.Builder, folder types.FolderInfo, depth int) {
-	indent := strings.Repeat("  ", depth)
-	result.WriteString(fmt.Sprintf("%s<directory name=\"%s\" path=\"%s\" files=\"%d\" size=\"%d\" />\n",
-		indent,
-		escapeXMLAttribute(folder.Name),
-		escapeXMLAttribute(folder.Path),
-		len(folder.Files),
-		folder.Size,
-	))
-
-	for _, subFolder := range folder.Folders {
-		f.formatFolderStructure(result, subFolder, depth+1)
-	}
+// generateDirectoryStructure 生成目录结构
+func (f *XMLFormatter) generateDirectoryStructure(data types.ContextData) string {
+	return "<directory_structure>\n" + escapeXMLAttribute(RenderTree(data)) + "</directory_structure>"
 }
 
 // detectLanguages 检测编程语言
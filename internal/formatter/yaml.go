@@ -0,0 +1,265 @@
+package formatter
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"code-context-generator/pkg/types"
+	"github.com/goccy/go-yaml"
+)
+
+// YAMLFormatter YAML格式转换器
+type YAMLFormatter struct {
+	BaseFormatter
+	config *types.Config
+}
+
+// NewYAMLFormatter 创建YAML格式转换器
+func NewYAMLFormatter(config *types.Config) Formatter {
+	var formatConfig *types.FormatConfig
+	if config != nil {
+		formatConfig = &config.Formats.YAML
+	}
+	return &YAMLFormatter{
+		BaseFormatter: BaseFormatter{
+			name:        "YAML",
+			description: "YAML Ain't Markup Language format",
+			config:      formatConfig,
+		},
+		config: config,
+	}
+}
+
+// quotedScalar 无法用字面量块表示的字符串，输出为双引号标量
+type quotedScalar string
+
+// MarshalYAML JSON字符串同时也是合法的YAML双引号标量
+func (s quotedScalar) MarshalYAML() ([]byte, error) {
+	return json.Marshal(string(s))
+}
+
+// Format 格式化上下文数据
+func (f *YAMLFormatter) Format(data types.ContextData) (string, error) {
+	// 检查是否启用AI优化
+	if f.config != nil && f.config.Output.AIOptimized {
+		return f.formatAIOptimized(data)
+	}
+
+	// 检查是否包含元信息
+	includeMetadata := false // 默认不包含元信息
+	if f.config != nil {
+		includeMetadata = f.config.Output.IncludeMetadata
+	}
+
+	doc := yaml.MapSlice{}
+	if data.Chunk != nil {
+		doc = append(doc, yaml.MapItem{Key: "chunk", Value: data.Chunk})
+	}
+	if security := simplifySecurity(data.Security); security != nil {
+		doc = append(doc, yaml.MapItem{Key: "security", Value: security})
+	}
+	if data.License != nil {
+		doc = append(doc, yaml.MapItem{Key: "license", Value: data.License})
+	}
+
+	files := make([]yaml.MapSlice, len(data.Files))
+	for i, file := range data.Files {
+		files[i] = f.fileNode(file, includeMetadata)
+	}
+	folders := make([]yaml.MapSlice, len(data.Folders))
+	for i, folder := range data.Folders {
+		folders[i] = f.folderNode(folder, includeMetadata)
+	}
+
+	doc = append(doc,
+		yaml.MapItem{Key: "files", Value: files},
+		yaml.MapItem{Key: "folders", Value: folders},
+		yaml.MapItem{Key: "file_count", Value: data.FileCount},
+		yaml.MapItem{Key: "folder_count", Value: data.FolderCount},
+		yaml.MapItem{Key: "total_size", Value: data.TotalSize},
+	)
+	if includeMetadata && len(data.Metadata) > 0 {
+		doc = append(doc, yaml.MapItem{Key: "metadata", Value: data.Metadata})
+	}
+
+	return f.marshal(doc)
+}
+
+// FormatFile 格式化单个文件
+func (f *YAMLFormatter) FormatFile(file types.FileInfo) (string, error) {
+	includeMetadata := f.config != nil && f.config.Output.IncludeMetadata
+	return f.marshal(f.fileNode(file, includeMetadata))
+}
+
+// FormatFolder 格式化文件夹
+func (f *YAMLFormatter) FormatFolder(folder types.FolderInfo) (string, error) {
+	includeMetadata := f.config != nil && f.config.Output.IncludeMetadata
+	return f.marshal(f.folderNode(folder, includeMetadata))
+}
+
+// formatAIOptimized AI优化的YAML格式化
+func (f *YAMLFormatter) formatAIOptimized(data types.ContextData) (string, error) {
+	xmlFormatter := &XMLFormatter{config: f.config}
+	languages := xmlFormatter.detectLanguages(data.Files)
+	summary := NewAISummaryGenerator(f.config).GenerateSummary(data.FileCount, data.TotalSize, languages)
+
+	doc := yaml.MapSlice{
+		{Key: "file_summary", Value: yaml.MapSlice{
+			{Key: "generated_at", Value: summary.ProjectInfo.GeneratedAt.Format(time.RFC3339)},
+			{Key: "purpose", Value: summary.Purpose},
+			{Key: "usage_guidelines", Value: summary.UsageGuidelines},
+			{Key: "file_count", Value: summary.ProjectInfo.FileCount},
+			{Key: "total_size", Value: summary.ProjectInfo.TotalSize},
+			{Key: "languages", Value: summary.ProjectInfo.Languages},
+		}},
+	}
+	if data.Chunk != nil {
+		doc = append(doc, yaml.MapItem{Key: "chunk", Value: data.Chunk})
+	}
+	if security := simplifySecurity(data.Security); security != nil {
+		doc = append(doc, yaml.MapItem{Key: "security", Value: security})
+	}
+	if data.License != nil {
+		doc = append(doc, yaml.MapItem{Key: "license", Value: data.License})
+	}
+
+	doc = append(doc, yaml.MapItem{Key: "directory_structure", Value: blockScalar(RenderTree(data))})
+
+	files := make([]yaml.MapSlice, len(data.Files))
+	for i, file := range data.Files {
+		content := file.Content
+		if file.IsBinary {
+			content = "[二进制文件 - 内容未显示]"
+		}
+		node := yaml.MapSlice{
+			{Key: f.fieldName("filepath", "path"), Value: file.Path},
+			{Key: "language", Value: xmlFormatter.detectLanguage(file.Path, content)},
+			{Key: "size", Value: file.Size},
+			{Key: "lines", Value: len(strings.Split(content, "\n"))},
+			{Key: "tokens", Value: xmlFormatter.estimateTokens(content)},
+		}
+		if file.Hash != "" {
+			node = append(node, yaml.MapItem{Key: "hash", Value: file.Hash})
+		}
+		if issues := simplifyIssues(file.Issues); len(issues) > 0 {
+			node = append(node, yaml.MapItem{Key: "issues", Value: issues})
+		}
+		node = append(node, yaml.MapItem{Key: f.fieldName("filecontent", "content"), Value: blockScalar(content)})
+		files[i] = node
+	}
+	doc = append(doc, yaml.MapItem{Key: "files", Value: files})
+
+	// 添加自定义指令
+	if f.config.Output.AIInstructions.Enabled {
+		instructions, err := NewInstructionLoader(f.config).LoadInstructions()
+		if err != nil {
+			return "", fmt.Errorf("加载AI指令失败: %w", err)
+		}
+		if instructions != "" {
+			doc = append(doc, yaml.MapItem{Key: "instruction", Value: blockScalar(instructions)})
+		}
+	}
+
+	return f.marshal(doc)
+}
+
+// fileNode 生成文件节点，字段名使用 fields.custom_names 的映射
+func (f *YAMLFormatter) fileNode(file types.FileInfo, includeMetadata bool) yaml.MapSlice {
+	content := file.Content
+	if file.IsBinary {
+		content = "[二进制文件 - 内容未显示]"
+	}
+
+	node := yaml.MapSlice{
+		{Key: f.fieldName("filepath", "path"), Value: file.Path},
+		{Key: f.fieldName("filename", "name"), Value: file.Name},
+		{Key: "size", Value: file.Size},
+	}
+	if file.Hash != "" {
+		node = append(node, yaml.MapItem{Key: "hash", Value: file.Hash})
+	}
+	if includeMetadata {
+		node = append(node,
+			yaml.MapItem{Key: "mod_time", Value: file.ModTime},
+			yaml.MapItem{Key: "is_hidden", Value: file.IsHidden},
+			yaml.MapItem{Key: "is_binary", Value: file.IsBinary},
+		)
+	}
+	if issues := simplifyIssues(file.Issues); len(issues) > 0 {
+		node = append(node, yaml.MapItem{Key: "issues", Value: issues})
+	}
+	return append(node, yaml.MapItem{Key: f.fieldName("filecontent", "content"), Value: blockScalar(content)})
+}
+
+// folderNode 生成文件夹节点
+func (f *YAMLFormatter) folderNode(folder types.FolderInfo, includeMetadata bool) yaml.MapSlice {
+	node := yaml.MapSlice{
+		{Key: f.fieldName("filepath", "path"), Value: folder.Path},
+		{Key: f.fieldName("filename", "name"), Value: folder.Name},
+		{Key: "size", Value: folder.Size},
+		{Key: "count", Value: folder.Count},
+	}
+	if includeMetadata {
+		node = append(node,
+			yaml.MapItem{Key: "mod_time", Value: folder.ModTime},
+			yaml.MapItem{Key: "is_hidden", Value: folder.IsHidden},
+		)
+	}
+	return node
+}
+
+// fieldName 返回字段的自定义名称，未配置时使用默认名称
+func (f *YAMLFormatter) fieldName(key, defaultName string) string {
+	if f.config != nil {
+		if name := f.config.Fields.CustomNames[key]; name != "" {
+			return name
+		}
+	}
+	return defaultName
+}
+
+// marshal 序列化为YAML，多行字符串使用字面量块
+func (f *YAMLFormatter) marshal(doc interface{}) (string, error) {
+	output, err := yaml.MarshalWithOptions(doc,
+		yaml.Indent(2),
+		yaml.IndentSequence(true),
+		yaml.UseLiteralStyleIfMultiline(true),
+	)
+	if err != nil {
+		return "", fmt.Errorf("YAML格式化失败: %w", err)
+	}
+
+	return string(output), nil
+}
+
+// blockScalar 返回适合输出的内容标量
+//
+// 多行内容由编码器输出为字面量块（|），但字面量块无法表示回车、控制字符
+// 以及首个非空行以空格开头的内容（缩进会被当作块的缩进），这些内容改用双引号标量。
+func blockScalar(content string) interface{} {
+	if !utf8.ValidString(content) {
+		return quotedScalar(content)
+	}
+	for _, r := range content {
+		if r != '\n' && r != '\t' && (unicode.IsControl(r) || r == '\uFEFF') {
+			return quotedScalar(content)
+		}
+	}
+	for _, line := range strings.Split(content, "\n") {
+		if strings.TrimSpace(line) == "" {
+			if line != "" {
+				return quotedScalar(content)
+			}
+			continue
+		}
+		if strings.HasPrefix(line, " ") {
+			return quotedScalar(content)
+		}
+		break
+	}
+	return content
+}
@@ -0,0 +1,392 @@
+// Package license 许可证检测与合规分析
+package license
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"code-context-generator/pkg/types"
+
+	"github.com/BurntSushi/toml"
+)
+
+// headerScanLines 查找SPDX头时检查的最大行数
+const headerScanLines = 30
+
+var (
+	spdxHeaderPattern = regexp.MustCompile(`SPDX-License-Identifier:\s*(.+)`)
+	gemspecPattern    = regexp.MustCompile(`\.licenses?\s*=\s*(.+)`)
+	quotedPattern     = regexp.MustCompile(`["']([^"']+)["']`)
+	setupCfgPattern   = regexp.MustCompile(`^\s*license\s*=\s*(.+)$`)
+)
+
+// licenseFileNames 许可证文件名（不含扩展名，小写）
+var licenseFileNames = []string{"license", "licence", "copying", "unlicense"}
+
+// sourceExtensions 需要SPDX头的源代码扩展名
+var sourceExtensions = map[string]bool{
+	".go": true, ".py": true, ".js": true, ".jsx": true, ".ts": true, ".tsx": true,
+	".java": true, ".kt": true, ".scala": true, ".c": true, ".h": true, ".cc": true,
+	".cpp": true, ".hpp": true, ".cs": true, ".rs": true, ".rb": true, ".php": true,
+	".swift": true, ".m": true, ".mm": true, ".sh": true, ".lua": true, ".pl": true,
+	".dart": true, ".vue": true, ".svelte": true,
+}
+
+// Analyzer 许可证分析器
+type Analyzer struct {
+	config *types.LicenseConfig
+}
+
+// NewAnalyzer 创建许可证分析器
+func NewAnalyzer(config *types.LicenseConfig) *Analyzer {
+	if config == nil {
+		config = &types.LicenseConfig{}
+	}
+	return &Analyzer{config: config}
+}
+
+// Analyze 分析文件中的许可证声明，文件路径应相对于项目根目录
+func (a *Analyzer) Analyze(files []types.FileInfo) *types.LicenseReport {
+	report := &types.LicenseReport{}
+
+	for _, file := range files {
+		if file.IsDir || file.IsBinary {
+			continue
+		}
+		filePath := cleanPath(file.Path)
+		name := path.Base(filePath)
+
+		switch {
+		case isLicenseFile(name):
+			report.Findings = append(report.Findings, newFinding(filePath, IdentifyText(file.Content), types.LicenseSourceFile))
+		case isManifest(name):
+			if expr := manifestLicense(name, file.Content); expr != "" {
+				report.Findings = append(report.Findings, newFinding(filePath, NormalizeExpression(expr), types.LicenseSourceManifest))
+			}
+		case sourceExtensions[strings.ToLower(path.Ext(name))]:
+			if expr := spdxHeader(file.Content); expr != "" {
+				report.Findings = append(report.Findings, newFinding(filePath, NormalizeExpression(expr), types.LicenseSourceHeader))
+			} else {
+				report.FilesWithoutHeader = append(report.FilesWithoutHeader, filePath)
+			}
+		}
+	}
+
+	sort.Slice(report.Findings, func(i, j int) bool {
+		return report.Findings[i].Path < report.Findings[j].Path
+	})
+	sort.Strings(report.FilesWithoutHeader)
+
+	declared := directoryDeclarations(report.Findings)
+	report.Directories = directoryList(report.Findings)
+	report.ProjectLicense = a.projectLicense(declared)
+	report.Conflicts = findConflicts(report.Findings, declared, report.ProjectLicense)
+	return report
+}
+
+// projectLicense 确定项目许可证，配置优先，否则使用根目录的声明
+func (a *Analyzer) projectLicense(declared map[string][]string) string {
+	if a.config.ProjectLicense != "" {
+		return NormalizeExpression(a.config.ProjectLicense)
+	}
+	if licenses := declared["."]; len(licenses) > 0 {
+		return joinAlternatives(licenses)
+	}
+	return NoAssertion
+}
+
+// directoryDeclarations 汇总每个目录中可识别的许可证文件和清单声明
+func directoryDeclarations(findings []types.LicenseFinding) map[string][]string {
+	declared := make(map[string][]string)
+	for _, finding := range findings {
+		if finding.Source == types.LicenseSourceHeader || finding.License == NoAssertion {
+			continue
+		}
+		dir := path.Dir(finding.Path)
+		declared[dir] = appendUnique(declared[dir], finding.License)
+	}
+	return declared
+}
+
+// directoryList 生成按路径排序的目录许可证列表，包含无法识别的许可证文件
+func directoryList(findings []types.LicenseFinding) []types.DirectoryLicense {
+	byDir := make(map[string][]string)
+	for _, finding := range findings {
+		if finding.Source == types.LicenseSourceHeader {
+			continue
+		}
+		dir := path.Dir(finding.Path)
+		byDir[dir] = appendUnique(byDir[dir], finding.License)
+	}
+
+	dirs := make([]types.DirectoryLicense, 0, len(byDir))
+	for dir, licenses := range byDir {
+		sort.Strings(licenses)
+		dirs = append(dirs, types.DirectoryLicense{Path: dir, Licenses: licenses})
+	}
+	sort.Slice(dirs, func(i, j int) bool {
+		return dirs[i].Path < dirs[j].Path
+	})
+	return dirs
+}
+
+// findConflicts 查找比所在范围许可证更严格的copyleft声明
+func findConflicts(findings []types.LicenseFinding, declared map[string][]string, project string) []types.LicenseConflict {
+	var conflicts []types.LicenseConflict
+	for _, finding := range findings {
+		rank := rankOf(finding.License)
+		if rank < categoryRanks[types.LicenseCategoryStrongCopyleft] {
+			continue
+		}
+
+		// 源文件受所在目录约束，许可证文件和清单受上级目录约束
+		dir := path.Dir(finding.Path)
+		if finding.Source != types.LicenseSourceHeader {
+			if dir == "." {
+				continue
+			}
+			dir = path.Dir(dir)
+		}
+		governing := governingLicense(dir, declared, project)
+		governingRank := rankOf(governing)
+		if governingRank < 0 || rank <= governingRank {
+			continue
+		}
+
+		conflicts = append(conflicts, types.LicenseConflict{
+			Path:      finding.Path,
+			License:   finding.License,
+			Governing: governing,
+			Message: fmt.Sprintf("%s 许可证（%s）比所在范围的 %s（%s）更严格，分发时需遵守其条款",
+				finding.License, Category(finding.License), governing, Category(governing)),
+		})
+	}
+	return conflicts
+}
+
+// governingLicense 从目录向上查找最近的许可证声明，找不到时使用项目许可证
+func governingLicense(dir string, declared map[string][]string, project string) string {
+	for dir != "." {
+		if licenses := declared[dir]; len(licenses) > 0 {
+			return joinAlternatives(licenses)
+		}
+		dir = path.Dir(dir)
+	}
+	return project
+}
+
+// joinAlternatives 将同一目录的多个许可证组合为可选授权
+func joinAlternatives(licenses []string) string {
+	sorted := append([]string(nil), licenses...)
+	sort.Strings(sorted)
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	parts := make([]string, len(sorted))
+	for i, l := range sorted {
+		if strings.ContainsAny(l, " ") {
+			l = "(" + l + ")"
+		}
+		parts[i] = l
+	}
+	return strings.Join(parts, " OR ")
+}
+
+// CollectFiles 收集上下文数据中的所有文件，按路径去重
+func CollectFiles(data *types.ContextData) []types.FileInfo {
+	seen := make(map[string]bool)
+	var files []types.FileInfo
+	add := func(list []types.FileInfo) {
+		for _, file := range list {
+			key := cleanPath(file.Path)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			files = append(files, file)
+		}
+	}
+
+	var walk func(folders []types.FolderInfo)
+	walk = func(folders []types.FolderInfo) {
+		for _, folder := range folders {
+			add(folder.Files)
+			walk(folder.Folders)
+		}
+	}
+	add(data.Files)
+	walk(data.Folders)
+	return files
+}
+
+// newFinding 创建许可证声明记录
+func newFinding(filePath, license, source string) types.LicenseFinding {
+	return types.LicenseFinding{
+		Path:     filePath,
+		License:  license,
+		Source:   source,
+		Category: Category(license),
+	}
+}
+
+// spdxHeader 读取文件开头的SPDX-License-Identifier声明
+func spdxHeader(content string) string {
+	lines := strings.SplitN(content, "\n", headerScanLines+1)
+	if len(lines) > headerScanLines {
+		lines = lines[:headerScanLines]
+	}
+	for _, line := range lines {
+		if m := spdxHeaderPattern.FindStringSubmatch(line); m != nil {
+			expr := strings.TrimSpace(m[1])
+			for _, suffix := range []string{"*/", "-->", "#}", "*)"} {
+				expr = strings.TrimSpace(strings.TrimSuffix(expr, suffix))
+			}
+			return expr
+		}
+	}
+	return ""
+}
+
+// isLicenseFile 判断是否为许可证文件，如 LICENSE、COPYING.txt、LICENSE-MIT
+func isLicenseFile(name string) bool {
+	lower := strings.ToLower(name)
+	for _, base := range licenseFileNames {
+		if lower == base || strings.HasPrefix(lower, base+".") || strings.HasPrefix(lower, base+"-") || strings.HasPrefix(lower, base+"_") {
+			return true
+		}
+	}
+	return false
+}
+
+// isManifest 判断是否为包含许可证字段的包清单
+func isManifest(name string) bool {
+	switch name {
+	case "package.json", "composer.json", "Cargo.toml", "pyproject.toml", "setup.cfg":
+		return true
+	}
+	return strings.HasSuffix(name, ".gemspec")
+}
+
+// manifestLicense 读取包清单中的许可证字段
+func manifestLicense(name, content string) string {
+	switch {
+	case name == "package.json":
+		var manifest struct {
+			License  interface{} `json:"license"`
+			Licenses []struct {
+				Type string `json:"type"`
+			} `json:"licenses"`
+		}
+		if json.Unmarshal([]byte(content), &manifest) != nil {
+			return ""
+		}
+		switch license := manifest.License.(type) {
+		case string:
+			return license
+		case map[string]interface{}:
+			if t, ok := license["type"].(string); ok {
+				return t
+			}
+		}
+		var alternatives []string
+		for _, l := range manifest.Licenses {
+			alternatives = append(alternatives, l.Type)
+		}
+		return strings.Join(alternatives, " OR ")
+	case name == "composer.json":
+		var manifest struct {
+			License interface{} `json:"license"`
+		}
+		if json.Unmarshal([]byte(content), &manifest) != nil {
+			return ""
+		}
+		switch license := manifest.License.(type) {
+		case string:
+			return license
+		case []interface{}:
+			// composer 的许可证数组表示可选授权
+			var alternatives []string
+			for _, l := range license {
+				if s, ok := l.(string); ok {
+					alternatives = append(alternatives, s)
+				}
+			}
+			return strings.Join(alternatives, " OR ")
+		}
+	case name == "Cargo.toml":
+		var manifest struct {
+			Package struct {
+				License string `toml:"license"`
+			} `toml:"package"`
+		}
+		if _, err := toml.Decode(content, &manifest); err != nil {
+			return ""
+		}
+		// 旧版Cargo使用 "/" 分隔可选许可证
+		return strings.ReplaceAll(manifest.Package.License, "/", " OR ")
+	case name == "pyproject.toml":
+		var manifest struct {
+			Project struct {
+				License interface{} `toml:"license"`
+			} `toml:"project"`
+			Tool struct {
+				Poetry struct {
+					License string `toml:"license"`
+				} `toml:"poetry"`
+			} `toml:"tool"`
+		}
+		if _, err := toml.Decode(content, &manifest); err != nil {
+			return ""
+		}
+		switch license := manifest.Project.License.(type) {
+		case string:
+			return license
+		case map[string]interface{}:
+			if text, ok := license["text"].(string); ok {
+				return text
+			}
+		}
+		return manifest.Tool.Poetry.License
+	case name == "setup.cfg":
+		inMetadata := false
+		for _, line := range strings.Split(content, "\n") {
+			trimmed := strings.TrimSpace(line)
+			if strings.HasPrefix(trimmed, "[") {
+				inMetadata = trimmed == "[metadata]"
+				continue
+			}
+			if m := setupCfgPattern.FindStringSubmatch(line); inMetadata && m != nil {
+				return strings.TrimSpace(m[1])
+			}
+		}
+	case strings.HasSuffix(name, ".gemspec"):
+		if m := gemspecPattern.FindStringSubmatch(content); m != nil {
+			var alternatives []string
+			for _, q := range quotedPattern.FindAllStringSubmatch(m[1], -1) {
+				alternatives = append(alternatives, q[1])
+			}
+			return strings.Join(alternatives, " OR ")
+		}
+	}
+	return ""
+}
+
+// cleanPath 统一为斜杠分隔的相对路径
+func cleanPath(p string) string {
+	return filepath.ToSlash(filepath.Clean(p))
+}
+
+// appendUnique 追加不重复的元素
+func appendUnique(list []string, value string) []string {
+	for _, v := range list {
+		if v == value {
+			return list
+		}
+	}
+	return append(list, value)
+}
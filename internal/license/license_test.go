@@ -0,0 +1,177 @@
+// Package license 提供许可证检测与合规分析的单元测试
+package license
+
+import (
+	"strings"
+	"testing"
+
+	"code-context-generator/pkg/types"
+)
+
+const mitText = `MIT License
+
+Copyright (c) 2024 Example
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction.
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+`
+
+const gplText = `                    GNU GENERAL PUBLIC LICENSE
+                       Version 3, 29 June 2007
+
+ Copyright (C) 2007 Free Software Foundation, Inc. <https://fsf.org/>
+`
+
+// TestIdentifyText 测试根据全文识别许可证
+func TestIdentifyText(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{"MIT", mitText, "MIT"},
+		{"GPL-3.0", gplText, "GPL-3.0-only"},
+		{"Apache-2.0", "Apache License\n   Version 2.0, January 2004\n", "Apache-2.0"},
+		{"LGPL-2.1", "GNU LESSER GENERAL PUBLIC LICENSE\nVersion 2.1, February 1999", "LGPL-2.1-only"},
+		{"无法识别", "All rights reserved.", NoAssertion},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IdentifyText(tt.text); got != tt.expected {
+				t.Errorf("IdentifyText() = %v, 期望 %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestNormalizeExpression 测试表达式规范化和类别判断
+func TestNormalizeExpression(t *testing.T) {
+	tests := []struct {
+		expr     string
+		expected string
+		category string
+	}{
+		{"mit", "MIT", types.LicenseCategoryPermissive},
+		{"Apache License 2.0", "Apache-2.0", types.LicenseCategoryPermissive},
+		{"GPL-3.0+", "GPL-3.0-or-later", types.LicenseCategoryStrongCopyleft},
+		{"MIT OR GPL-2.0", "MIT OR GPL-2.0-only", types.LicenseCategoryPermissive},
+		{"(MIT AND LGPL-2.1)", "(MIT AND LGPL-2.1-only)", types.LicenseCategoryWeakCopyleft},
+		{"GPL-2.0 WITH Classpath-exception-2.0", "GPL-2.0-only WITH Classpath-exception-2.0", types.LicenseCategoryStrongCopyleft},
+		{"LicenseRef-Proprietary", "LicenseRef-Proprietary", types.LicenseCategoryUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got := NormalizeExpression(tt.expr)
+			if got != tt.expected {
+				t.Errorf("NormalizeExpression() = %v, 期望 %v", got, tt.expected)
+			}
+			if category := Category(got); category != tt.category {
+				t.Errorf("Category() = %v, 期望 %v", category, tt.category)
+			}
+		})
+	}
+}
+
+// TestAnalyze 测试目录许可证、缺少头的文件和冲突检测
+func TestAnalyze(t *testing.T) {
+	files := []types.FileInfo{
+		{Name: "LICENSE", Path: "LICENSE", Content: mitText},
+		{Name: "package.json", Path: "package.json", Content: `{"name": "app", "license": "MIT"}`},
+		{Name: "main.go", Path: "main.go", Content: "// SPDX-License-Identifier: MIT\npackage main\n"},
+		{Name: "util.go", Path: "util/util.go", Content: "package util\n"},
+		{Name: "copied.c", Path: "util/copied.c", Content: "/* SPDX-License-Identifier: GPL-2.0-or-later */\nint x;\n"},
+		{Name: "COPYING", Path: "vendor/gpl/COPYING", Content: gplText},
+		{Name: "lib.c", Path: "vendor/gpl/lib.c", Content: "// SPDX-License-Identifier: GPL-3.0-only\n"},
+		{Name: "Cargo.toml", Path: "crates/dual/Cargo.toml", Content: "[package]\nname = \"dual\"\nlicense = \"MIT/Apache-2.0\"\n"},
+		{Name: "logo.png", Path: "logo.png", IsBinary: true},
+	}
+
+	report := NewAnalyzer(nil).Analyze(files)
+
+	if report.ProjectLicense != "MIT" {
+		t.Errorf("ProjectLicense = %v, 期望 MIT", report.ProjectLicense)
+	}
+
+	dirs := make(map[string]string)
+	for _, dir := range report.Directories {
+		dirs[dir.Path] = strings.Join(dir.Licenses, ",")
+	}
+	expectedDirs := map[string]string{
+		".":           "MIT",
+		"vendor/gpl":  "GPL-3.0-only",
+		"crates/dual": "MIT OR Apache-2.0",
+	}
+	for path, licenses := range expectedDirs {
+		if dirs[path] != licenses {
+			t.Errorf("目录 %s 的许可证 = %q, 期望 %q", path, dirs[path], licenses)
+		}
+	}
+
+	if len(report.FilesWithoutHeader) != 1 || report.FilesWithoutHeader[0] != "util/util.go" {
+		t.Errorf("FilesWithoutHeader = %v, 期望 [util/util.go]", report.FilesWithoutHeader)
+	}
+
+	// vendor/gpl 中的源文件受目录自身的GPL约束，不算冲突
+	conflicts := make(map[string]string)
+	for _, conflict := range report.Conflicts {
+		conflicts[conflict.Path] = conflict.Governing
+	}
+	expectedConflicts := map[string]string{
+		"util/copied.c":      "MIT",
+		"vendor/gpl/COPYING": "MIT",
+	}
+	if len(conflicts) != len(expectedConflicts) {
+		t.Errorf("Conflicts = %v, 期望 %v", report.Conflicts, expectedConflicts)
+	}
+	for path, governing := range expectedConflicts {
+		if conflicts[path] != governing {
+			t.Errorf("%s 的约束许可证 = %q, 期望 %q", path, conflicts[path], governing)
+		}
+	}
+}
+
+// TestAnalyzeProjectLicenseOverride 测试配置中的项目许可证优先于根目录声明
+func TestAnalyzeProjectLicenseOverride(t *testing.T) {
+	files := []types.FileInfo{
+		{Name: "LICENSE", Path: "LICENSE", Content: mitText},
+		{Name: "lib.c", Path: "src/lib.c", Content: "// SPDX-License-Identifier: GPL-3.0-only\n"},
+	}
+
+	report := NewAnalyzer(&types.LicenseConfig{ProjectLicense: "gpl-3.0+"}).Analyze(files)
+
+	if report.ProjectLicense != "GPL-3.0-or-later" {
+		t.Errorf("ProjectLicense = %v, 期望 GPL-3.0-or-later", report.ProjectLicense)
+	}
+	if len(report.Conflicts) != 0 {
+		t.Errorf("不应有冲突, 实际 %v", report.Conflicts)
+	}
+}
+
+// TestManifestLicense 测试读取包清单中的许可证字段
+func TestManifestLicense(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"package.json", `{"license": {"type": "ISC"}}`, "ISC"},
+		{"composer.json", `{"license": ["MIT", "GPL-3.0-or-later"]}`, "MIT OR GPL-3.0-or-later"},
+		{"pyproject.toml", "[project]\nlicense = {text = \"BSD-3-Clause\"}\n", "BSD-3-Clause"},
+		{"setup.cfg", "[metadata]\nname = x\nlicense = Apache-2.0\n", "Apache-2.0"},
+		{"demo.gemspec", "spec.licenses = ['MIT', 'Ruby']\n", "MIT OR Ruby"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := manifestLicense(tt.name, tt.content); got != tt.expected {
+				t.Errorf("manifestLicense() = %v, 期望 %v", got, tt.expected)
+			}
+		})
+	}
+}
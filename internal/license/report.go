@@ -0,0 +1,46 @@
+// Package license 许可证检测与合规分析
+package license
+
+import (
+	"fmt"
+	"strings"
+
+	"code-context-generator/pkg/types"
+)
+
+// FormatReport 生成文本格式的许可证合规报告
+func FormatReport(report *types.LicenseReport) string {
+	var result strings.Builder
+
+	result.WriteString("许可证合规报告\n")
+	result.WriteString("==============\n\n")
+	result.WriteString(fmt.Sprintf("项目许可证: %s (%s)\n", report.ProjectLicense, Category(report.ProjectLicense)))
+	result.WriteString(fmt.Sprintf("许可证声明: %d\n", len(report.Findings)))
+	result.WriteString(fmt.Sprintf("许可证冲突: %d\n", len(report.Conflicts)))
+	result.WriteString(fmt.Sprintf("缺少SPDX头的源文件: %d\n\n", len(report.FilesWithoutHeader)))
+
+	if len(report.Directories) > 0 {
+		result.WriteString("目录许可证:\n")
+		for _, dir := range report.Directories {
+			result.WriteString(fmt.Sprintf("  %s: %s\n", dir.Path, strings.Join(dir.Licenses, ", ")))
+		}
+		result.WriteString("\n")
+	}
+
+	if len(report.Conflicts) > 0 {
+		result.WriteString("冲突:\n")
+		for _, conflict := range report.Conflicts {
+			result.WriteString(fmt.Sprintf("  %s: %s\n", conflict.Path, conflict.Message))
+		}
+		result.WriteString("\n")
+	}
+
+	if len(report.FilesWithoutHeader) > 0 {
+		result.WriteString("缺少SPDX头的源文件:\n")
+		for _, file := range report.FilesWithoutHeader {
+			result.WriteString(fmt.Sprintf("  %s\n", file))
+		}
+	}
+
+	return result.String()
+}
@@ -0,0 +1,204 @@
+// Package license 许可证检测与合规分析
+package license
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"code-context-generator/pkg/types"
+)
+
+// NoAssertion 无法识别许可证时使用的SPDX占位值
+const NoAssertion = "NOASSERTION"
+
+//go:embed spdx_licenses.json
+var spdxData []byte
+
+// spdxLicense 内置SPDX许可证条目
+type spdxLicense struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Category string   `json:"category"`
+	Aliases  []string `json:"aliases"`
+	Phrases  []string `json:"phrases"` // 许可证全文中必须同时出现的特征短语，按文件顺序先匹配先得
+}
+
+var (
+	spdxLicenses []spdxLicense
+	spdxIndex    map[string]*spdxLicense
+
+	whitespacePattern = regexp.MustCompile(`\s+`)
+)
+
+// categoryRanks 许可证类别的限制程度，数值越大越严格
+var categoryRanks = map[string]int{
+	types.LicenseCategoryPublicDomain:    0,
+	types.LicenseCategoryPermissive:      1,
+	types.LicenseCategoryWeakCopyleft:    2,
+	types.LicenseCategoryStrongCopyleft:  3,
+	types.LicenseCategoryNetworkCopyleft: 4,
+}
+
+func init() {
+	if err := json.Unmarshal(spdxData, &spdxLicenses); err != nil {
+		panic(fmt.Sprintf("解析内置SPDX许可证列表失败: %v", err))
+	}
+	spdxIndex = make(map[string]*spdxLicense)
+	for i := range spdxLicenses {
+		l := &spdxLicenses[i]
+		spdxIndex[strings.ToLower(l.ID)] = l
+		for _, alias := range l.Aliases {
+			spdxIndex[strings.ToLower(alias)] = l
+		}
+	}
+}
+
+// IdentifyText 根据许可证全文识别SPDX标识符，无法识别时返回 NoAssertion
+func IdentifyText(text string) string {
+	normalized := normalizeText(text)
+	for _, l := range spdxLicenses {
+		if len(l.Phrases) == 0 {
+			continue
+		}
+		matched := true
+		for _, phrase := range l.Phrases {
+			if !strings.Contains(normalized, phrase) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return l.ID
+		}
+	}
+	return NoAssertion
+}
+
+// normalizeText 统一大小写和空白，便于短语匹配
+func normalizeText(text string) string {
+	return whitespacePattern.ReplaceAllString(strings.ToLower(text), " ")
+}
+
+// NormalizeExpression 将许可证声明规范化为SPDX表达式，已知的别名替换为标准标识符
+func NormalizeExpression(expr string) string {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return NoAssertion
+	}
+	// 整体匹配别名，如 "Apache License 2.0"
+	if l, ok := spdxIndex[strings.ToLower(expr)]; ok {
+		return l.ID
+	}
+
+	var result strings.Builder
+	afterWith := false
+	for i, token := range tokenize(expr) {
+		if i > 0 && token != ")" && !strings.HasSuffix(result.String(), "(") {
+			result.WriteString(" ")
+		}
+		switch upper := strings.ToUpper(token); {
+		case upper == "AND" || upper == "OR" || upper == "WITH":
+			result.WriteString(upper)
+			afterWith = upper == "WITH"
+			continue
+		case token == "(" || token == ")":
+			result.WriteString(token)
+		case afterWith:
+			result.WriteString(token)
+		default:
+			if l, ok := spdxIndex[strings.ToLower(token)]; ok {
+				result.WriteString(l.ID)
+			} else {
+				result.WriteString(token)
+			}
+		}
+		afterWith = false
+	}
+	return result.String()
+}
+
+// Category 返回SPDX表达式的类别，OR 取最宽松的选项，AND 取最严格的条款
+func Category(expr string) string {
+	p := &expressionParser{tokens: tokenize(expr)}
+	rank := p.parseOr()
+	for category, r := range categoryRanks {
+		if r == rank {
+			return category
+		}
+	}
+	return types.LicenseCategoryUnknown
+}
+
+// rankOf 返回表达式的限制程度，无法判断时返回-1
+func rankOf(expr string) int {
+	p := &expressionParser{tokens: tokenize(expr)}
+	return p.parseOr()
+}
+
+// tokenize 按空白和括号拆分表达式
+func tokenize(expr string) []string {
+	expr = strings.NewReplacer("(", " ( ", ")", " ) ").Replace(expr)
+	return strings.Fields(expr)
+}
+
+// expressionParser SPDX表达式解析器，只计算限制程度
+type expressionParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *expressionParser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	return strings.ToUpper(p.tokens[p.pos])
+}
+
+func (p *expressionParser) parseOr() int {
+	rank := p.parseAnd()
+	for p.peek() == "OR" {
+		p.pos++
+		next := p.parseAnd()
+		if rank < 0 || (next >= 0 && next < rank) {
+			rank = next
+		}
+	}
+	return rank
+}
+
+func (p *expressionParser) parseAnd() int {
+	rank := p.parseTerm()
+	for p.peek() == "AND" {
+		p.pos++
+		if next := p.parseTerm(); next > rank {
+			rank = next
+		}
+	}
+	return rank
+}
+
+func (p *expressionParser) parseTerm() int {
+	if p.pos >= len(p.tokens) {
+		return -1
+	}
+	token := p.tokens[p.pos]
+	p.pos++
+	if token == "(" {
+		rank := p.parseOr()
+		if p.peek() == ")" {
+			p.pos++
+		}
+		return rank
+	}
+	// 例外条款只放宽原许可证，不改变类别
+	if p.peek() == "WITH" {
+		p.pos += 2
+	}
+	if l, ok := spdxIndex[strings.ToLower(token)]; ok {
+		return categoryRanks[l.Category]
+	}
+	return -1
+}
@@ -0,0 +1,243 @@
+// Package pipeline 文件内容处理管道，在遍历之后、格式化之前统一处理所有输出格式的文件内容
+package pipeline
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"code-context-generator/pkg/types"
+)
+
+// 处理阶段名称
+const (
+	StageLineEndings = "line_endings"
+	StageExpandTabs  = "expand_tabs"
+	StageTrim        = "trim_whitespace"
+	StageBlankLines  = "collapse_blank_lines"
+	StageLineNumbers = "line_numbers"
+	StageTruncate    = "truncate"
+)
+
+// DefaultStages 未配置 stages 时的执行顺序
+//
+// 行号在截断之前添加，截断标记因此不带行号；合并空行会改变行号，
+// 需要行号与源文件一致时不要同时启用两者。
+var DefaultStages = []string{
+	StageLineEndings,
+	StageExpandTabs,
+	StageTrim,
+	StageBlankLines,
+	StageLineNumbers,
+	StageTruncate,
+}
+
+// Stage 内容处理阶段
+type Stage struct {
+	Name  string
+	Apply func(content string) string
+}
+
+// Pipeline 按顺序执行的内容处理阶段
+type Pipeline struct {
+	stages []Stage
+}
+
+// New 根据配置创建处理管道，只包含已启用的阶段
+func New(config *types.ProcessingConfig) (*Pipeline, error) {
+	order := config.Stages
+	if len(order) == 0 {
+		order = DefaultStages
+	}
+
+	p := &Pipeline{}
+	seen := make(map[string]bool)
+	for _, name := range order {
+		if seen[name] {
+			return nil, fmt.Errorf("内容处理阶段重复: %s", name)
+		}
+		seen[name] = true
+
+		var apply func(string) string
+		switch name {
+		case StageLineEndings:
+			if config.NormalizeLineEndings {
+				apply = normalizeLineEndings
+			}
+		case StageExpandTabs:
+			if config.TabWidth < 0 {
+				return nil, fmt.Errorf("无效的制表符宽度: %d", config.TabWidth)
+			}
+			if config.TabWidth > 0 {
+				width := config.TabWidth
+				apply = func(content string) string { return expandTabs(content, width) }
+			}
+		case StageTrim:
+			if config.TrimWhitespace {
+				apply = trimTrailingWhitespace
+			}
+		case StageBlankLines:
+			if config.CollapseBlankLines {
+				apply = collapseBlankLines
+			}
+		case StageLineNumbers:
+			if config.AddLineNumbers {
+				apply = addLineNumbers
+			}
+		case StageTruncate:
+			if config.MaxLength < 0 {
+				return nil, fmt.Errorf("无效的最大内容长度: %d", config.MaxLength)
+			}
+			if config.MaxLength > 0 {
+				maxLength := config.MaxLength
+				apply = func(content string) string { return truncate(content, maxLength) }
+			}
+		default:
+			return nil, fmt.Errorf("未知的内容处理阶段: %s (支持: %s)", name, strings.Join(DefaultStages, ", "))
+		}
+		if apply != nil {
+			p.stages = append(p.stages, Stage{Name: name, Apply: apply})
+		}
+	}
+	return p, nil
+}
+
+// Stages 返回已启用的阶段名称
+func (p *Pipeline) Stages() []string {
+	names := make([]string, len(p.stages))
+	for i, stage := range p.stages {
+		names[i] = stage.Name
+	}
+	return names
+}
+
+// Process 依次执行所有阶段
+func (p *Pipeline) Process(content string) string {
+	for _, stage := range p.stages {
+		content = stage.Apply(content)
+	}
+	return content
+}
+
+// ProcessFiles 处理所有文本文件的内容，二进制文件保持不变
+func (p *Pipeline) ProcessFiles(files []types.FileInfo) {
+	if len(p.stages) == 0 {
+		return
+	}
+	for i := range files {
+		if files[i].IsBinary || files[i].Content == "" {
+			continue
+		}
+		files[i].Content = p.Process(files[i].Content)
+	}
+}
+
+// normalizeLineEndings 将CRLF和CR统一为LF
+func normalizeLineEndings(content string) string {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	return strings.ReplaceAll(content, "\r", "\n")
+}
+
+// expandTabs 按列位置将制表符展开为空格
+func expandTabs(content string, width int) string {
+	if !strings.Contains(content, "\t") {
+		return content
+	}
+	var result strings.Builder
+	column := 0
+	for _, r := range content {
+		switch r {
+		case '\t':
+			spaces := width - column%width
+			result.WriteString(strings.Repeat(" ", spaces))
+			column += spaces
+		case '\n':
+			result.WriteRune(r)
+			column = 0
+		default:
+			result.WriteRune(r)
+			column++
+		}
+	}
+	return result.String()
+}
+
+// trimTrailingWhitespace 去除每行末尾的空格和制表符，保留原有换行符
+func trimTrailingWhitespace(content string) string {
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		if strings.HasSuffix(line, "\r") {
+			lines[i] = strings.TrimRight(line[:len(line)-1], " \t") + "\r"
+		} else {
+			lines[i] = strings.TrimRight(line, " \t")
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// collapseBlankLines 将连续空行合并为一行
+func collapseBlankLines(content string) string {
+	lines := strings.Split(content, "\n")
+	result := lines[:0]
+	previousBlank := false
+	for i, line := range lines {
+		blank := strings.TrimSpace(line) == ""
+		// 保留末尾换行产生的空元素
+		if blank && previousBlank && i < len(lines)-1 {
+			continue
+		}
+		result = append(result, line)
+		previousBlank = blank
+	}
+	return strings.Join(result, "\n")
+}
+
+// addLineNumbers 在每行前添加右对齐的行号
+func addLineNumbers(content string) string {
+	lines := strings.Split(content, "\n")
+	trailingNewline := lines[len(lines)-1] == ""
+	if trailingNewline {
+		lines = lines[:len(lines)-1]
+	}
+	width := len(strconv.Itoa(len(lines)))
+	for i, line := range lines {
+		lines[i] = fmt.Sprintf("%*d | %s", width, i+1, line)
+	}
+	result := strings.Join(lines, "\n")
+	if trailingNewline {
+		result += "\n"
+	}
+	return result
+}
+
+// truncate 将内容截断到最多 maxLength 个字符，尽量在行尾截断，并添加截断标记
+func truncate(content string, maxLength int) string {
+	if utf8.RuneCountInString(content) <= maxLength {
+		return content
+	}
+
+	cut := len(content)
+	count := 0
+	for i := range content {
+		if count == maxLength {
+			cut = i
+			break
+		}
+		count++
+	}
+	kept := content[:cut]
+	if i := strings.LastIndex(kept, "\n"); i >= 0 {
+		kept = kept[:i+1]
+	} else {
+		kept += "\n"
+	}
+
+	rest := content[len(strings.TrimSuffix(kept, "\n")):]
+	rest = strings.TrimPrefix(rest, "\n")
+	omitted := strings.Count(rest, "\n")
+	if !strings.HasSuffix(rest, "\n") {
+		omitted++
+	}
+	return kept + fmt.Sprintf("... [内容已截断，省略 %d 行]\n", omitted)
+}
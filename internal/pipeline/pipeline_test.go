@@ -0,0 +1,113 @@
+// Package pipeline 提供内容处理管道的单元测试
+package pipeline
+
+import (
+	"reflect"
+	"testing"
+
+	"code-context-generator/pkg/types"
+)
+
+// TestStages 测试各个处理阶段
+func TestStages(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   types.ProcessingConfig
+		input    string
+		expected string
+	}{
+		{"统一换行符", types.ProcessingConfig{NormalizeLineEndings: true}, "a\r\nb\rc\n", "a\nb\nc\n"},
+		{"展开制表符", types.ProcessingConfig{TabWidth: 4}, "\tx\nab\tc\n", "    x\nab  c\n"},
+		{"去除行尾空白", types.ProcessingConfig{TrimWhitespace: true}, "a  \nb\t\r\n  c\n", "a\nb\r\n  c\n"},
+		{"合并空行", types.ProcessingConfig{CollapseBlankLines: true}, "a\n\n\n  \nb\n\n", "a\n\nb\n\n"},
+		{"添加行号", types.ProcessingConfig{AddLineNumbers: true}, "a\n\nb\nc\nd\ne\nf\ng\nh\ni\n", " 1 | a\n 2 | \n 3 | b\n 4 | c\n 5 | d\n 6 | e\n 7 | f\n 8 | g\n 9 | h\n10 | i\n"},
+		{"无末尾换行的行号", types.ProcessingConfig{AddLineNumbers: true}, "a\nb", "1 | a\n2 | b"},
+		{"在行尾截断", types.ProcessingConfig{MaxLength: 6}, "abc\ndef\nghi\n", "abc\n... [内容已截断，省略 2 行]\n"},
+		{"单行截断", types.ProcessingConfig{MaxLength: 2}, "中文内容", "中文\n... [内容已截断，省略 1 行]\n"},
+		{"未超出长度", types.ProcessingConfig{MaxLength: 20}, "abc\n", "abc\n"},
+		{"未启用任何阶段", types.ProcessingConfig{}, "a  \r\n\tb", "a  \r\n\tb"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := New(&tt.config)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if got := p.Process(tt.input); got != tt.expected {
+				t.Errorf("Process() = %q, 期望 %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestPipelineOrder 测试默认顺序和自定义顺序
+func TestPipelineOrder(t *testing.T) {
+	config := types.ProcessingConfig{
+		NormalizeLineEndings: true,
+		TrimWhitespace:       true,
+		CollapseBlankLines:   true,
+		AddLineNumbers:       true,
+		MaxLength:            16,
+	}
+	p, err := New(&config)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	expected := []string{StageLineEndings, StageTrim, StageBlankLines, StageLineNumbers, StageTruncate}
+	if !reflect.DeepEqual(p.Stages(), expected) {
+		t.Errorf("Stages() = %v, 期望 %v", p.Stages(), expected)
+	}
+
+	// 行号在截断之前添加，截断标记不带行号
+	if got := p.Process("a \r\n\r\n\r\nb\r\nc\r\n"); got != "1 | a\n2 | \n... [内容已截断，省略 2 行]\n" {
+		t.Errorf("Process() = %q", got)
+	}
+
+	// 自定义顺序：先截断再添加行号
+	config.Stages = []string{StageTruncate, StageLineNumbers}
+	p, err = New(&config)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if got := p.Process("aaaa\nbbbb\ncccc\ndddd\n"); got != "1 | aaaa\n2 | bbbb\n3 | cccc\n4 | ... [内容已截断，省略 1 行]\n" {
+		t.Errorf("Process() = %q", got)
+	}
+}
+
+// TestNewErrors 测试无效配置
+func TestNewErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config types.ProcessingConfig
+	}{
+		{"未知阶段", types.ProcessingConfig{Stages: []string{"highlight"}}},
+		{"重复阶段", types.ProcessingConfig{Stages: []string{StageTrim, StageTrim}}},
+		{"负的制表符宽度", types.ProcessingConfig{TabWidth: -1}},
+		{"负的最大长度", types.ProcessingConfig{MaxLength: -1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(&tt.config); err == nil {
+				t.Error("New() 应该返回错误")
+			}
+		})
+	}
+}
+
+// TestProcessFiles 测试二进制文件不被处理
+func TestProcessFiles(t *testing.T) {
+	p, err := New(&types.ProcessingConfig{AddLineNumbers: true})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	files := []types.FileInfo{
+		{Path: "a.go", Content: "package a\n"},
+		{Path: "b.bin", Content: "\x00\x01", IsBinary: true},
+	}
+	p.ProcessFiles(files)
+	if files[0].Content != "1 | package a\n" || files[1].Content != "\x00\x01" {
+		t.Errorf("ProcessFiles() 结果错误: %q, %q", files[0].Content, files[1].Content)
+	}
+}
@@ -0,0 +1,248 @@
+// Package sbom 根据依赖清单和锁文件生成软件物料清单（SBOM）
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// toolName 写入SBOM的生成工具名称
+const toolName = "c-gen"
+
+var spdxIDInvalid = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+
+// Format 按指定格式序列化SBOM
+func Format(bom *BOM, format string) ([]byte, error) {
+	switch strings.ToLower(format) {
+	case FormatCycloneDX:
+		return FormatCycloneDXJSON(bom)
+	case FormatSPDX:
+		return FormatSPDXJSON(bom)
+	default:
+		return nil, fmt.Errorf("不支持的SBOM格式: %s", format)
+	}
+}
+
+// FormatFromFilename 根据文件名推断SBOM格式，*.spdx.json 为SPDX，其余为CycloneDX
+func FormatFromFilename(filename string) string {
+	if strings.HasSuffix(strings.ToLower(filename), ".spdx.json") {
+		return FormatSPDX
+	}
+	return FormatCycloneDX
+}
+
+// cdxBOM CycloneDX 1.5 文档
+type cdxBOM struct {
+	BOMFormat    string          `json:"bomFormat"`
+	SpecVersion  string          `json:"specVersion"`
+	SerialNumber string          `json:"serialNumber"`
+	Version      int             `json:"version"`
+	Metadata     cdxMetadata     `json:"metadata"`
+	Components   []cdxComponent  `json:"components"`
+	Dependencies []cdxDependency `json:"dependencies"`
+}
+
+type cdxMetadata struct {
+	Timestamp string `json:"timestamp"`
+	Tools     struct {
+		Components []cdxComponent `json:"components"`
+	} `json:"tools"`
+	Component cdxComponent `json:"component"`
+}
+
+type cdxComponent struct {
+	Type     string       `json:"type"`
+	BOMRef   string       `json:"bom-ref,omitempty"`
+	Name     string       `json:"name"`
+	Version  string       `json:"version,omitempty"`
+	PURL     string       `json:"purl,omitempty"`
+	Hashes   []cdxHash    `json:"hashes,omitempty"`
+	Licenses []cdxLicense `json:"licenses,omitempty"`
+}
+
+type cdxHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+type cdxLicense struct {
+	Expression string `json:"expression"`
+}
+
+type cdxDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+// FormatCycloneDXJSON 生成CycloneDX 1.5 JSON
+func FormatCycloneDXJSON(bom *BOM) ([]byte, error) {
+	doc := cdxBOM{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + bom.SerialNumber,
+		Version:      1,
+		Components:   []cdxComponent{},
+		Dependencies: []cdxDependency{},
+	}
+	doc.Metadata.Timestamp = bom.Created.Format(time.RFC3339)
+	doc.Metadata.Tools.Components = []cdxComponent{{Type: ComponentApplication, Name: toolName}}
+	doc.Metadata.Component = toCycloneDXComponent(bom.Root)
+
+	for _, c := range bom.Components {
+		doc.Components = append(doc.Components, toCycloneDXComponent(c))
+	}
+	// 每个组件都列出依赖项，没有依赖的组件 dependsOn 为空数组
+	for _, c := range append([]*Component{bom.Root}, bom.Components...) {
+		dependsOn := bom.Dependencies[c.Ref]
+		if dependsOn == nil {
+			dependsOn = []string{}
+		}
+		doc.Dependencies = append(doc.Dependencies, cdxDependency{Ref: c.Ref, DependsOn: dependsOn})
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// toCycloneDXComponent 转换为CycloneDX组件
+func toCycloneDXComponent(c *Component) cdxComponent {
+	component := cdxComponent{
+		Type:    c.Type,
+		BOMRef:  c.Ref,
+		Name:    c.Name,
+		Version: c.Version,
+		PURL:    c.PURL,
+	}
+	for _, h := range c.Hashes {
+		component.Hashes = append(component.Hashes, cdxHash{Alg: h.Algorithm, Content: h.Value})
+	}
+	if c.License != "" {
+		component.Licenses = []cdxLicense{{Expression: c.License}}
+	}
+	return component
+}
+
+// spdxDocument SPDX 2.3 文档
+type spdxDocument struct {
+	SPDXVersion       string `json:"spdxVersion"`
+	DataLicense       string `json:"dataLicense"`
+	SPDXID            string `json:"SPDXID"`
+	Name              string `json:"name"`
+	DocumentNamespace string `json:"documentNamespace"`
+	CreationInfo      struct {
+		Created  string   `json:"created"`
+		Creators []string `json:"creators"`
+	} `json:"creationInfo"`
+	DocumentDescribes []string           `json:"documentDescribes"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxPackage struct {
+	Name             string            `json:"name"`
+	SPDXID           string            `json:"SPDXID"`
+	VersionInfo      string            `json:"versionInfo,omitempty"`
+	DownloadLocation string            `json:"downloadLocation"`
+	FilesAnalyzed    bool              `json:"filesAnalyzed"`
+	LicenseDeclared  string            `json:"licenseDeclared,omitempty"`
+	Checksums        []spdxChecksum    `json:"checksums,omitempty"`
+	ExternalRefs     []spdxExternalRef `json:"externalRefs,omitempty"`
+	PrimaryPurpose   string            `json:"primaryPackagePurpose,omitempty"`
+}
+
+type spdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+// FormatSPDXJSON 生成SPDX 2.3 JSON
+func FormatSPDXJSON(bom *BOM) ([]byte, error) {
+	doc := spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              bom.Root.Name,
+		DocumentNamespace: fmt.Sprintf("https://spdx.org/spdxdocs/%s-%s", spdxIDInvalid.ReplaceAllString(bom.Root.Name, "-"), bom.SerialNumber),
+		Packages:          []spdxPackage{},
+		Relationships:     []spdxRelationship{},
+	}
+	doc.CreationInfo.Created = bom.Created.Format(time.RFC3339)
+	doc.CreationInfo.Creators = []string{"Tool: " + toolName}
+
+	ids := spdxIDs(bom)
+	doc.DocumentDescribes = []string{ids[bom.Root.Ref]}
+	doc.Relationships = append(doc.Relationships, spdxRelationship{
+		SPDXElementID:      doc.SPDXID,
+		RelationshipType:   "DESCRIBES",
+		RelatedSPDXElement: ids[bom.Root.Ref],
+	})
+
+	for _, c := range append([]*Component{bom.Root}, bom.Components...) {
+		doc.Packages = append(doc.Packages, toSPDXPackage(c, ids[c.Ref]))
+		for _, dep := range bom.Dependencies[c.Ref] {
+			doc.Relationships = append(doc.Relationships, spdxRelationship{
+				SPDXElementID:      ids[c.Ref],
+				RelationshipType:   "DEPENDS_ON",
+				RelatedSPDXElement: ids[dep],
+			})
+		}
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// spdxIDs 为组件分配唯一的SPDX标识符
+func spdxIDs(bom *BOM) map[string]string {
+	ids := make(map[string]string)
+	used := make(map[string]bool)
+	for _, c := range append([]*Component{bom.Root}, bom.Components...) {
+		base := "SPDXRef-Package-" + strings.Trim(spdxIDInvalid.ReplaceAllString(c.Name+"-"+c.Version, "-"), "-")
+		id := base
+		for i := 2; used[id]; i++ {
+			id = fmt.Sprintf("%s-%d", base, i)
+		}
+		used[id] = true
+		ids[c.Ref] = id
+	}
+	return ids
+}
+
+// toSPDXPackage 转换为SPDX包
+func toSPDXPackage(c *Component, id string) spdxPackage {
+	pkg := spdxPackage{
+		Name:             c.Name,
+		SPDXID:           id,
+		VersionInfo:      c.Version,
+		DownloadLocation: "NOASSERTION",
+		LicenseDeclared:  c.License,
+		PrimaryPurpose:   strings.ToUpper(c.Type),
+	}
+	for _, h := range c.Hashes {
+		pkg.Checksums = append(pkg.Checksums, spdxChecksum{
+			Algorithm:     strings.ReplaceAll(h.Algorithm, "-", ""),
+			ChecksumValue: h.Value,
+		})
+	}
+	if c.PURL != "" {
+		pkg.ExternalRefs = []spdxExternalRef{{
+			ReferenceCategory: "PACKAGE-MANAGER",
+			ReferenceType:     "purl",
+			ReferenceLocator:  c.PURL,
+		}}
+	}
+	return pkg
+}
@@ -0,0 +1,576 @@
+// Package sbom 根据依赖清单和锁文件生成软件物料清单（SBOM）
+package sbom
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// purl 类型
+const (
+	purlGolang = "golang"
+	purlNpm    = "npm"
+	purlPyPI   = "pypi"
+	purlCargo  = "cargo"
+)
+
+var (
+	requirementPattern = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)(?:\[[^\]]*\])?\s*===?\s*([^\s;,\\]+)`)
+	requirementHash    = regexp.MustCompile(`--hash[=\s]+(sha256|sha384|sha512):([0-9a-fA-F]+)`)
+	pythonNameSeps     = regexp.MustCompile(`[-_.]+`)
+)
+
+// integrityAlgorithms SRI哈希算法到CycloneDX算法名称的映射
+var integrityAlgorithms = map[string]string{
+	"sha1":   "SHA-1",
+	"sha256": "SHA-256",
+	"sha384": "SHA-384",
+	"sha512": "SHA-512",
+}
+
+// parseGoMod 解析 go.mod，哈希取自同目录的 go.sum
+func parseGoMod(g *graph, dir, source string, content []byte) error {
+	sums := parseGoSum(g.sibling(dir, "go.sum"))
+
+	var module *Component
+	inRequire := false
+	for _, line := range strings.Split(string(content), "\n") {
+		indirect := strings.Contains(line, "// indirect")
+		if idx := strings.Index(line, "//"); idx >= 0 {
+			line = line[:idx]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch {
+		case fields[0] == "module" && len(fields) > 1:
+			module = g.add(&Component{
+				Ref:    purl(purlGolang, strings.Trim(fields[1], `"`), ""),
+				Name:   strings.Trim(fields[1], `"`),
+				Type:   ComponentApplication,
+				PURL:   purl(purlGolang, strings.Trim(fields[1], `"`), ""),
+				Source: source,
+			})
+			g.depend(g.root.Ref, module.Ref)
+			continue
+		case fields[0] == "require" && len(fields) > 1 && fields[1] == "(":
+			inRequire = true
+			continue
+		case inRequire && fields[0] == ")":
+			inRequire = false
+			continue
+		case fields[0] == "require" && len(fields) >= 3:
+			fields = fields[1:]
+		case !inRequire || len(fields) < 2:
+			continue
+		}
+
+		name, version := fields[0], fields[1]
+		dep := g.add(&Component{
+			Ref:     purl(purlGolang, name, version),
+			Name:    name,
+			Version: version,
+			Type:    ComponentLibrary,
+			PURL:    purl(purlGolang, name, version),
+			Hashes:  sums[name+"@"+version],
+			Source:  source,
+		})
+		// go.mod 不记录间接依赖的来源，只连接直接依赖
+		if !indirect {
+			parent := g.root.Ref
+			if module != nil {
+				parent = module.Ref
+			}
+			g.depend(parent, dep.Ref)
+		}
+	}
+	return nil
+}
+
+// parseGoSum 读取 go.sum 中模块内容的 h1 哈希（SHA-256）
+func parseGoSum(content []byte) map[string][]Hash {
+	sums := make(map[string][]Hash)
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 || strings.HasSuffix(fields[1], "/go.mod") || !strings.HasPrefix(fields[2], "h1:") {
+			continue
+		}
+		if sum, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(fields[2], "h1:")); err == nil {
+			sums[fields[0]+"@"+fields[1]] = []Hash{{Algorithm: "SHA-256", Value: hex.EncodeToString(sum)}}
+		}
+	}
+	return sums
+}
+
+// npmLock package-lock.json 结构
+type npmLock struct {
+	Name         string                `json:"name"`
+	Version      string                `json:"version"`
+	Packages     map[string]npmPackage `json:"packages"`
+	Dependencies map[string]npmV1Entry `json:"dependencies"`
+}
+
+// npmPackage lockfileVersion 2/3 的包条目
+type npmPackage struct {
+	Name                 string            `json:"name"`
+	Version              string            `json:"version"`
+	Integrity            string            `json:"integrity"`
+	Link                 bool              `json:"link"`
+	Dependencies         map[string]string `json:"dependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+}
+
+// npmV1Entry lockfileVersion 1 的依赖条目
+type npmV1Entry struct {
+	Version      string                `json:"version"`
+	Integrity    string                `json:"integrity"`
+	Requires     map[string]string     `json:"requires"`
+	Dependencies map[string]npmV1Entry `json:"dependencies"`
+}
+
+// parsePackageLock 解析 package-lock.json
+func parsePackageLock(g *graph, dir, source string, content []byte) error {
+	var lock npmLock
+	if err := json.Unmarshal(content, &lock); err != nil {
+		return err
+	}
+
+	name, version := lock.Name, lock.Version
+	if rootPkg, ok := lock.Packages[""]; ok {
+		if rootPkg.Name != "" {
+			name = rootPkg.Name
+		}
+		if rootPkg.Version != "" {
+			version = rootPkg.Version
+		}
+	}
+	if name == "" {
+		name = dir
+	}
+	project := g.add(&Component{
+		Ref:     purl(purlNpm, name, version),
+		Name:    name,
+		Version: version,
+		Type:    ComponentApplication,
+		PURL:    purl(purlNpm, name, version),
+		Source:  source,
+	})
+	g.depend(g.root.Ref, project.Ref)
+
+	if len(lock.Packages) > 0 {
+		parsePackageLockV2(g, project, source, lock.Packages)
+	} else {
+		parsePackageLockV1(g, project, source, lock.Dependencies, nil)
+	}
+	return nil
+}
+
+// parsePackageLockV2 解析 lockfileVersion 2/3，键为 node_modules 路径
+func parsePackageLockV2(g *graph, project *Component, source string, packages map[string]npmPackage) {
+	refs := map[string]string{"": project.Ref}
+	for key, pkg := range packages {
+		idx := strings.LastIndex(key, "node_modules/")
+		if idx < 0 || pkg.Link || pkg.Version == "" {
+			continue
+		}
+		name := pkg.Name
+		if name == "" {
+			name = key[idx+len("node_modules/"):]
+		}
+		c := g.add(&Component{
+			Ref:     purl(purlNpm, name, pkg.Version),
+			Name:    name,
+			Version: pkg.Version,
+			Type:    ComponentLibrary,
+			PURL:    purl(purlNpm, name, pkg.Version),
+			Hashes:  integrityHashes(pkg.Integrity),
+			Source:  source,
+		})
+		refs[key] = c.Ref
+	}
+
+	for key, from := range refs {
+		pkg := packages[key]
+		names := dependencyNames(pkg.Dependencies, pkg.OptionalDependencies)
+		if key == "" {
+			names = append(names, dependencyNames(pkg.DevDependencies)...)
+		}
+		for _, name := range names {
+			if to, ok := refs[resolveNodeModule(packages, key, name)]; ok {
+				g.depend(from, to)
+			}
+		}
+	}
+}
+
+// resolveNodeModule 按 Node 的查找规则从内向外解析依赖所在的 node_modules 路径
+func resolveNodeModule(packages map[string]npmPackage, from, name string) string {
+	base := from
+	for {
+		candidate := "node_modules/" + name
+		if base != "" {
+			candidate = base + "/node_modules/" + name
+		}
+		if _, ok := packages[candidate]; ok {
+			return candidate
+		}
+		if base == "" {
+			return ""
+		}
+		idx := strings.LastIndex(base, "node_modules/")
+		if idx <= 0 {
+			base = ""
+		} else {
+			base = strings.TrimSuffix(base[:idx], "/")
+		}
+	}
+}
+
+// parsePackageLockV1 解析 lockfileVersion 1 的嵌套依赖树，scopes 为外层的依赖表
+func parsePackageLockV1(g *graph, parent *Component, source string, entries map[string]npmV1Entry, scopes []map[string]npmV1Entry) {
+	scopes = append([]map[string]npmV1Entry{entries}, scopes...)
+	for _, name := range sortedKeys(entries) {
+		entry := entries[name]
+		c := g.add(&Component{
+			Ref:     purl(purlNpm, name, entry.Version),
+			Name:    name,
+			Version: entry.Version,
+			Type:    ComponentLibrary,
+			PURL:    purl(purlNpm, name, entry.Version),
+			Hashes:  integrityHashes(entry.Integrity),
+			Source:  source,
+		})
+		if len(scopes) == 1 {
+			g.depend(parent.Ref, c.Ref)
+		}
+
+		nested := append([]map[string]npmV1Entry{entry.Dependencies}, scopes...)
+		for required := range entry.Requires {
+			for _, scope := range nested {
+				if dep, ok := scope[required]; ok {
+					g.depend(c.Ref, purl(purlNpm, required, dep.Version))
+					break
+				}
+			}
+		}
+		parsePackageLockV1(g, c, source, entry.Dependencies, scopes)
+	}
+}
+
+// integrityHashes 将SRI格式的 integrity 转换为十六进制哈希
+func integrityHashes(integrity string) []Hash {
+	var hashes []Hash
+	for _, item := range strings.Fields(integrity) {
+		alg, value, ok := strings.Cut(item, "-")
+		if !ok || integrityAlgorithms[alg] == "" {
+			continue
+		}
+		if sum, err := base64.StdEncoding.DecodeString(value); err == nil {
+			hashes = append(hashes, Hash{Algorithm: integrityAlgorithms[alg], Value: hex.EncodeToString(sum)})
+		}
+	}
+	return hashes
+}
+
+// poetryLock poetry.lock 结构
+type poetryLock struct {
+	Packages []struct {
+		Name         string                 `toml:"name"`
+		Version      string                 `toml:"version"`
+		Dependencies map[string]interface{} `toml:"dependencies"`
+		Files        []poetryFile           `toml:"files"`
+	} `toml:"package"`
+	Metadata struct {
+		Files map[string][]poetryFile `toml:"files"` // poetry 1.2 之前的格式
+	} `toml:"metadata"`
+}
+
+// poetryFile poetry.lock 中的发行文件
+type poetryFile struct {
+	File string `toml:"file"`
+	Hash string `toml:"hash"`
+}
+
+// pyproject pyproject.toml 中用于确定项目和直接依赖的字段
+type pyproject struct {
+	Project struct {
+		Name         string   `toml:"name"`
+		Version      string   `toml:"version"`
+		Dependencies []string `toml:"dependencies"`
+	} `toml:"project"`
+	Tool struct {
+		Poetry struct {
+			Name            string                 `toml:"name"`
+			Version         string                 `toml:"version"`
+			Dependencies    map[string]interface{} `toml:"dependencies"`
+			DevDependencies map[string]interface{} `toml:"dev-dependencies"`
+			Group           map[string]struct {
+				Dependencies map[string]interface{} `toml:"dependencies"`
+			} `toml:"group"`
+		} `toml:"poetry"`
+	} `toml:"tool"`
+}
+
+// parsePoetryLock 解析 poetry.lock，项目信息取自同目录的 pyproject.toml
+func parsePoetryLock(g *graph, dir, source string, content []byte) error {
+	var lock poetryLock
+	if _, err := toml.Decode(string(content), &lock); err != nil {
+		return err
+	}
+
+	refs := make(map[string]string)
+	depended := make(map[string]bool)
+	for _, pkg := range lock.Packages {
+		name := normalizePythonName(pkg.Name)
+		files := pkg.Files
+		if len(files) == 0 {
+			files = lock.Metadata.Files[pkg.Name]
+		}
+		c := g.add(&Component{
+			Ref:     purl(purlPyPI, name, pkg.Version),
+			Name:    pkg.Name,
+			Version: pkg.Version,
+			Type:    ComponentLibrary,
+			PURL:    purl(purlPyPI, name, pkg.Version),
+			Hashes:  poetryHashes(files),
+			Source:  source,
+		})
+		refs[name] = c.Ref
+		for dep := range pkg.Dependencies {
+			depended[normalizePythonName(dep)] = true
+		}
+	}
+	for _, pkg := range lock.Packages {
+		for dep := range pkg.Dependencies {
+			if to, ok := refs[normalizePythonName(dep)]; ok {
+				g.depend(refs[normalizePythonName(pkg.Name)], to)
+			}
+		}
+	}
+
+	project := g.root
+	direct := make(map[string]bool)
+	if data := g.sibling(dir, "pyproject.toml"); data != nil {
+		var manifest pyproject
+		if _, err := toml.Decode(string(data), &manifest); err == nil {
+			project = pythonProject(g, &manifest, source)
+			for _, name := range pythonDirectDependencies(&manifest) {
+				direct[name] = true
+			}
+		}
+	}
+
+	// 没有 pyproject.toml 时，将未被其他包依赖的包视为直接依赖
+	for name, ref := range refs {
+		if direct[name] || (len(direct) == 0 && !depended[name]) {
+			g.depend(project.Ref, ref)
+		}
+	}
+	return nil
+}
+
+// pythonProject 添加 pyproject.toml 描述的项目组件
+func pythonProject(g *graph, manifest *pyproject, source string) *Component {
+	name, version := manifest.Project.Name, manifest.Project.Version
+	if name == "" {
+		name, version = manifest.Tool.Poetry.Name, manifest.Tool.Poetry.Version
+	}
+	if name == "" {
+		return g.root
+	}
+	project := g.add(&Component{
+		Ref:     purl(purlPyPI, normalizePythonName(name), version),
+		Name:    name,
+		Version: version,
+		Type:    ComponentApplication,
+		PURL:    purl(purlPyPI, normalizePythonName(name), version),
+		Source:  source,
+	})
+	g.depend(g.root.Ref, project.Ref)
+	return project
+}
+
+// pythonDirectDependencies 读取 pyproject.toml 中声明的直接依赖名称
+func pythonDirectDependencies(manifest *pyproject) []string {
+	var names []string
+	for _, spec := range manifest.Project.Dependencies {
+		if m := requirementName(spec); m != "" {
+			names = append(names, normalizePythonName(m))
+		}
+	}
+	poetry := manifest.Tool.Poetry
+	tables := []map[string]interface{}{poetry.Dependencies, poetry.DevDependencies}
+	for _, group := range poetry.Group {
+		tables = append(tables, group.Dependencies)
+	}
+	for _, table := range tables {
+		for name := range table {
+			if name != "python" {
+				names = append(names, normalizePythonName(name))
+			}
+		}
+	}
+	return names
+}
+
+// requirementName 提取PEP 508依赖声明中的包名
+func requirementName(spec string) string {
+	end := strings.IndexAny(spec, " <>=!~;[(")
+	if end < 0 {
+		return strings.TrimSpace(spec)
+	}
+	return strings.TrimSpace(spec[:end])
+}
+
+// poetryHashes 选取源码包的哈希，没有源码包时使用第一个发行文件
+func poetryHashes(files []poetryFile) []Hash {
+	if len(files) == 0 {
+		return nil
+	}
+	chosen := files[0]
+	for _, f := range files {
+		if strings.HasSuffix(f.File, ".tar.gz") {
+			chosen = f
+			break
+		}
+	}
+	alg, value, ok := strings.Cut(chosen.Hash, ":")
+	if !ok || integrityAlgorithms[alg] == "" {
+		return nil
+	}
+	return []Hash{{Algorithm: integrityAlgorithms[alg], Value: strings.ToLower(value)}}
+}
+
+// normalizePythonName 按PEP 503规范化包名
+func normalizePythonName(name string) string {
+	return pythonNameSeps.ReplaceAllString(strings.ToLower(name), "-")
+}
+
+// cargoLock Cargo.lock 结构
+type cargoLock struct {
+	Packages []struct {
+		Name         string   `toml:"name"`
+		Version      string   `toml:"version"`
+		Source       string   `toml:"source"`
+		Checksum     string   `toml:"checksum"`
+		Dependencies []string `toml:"dependencies"`
+	} `toml:"package"`
+}
+
+// parseCargoLock 解析 Cargo.lock，没有 source 的包为工作区成员
+func parseCargoLock(g *graph, dir, source string, content []byte) error {
+	var lock cargoLock
+	if _, err := toml.Decode(string(content), &lock); err != nil {
+		return err
+	}
+
+	byName := make(map[string][]*Component)
+	for _, pkg := range lock.Packages {
+		c := &Component{
+			Ref:     purl(purlCargo, pkg.Name, pkg.Version),
+			Name:    pkg.Name,
+			Version: pkg.Version,
+			Type:    ComponentLibrary,
+			PURL:    purl(purlCargo, pkg.Name, pkg.Version),
+			Source:  source,
+		}
+		if pkg.Checksum != "" {
+			c.Hashes = []Hash{{Algorithm: "SHA-256", Value: pkg.Checksum}}
+		}
+		if pkg.Source == "" {
+			c.Type = ComponentApplication
+		}
+		c = g.add(c)
+		if pkg.Source == "" {
+			g.depend(g.root.Ref, c.Ref)
+		}
+		byName[pkg.Name] = append(byName[pkg.Name], c)
+	}
+
+	for _, pkg := range lock.Packages {
+		from := purl(purlCargo, pkg.Name, pkg.Version)
+		for _, dep := range pkg.Dependencies {
+			// 依赖写作 "name"、"name version" 或 "name version (source)"
+			fields := strings.Fields(dep)
+			if len(fields) == 0 {
+				continue
+			}
+			candidates := byName[fields[0]]
+			for _, c := range candidates {
+				if len(fields) == 1 || c.Version == fields[1] {
+					g.depend(from, c.Ref)
+					break
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// parseRequirements 解析 requirements.txt 中固定版本的依赖及其 --hash
+func parseRequirements(g *graph, dir, source string, content []byte) error {
+	// 合并以反斜杠续行的条目
+	text := strings.ReplaceAll(string(content), "\\\r\n", " ")
+	text = strings.ReplaceAll(text, "\\\n", " ")
+
+	for _, line := range strings.Split(text, "\n") {
+		if idx := strings.Index(line, " #"); idx >= 0 {
+			line = line[:idx]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "-") {
+			continue
+		}
+		m := requirementPattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		var hashes []Hash
+		if h := requirementHash.FindStringSubmatch(line); h != nil {
+			hashes = []Hash{{Algorithm: integrityAlgorithms[h[1]], Value: strings.ToLower(h[2])}}
+		}
+		name := normalizePythonName(m[1])
+		c := g.add(&Component{
+			Ref:     purl(purlPyPI, name, m[2]),
+			Name:    m[1],
+			Version: m[2],
+			Type:    ComponentLibrary,
+			PURL:    purl(purlPyPI, name, m[2]),
+			Hashes:  hashes,
+			Source:  source,
+		})
+		g.depend(g.root.Ref, c.Ref)
+	}
+	return nil
+}
+
+// dependencyNames 合并依赖表中的包名
+func dependencyNames(tables ...map[string]string) []string {
+	var names []string
+	for _, table := range tables {
+		for name := range table {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sortedKeys 返回排序后的键
+func sortedKeys(entries map[string]npmV1Entry) []string {
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
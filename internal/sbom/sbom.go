@@ -0,0 +1,229 @@
+// Package sbom 根据依赖清单和锁文件生成软件物料清单（SBOM）
+package sbom
+
+import (
+	"crypto/rand"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"code-context-generator/internal/license"
+	"code-context-generator/pkg/types"
+)
+
+// 组件类型
+const (
+	ComponentApplication = "application"
+	ComponentLibrary     = "library"
+)
+
+// 支持的SBOM格式
+const (
+	FormatCycloneDX = "cyclonedx"
+	FormatSPDX      = "spdx"
+)
+
+// Hash 组件哈希，算法名称使用CycloneDX写法（如 SHA-256）
+type Hash struct {
+	Algorithm string
+	Value     string // 十六进制
+}
+
+// Component SBOM中的组件
+type Component struct {
+	Ref     string // 唯一引用，库组件为purl
+	Name    string
+	Version string
+	Type    string
+	PURL    string
+	Hashes  []Hash
+	License string // SPDX表达式，仅项目组件
+	Source  string // 来源清单路径
+}
+
+// BOM 软件物料清单
+type BOM struct {
+	SerialNumber string
+	Created      time.Time
+	Root         *Component
+	Components   []*Component        // 不含根组件，按引用排序
+	Dependencies map[string][]string // 组件引用 -> 直接依赖的引用
+}
+
+// lockfileParser 锁文件解析函数，dir 为锁文件所在目录
+type lockfileParser func(g *graph, dir, source string, content []byte) error
+
+// lockfileParsers 支持的清单和锁文件
+var lockfileParsers = map[string]lockfileParser{
+	"go.mod":            parseGoMod,
+	"package-lock.json": parsePackageLock,
+	"poetry.lock":       parsePoetryLock,
+	"Cargo.lock":        parseCargoLock,
+	"requirements.txt":  parseRequirements,
+}
+
+// auxiliaryFiles 只为其他锁文件提供补充信息的文件
+var auxiliaryFiles = map[string]bool{
+	"go.sum":         true,
+	"pyproject.toml": true,
+}
+
+// IsSupportedFile 检查文件名是否为支持的清单或锁文件
+func IsSupportedFile(name string) bool {
+	_, ok := lockfileParsers[name]
+	return ok || auxiliaryFiles[name]
+}
+
+// Build 从遍历得到的文件中生成SBOM，文件路径应相对于 root
+func Build(root string, files []types.FileInfo) (*BOM, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("获取绝对路径失败: %v", err)
+	}
+
+	g := newGraph()
+	g.root = &Component{
+		Ref:     filepath.Base(absRoot),
+		Name:    filepath.Base(absRoot),
+		Type:    ComponentApplication,
+		License: projectLicense(files),
+	}
+	g.add(g.root)
+
+	// 先读取所有文件，go.sum 和 pyproject.toml 需要在解析同目录锁文件时使用
+	var lockfiles []string
+	for _, file := range files {
+		name := path.Base(filepath.ToSlash(file.Path))
+		if file.IsDir || !IsSupportedFile(name) {
+			continue
+		}
+		source := filepath.ToSlash(filepath.Clean(file.Path))
+		if _, seen := g.files[source]; seen {
+			continue
+		}
+		content := file.Content
+		if content == "" {
+			data, err := os.ReadFile(filepath.Join(root, file.Path))
+			if err != nil {
+				return nil, fmt.Errorf("读取清单失败: %v", err)
+			}
+			content = string(data)
+		}
+		g.files[source] = []byte(content)
+		if !auxiliaryFiles[name] {
+			lockfiles = append(lockfiles, source)
+		}
+	}
+	sort.Strings(lockfiles)
+
+	for _, source := range lockfiles {
+		parser := lockfileParsers[path.Base(source)]
+		if err := parser(g, path.Dir(source), source, g.files[source]); err != nil {
+			return nil, fmt.Errorf("解析清单 %s 失败: %v", source, err)
+		}
+	}
+
+	return g.bom(), nil
+}
+
+// projectLicense 识别项目许可证，未声明时返回空
+func projectLicense(files []types.FileInfo) string {
+	report := license.NewAnalyzer(nil).Analyze(files)
+	if report.ProjectLicense == license.NoAssertion {
+		return ""
+	}
+	return report.ProjectLicense
+}
+
+// graph 构建中的组件和依赖关系
+type graph struct {
+	root       *Component
+	components map[string]*Component
+	edges      map[string]map[string]bool
+	files      map[string][]byte
+}
+
+func newGraph() *graph {
+	return &graph{
+		components: make(map[string]*Component),
+		edges:      make(map[string]map[string]bool),
+		files:      make(map[string][]byte),
+	}
+}
+
+// add 添加组件，已存在时合并哈希并返回已有组件
+func (g *graph) add(c *Component) *Component {
+	if existing, ok := g.components[c.Ref]; ok {
+		if len(existing.Hashes) == 0 {
+			existing.Hashes = c.Hashes
+		}
+		return existing
+	}
+	g.components[c.Ref] = c
+	return c
+}
+
+// depend 记录依赖关系
+func (g *graph) depend(from, to string) {
+	if from == to {
+		return
+	}
+	if g.edges[from] == nil {
+		g.edges[from] = make(map[string]bool)
+	}
+	g.edges[from][to] = true
+}
+
+// sibling 返回同目录下的辅助文件内容
+func (g *graph) sibling(dir, name string) []byte {
+	return g.files[path.Join(dir, name)]
+}
+
+// bom 生成排序后的SBOM
+func (g *graph) bom() *BOM {
+	bom := &BOM{
+		SerialNumber: newUUID(),
+		Created:      time.Now().UTC(),
+		Root:         g.root,
+		Dependencies: make(map[string][]string),
+	}
+	for ref, c := range g.components {
+		if ref != g.root.Ref {
+			bom.Components = append(bom.Components, c)
+		}
+	}
+	sort.Slice(bom.Components, func(i, j int) bool {
+		return bom.Components[i].Ref < bom.Components[j].Ref
+	})
+	for from, targets := range g.edges {
+		for to := range targets {
+			bom.Dependencies[from] = append(bom.Dependencies[from], to)
+		}
+		sort.Strings(bom.Dependencies[from])
+	}
+	return bom
+}
+
+// newUUID 生成随机的第4版UUID
+func newUUID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "00000000-0000-4000-8000-000000000000"
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// purl 生成package URL
+func purl(ecosystem, name, version string) string {
+	name = strings.ReplaceAll(name, "@", "%40")
+	if version == "" {
+		return fmt.Sprintf("pkg:%s/%s", ecosystem, name)
+	}
+	return fmt.Sprintf("pkg:%s/%s@%s", ecosystem, name, strings.ReplaceAll(version, "+", "%2B"))
+}
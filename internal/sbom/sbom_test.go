@@ -0,0 +1,257 @@
+// Package sbom 提供SBOM生成功能的单元测试
+package sbom
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"code-context-generator/pkg/types"
+)
+
+const testGoMod = `module example.com/app
+
+go 1.22
+
+require (
+	github.com/spf13/cobra v1.8.1
+	golang.org/x/text v0.24.0 // indirect
+)
+`
+
+const testGoSum = `github.com/spf13/cobra v1.8.1 h1:e5/vxKd/rZsfSJMUX1agtjeTDf+qv1/JdBF8gg5k9ZM=
+github.com/spf13/cobra v1.8.1/go.mod h1:wHxEcudfqmLYa8iTfL+OuZPbBZkmvliBWKIezN3kD9Y=
+`
+
+const testPackageLock = `{
+  "name": "web",
+  "version": "1.0.0",
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "web", "version": "1.0.0", "dependencies": {"@scope/ui": "^2.0.0"}, "devDependencies": {"left-pad": "^1.3.0"}},
+    "node_modules/@scope/ui": {"version": "2.1.0", "integrity": "sha512-AAAA", "dependencies": {"left-pad": "^1.0.0"}},
+    "node_modules/@scope/ui/node_modules/left-pad": {"version": "1.0.0"},
+    "node_modules/left-pad": {"version": "1.3.0", "integrity": "sha1-AQID"}
+  }
+}`
+
+const testCargoLock = `version = 3
+
+[[package]]
+name = "cli"
+version = "0.1.0"
+dependencies = ["serde", "log 0.4.20"]
+
+[[package]]
+name = "serde"
+version = "1.0.190"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+checksum = "91d3c334ca1ee894a2c6f6ad698fe8c435b76d504b13d436f0685d648d6d96f7"
+
+[[package]]
+name = "log"
+version = "0.4.20"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+checksum = "b5e6163cb8c49088c2c36f57875e58ccd8c87c7427f7fbd50ea6710b2f3f2e8f"
+`
+
+const testPoetryLock = `[[package]]
+name = "requests"
+version = "2.31.0"
+files = [
+    {file = "requests-2.31.0-py3-none-any.whl", hash = "sha256:aaaa"},
+    {file = "requests-2.31.0.tar.gz", hash = "sha256:bbbb"},
+]
+
+[package.dependencies]
+urllib3 = ">=1.21.1,<3"
+
+[[package]]
+name = "urllib3"
+version = "2.0.7"
+files = []
+`
+
+const testPyproject = `[tool.poetry]
+name = "svc"
+version = "0.2.0"
+
+[tool.poetry.dependencies]
+python = "^3.11"
+Requests = "^2.31"
+`
+
+// findComponent 按purl查找组件
+func findComponent(bom *BOM, ref string) *Component {
+	for _, c := range bom.Components {
+		if c.Ref == ref {
+			return c
+		}
+	}
+	return nil
+}
+
+// hasDependency 检查依赖关系是否存在
+func hasDependency(bom *BOM, from, to string) bool {
+	for _, dep := range bom.Dependencies[from] {
+		if dep == to {
+			return true
+		}
+	}
+	return false
+}
+
+// TestBuild 测试从多种锁文件构建组件和依赖图
+func TestBuild(t *testing.T) {
+	files := []types.FileInfo{
+		{Name: "go.mod", Path: "go.mod", Content: testGoMod},
+		{Name: "go.sum", Path: "go.sum", Content: testGoSum},
+		{Name: "package-lock.json", Path: "web/package-lock.json", Content: testPackageLock},
+		{Name: "Cargo.lock", Path: "cli/Cargo.lock", Content: testCargoLock},
+		{Name: "poetry.lock", Path: "svc/poetry.lock", Content: testPoetryLock},
+		{Name: "pyproject.toml", Path: "svc/pyproject.toml", Content: testPyproject},
+		{Name: "requirements.txt", Path: "tools/requirements.txt", Content: "flask==3.0.0 \\\n    --hash=sha256:CCCC\nrequests>=2\n"},
+		{Name: "main.go", Path: "main.go", Content: "package main"},
+	}
+
+	bom, err := Build(t.TempDir(), files)
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	tests := []struct {
+		ref    string
+		typ    string
+		hashes string
+	}{
+		{"pkg:golang/example.com/app", ComponentApplication, ""},
+		{"pkg:golang/github.com/spf13/cobra@v1.8.1", ComponentLibrary, "SHA-256"},
+		{"pkg:golang/golang.org/x/text@v0.24.0", ComponentLibrary, ""},
+		{"pkg:npm/web@1.0.0", ComponentApplication, ""},
+		{"pkg:npm/%40scope/ui@2.1.0", ComponentLibrary, "SHA-512"},
+		{"pkg:npm/left-pad@1.0.0", ComponentLibrary, ""},
+		{"pkg:npm/left-pad@1.3.0", ComponentLibrary, "SHA-1"},
+		{"pkg:cargo/cli@0.1.0", ComponentApplication, ""},
+		{"pkg:cargo/serde@1.0.190", ComponentLibrary, "SHA-256"},
+		{"pkg:cargo/log@0.4.20", ComponentLibrary, "SHA-256"},
+		{"pkg:pypi/svc@0.2.0", ComponentApplication, ""},
+		{"pkg:pypi/requests@2.31.0", ComponentLibrary, "SHA-256"},
+		{"pkg:pypi/urllib3@2.0.7", ComponentLibrary, ""},
+		{"pkg:pypi/flask@3.0.0", ComponentLibrary, "SHA-256"},
+	}
+	for _, tt := range tests {
+		c := findComponent(bom, tt.ref)
+		if c == nil {
+			t.Errorf("缺少组件 %s", tt.ref)
+			continue
+		}
+		if c.Type != tt.typ {
+			t.Errorf("%s 的类型 = %s, 期望 %s", tt.ref, c.Type, tt.typ)
+		}
+		if tt.hashes == "" && len(c.Hashes) != 0 || tt.hashes != "" && (len(c.Hashes) == 0 || c.Hashes[0].Algorithm != tt.hashes) {
+			t.Errorf("%s 的哈希 = %v, 期望算法 %q", tt.ref, c.Hashes, tt.hashes)
+		}
+	}
+
+	if c := findComponent(bom, "pkg:pypi/requests@2.31.0"); c != nil && c.Hashes[0].Value != "bbbb" {
+		t.Errorf("poetry 应使用源码包的哈希, 实际 %s", c.Hashes[0].Value)
+	}
+	if len(bom.Components) != len(tests) {
+		t.Errorf("组件数量 = %d, 期望 %d", len(bom.Components), len(tests))
+	}
+
+	edges := []struct{ from, to string }{
+		{bom.Root.Ref, "pkg:golang/example.com/app"},
+		{"pkg:golang/example.com/app", "pkg:golang/github.com/spf13/cobra@v1.8.1"},
+		{"pkg:npm/web@1.0.0", "pkg:npm/%40scope/ui@2.1.0"},
+		{"pkg:npm/web@1.0.0", "pkg:npm/left-pad@1.3.0"},
+		{"pkg:npm/%40scope/ui@2.1.0", "pkg:npm/left-pad@1.0.0"},
+		{bom.Root.Ref, "pkg:cargo/cli@0.1.0"},
+		{"pkg:cargo/cli@0.1.0", "pkg:cargo/log@0.4.20"},
+		{"pkg:pypi/svc@0.2.0", "pkg:pypi/requests@2.31.0"},
+		{"pkg:pypi/requests@2.31.0", "pkg:pypi/urllib3@2.0.7"},
+		{bom.Root.Ref, "pkg:pypi/flask@3.0.0"},
+	}
+	for _, e := range edges {
+		if !hasDependency(bom, e.from, e.to) {
+			t.Errorf("缺少依赖关系 %s -> %s", e.from, e.to)
+		}
+	}
+	if hasDependency(bom, "pkg:golang/example.com/app", "pkg:golang/golang.org/x/text@v0.24.0") {
+		t.Error("间接依赖不应连接到模块")
+	}
+	if hasDependency(bom, "pkg:pypi/svc@0.2.0", "pkg:pypi/urllib3@2.0.7") {
+		t.Error("urllib3 不是 pyproject.toml 声明的直接依赖")
+	}
+}
+
+// TestFormat 测试CycloneDX和SPDX输出
+func TestFormat(t *testing.T) {
+	files := []types.FileInfo{
+		{Name: "LICENSE", Path: "LICENSE", Content: "Permission is hereby granted, free of charge\n\nThe above copyright notice and this permission notice shall be included"},
+		{Name: "Cargo.lock", Path: "Cargo.lock", Content: testCargoLock},
+	}
+	bom, err := Build(t.TempDir(), files)
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if bom.Root.License != "MIT" {
+		t.Errorf("项目许可证 = %q, 期望 MIT", bom.Root.License)
+	}
+
+	output, err := Format(bom, FormatCycloneDX)
+	if err != nil {
+		t.Fatalf("Format(cyclonedx) error = %v", err)
+	}
+	var cdx cdxBOM
+	if err := json.Unmarshal(output, &cdx); err != nil {
+		t.Fatalf("CycloneDX 输出不是有效JSON: %v", err)
+	}
+	if cdx.BOMFormat != "CycloneDX" || cdx.SpecVersion != "1.5" || !strings.HasPrefix(cdx.SerialNumber, "urn:uuid:") {
+		t.Errorf("CycloneDX 头部信息错误: %+v", cdx)
+	}
+	if len(cdx.Components) != 3 || len(cdx.Dependencies) != 4 {
+		t.Errorf("CycloneDX 组件 %d 个、依赖 %d 项, 期望 3 和 4", len(cdx.Components), len(cdx.Dependencies))
+	}
+	if len(cdx.Metadata.Component.Licenses) != 1 || cdx.Metadata.Component.Licenses[0].Expression != "MIT" {
+		t.Errorf("CycloneDX 根组件许可证错误: %+v", cdx.Metadata.Component)
+	}
+
+	output, err = Format(bom, FormatSPDX)
+	if err != nil {
+		t.Fatalf("Format(spdx) error = %v", err)
+	}
+	var spdx spdxDocument
+	if err := json.Unmarshal(output, &spdx); err != nil {
+		t.Fatalf("SPDX 输出不是有效JSON: %v", err)
+	}
+	if spdx.SPDXVersion != "SPDX-2.3" || len(spdx.Packages) != 4 {
+		t.Errorf("SPDX 版本 %s、包 %d 个, 期望 SPDX-2.3 和 4", spdx.SPDXVersion, len(spdx.Packages))
+	}
+	dependsOn := 0
+	for _, r := range spdx.Relationships {
+		if r.RelationshipType == "DEPENDS_ON" {
+			dependsOn++
+		}
+	}
+	if dependsOn != 3 {
+		t.Errorf("DEPENDS_ON 关系 = %d, 期望 3", dependsOn)
+	}
+	if !strings.Contains(string(output), `"algorithm": "SHA256"`) {
+		t.Error("SPDX 校验和算法应为 SHA256")
+	}
+
+	if _, err := Format(bom, "unknown"); err == nil {
+		t.Error("不支持的格式应返回错误")
+	}
+}
+
+// TestFormatFromFilename 测试根据文件名推断格式
+func TestFormatFromFilename(t *testing.T) {
+	if FormatFromFilename("bom.spdx.json") != FormatSPDX {
+		t.Error("*.spdx.json 应使用SPDX格式")
+	}
+	if FormatFromFilename("bom.cdx.json") != FormatCycloneDX {
+		t.Error("其他文件名应使用CycloneDX格式")
+	}
+}
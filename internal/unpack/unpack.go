@@ -0,0 +1,630 @@
+// Package unpack 解析本工具生成的上下文文件，还原目录结构和文件内容
+package unpack
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/goccy/go-yaml"
+)
+
+// 支持还原的格式
+const (
+	FormatJSON     = "json"
+	FormatXML      = "xml"
+	FormatTOML     = "toml"
+	FormatMarkdown = "markdown"
+	FormatYAML     = "yaml"
+	FormatNDJSON   = "ndjson"
+	FormatPlain    = "plain"
+)
+
+// SupportedFormats 支持还原的格式列表
+var SupportedFormats = []string{FormatJSON, FormatXML, FormatTOML, FormatMarkdown, FormatYAML, FormatNDJSON, FormatPlain}
+
+// binaryPlaceholder 格式化时代替二进制文件内容的占位文本
+const binaryPlaceholder = "[二进制文件 - 内容未显示]"
+
+// missingContent 大小不为零但输出中没有内容（如简化输出中的二进制文件）时的跳过原因
+const missingContent = "输出中不包含文件内容"
+
+// truncatedContent 内容被格式化或内容处理截断时的跳过原因
+const truncatedContent = "内容已截断"
+
+// truncationMarkerPattern 内容处理的截断阶段在末尾追加的标记行
+var truncationMarkerPattern = regexp.MustCompile(`^\.\.\. \[内容已截断，省略 \d+ 行\]$`)
+
+// File 从上下文文件中解析出的文件
+type File struct {
+	Path    string // 相对于还原目录的斜杠分隔路径
+	Content string
+	Hash    string // 生成时记录的内容SHA-256，输出中没有时为空
+	Skip    string // 无法还原的原因，如二进制文件或内容已截断，为空时可以还原
+}
+
+// Snapshot 上下文文件中的全部文件
+type Snapshot struct {
+	RootPath string // 生成时的根目录，输出中包含时用于计算相对路径
+	Files    []File
+}
+
+// DetectFormat 根据扩展名判断格式，无法判断时根据内容判断
+func DetectFormat(filename string, content []byte) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		return FormatJSON
+	case ".xml":
+		return FormatXML
+	case ".toml":
+		return FormatTOML
+	case ".md", ".markdown":
+		return FormatMarkdown
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".ndjson", ".jsonl":
+		return FormatNDJSON
+	case ".txt", ".plain":
+		return FormatPlain
+	}
+
+	trimmed := strings.TrimSpace(string(content))
+	switch {
+	case strings.HasPrefix(trimmed, "<"):
+		return FormatXML
+	case strings.HasPrefix(trimmed, `{"type":"header"`):
+		return FormatNDJSON
+	case strings.HasPrefix(trimmed, "{"):
+		return FormatJSON
+	case strings.HasPrefix(trimmed, "# "):
+		return FormatMarkdown
+	case plainFilePattern.MatchString(string(content)):
+		return FormatPlain
+	}
+	return ""
+}
+
+// ReadFile 读取上下文文件并转换为相对路径，format 为空时自动判断格式
+//
+// 传入分块清单（*-manifest.json）时按顺序读取所有分块，拆分到多个分块的文件片段会重新拼接。
+func ReadFile(filename, format string, customNames map[string]string) (*Snapshot, error) {
+	snapshot, err := DecodeFile(filename, format, customNames)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.relativize()
+	return snapshot, nil
+}
+
+// DecodeFile 读取上下文文件，保留输出中的原始路径，其余与 ReadFile 相同
+func DecodeFile(filename, format string, customNames map[string]string) (*Snapshot, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("读取上下文文件失败: %w", err)
+	}
+
+	if manifest, ok := parseManifest(content); ok {
+		return readChunks(filepath.Dir(filename), manifest, format, customNames)
+	}
+
+	if format == "" {
+		format = DetectFormat(filename, content)
+		if format == "" {
+			return nil, fmt.Errorf("无法识别上下文文件格式，请使用 --format 指定")
+		}
+	}
+	return Decode(content, format, customNames)
+}
+
+// Parse 按指定格式解析上下文内容并转换为相对路径，customNames 为配置中的自定义字段名
+func Parse(content []byte, format string, customNames map[string]string) (*Snapshot, error) {
+	snapshot, err := Decode(content, format, customNames)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.relativize()
+	return snapshot, nil
+}
+
+// Decode 按指定格式解析上下文内容，保留输出中的原始路径
+func Decode(content []byte, format string, customNames map[string]string) (*Snapshot, error) {
+	snapshot, err := decode(content, format, customNames)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.undoProcessing()
+	return snapshot, nil
+}
+
+// decode 按指定格式解析上下文内容，不处理截断和行号
+func decode(content []byte, format string, customNames map[string]string) (*Snapshot, error) {
+	var snapshot *Snapshot
+	var err error
+	switch format {
+	case FormatJSON:
+		var doc map[string]interface{}
+		if err = json.Unmarshal(content, &doc); err != nil {
+			return nil, fmt.Errorf("解析JSON失败: %w", err)
+		}
+		snapshot, err = parseDocument(doc, customNames)
+	case FormatYAML:
+		var doc map[string]interface{}
+		if err = yaml.Unmarshal(content, &doc); err != nil {
+			return nil, fmt.Errorf("解析YAML失败: %w", err)
+		}
+		snapshot, err = parseDocument(doc, customNames)
+	case FormatTOML:
+		var doc map[string]interface{}
+		if _, err = toml.Decode(string(content), &doc); err != nil {
+			return nil, fmt.Errorf("解析TOML失败: %w", err)
+		}
+		snapshot, err = parseDocument(doc, customNames)
+	case FormatXML:
+		snapshot, err = parseXML(content)
+	case FormatMarkdown:
+		snapshot, err = parseMarkdown(string(content))
+	case FormatNDJSON:
+		snapshot, err = parseNDJSON(content)
+	case FormatPlain:
+		snapshot, err = parsePlain(string(content))
+	default:
+		return nil, fmt.Errorf("不支持还原的格式: %s (支持: %s)", format, strings.Join(SupportedFormats, ", "))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshot.Files) == 0 {
+		return nil, fmt.Errorf("上下文文件中没有找到文件内容")
+	}
+	return snapshot, nil
+}
+
+// undoProcessing 识别所有文件的内容处理结果
+func (s *Snapshot) undoProcessing() {
+	for i := range s.Files {
+		s.Files[i].undoProcessing()
+	}
+}
+
+// undoProcessing 识别生成时内容处理的结果：截断的内容无法还原，标记为跳过；
+// 逐行添加的行号前缀可以去掉，恢复原始内容
+func (f *File) undoProcessing() {
+	if f.Skip != "" {
+		return
+	}
+	body := strings.TrimRight(f.Content, "\n")
+	lastLine := body[strings.LastIndex(body, "\n")+1:]
+	if truncationMarkerPattern.MatchString(lastLine) || strings.HasSuffix(body, "\n... (内容已截断)") {
+		f.Skip = truncatedContent
+		return
+	}
+	if content, ok := stripLineNumbers(f.Content); ok {
+		f.Content = content
+	}
+}
+
+// stripLineNumbers 去掉内容处理添加的 "N | " 行号前缀，
+// 只有全部行都带有从1开始连续、宽度一致的行号时才视为添加过行号
+func stripLineNumbers(content string) (string, bool) {
+	lines := strings.Split(content, "\n")
+	trailingNewline := lines[len(lines)-1] == ""
+	if trailingNewline {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return content, false
+	}
+
+	width := len(strconv.Itoa(len(lines)))
+	for i, line := range lines {
+		prefix := fmt.Sprintf("%*d | ", width, i+1)
+		switch {
+		case strings.HasPrefix(line, prefix):
+			lines[i] = line[len(prefix):]
+		case line == strings.TrimSuffix(prefix, " "):
+			lines[i] = "" // 空行的行号后缀空格可能被去掉
+		default:
+			return content, false
+		}
+	}
+	result := strings.Join(lines, "\n")
+	if trailingNewline {
+		result += "\n"
+	}
+	return result, true
+}
+
+// chunkManifest 分块清单中还原所需的字段
+type chunkManifest struct {
+	Format string `json:"format"`
+	Chunks []struct {
+		File string `json:"file"`
+	} `json:"chunks"`
+}
+
+// parseManifest 判断内容是否为分块清单
+func parseManifest(content []byte) (*chunkManifest, bool) {
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(content, &probe); err != nil {
+		return nil, false
+	}
+	if _, ok := probe["files"]; ok {
+		return nil, false
+	}
+	if _, ok := probe["chunks"]; !ok {
+		return nil, false
+	}
+	var manifest chunkManifest
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, false
+	}
+	return &manifest, true
+}
+
+// readChunks 依次解析清单中的分块，同一路径的片段按分块顺序拼接
+func readChunks(dir string, manifest *chunkManifest, format string, customNames map[string]string) (*Snapshot, error) {
+	if format == "" {
+		format = manifest.Format
+	}
+	snapshot := &Snapshot{}
+	index := make(map[string]int)
+	for _, entry := range manifest.Chunks {
+		content, err := os.ReadFile(filepath.Join(dir, filepath.Base(entry.File)))
+		if err != nil {
+			return nil, fmt.Errorf("读取分块文件失败: %w", err)
+		}
+		chunkFormat := format
+		if chunkFormat == "" {
+			chunkFormat = DetectFormat(entry.File, content)
+		}
+		// 片段拼接后再识别截断和行号，后续片段的行号不从1开始
+		part, err := decode(content, chunkFormat, customNames)
+		if err != nil {
+			return nil, fmt.Errorf("解析分块 %s 失败: %w", entry.File, err)
+		}
+		if snapshot.RootPath == "" {
+			snapshot.RootPath = part.RootPath
+		}
+		for _, file := range part.Files {
+			if i, ok := index[file.Path]; ok {
+				snapshot.Files[i].Content += file.Content
+				if snapshot.Files[i].Skip == "" {
+					snapshot.Files[i].Skip = file.Skip
+				}
+				continue
+			}
+			index[file.Path] = len(snapshot.Files)
+			snapshot.Files = append(snapshot.Files, file)
+		}
+	}
+	if len(snapshot.Files) == 0 {
+		return nil, fmt.Errorf("分块清单中没有找到文件内容")
+	}
+	snapshot.undoProcessing()
+	return snapshot, nil
+}
+
+// newFile 创建文件记录，二进制占位内容标记为跳过
+func newFile(filePath, content string) File {
+	file := File{Path: filePath, Content: content}
+	if strings.TrimSuffix(content, "\n") == binaryPlaceholder {
+		file.Skip = "二进制文件"
+	}
+	return file
+}
+
+// parseDocument 从JSON/YAML/TOML文档中提取顶层 files 列表，字段名不区分大小写
+func parseDocument(doc map[string]interface{}, customNames map[string]string) (*Snapshot, error) {
+	pathKeys := []string{"path"}
+	contentKeys := []string{"content"}
+	if name := customNames["filepath"]; name != "" {
+		pathKeys = append([]string{name}, pathKeys...)
+	}
+	if name := customNames["filecontent"]; name != "" {
+		contentKeys = append([]string{name}, contentKeys...)
+	}
+
+	snapshot := &Snapshot{}
+	if metadata, ok := lookup(doc, "metadata").(map[string]interface{}); ok {
+		snapshot.RootPath, _ = lookup(metadata, "root_path").(string)
+	}
+
+	for _, entry := range toMaps(lookup(doc, "files")) {
+		filePath, _ := lookup(entry, pathKeys...).(string)
+		if filePath == "" {
+			continue
+		}
+		content, _ := lookup(entry, contentKeys...).(string)
+		file := newFile(filePath, content)
+		file.Hash, _ = lookup(entry, "hash").(string)
+		if binary, _ := lookup(entry, "is_binary", "isbinary").(bool); binary {
+			file.Skip = "二进制文件"
+		}
+		if content == "" && toInt64(lookup(entry, "size")) > 0 {
+			file.Skip = missingContent
+		}
+		snapshot.Files = append(snapshot.Files, file)
+	}
+	return snapshot, nil
+}
+
+// toInt64 将解码得到的数值转换为 int64
+func toInt64(value interface{}) int64 {
+	switch n := value.(type) {
+	case int:
+		return int64(n)
+	case int64:
+		return n
+	case uint64:
+		return int64(n)
+	case float64:
+		return int64(n)
+	}
+	return 0
+}
+
+// lookup 按顺序查找第一个存在的键，键名不区分大小写
+func lookup(m map[string]interface{}, keys ...string) interface{} {
+	for _, key := range keys {
+		if value, ok := m[key]; ok {
+			return value
+		}
+		for k, value := range m {
+			if strings.EqualFold(k, key) {
+				return value
+			}
+		}
+	}
+	return nil
+}
+
+// toMaps 将解码得到的列表转换为键为字符串的映射列表
+func toMaps(value interface{}) []map[string]interface{} {
+	switch list := value.(type) {
+	case []map[string]interface{}:
+		return list
+	case []interface{}:
+		result := make([]map[string]interface{}, 0, len(list))
+		for _, item := range list {
+			switch m := item.(type) {
+			case map[string]interface{}:
+				result = append(result, m)
+			case map[interface{}]interface{}:
+				converted := make(map[string]interface{}, len(m))
+				for k, v := range m {
+					converted[fmt.Sprint(k)] = v
+				}
+				result = append(result, converted)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+// xmlAIFilePattern 匹配AI优化XML输出中的文件节点，容忍模型回复中不同的缩进
+var xmlAIFilePattern = regexp.MustCompile(`(?s)<file path="([^"]*)"[^>]*>(.*?)<content>\s*<!\[CDATA\[(.*?)\]\]>\s*</content>`)
+
+// xmlHashPattern 匹配AI优化XML文件元数据中的内容哈希
+var xmlHashPattern = regexp.MustCompile(`<hash>([0-9a-fA-F]+)</hash>`)
+
+// parseXML 解析XML输出，支持标准结构和AI优化结构
+func parseXML(content []byte) (*Snapshot, error) {
+	snapshot := &Snapshot{}
+	if matches := xmlAIFilePattern.FindAllSubmatch(content, -1); len(matches) > 0 {
+		for _, match := range matches {
+			file := newFile(html.UnescapeString(string(match[1])), string(match[3]))
+			if hash := xmlHashPattern.FindSubmatch(match[2]); hash != nil {
+				file.Hash = string(hash[1])
+			}
+			snapshot.Files = append(snapshot.Files, file)
+		}
+		return snapshot, nil
+	}
+
+	var doc struct {
+		Files []struct {
+			Path    string `xml:"Path"`
+			Size    int64  `xml:"Size"`
+			Hash    string `xml:"Hash"`
+			Content string `xml:"Content"`
+		} `xml:"files>file"`
+		Metadata struct {
+			RootPath string `xml:"root_path"`
+		} `xml:"metadata"`
+	}
+	if err := xml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("解析XML失败: %w", err)
+	}
+	snapshot.RootPath = doc.Metadata.RootPath
+	for _, file := range doc.Files {
+		if file.Path == "" {
+			continue
+		}
+		entry := newFile(file.Path, file.Content)
+		entry.Hash = file.Hash
+		if file.Content == "" && file.Size > 0 {
+			entry.Skip = missingContent
+		}
+		snapshot.Files = append(snapshot.Files, entry)
+	}
+	return snapshot, nil
+}
+
+// parseNDJSON 解析NDJSON输出中的 file 记录
+func parseNDJSON(content []byte) (*Snapshot, error) {
+	snapshot := &Snapshot{}
+	for i, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		var record struct {
+			Type     string `json:"type"`
+			RootPath string `json:"root_path"`
+			Path     string `json:"path"`
+			Hash     string `json:"hash"`
+			Content  string `json:"content"`
+		}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			return nil, fmt.Errorf("解析NDJSON第 %d 行失败: %w", i+1, err)
+		}
+		switch record.Type {
+		case "header":
+			snapshot.RootPath = record.RootPath
+		case "file":
+			file := newFile(record.Path, record.Content)
+			file.Hash = record.Hash
+			snapshot.Files = append(snapshot.Files, file)
+		}
+	}
+	return snapshot, nil
+}
+
+// plainFilePattern 匹配纯文本输出中的文件头
+var plainFilePattern = regexp.MustCompile(`(?m)^={16}\nFile: (.+)\n={16}\n`)
+
+// parsePlain 解析纯文本输出，文件内容后跟一个空行
+func parsePlain(content string) (*Snapshot, error) {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	snapshot := &Snapshot{}
+	matches := plainFilePattern.FindAllStringSubmatchIndex(content, -1)
+	for i, match := range matches {
+		end := len(content)
+		if i+1 < len(matches) {
+			end = matches[i+1][0]
+		}
+		body := strings.TrimSuffix(content[match[1]:end], "\n")
+		snapshot.Files = append(snapshot.Files, newFile(content[match[2]:match[3]], body))
+	}
+	return snapshot, nil
+}
+
+// markdownPathPattern 匹配Markdown输出中文件的路径行，AI优化输出的路径带反引号
+var markdownPathPattern = regexp.MustCompile("(?m)^- \\*\\*路径\\*\\*: (?:`([^`\n]*)`|([^\n]*))$")
+
+// markdownCodePattern 匹配文件内容代码块的开始，取路径行之后的第一个代码块
+var markdownCodePattern = regexp.MustCompile("(?m)^```[^\n]*\n")
+
+// markdownHashPattern 匹配文件信息中的内容哈希
+var markdownHashPattern = regexp.MustCompile("(?m)^- \\*\\*SHA-256\\*\\*: `?([0-9a-fA-F]+)`?$")
+
+// parseMarkdown 解析Markdown输出，文件内容在路径行之后的第一个代码块中
+func parseMarkdown(content string) (*Snapshot, error) {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	if i := strings.Index(content, "\n## AI分析指令\n"); i >= 0 {
+		content = content[:i+1]
+	}
+
+	snapshot := &Snapshot{}
+	matches := markdownPathPattern.FindAllStringSubmatchIndex(content, -1)
+	for i, match := range matches {
+		filePath := ""
+		if match[2] >= 0 {
+			filePath = content[match[2]:match[3]]
+		} else {
+			filePath = content[match[4]:match[5]]
+		}
+
+		end := len(content)
+		if i+1 < len(matches) {
+			end = matches[i+1][0]
+		}
+		section := content[match[1]:end]
+		code := markdownCodePattern.FindStringIndex(section)
+
+		// 只在代码块之前的文件信息中查找哈希
+		header := section
+		if code != nil {
+			header = section[:code[0]]
+		}
+		hash := ""
+		if m := markdownHashPattern.FindStringSubmatch(header); m != nil {
+			hash = m[1]
+		}
+
+		if code == nil {
+			snapshot.Files = append(snapshot.Files, File{Path: filePath, Hash: hash, Skip: "二进制文件"})
+			continue
+		}
+		body := section[code[1]:]
+		// 代码块以换行加 ``` 结束，格式化时总会在内容后追加一个换行
+		closing := strings.LastIndex(body, "\n```")
+		if closing < 0 {
+			return nil, fmt.Errorf("文件 %s 的代码块没有结束标记", filePath)
+		}
+		file := newFile(filePath, body[:closing])
+		file.Hash = hash
+		snapshot.Files = append(snapshot.Files, file)
+	}
+	if len(snapshot.Files) == 0 && strings.Contains(content, "自定义结构") {
+		return nil, fmt.Errorf("使用自定义结构生成的Markdown不包含文件内容，无法还原")
+	}
+	return snapshot, nil
+}
+
+// relativize 将路径转换为相对路径：优先相对于根目录，否则去掉绝对路径的公共父目录
+func (s *Snapshot) relativize() {
+	var absolute []string
+	for i := range s.Files {
+		p, ok := RelativeTo(s.Files[i].Path, s.RootPath)
+		if !ok {
+			absolute = append(absolute, p)
+		}
+		s.Files[i].Path = p
+	}
+
+	common := commonDir(absolute)
+	for i := range s.Files {
+		p := s.Files[i].Path
+		if isAbsolute(p) {
+			p = strings.TrimPrefix(strings.TrimPrefix(p, common), "/")
+		}
+		s.Files[i].Path = path.Clean(p)
+	}
+}
+
+// RelativeTo 将路径转换为斜杠分隔的形式，绝对路径位于 root 之内时返回相对路径
+//
+// 路径本身是相对路径或成功转换时第二个返回值为 true；绝对路径不在 root 之内时原样返回并返回 false。
+func RelativeTo(p, root string) (string, bool) {
+	p = strings.ReplaceAll(p, "\\", "/")
+	if !isAbsolute(p) {
+		return path.Clean(p), true
+	}
+	root = strings.TrimSuffix(strings.ReplaceAll(root, "\\", "/"), "/")
+	if root != "" && strings.HasPrefix(p, root+"/") {
+		return path.Clean(p[len(root)+1:]), true
+	}
+	return p, false
+}
+
+// windowsDrivePattern 匹配Windows盘符
+var windowsDrivePattern = regexp.MustCompile(`^[A-Za-z]:/`)
+
+// isAbsolute 判断斜杠分隔的路径是否为绝对路径（包括Windows盘符路径）
+func isAbsolute(p string) bool {
+	return strings.HasPrefix(p, "/") || windowsDrivePattern.MatchString(p)
+}
+
+// commonDir 返回路径列表的公共父目录
+func commonDir(paths []string) string {
+	if len(paths) == 0 {
+		return ""
+	}
+	common := path.Dir(paths[0])
+	for _, p := range paths[1:] {
+		for common != "/" && common != "." && p != common && !strings.HasPrefix(p, common+"/") {
+			common = path.Dir(common)
+		}
+	}
+	return common
+}
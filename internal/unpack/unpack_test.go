@@ -0,0 +1,312 @@
+// Package unpack 提供上下文还原的单元测试
+package unpack
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"code-context-generator/internal/chunk"
+	"code-context-generator/internal/formatter"
+	"code-context-generator/internal/pipeline"
+	"code-context-generator/pkg/types"
+)
+
+// testData 创建包含特殊字符的测试上下文，文件路径为绝对路径
+func testData(root string) types.ContextData {
+	return types.ContextData{
+		Files: []types.FileInfo{
+			{Path: filepath.Join(root, "main.go"), Name: "main.go", Content: "package main\n\nfunc main() {}\n", Hash: testHash},
+			{Path: filepath.Join(root, "docs", "README.md"), Name: "README.md", Content: "# 标题\n\n```go\nx := 1 < 2 && a > b\n```\n"},
+			{Path: filepath.Join(root, "docs", "deep", "notes.txt"), Name: "notes.txt", Content: "  缩进\n\t制表符\n]]> \"引号\" 'single'\n"},
+			{Path: filepath.Join(root, "logo.png"), Name: "logo.png", Size: 2048, IsBinary: true},
+		},
+		FileCount: 4,
+		Metadata:  map[string]interface{}{"root_path": root},
+	}
+}
+
+// testHash 测试上下文中 main.go 的生成时哈希
+const testHash = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+// expectedFiles 测试上下文还原后的预期结果
+func expectedFiles() map[string]string {
+	return map[string]string{
+		"main.go":             "package main\n\nfunc main() {}\n",
+		"docs/README.md":      "# 标题\n\n```go\nx := 1 < 2 && a > b\n```\n",
+		"docs/deep/notes.txt": "  缩进\n\t制表符\n]]> \"引号\" 'single'\n",
+	}
+}
+
+// TestRoundTrip 测试各格式输出能还原出原始文件
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		config types.Config
+	}{
+		{"JSON", FormatJSON, types.Config{}},
+		{"JSON元信息", FormatJSON, types.Config{Output: types.OutputConfig{IncludeMetadata: true}}},
+		{"XML", FormatXML, types.Config{}},
+		{"XML AI优化", FormatXML, types.Config{Output: types.OutputConfig{AIOptimized: true}}},
+		{"TOML", FormatTOML, types.Config{}},
+		{"YAML", FormatYAML, types.Config{}},
+		{"YAML自定义字段名", FormatYAML, types.Config{Fields: types.FieldsConfig{CustomNames: map[string]string{"filepath": "file", "filecontent": "body"}}}},
+		{"NDJSON", FormatNDJSON, types.Config{}},
+		{"纯文本", FormatPlain, types.Config{}},
+		{"Markdown", FormatMarkdown, types.Config{}},
+		{"Markdown AI优化", FormatMarkdown, types.Config{Output: types.OutputConfig{AIOptimized: true}}},
+	}
+
+	root := filepath.Join(t.TempDir(), "project")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := formatter.NewFormatter(tt.format, &tt.config)
+			if err != nil {
+				t.Fatalf("NewFormatter() error = %v", err)
+			}
+			output, err := f.Format(testData(root))
+			if err != nil {
+				t.Fatalf("Format() error = %v", err)
+			}
+
+			snapshot, err := Parse([]byte(output), tt.format, tt.config.Fields.CustomNames)
+			if err != nil {
+				t.Fatalf("Parse() error = %v\n%s", err, output)
+			}
+			got := make(map[string]string)
+			for _, file := range snapshot.Files {
+				if file.Skip != "" {
+					if file.Path != "logo.png" {
+						t.Errorf("文件 %s 不应该被跳过: %s", file.Path, file.Skip)
+					}
+					continue
+				}
+				got[file.Path] = file.Content
+				// 纯文本格式不记录哈希
+				if file.Path == "main.go" && tt.format != FormatPlain && file.Hash != testHash {
+					t.Errorf("main.go 的哈希 = %q, 期望 %q", file.Hash, testHash)
+				}
+			}
+			if !reflect.DeepEqual(got, expectedFiles()) {
+				t.Errorf("Parse() = %q, 期望 %q", got, expectedFiles())
+			}
+		})
+	}
+}
+
+// TestParseProcessedContent 测试还原经过内容处理的输出：去掉行号，截断的文件跳过
+func TestParseProcessedContent(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "project")
+	original := "package main\n\nfunc main() {}\n"
+	data := types.ContextData{
+		Files: []types.FileInfo{
+			{Path: filepath.Join(root, "main.go"), Name: "main.go", Content: original},
+			{Path: filepath.Join(root, "long.txt"), Name: "long.txt", Content: strings.Repeat("0123456789\n", 20)},
+		},
+		FileCount: 2,
+		Metadata:  map[string]interface{}{"root_path": root},
+	}
+	p, err := pipeline.New(&types.ProcessingConfig{AddLineNumbers: true, MaxLength: 60})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.ProcessFiles(data.Files)
+
+	for _, format := range []string{FormatMarkdown, FormatJSON, FormatXML, FormatPlain} {
+		t.Run(format, func(t *testing.T) {
+			f, err := formatter.NewFormatter(format, &types.Config{})
+			if err != nil {
+				t.Fatalf("NewFormatter() error = %v", err)
+			}
+			output, err := f.Format(data)
+			if err != nil {
+				t.Fatalf("Format() error = %v", err)
+			}
+			snapshot, err := Parse([]byte(output), format, nil)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			files := make(map[string]File)
+			for _, file := range snapshot.Files {
+				files[file.Path] = file
+			}
+			if main := files["main.go"]; main.Skip != "" || strings.TrimSuffix(main.Content, "\n") != strings.TrimSuffix(original, "\n") {
+				t.Errorf("main.go 应该去掉行号后还原，实际 %q (跳过: %s)", main.Content, main.Skip)
+			}
+			if long := files["long.txt"]; long.Skip != "内容已截断" {
+				t.Errorf("截断的 long.txt 应该跳过，实际跳过原因 %q", long.Skip)
+			}
+		})
+	}
+}
+
+// TestDetectFormat 测试格式识别
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		filename string
+		content  string
+		expected string
+	}{
+		{"context.json", "", FormatJSON},
+		{"context.yml", "", FormatYAML},
+		{"context.md", "", FormatMarkdown},
+		{"context", `<?xml version="1.0"?>`, FormatXML},
+		{"context", `{"type":"header","root_path":"/x"}`, FormatNDJSON},
+		{"context", `{"files":[]}`, FormatJSON},
+		{"context", "================\nFile: a.go\n================\n", FormatPlain},
+		{"context", "unknown", ""},
+	}
+
+	for _, tt := range tests {
+		if got := DetectFormat(tt.filename, []byte(tt.content)); got != tt.expected {
+			t.Errorf("DetectFormat(%q) = %q, 期望 %q", tt.filename, got, tt.expected)
+		}
+	}
+}
+
+// TestRelativize 测试相对路径计算
+func TestRelativize(t *testing.T) {
+	tests := []struct {
+		name     string
+		snapshot Snapshot
+		expected []string
+	}{
+		{"使用根目录", Snapshot{RootPath: "/p", Files: []File{{Path: "/p/sub/a.go"}, {Path: "/p/sub/b.go"}}}, []string{"sub/a.go", "sub/b.go"}},
+		{"公共父目录", Snapshot{Files: []File{{Path: "/p/sub/a.go"}, {Path: "/p/b.go"}}}, []string{"sub/a.go", "b.go"}},
+		{"Windows路径", Snapshot{Files: []File{{Path: `C:\p\a.go`}, {Path: `C:\p\x\b.go`}}}, []string{"a.go", "x/b.go"}},
+		{"相对路径不变", Snapshot{Files: []File{{Path: "./src/a.go"}}}, []string{"src/a.go"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.snapshot.relativize()
+			var got []string
+			for _, file := range tt.snapshot.Files {
+				got = append(got, file.Path)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("relativize() = %v, 期望 %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestReadFileManifest 测试通过分块清单还原，被拆分的文件重新拼接
+func TestReadFileManifest(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "context.json")
+	data := testData(filepath.Join(dir, "project"))
+	data.Files = data.Files[:3]
+
+	chunks, manifest, err := chunk.Split(data, chunk.Options{MaxBytes: 24}, output)
+	if err != nil {
+		t.Fatalf("Split() error = %v", err)
+	}
+	if len(chunks) < 3 {
+		t.Fatalf("期望至少3个分块，实际 %d 个", len(chunks))
+	}
+	manifest.Format = FormatJSON
+
+	f, _ := formatter.NewFormatter(FormatJSON, &types.Config{})
+	for i, chunkData := range chunks {
+		content, err := f.Format(chunkData)
+		if err != nil {
+			t.Fatalf("Format() error = %v", err)
+		}
+		if err := os.WriteFile(chunk.FileName(output, i+1), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	manifestContent, _ := json.Marshal(manifest)
+	manifestPath := chunk.ManifestName(output)
+	if err := os.WriteFile(manifestPath, manifestContent, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot, err := ReadFile(manifestPath, "", nil)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	got := make(map[string]string)
+	for _, file := range snapshot.Files {
+		got[file.Path] = file.Content
+	}
+	if !reflect.DeepEqual(got, expectedFiles()) {
+		t.Errorf("ReadFile() = %q, 期望 %q", got, expectedFiles())
+	}
+}
+
+// TestPlanAndApply 测试还原计划和写入
+func TestPlanAndApply(t *testing.T) {
+	into := t.TempDir()
+	if err := os.WriteFile(filepath.Join(into, "existing.txt"), []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	snapshot := &Snapshot{Files: []File{
+		{Path: "a/b.txt", Content: "new"},
+		{Path: "existing.txt", Content: "new"},
+		{Path: "logo.png", Skip: "二进制文件"},
+	}}
+
+	entries, err := Plan(snapshot, into, false)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	actions := []string{entries[0].Action, entries[1].Action, entries[2].Action}
+	if !reflect.DeepEqual(actions, []string{ActionCreate, ActionSkip, ActionSkip}) {
+		t.Errorf("Plan() 操作 = %v", actions)
+	}
+	if written, err := Apply(entries); err != nil || written != 1 {
+		t.Fatalf("Apply() = %d, %v", written, err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(into, "a", "b.txt")); string(content) != "new" {
+		t.Errorf("写入内容 = %q", content)
+	}
+	if content, _ := os.ReadFile(filepath.Join(into, "existing.txt")); string(content) != "old" {
+		t.Errorf("未指定覆盖时已存在的文件被修改: %q", content)
+	}
+
+	entries, err = Plan(snapshot, into, true)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	if entries[0].Action != ActionOverwrite || entries[1].Action != ActionOverwrite {
+		t.Errorf("覆盖模式操作 = %s, %s", entries[0].Action, entries[1].Action)
+	}
+}
+
+// TestPlanRejectsEscapes 测试拒绝越出目标目录的路径
+func TestPlanRejectsEscapes(t *testing.T) {
+	into := t.TempDir()
+	outside := t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(into, "link")); err != nil {
+		t.Skipf("无法创建符号链接: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+	}{
+		{"上级目录", "../escape.txt"},
+		{"中间的上级目录", "a/../../escape.txt"},
+		{"目标目录本身", "."},
+		{"符号链接", "link/escape.txt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshot := &Snapshot{Files: []File{{Path: "ok.txt"}, {Path: tt.path, Content: "x"}}}
+			if _, err := Plan(snapshot, into, true); err == nil {
+				t.Errorf("Plan(%q) 应该返回错误", tt.path)
+			}
+		})
+	}
+	if _, err := os.Stat(filepath.Join(outside, "escape.txt")); !os.IsNotExist(err) {
+		t.Error("不应该在目标目录之外写入文件")
+	}
+}
@@ -0,0 +1,117 @@
+package unpack
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"code-context-generator/internal/utils"
+)
+
+// 还原操作
+const (
+	ActionCreate    = "create"
+	ActionOverwrite = "overwrite"
+	ActionSkip      = "skip"
+)
+
+// Entry 单个文件的还原计划
+type Entry struct {
+	Path    string // 上下文中的相对路径
+	Target  string // 写入的目标路径
+	Content string
+	Action  string
+	Reason  string // 跳过的原因
+}
+
+// Plan 计算每个文件的目标路径和操作，不写入任何文件
+//
+// 目标路径通过 utils.SafePathJoin 计算，任何文件越出目标目录或经过符号链接时整体返回错误。
+// 已存在的文件在 overwrite 为 false 时跳过。
+func Plan(snapshot *Snapshot, into string, overwrite bool) ([]Entry, error) {
+	base, err := filepath.Abs(into)
+	if err != nil {
+		return nil, fmt.Errorf("解析目标目录失败: %w", err)
+	}
+
+	entries := make([]Entry, 0, len(snapshot.Files))
+	for _, file := range snapshot.Files {
+		target, err := SafeTarget(base, file.Path)
+		if err != nil {
+			return nil, fmt.Errorf("拒绝还原 %s: %w", file.Path, err)
+		}
+
+		entry := Entry{Path: file.Path, Target: target, Content: file.Content, Action: ActionCreate}
+		switch info, err := os.Lstat(target); {
+		case file.Skip != "":
+			entry.Action, entry.Reason = ActionSkip, file.Skip
+		case err == nil && info.IsDir():
+			entry.Action, entry.Reason = ActionSkip, "目标是目录"
+		case err == nil && !overwrite:
+			entry.Action, entry.Reason = ActionSkip, "文件已存在"
+		case err == nil:
+			entry.Action = ActionOverwrite
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// SafeTarget 计算相对路径在 base 目录下的目标路径，base 应为绝对路径
+//
+// 通过 utils.SafePathJoin 拒绝越出目录的路径，并拒绝指向目录本身或经过已存在符号链接的路径。
+func SafeTarget(base, relPath string) (string, error) {
+	target, err := utils.SafePathJoin(base, filepath.FromSlash(relPath))
+	if err != nil {
+		return "", err
+	}
+	if target == filepath.Clean(base) {
+		return "", fmt.Errorf("路径指向目标目录本身")
+	}
+	if err := checkSymlinks(base, target); err != nil {
+		return "", err
+	}
+	return target, nil
+}
+
+// checkSymlinks 检查目标目录下已存在的各级路径都不是符号链接，防止写入被重定向到目录之外
+func checkSymlinks(base, target string) error {
+	rel, err := filepath.Rel(base, target)
+	if err != nil {
+		return err
+	}
+	current := base
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		current = filepath.Join(current, part)
+		info, err := os.Lstat(current)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("路径经过符号链接: %s", current)
+		}
+	}
+	return nil
+}
+
+// Apply 按计划写入文件，跳过的条目不做处理，返回写入的文件数
+func Apply(entries []Entry) (int, error) {
+	written := 0
+	for _, entry := range entries {
+		if entry.Action == ActionSkip {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(entry.Target), 0755); err != nil {
+			return written, fmt.Errorf("创建目录失败: %w", err)
+		}
+		if err := os.WriteFile(entry.Target, []byte(entry.Content), 0644); err != nil {
+			return written, fmt.Errorf("写入文件失败: %w", err)
+		}
+		written++
+	}
+	return written, nil
+}
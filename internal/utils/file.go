@@ -3,6 +3,7 @@ package utils
 
 import (
 	"crypto/md5"
+	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"io"
@@ -70,6 +71,7 @@ func IsTextFile(path string) bool {
 		".go", ".py", ".js", ".ts", ".java", ".cpp", ".c", ".h",
 		".html", ".css", ".scss", ".sass", ".sql", ".sh", ".bat",
 		".ps1", ".rb", ".php", ".rs", ".swift", ".kt", ".scala",
+		".mod", ".sum", ".lock", // 依赖清单和锁文件
 	}
 
 	for _, textExt := range textExtensions {
@@ -125,6 +127,12 @@ func IsBinaryFile(path string) bool {
 	return !IsTextFile(path)
 }
 
+// ContentHash 返回内容的SHA-256十六进制摘要
+func ContentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
 // ReadFileContent 读取文件内容（带大小限制）
 func ReadFileContent(path string, maxSize int64) (string, bool, error) {
 	// 使用新的编码感知函数
@@ -126,6 +126,8 @@ func TestIsTextFile(t *testing.T) {
 		{"test.js", true},
 		{"test.html", true},
 		{"test.css", true},
+		{"go.mod", true},
+		{"Cargo.lock", true},
 		{"test.exe", false},
 		{"test.bin", false},
 		{"test.jpg", false},
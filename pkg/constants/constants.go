@@ -44,6 +44,9 @@ const (
 	FormatJSON     = "json"
 	FormatTOML     = "toml"
 	FormatMarkdown = "markdown"
+	FormatYAML     = "yaml"
+	FormatNDJSON   = "ndjson"
+	FormatPlain    = "plain"
 )
 
 // 错误消息常量
@@ -104,4 +107,7 @@ var SupportedFormats = []string{
 	FormatJSON,
 	FormatTOML,
 	FormatMarkdown,
+	FormatYAML,
+	FormatNDJSON,
+	FormatPlain,
 }
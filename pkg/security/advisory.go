@@ -0,0 +1,599 @@
+// Package security 实现安全扫描功能
+package security
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"code-context-generator/pkg/types"
+)
+
+// OSVAdvisory OSV格式的安全公告
+type OSVAdvisory struct {
+	ID               string           `json:"id"`
+	Summary          string           `json:"summary"`
+	Details          string           `json:"details"`
+	Aliases          []string         `json:"aliases"`
+	Withdrawn        string           `json:"withdrawn"`
+	Severity         []OSVSeverity    `json:"severity"`
+	Affected         []OSVAffected    `json:"affected"`
+	DatabaseSpecific OSVSpecificField `json:"database_specific"`
+}
+
+// OSVSeverity 公告的严重性评分，Score 为 CVSS 向量
+type OSVSeverity struct {
+	Type  string `json:"type"`
+	Score string `json:"score"`
+}
+
+// OSVAffected 公告影响的包
+type OSVAffected struct {
+	Package struct {
+		Ecosystem string `json:"ecosystem"`
+		Name      string `json:"name"`
+	} `json:"package"`
+	Ranges            []OSVRange       `json:"ranges"`
+	Versions          []string         `json:"versions"`
+	Severity          []OSVSeverity    `json:"severity"`
+	DatabaseSpecific  OSVSpecificField `json:"database_specific"`
+	EcosystemSpecific OSVSpecificField `json:"ecosystem_specific"`
+}
+
+// OSVRange 受影响的版本范围
+type OSVRange struct {
+	Type   string     `json:"type"`
+	Events []OSVEvent `json:"events"`
+}
+
+// OSVEvent 版本范围事件
+type OSVEvent struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+	Limit        string `json:"limit,omitempty"`
+}
+
+// OSVSpecificField 数据库或生态系统自定义字段，只读取严重性
+type OSVSpecificField struct {
+	Severity string `json:"severity"`
+}
+
+// AdvisoryMatch 依赖命中的公告
+type AdvisoryMatch struct {
+	Advisory       *OSVAdvisory
+	AffectedRanges []string // 可读的受影响版本范围
+	FixedVersions  []string
+}
+
+// AdvisoryDatabase 本地OSV公告库
+type AdvisoryDatabase struct {
+	// 生态系统 -> 规范化包名 -> 公告影响条目
+	index map[string]map[string][]advisoryEntry
+	count int
+}
+
+// advisoryEntry 公告中针对单个包的影响条目
+type advisoryEntry struct {
+	advisory *OSVAdvisory
+	affected *OSVAffected
+}
+
+// LoadAdvisoryDatabase 从目录、zip 或 tar.gz 归档加载OSV公告，不访问网络
+func LoadAdvisoryDatabase(path string) (*AdvisoryDatabase, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("公告库不存在: %v", err)
+	}
+
+	db := &AdvisoryDatabase{index: make(map[string]map[string][]advisoryEntry)}
+	lower := strings.ToLower(path)
+
+	switch {
+	case info.IsDir():
+		err = filepath.Walk(path, func(file string, fi os.FileInfo, err error) error {
+			if err != nil || fi.IsDir() || !strings.HasSuffix(strings.ToLower(file), ".json") {
+				return err
+			}
+			f, err := os.Open(file)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			return db.add(file, f)
+		})
+	case strings.HasSuffix(lower, ".zip"):
+		err = db.loadZip(path)
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		err = db.loadTarGz(path)
+	default:
+		return nil, fmt.Errorf("不支持的公告库格式: %s (支持目录、.zip、.tar.gz)", path)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("加载公告库失败: %v", err)
+	}
+	return db, nil
+}
+
+// loadZip 从zip归档加载公告
+func (db *AdvisoryDatabase) loadZip(path string) error {
+	reader, err := zip.OpenReader(path)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	for _, file := range reader.File {
+		if file.FileInfo().IsDir() || !strings.HasSuffix(strings.ToLower(file.Name), ".json") {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return err
+		}
+		err = db.add(file.Name, rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadTarGz 从tar.gz归档加载公告
+func (db *AdvisoryDatabase) loadTarGz(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg || !strings.HasSuffix(strings.ToLower(header.Name), ".json") {
+			continue
+		}
+		if err := db.add(header.Name, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// add 解析并索引单个公告文件
+func (db *AdvisoryDatabase) add(name string, r io.Reader) error {
+	var advisory OSVAdvisory
+	if err := json.NewDecoder(r).Decode(&advisory); err != nil {
+		return fmt.Errorf("解析公告 %s 失败: %v", name, err)
+	}
+	if advisory.ID == "" || advisory.Withdrawn != "" {
+		return nil
+	}
+
+	db.count++
+	for i := range advisory.Affected {
+		affected := &advisory.Affected[i]
+		// 生态系统可能带有版本后缀，例如 "Debian:11"
+		ecosystem := strings.SplitN(affected.Package.Ecosystem, ":", 2)[0]
+		name := normalizePackageName(ecosystem, affected.Package.Name)
+		if db.index[ecosystem] == nil {
+			db.index[ecosystem] = make(map[string][]advisoryEntry)
+		}
+		db.index[ecosystem][name] = append(db.index[ecosystem][name], advisoryEntry{&advisory, affected})
+	}
+	return nil
+}
+
+// Count 返回已加载的公告数量
+func (db *AdvisoryDatabase) Count() int {
+	return db.count
+}
+
+// Match 查找影响指定依赖版本的公告
+func (db *AdvisoryDatabase) Match(dep Dependency) []AdvisoryMatch {
+	var matches []AdvisoryMatch
+	for _, entry := range db.index[dep.Ecosystem][normalizePackageName(dep.Ecosystem, dep.Name)] {
+		if !isAffected(entry.affected, dep.Version) {
+			continue
+		}
+		match := AdvisoryMatch{Advisory: entry.advisory}
+		for _, r := range entry.affected.Ranges {
+			if r.Type == "GIT" {
+				continue
+			}
+			match.AffectedRanges = append(match.AffectedRanges, describeRange(r.Events))
+			for _, event := range r.Events {
+				if event.Fixed != "" {
+					match.FixedVersions = append(match.FixedVersions, event.Fixed)
+				}
+			}
+		}
+		matches = append(matches, match)
+	}
+	return matches
+}
+
+// Severity 返回公告的严重性，优先根据 CVSS 向量计算，其次读取自定义字段，都没有时按高危处理
+func (m AdvisoryMatch) Severity(ecosystem, name string) types.SeverityLevel {
+	scores := m.Advisory.Severity
+	candidates := []string{m.Advisory.DatabaseSpecific.Severity}
+	for _, affected := range m.Advisory.Affected {
+		if strings.HasPrefix(affected.Package.Ecosystem, ecosystem) &&
+			normalizePackageName(ecosystem, affected.Package.Name) == normalizePackageName(ecosystem, name) {
+			scores = append(scores, affected.Severity...)
+			candidates = append(candidates, affected.DatabaseSpecific.Severity, affected.EcosystemSpecific.Severity)
+		}
+	}
+
+	for _, severity := range scores {
+		if score, ok := cvssBaseScore(severity.Type, severity.Score); ok {
+			return severityFromCVSS(score)
+		}
+	}
+
+	for _, severity := range candidates {
+		switch strings.ToUpper(severity) {
+		case "CRITICAL":
+			return types.SeverityCritical
+		case "HIGH":
+			return types.SeverityHigh
+		case "MODERATE", "MEDIUM":
+			return types.SeverityMedium
+		case "LOW":
+			return types.SeverityLow
+		}
+	}
+	return types.SeverityHigh
+}
+
+// severityFromCVSS 按 CVSS 定性等级把基础分映射为严重性
+func severityFromCVSS(score float64) types.SeverityLevel {
+	switch {
+	case score >= 9.0:
+		return types.SeverityCritical
+	case score >= 7.0:
+		return types.SeverityHigh
+	case score >= 4.0:
+		return types.SeverityMedium
+	default:
+		return types.SeverityLow
+	}
+}
+
+// cvssV3Weights CVSS v3 基础指标的权重
+var cvssV3Weights = map[string]map[string]float64{
+	"AV": {"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2},
+	"AC": {"L": 0.77, "H": 0.44},
+	"PR": {"N": 0.85, "L": 0.62, "H": 0.27},
+	"UI": {"N": 0.85, "R": 0.62},
+	"C":  {"H": 0.56, "L": 0.22, "N": 0},
+	"I":  {"H": 0.56, "L": 0.22, "N": 0},
+	"A":  {"H": 0.56, "L": 0.22, "N": 0},
+}
+
+// cvssBaseScore 根据 CVSS v3 向量计算基础分。
+// CVSS v4 的评分依赖官方查找表，这里不计算，交由自定义字段决定；
+// 评分为 0（没有影响）时同样视为无法确定
+func cvssBaseScore(scoreType, vector string) (float64, bool) {
+	if scoreType != "" && scoreType != "CVSS_V3" {
+		return 0, false
+	}
+	parts := strings.Split(vector, "/")
+	if len(parts) == 0 || !strings.HasPrefix(parts[0], "CVSS:3.") {
+		return 0, false
+	}
+
+	metrics := make(map[string]string)
+	for _, part := range parts[1:] {
+		if key, value, ok := strings.Cut(part, ":"); ok {
+			metrics[key] = value
+		}
+	}
+	scope := metrics["S"]
+	if scope != "U" && scope != "C" {
+		return 0, false
+	}
+	weights := make(map[string]float64)
+	for metric, values := range cvssV3Weights {
+		weight, ok := values[metrics[metric]]
+		if !ok {
+			return 0, false
+		}
+		weights[metric] = weight
+	}
+	// 范围改变时权限要求的权重更高
+	if scope == "C" {
+		switch metrics["PR"] {
+		case "L":
+			weights["PR"] = 0.68
+		case "H":
+			weights["PR"] = 0.5
+		}
+	}
+
+	iss := 1 - (1-weights["C"])*(1-weights["I"])*(1-weights["A"])
+	var impact float64
+	if scope == "U" {
+		impact = 6.42 * iss
+	} else {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	}
+	if impact <= 0 {
+		return 0, false
+	}
+	exploitability := 8.22 * weights["AV"] * weights["AC"] * weights["PR"] * weights["UI"]
+	if scope == "U" {
+		return cvssRoundUp(math.Min(impact+exploitability, 10)), true
+	}
+	return cvssRoundUp(math.Min(1.08*(impact+exploitability), 10)), true
+}
+
+// cvssRoundUp 按 CVSS v3.1 规范向上取整到一位小数
+func cvssRoundUp(value float64) float64 {
+	scaled := int64(math.Round(value * 100000))
+	if scaled%10000 == 0 {
+		return float64(scaled) / 100000
+	}
+	return float64(scaled/10000+1) / 10
+}
+
+// FixedVersionFor 返回高于当前版本的最小修复版本
+func (m AdvisoryMatch) FixedVersionFor(version string) string {
+	best := ""
+	for _, fixed := range m.FixedVersions {
+		if compareVersions(fixed, version) > 0 && (best == "" || compareVersions(fixed, best) < 0) {
+			best = fixed
+		}
+	}
+	return best
+}
+
+// isAffected 检查版本是否受影响
+func isAffected(affected *OSVAffected, version string) bool {
+	for _, v := range affected.Versions {
+		if compareVersions(v, version) == 0 {
+			return true
+		}
+	}
+
+	for _, r := range affected.Ranges {
+		if r.Type == "GIT" {
+			continue // 无法从清单得到提交哈希
+		}
+		// 事件按版本顺序排列，依次处理引入和修复
+		inRange := false
+		for _, event := range r.Events {
+			switch {
+			case event.Introduced != "":
+				if event.Introduced == "0" || compareVersions(version, event.Introduced) >= 0 {
+					inRange = true
+				}
+			case event.Fixed != "":
+				if compareVersions(version, event.Fixed) >= 0 {
+					inRange = false
+				}
+			case event.LastAffected != "":
+				if compareVersions(version, event.LastAffected) > 0 {
+					inRange = false
+				}
+			case event.Limit != "":
+				if compareVersions(version, event.Limit) >= 0 {
+					inRange = false
+				}
+			}
+		}
+		if inRange {
+			return true
+		}
+	}
+	return false
+}
+
+// describeRange 将范围事件转换为可读描述
+func describeRange(events []OSVEvent) string {
+	var parts []string
+	current := ""
+	for _, event := range events {
+		switch {
+		case event.Introduced != "":
+			if event.Introduced == "0" {
+				current = "所有版本"
+			} else {
+				current = ">= " + event.Introduced
+			}
+		case event.Fixed != "":
+			parts = append(parts, joinBound(current, "< "+event.Fixed))
+			current = ""
+		case event.LastAffected != "":
+			parts = append(parts, joinBound(current, "<= "+event.LastAffected))
+			current = ""
+		}
+	}
+	if current != "" {
+		parts = append(parts, current)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// joinBound 合并范围的上下界
+func joinBound(lower, upper string) string {
+	if lower == "" || lower == "所有版本" {
+		return upper
+	}
+	return lower + ", " + upper
+}
+
+// pypiNameSeparator PyPI 包名中可互换的分隔符
+var pypiNameSeparator = regexp.MustCompile(`[-_.]+`)
+
+// normalizePackageName 按生态系统规则规范化包名
+func normalizePackageName(ecosystem, name string) string {
+	switch ecosystem {
+	case EcosystemPyPI:
+		return pypiNameSeparator.ReplaceAllString(strings.ToLower(name), "-")
+	case EcosystemGo:
+		return name
+	default:
+		return strings.ToLower(name)
+	}
+}
+
+// versionToken 版本号的一段
+type versionToken struct {
+	number  int64
+	text    string
+	numeric bool
+}
+
+// compareVersions 比较两个版本号，兼容 semver、PEP 440 和 Maven 的常见写法
+//
+// 版本按数字和字母分段逐段比较；"-" 之后或紧跟字母的部分视为预发布，
+// 低于对应的正式版本。返回 -1、0 或 1。
+func compareVersions(a, b string) int {
+	a, b = trimVersion(a), trimVersion(b)
+	if a == b {
+		return 0
+	}
+
+	mainA, preA := splitPrerelease(a)
+	mainB, preB := splitPrerelease(b)
+
+	if c := compareTokens(tokenizeVersion(mainA), tokenizeVersion(mainB)); c != 0 {
+		return c
+	}
+
+	// 主版本相同时，没有预发布标记的版本更高
+	switch {
+	case preA == "" && preB == "":
+		return 0
+	case preA == "":
+		return 1
+	case preB == "":
+		return -1
+	}
+	return compareTokens(tokenizeVersion(preA), tokenizeVersion(preB))
+}
+
+// trimVersion 去除版本前缀和构建元数据
+func trimVersion(v string) string {
+	v = strings.TrimSpace(v)
+	v = strings.TrimPrefix(strings.TrimPrefix(v, "v"), "V")
+	if idx := strings.Index(v, "+"); idx >= 0 {
+		v = v[:idx]
+	}
+	return v
+}
+
+// splitPrerelease 拆分主版本和预发布部分
+func splitPrerelease(v string) (string, string) {
+	if idx := strings.Index(v, "-"); idx >= 0 {
+		return v[:idx], v[idx+1:]
+	}
+	return v, ""
+}
+
+// tokenizeVersion 将版本拆分为数字段和字母段
+func tokenizeVersion(v string) []versionToken {
+	var tokens []versionToken
+	i := 0
+	for i < len(v) {
+		c := v[i]
+		switch {
+		case c >= '0' && c <= '9':
+			j := i
+			for j < len(v) && v[j] >= '0' && v[j] <= '9' {
+				j++
+			}
+			n, _ := strconv.ParseInt(v[i:j], 10, 64)
+			tokens = append(tokens, versionToken{number: n, numeric: true})
+			i = j
+		case (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+			j := i
+			for j < len(v) && ((v[j] >= 'a' && v[j] <= 'z') || (v[j] >= 'A' && v[j] <= 'Z')) {
+				j++
+			}
+			tokens = append(tokens, versionToken{text: strings.ToLower(v[i:j])})
+			i = j
+		default:
+			i++ // 分隔符
+		}
+	}
+	return tokens
+}
+
+// compareTokens 逐段比较版本
+func compareTokens(a, b []versionToken) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		switch {
+		case i >= len(a):
+			if order := trailingOrder(b[i]); order != 0 {
+				return -order
+			}
+			continue
+		case i >= len(b):
+			if order := trailingOrder(a[i]); order != 0 {
+				return order
+			}
+			continue
+		}
+
+		ta, tb := a[i], b[i]
+		switch {
+		case ta.numeric && tb.numeric:
+			if ta.number != tb.number {
+				if ta.number < tb.number {
+					return -1
+				}
+				return 1
+			}
+		case ta.numeric:
+			return 1 // 数字段高于字母段（1.0.1 > 1.0.rc1）
+		case tb.numeric:
+			return -1
+		default:
+			if c := strings.Compare(ta.text, tb.text); c != 0 {
+				return c
+			}
+		}
+	}
+	return 0
+}
+
+// trailingOrder 多出的版本段对比较结果的影响：数字段更高，字母段通常为预发布更低
+func trailingOrder(t versionToken) int {
+	if t.numeric {
+		if t.number == 0 {
+			return 0 // 1.0 与 1.0.0 视为相同
+		}
+		return 1
+	}
+	switch t.text {
+	case "final", "ga", "release":
+		return 0 // Maven 正式版本限定符
+	case "post":
+		return 1 // PEP 440 后发布版本
+	}
+	return -1
+}
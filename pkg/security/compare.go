@@ -0,0 +1,282 @@
+// Package security 实现安全扫描功能
+package security
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"code-context-generator/pkg/types"
+)
+
+// ReportComparison 两次扫描的问题对比结果
+type ReportComparison struct {
+	Old       *types.SecurityReport
+	New       *types.SecurityReport
+	Added     []types.SecurityIssue // 新报告中新出现的问题
+	Fixed     []types.SecurityIssue // 旧报告中已修复的问题
+	Unchanged []types.SecurityIssue // 两次扫描都存在的问题
+}
+
+// TrendPoint 某次扫描各严重级别的问题数量
+type TrendPoint struct {
+	ScanID    string
+	Timestamp string
+	Critical  int
+	High      int
+	Medium    int
+	Low       int
+	Total     int
+}
+
+// IssueFingerprint 计算问题的稳定指纹
+//
+// 指纹由规则、相对扫描根目录的文件路径和归一化后的代码片段组成，不包含行号，
+// 在问题上方增删代码、从其他目录或以绝对路径扫描都不会改变指纹。
+func IssueFingerprint(issue types.SecurityIssue, root string) string {
+	rule := issue.ID
+	if rule == "" {
+		rule = issue.Type
+	}
+	file := path.Clean(filepath.ToSlash(relativeToRoot(issue.File, root)))
+	snippet := strings.Join(strings.Fields(issue.Snippet), " ")
+
+	sum := sha256.Sum256([]byte(rule + "\x00" + file + "\x00" + snippet))
+	return hex.EncodeToString(sum[:16])
+}
+
+// relativeToRoot 返回文件相对扫描根目录的路径，根目录之外的文件保持原样
+func relativeToRoot(file, root string) string {
+	if root == "" {
+		return file
+	}
+	rel, err := filepath.Rel(root, file)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return file
+	}
+	if rel == "." {
+		// 扫描的是单个文件
+		return filepath.Base(file)
+	}
+	return rel
+}
+
+// CompareReports 按指纹对比两次扫描，将问题分为新增、已修复和未变化
+//
+// 同一指纹出现多次时按数量配对，多出的部分视为新增或已修复。
+func CompareReports(oldReport, newReport *types.SecurityReport) *ReportComparison {
+	comparison := &ReportComparison{Old: oldReport, New: newReport}
+
+	remaining := make(map[string][]types.SecurityIssue)
+	for _, issue := range oldReport.Issues {
+		fp := IssueFingerprint(issue, oldReport.Root)
+		remaining[fp] = append(remaining[fp], issue)
+	}
+
+	for _, issue := range newReport.Issues {
+		fp := IssueFingerprint(issue, newReport.Root)
+		if matches := remaining[fp]; len(matches) > 0 {
+			comparison.Unchanged = append(comparison.Unchanged, issue)
+			remaining[fp] = matches[1:]
+			continue
+		}
+		comparison.Added = append(comparison.Added, issue)
+	}
+
+	// 保持旧报告中的顺序
+	for _, issue := range oldReport.Issues {
+		fp := IssueFingerprint(issue, oldReport.Root)
+		if matches := remaining[fp]; len(matches) > 0 {
+			comparison.Fixed = append(comparison.Fixed, matches[0])
+			remaining[fp] = matches[1:]
+		}
+	}
+
+	return comparison
+}
+
+// LoadReport 读取JSON格式的安全报告
+func LoadReport(reportPath string) (*types.SecurityReport, error) {
+	data, err := os.ReadFile(reportPath)
+	if err != nil {
+		return nil, fmt.Errorf("读取报告失败: %v", err)
+	}
+
+	var report types.SecurityReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("解析报告 %s 失败，需要JSON格式的报告: %v", reportPath, err)
+	}
+	return &report, nil
+}
+
+// ReportHistory 保存在目录中的历史扫描报告
+type ReportHistory struct {
+	dir string
+}
+
+// NewReportHistory 创建历史报告存储
+func NewReportHistory(dir string) *ReportHistory {
+	return &ReportHistory{dir: dir}
+}
+
+// Save 将报告以JSON格式保存到历史目录，返回文件路径
+func (h *ReportHistory) Save(report *types.SecurityReport) (string, error) {
+	if err := os.MkdirAll(h.dir, 0755); err != nil {
+		return "", fmt.Errorf("创建历史目录失败: %v", err)
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("序列化报告失败: %v", err)
+	}
+
+	// 文件名以时间开头，按名称排序即按时间排序
+	name := fmt.Sprintf("%s-%s.json", report.Timestamp.UTC().Format("20060102T150405.000Z"), report.ScanID)
+	reportPath := filepath.Join(h.dir, name)
+	if err := os.WriteFile(reportPath, data, 0644); err != nil {
+		return "", fmt.Errorf("写入历史报告失败: %v", err)
+	}
+	return reportPath, nil
+}
+
+// Load 按扫描时间顺序加载历史目录中的所有报告
+func (h *ReportHistory) Load() ([]*types.SecurityReport, error) {
+	entries, err := os.ReadDir(h.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("读取历史目录失败: %v", err)
+	}
+
+	var reports []*types.SecurityReport
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		report, err := LoadReport(filepath.Join(h.dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, report)
+	}
+
+	sort.SliceStable(reports, func(i, j int) bool {
+		return reports[i].Timestamp.Before(reports[j].Timestamp)
+	})
+	return reports, nil
+}
+
+// BuildTrend 统计每次扫描各严重级别的问题数量
+func BuildTrend(reports []*types.SecurityReport) []TrendPoint {
+	points := make([]TrendPoint, 0, len(reports))
+	for _, report := range reports {
+		point := TrendPoint{
+			ScanID:    report.ScanID,
+			Timestamp: report.Timestamp.Format("2006-01-02 15:04:05"),
+			Total:     len(report.Issues),
+		}
+		for _, issue := range report.Issues {
+			switch issue.Severity {
+			case types.SeverityCritical:
+				point.Critical++
+			case types.SeverityHigh:
+				point.High++
+			case types.SeverityMedium:
+				point.Medium++
+			default:
+				point.Low++
+			}
+		}
+		points = append(points, point)
+	}
+	return points
+}
+
+// FormatComparison 生成对比结果的文本报告
+func FormatComparison(c *ReportComparison, showUnchanged bool) []byte {
+	var builder strings.Builder
+
+	builder.WriteString("=== 安全扫描对比 ===\n")
+	builder.WriteString(fmt.Sprintf("旧扫描: %s (%s)\n", c.Old.ScanID, c.Old.Timestamp.Format("2006-01-02 15:04:05")))
+	builder.WriteString(fmt.Sprintf("新扫描: %s (%s)\n", c.New.ScanID, c.New.Timestamp.Format("2006-01-02 15:04:05")))
+	builder.WriteString(fmt.Sprintf("新增问题: %d\n", len(c.Added)))
+	builder.WriteString(fmt.Sprintf("已修复问题: %d\n", len(c.Fixed)))
+	builder.WriteString(fmt.Sprintf("未变化问题: %d\n", len(c.Unchanged)))
+	builder.WriteString("\n")
+
+	builder.WriteString(formatTrendTable(BuildTrend([]*types.SecurityReport{c.Old, c.New})))
+	builder.WriteString("\n")
+
+	writeIssueList(&builder, "新增问题", c.Added)
+	writeIssueList(&builder, "已修复问题", c.Fixed)
+	if showUnchanged {
+		writeIssueList(&builder, "未变化问题", c.Unchanged)
+	}
+
+	return []byte(builder.String())
+}
+
+// FormatTrend 生成问题趋势的文本报告
+func FormatTrend(points []TrendPoint) []byte {
+	var builder strings.Builder
+	builder.WriteString("=== 问题趋势 ===\n")
+	builder.WriteString(formatTrendTable(points))
+	return []byte(builder.String())
+}
+
+// formatTrendTable 按扫描时间输出各严重级别的数量及变化
+func formatTrendTable(points []TrendPoint) string {
+	var builder strings.Builder
+	// 中文表头每个字占两列宽度
+	builder.WriteString(fmt.Sprintf("%-15s  %6s  %6s  %6s  %6s  %6s\n", "扫描时间", "严重", "高危", "中危", "低危", "合计"))
+
+	for i, point := range points {
+		var prev *TrendPoint
+		if i > 0 {
+			prev = &points[i-1]
+		}
+		builder.WriteString(fmt.Sprintf("%-19s  %8s  %8s  %8s  %8s  %8s\n", point.Timestamp,
+			trendCell(point.Critical, prev, func(p *TrendPoint) int { return p.Critical }),
+			trendCell(point.High, prev, func(p *TrendPoint) int { return p.High }),
+			trendCell(point.Medium, prev, func(p *TrendPoint) int { return p.Medium }),
+			trendCell(point.Low, prev, func(p *TrendPoint) int { return p.Low }),
+			trendCell(point.Total, prev, func(p *TrendPoint) int { return p.Total }),
+		))
+	}
+	return builder.String()
+}
+
+// trendCell 格式化数量及相对上一次扫描的变化
+func trendCell(value int, prev *TrendPoint, field func(*TrendPoint) int) string {
+	if prev == nil {
+		return fmt.Sprintf("%d", value)
+	}
+	delta := value - field(prev)
+	switch {
+	case delta > 0:
+		return fmt.Sprintf("%d(+%d)", value, delta)
+	case delta < 0:
+		return fmt.Sprintf("%d(%d)", value, delta)
+	}
+	return fmt.Sprintf("%d", value)
+}
+
+// writeIssueList 输出问题列表
+func writeIssueList(builder *strings.Builder, title string, issues []types.SecurityIssue) {
+	if len(issues) == 0 {
+		return
+	}
+	builder.WriteString(fmt.Sprintf("=== %s ===\n", title))
+	for i, issue := range issues {
+		builder.WriteString(fmt.Sprintf("%d. [%s] %s %s:%d\n", i+1, issue.Severity.String(), issue.ID, issue.File, issue.Line))
+		builder.WriteString(fmt.Sprintf("   描述: %s\n", issue.Message))
+	}
+	builder.WriteString("\n")
+}
@@ -0,0 +1,148 @@
+// Package security 报告对比测试
+package security
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"code-context-generator/pkg/types"
+)
+
+// TestCompareReports 测试按指纹对比两次扫描
+func TestCompareReports(t *testing.T) {
+	oldReport := &types.SecurityReport{
+		ScanID: "old",
+		Issues: []types.SecurityIssue{
+			{ID: "CREDENTIALS_001", File: "a.py", Line: 1, Snippet: `password = "one"`, Severity: types.SeverityHigh},
+			{ID: "CREDENTIALS_001", File: "b.py", Line: 3, Snippet: `token = "two"`, Severity: types.SeverityHigh},
+			{ID: "XSS_001", File: "c.js", Line: 5, Snippet: "el.innerHTML = x", Severity: types.SeverityMedium},
+			{ID: "XSS_001", File: "c.js", Line: 9, Snippet: "el.innerHTML = x", Severity: types.SeverityMedium},
+		},
+	}
+	newReport := &types.SecurityReport{
+		ScanID: "new",
+		Issues: []types.SecurityIssue{
+			// 行号和缩进变化不影响指纹
+			{ID: "CREDENTIALS_001", File: "./a.py", Line: 10, Snippet: `password  =  "one"`, Severity: types.SeverityHigh},
+			{ID: "XSS_001", File: "c.js", Line: 6, Snippet: "el.innerHTML = x", Severity: types.SeverityMedium},
+			{ID: "SQL_INJECTION_001", File: "d.py", Line: 2, Snippet: "query + id", Severity: types.SeverityCritical},
+		},
+	}
+
+	c := CompareReports(oldReport, newReport)
+	if len(c.Added) != 1 || c.Added[0].ID != "SQL_INJECTION_001" {
+		t.Errorf("期望新增 1 个SQL注入问题，实际 %+v", c.Added)
+	}
+	if len(c.Unchanged) != 2 {
+		t.Errorf("期望 2 个未变化问题，实际 %d 个", len(c.Unchanged))
+	}
+	if len(c.Fixed) != 2 || c.Fixed[0].File != "b.py" || c.Fixed[1].Line != 9 {
+		t.Errorf("期望修复 b.py 和第二个XSS问题，实际 %+v", c.Fixed)
+	}
+
+	output := string(FormatComparison(c, false))
+	for _, expected := range []string{"新增问题: 1", "已修复问题: 2", "未变化问题: 2", "1(+1)"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("对比报告应该包含 %q", expected)
+		}
+	}
+}
+
+// TestReportHistory 测试历史报告的保存、加载和趋势
+func TestReportHistory(t *testing.T) {
+	history := NewReportHistory(t.TempDir())
+
+	reports, err := history.Load()
+	if err != nil || len(reports) != 0 {
+		t.Fatalf("空目录应该没有报告: %v", err)
+	}
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	// 先保存较新的报告，加载后应按时间排序
+	for i, count := range []int{1, 3} {
+		report := &types.SecurityReport{ScanID: []string{"second", "first"}[i], Timestamp: base.Add(time.Duration(1-i) * time.Hour)}
+		for j := 0; j < count; j++ {
+			report.Issues = append(report.Issues, types.SecurityIssue{ID: "CREDENTIALS_001", Severity: types.SeverityHigh})
+		}
+		if _, err := history.Save(report); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reports, err = history.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 2 || reports[0].ScanID != "first" {
+		t.Fatalf("期望按时间加载 2 份报告")
+	}
+	if reports[0].Issues[0].Severity != types.SeverityHigh {
+		t.Errorf("严重性应该在JSON中往返保持，实际 %v", reports[0].Issues[0].Severity)
+	}
+
+	trend := BuildTrend(reports)
+	if trend[0].High != 3 || trend[1].High != 1 || trend[1].Total != 1 {
+		t.Errorf("趋势统计不正确: %+v", trend)
+	}
+	if !strings.Contains(string(FormatTrend(trend)), "1(-2)") {
+		t.Error("趋势应该显示相对上一次扫描的变化")
+	}
+}
+
+// TestCompareReportsAcrossRoots 测试以相对路径和绝对路径扫描同一项目时指纹一致
+func TestCompareReportsAcrossRoots(t *testing.T) {
+	project := t.TempDir()
+	writeTestFile(t, project, "app/config.py", "password = \"hunter2\"\n")
+
+	scanner := NewSecurityScanner(allDetectorsConfig("standard"))
+	absReport, err := scanner.Scan(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(absReport.Issues) == 0 {
+		t.Fatal("期望发现硬编码凭证")
+	}
+
+	t.Chdir(project)
+	relReport, err := scanner.Scan(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := CompareReports(absReport, relReport)
+	if len(c.Added) != 0 || len(c.Fixed) != 0 || len(c.Unchanged) != len(absReport.Issues) {
+		t.Errorf("期望所有问题未变化，实际新增 %d，已修复 %d，未变化 %d", len(c.Added), len(c.Fixed), len(c.Unchanged))
+	}
+	if IssueFingerprint(absReport.Issues[0], absReport.Root) != IssueFingerprint(relReport.Issues[0], relReport.Root) {
+		t.Error("相同问题的指纹应该一致")
+	}
+}
+
+// TestLoadReportNumericSeverity 测试加载以数字记录严重性级别的旧报告
+func TestLoadReportNumericSeverity(t *testing.T) {
+	reportPath := filepath.Join(t.TempDir(), "old.json")
+	data := `{"scan_id":"old","issues":[` +
+		`{"id":"CREDENTIALS_001","file":"a.py","severity":2},` +
+		`{"id":"SQL_INJECTION_001","file":"b.py","severity":"critical"}]}`
+	if err := os.WriteFile(reportPath, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := LoadReport(reportPath)
+	if err != nil {
+		t.Fatalf("加载旧报告失败: %v", err)
+	}
+	if report.Issues[0].Severity != types.SeverityHigh || report.Issues[1].Severity != types.SeverityCritical {
+		t.Errorf("严重性级别解析错误: %v, %v", report.Issues[0].Severity, report.Issues[1].Severity)
+	}
+
+	if err := os.WriteFile(reportPath, []byte(`{"issues":[{"severity":7}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadReport(reportPath); err == nil {
+		t.Error("超出范围的严重性级别应该返回错误")
+	}
+}
@@ -0,0 +1,395 @@
+// Package security 实现安全扫描功能
+package security
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// OSV 生态系统名称
+const (
+	EcosystemGo    = "Go"
+	EcosystemNpm   = "npm"
+	EcosystemPyPI  = "PyPI"
+	EcosystemCargo = "crates.io"
+	EcosystemMaven = "Maven"
+)
+
+// Dependency 清单文件中声明的依赖
+type Dependency struct {
+	Name      string
+	Version   string
+	Ecosystem string
+	Source    string // 清单文件路径
+	Line      int
+}
+
+// manifestParser 清单文件解析函数
+type manifestParser func(path string, content []byte) ([]Dependency, error)
+
+// manifestParsers 支持的清单文件
+var manifestParsers = map[string]manifestParser{
+	"go.mod":            parseGoMod,
+	"go.sum":            parseGoSum,
+	"package-lock.json": parsePackageLock,
+	"requirements.txt":  parseRequirements,
+	"Cargo.lock":        parseCargoLock,
+	"pom.xml":           parsePom,
+}
+
+// manifestSkipDirs 查找清单时跳过的目录
+var manifestSkipDirs = map[string]bool{
+	".git":         true,
+	"node_modules": true,
+	"vendor":       true,
+	"target":       true,
+	".venv":        true,
+	"venv":         true,
+}
+
+// IsManifestFile 检查文件名是否为支持的依赖清单
+func IsManifestFile(name string) bool {
+	_, ok := manifestParsers[name]
+	return ok
+}
+
+// FindManifests 查找路径下的依赖清单文件
+func FindManifests(root string) ([]string, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, fmt.Errorf("路径不存在: %v", err)
+	}
+	if !info.IsDir() {
+		if !IsManifestFile(filepath.Base(root)) {
+			return nil, fmt.Errorf("不支持的依赖清单: %s", root)
+		}
+		return []string{root}, nil
+	}
+
+	var manifests []string
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil // 跳过无法访问的路径
+		}
+		if info.IsDir() {
+			if path != root && manifestSkipDirs[info.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if IsManifestFile(info.Name()) {
+			manifests = append(manifests, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("查找依赖清单失败: %v", err)
+	}
+
+	sort.Strings(manifests)
+	return manifests, nil
+}
+
+// ParseManifest 解析依赖清单文件
+func ParseManifest(path string) ([]Dependency, error) {
+	parser, ok := manifestParsers[filepath.Base(path)]
+	if !ok {
+		return nil, fmt.Errorf("不支持的依赖清单: %s", path)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取依赖清单失败: %v", err)
+	}
+
+	deps, err := parser(path, content)
+	if err != nil {
+		return nil, fmt.Errorf("解析依赖清单 %s 失败: %v", path, err)
+	}
+	return deps, nil
+}
+
+// parseGoMod 解析 go.mod 的 require 指令
+func parseGoMod(path string, content []byte) ([]Dependency, error) {
+	var deps []Dependency
+	inRequire := false
+
+	for i, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(stripComment(line, "//"))
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "require ("), line == "require(":
+			inRequire = true
+			continue
+		case inRequire && line == ")":
+			inRequire = false
+			continue
+		case strings.HasPrefix(line, "require "):
+			line = strings.TrimSpace(strings.TrimPrefix(line, "require "))
+		case !inRequire:
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		deps = append(deps, Dependency{
+			Name:      fields[0],
+			Version:   fields[1],
+			Ecosystem: EcosystemGo,
+			Source:    path,
+			Line:      i + 1,
+		})
+	}
+
+	return deps, nil
+}
+
+// parseGoSum 解析 go.sum 中实际下载过源码的模块
+func parseGoSum(path string, content []byte) ([]Dependency, error) {
+	var deps []Dependency
+	seen := make(map[string]bool)
+
+	for i, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		// 只有 go.mod 哈希的条目仅参与版本选择，未被编译
+		if len(fields) != 3 || strings.HasSuffix(fields[1], "/go.mod") {
+			continue
+		}
+		key := fields[0] + "@" + fields[1]
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		deps = append(deps, Dependency{
+			Name:      fields[0],
+			Version:   fields[1],
+			Ecosystem: EcosystemGo,
+			Source:    path,
+			Line:      i + 1,
+		})
+	}
+
+	return deps, nil
+}
+
+// packageLock package-lock.json 结构
+type packageLock struct {
+	Packages     map[string]packageLockEntry `json:"packages"`
+	Dependencies map[string]packageLockEntry `json:"dependencies"`
+}
+
+// packageLockEntry package-lock.json 中的依赖条目
+type packageLockEntry struct {
+	Version      string                      `json:"version"`
+	Link         bool                        `json:"link"`
+	Dependencies map[string]packageLockEntry `json:"dependencies"`
+}
+
+// parsePackageLock 解析 package-lock.json（lockfileVersion 1-3）
+func parsePackageLock(path string, content []byte) ([]Dependency, error) {
+	var lock packageLock
+	if err := json.Unmarshal(content, &lock); err != nil {
+		return nil, err
+	}
+
+	var deps []Dependency
+	add := func(name, version, key string) {
+		if name == "" || version == "" {
+			return
+		}
+		deps = append(deps, Dependency{
+			Name:      name,
+			Version:   version,
+			Ecosystem: EcosystemNpm,
+			Source:    path,
+			Line:      lineOf(content, `"`+key+`"`),
+		})
+	}
+
+	if len(lock.Packages) > 0 {
+		// lockfileVersion 2/3：键为 node_modules 路径，空键为项目自身
+		for key, entry := range lock.Packages {
+			idx := strings.LastIndex(key, "node_modules/")
+			if idx < 0 || entry.Link {
+				continue
+			}
+			add(key[idx+len("node_modules/"):], entry.Version, key)
+		}
+	} else {
+		// lockfileVersion 1：依赖树嵌套
+		var walk func(entries map[string]packageLockEntry)
+		walk = func(entries map[string]packageLockEntry) {
+			for name, entry := range entries {
+				add(name, entry.Version, name)
+				walk(entry.Dependencies)
+			}
+		}
+		walk(lock.Dependencies)
+	}
+
+	sortDependencies(deps)
+	return deps, nil
+}
+
+// requirementPattern 固定版本的 requirements.txt 条目
+var requirementPattern = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)(?:\[[^\]]*\])?\s*===?\s*([^\s;,]+)`)
+
+// parseRequirements 解析 requirements.txt 中固定版本（==）的依赖
+func parseRequirements(path string, content []byte) ([]Dependency, error) {
+	var deps []Dependency
+	scanner := bufio.NewScanner(strings.NewReader(string(content)))
+	lineNum := 0
+
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(stripComment(scanner.Text(), "#"))
+		if line == "" || strings.HasPrefix(line, "-") {
+			continue // 跳过选项和嵌套文件
+		}
+		match := requirementPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue // 未固定版本无法判断是否受影响
+		}
+		deps = append(deps, Dependency{
+			Name:      match[1],
+			Version:   match[2],
+			Ecosystem: EcosystemPyPI,
+			Source:    path,
+			Line:      lineNum,
+		})
+	}
+
+	return deps, scanner.Err()
+}
+
+// cargoLock Cargo.lock 结构
+type cargoLock struct {
+	Packages []struct {
+		Name    string `toml:"name"`
+		Version string `toml:"version"`
+		Source  string `toml:"source"`
+	} `toml:"package"`
+}
+
+// parseCargoLock 解析 Cargo.lock 中来自 crates.io 的包
+func parseCargoLock(path string, content []byte) ([]Dependency, error) {
+	var lock cargoLock
+	if _, err := toml.Decode(string(content), &lock); err != nil {
+		return nil, err
+	}
+
+	var deps []Dependency
+	for _, pkg := range lock.Packages {
+		// 没有 source 的是工作区内的本地包
+		if pkg.Source == "" || !strings.HasPrefix(pkg.Source, "registry+") {
+			continue
+		}
+		deps = append(deps, Dependency{
+			Name:      pkg.Name,
+			Version:   pkg.Version,
+			Ecosystem: EcosystemCargo,
+			Source:    path,
+			Line:      lineOf(content, `name = "`+pkg.Name+`"`),
+		})
+	}
+
+	return deps, nil
+}
+
+// pomProject pom.xml 结构
+type pomProject struct {
+	Properties struct {
+		Entries []struct {
+			XMLName xml.Name
+			Value   string `xml:",chardata"`
+		} `xml:",any"`
+	} `xml:"properties"`
+	Version      string          `xml:"version"`
+	Dependencies []pomDependency `xml:"dependencies>dependency"`
+	Managed      []pomDependency `xml:"dependencyManagement>dependencies>dependency"`
+}
+
+// pomDependency pom.xml 中的依赖
+type pomDependency struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+}
+
+// pomPropertyPattern pom.xml 中的属性引用
+var pomPropertyPattern = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// parsePom 解析 pom.xml 中声明了版本的依赖
+func parsePom(path string, content []byte) ([]Dependency, error) {
+	var project pomProject
+	if err := xml.Unmarshal(content, &project); err != nil {
+		return nil, err
+	}
+
+	properties := map[string]string{"project.version": project.Version}
+	for _, entry := range project.Properties.Entries {
+		properties[entry.XMLName.Local] = strings.TrimSpace(entry.Value)
+	}
+
+	var deps []Dependency
+	for _, dep := range append(project.Dependencies, project.Managed...) {
+		version := pomPropertyPattern.ReplaceAllStringFunc(strings.TrimSpace(dep.Version), func(ref string) string {
+			if value, ok := properties[ref[2:len(ref)-1]]; ok {
+				return value
+			}
+			return ref
+		})
+		// 版本由父POM管理或属性无法解析时跳过
+		if version == "" || strings.Contains(version, "${") {
+			continue
+		}
+		deps = append(deps, Dependency{
+			Name:      strings.TrimSpace(dep.GroupID) + ":" + strings.TrimSpace(dep.ArtifactID),
+			Version:   version,
+			Ecosystem: EcosystemMaven,
+			Source:    path,
+			Line:      lineOf(content, "<artifactId>"+strings.TrimSpace(dep.ArtifactID)+"</artifactId>"),
+		})
+	}
+
+	return deps, nil
+}
+
+// stripComment 去除行尾注释
+func stripComment(line, marker string) string {
+	if idx := strings.Index(line, marker); idx >= 0 {
+		return line[:idx]
+	}
+	return line
+}
+
+// lineOf 返回文本首次出现的行号，未找到时返回0
+func lineOf(content []byte, needle string) int {
+	idx := strings.Index(string(content), needle)
+	if idx < 0 {
+		return 0
+	}
+	return strings.Count(string(content[:idx]), "\n") + 1
+}
+
+// sortDependencies 按名称和版本排序依赖
+func sortDependencies(deps []Dependency) {
+	sort.Slice(deps, func(i, j int) bool {
+		if deps[i].Name != deps[j].Name {
+			return deps[i].Name < deps[j].Name
+		}
+		return deps[i].Version < deps[j].Version
+	})
+}
@@ -0,0 +1,269 @@
+// Package security 依赖漏洞审计测试
+package security
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"code-context-generator/pkg/types"
+)
+
+// writeTestFile 在目录中写入测试文件
+func writeTestFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// TestParseManifests 测试依赖清单解析
+func TestParseManifests(t *testing.T) {
+	dir := t.TempDir()
+
+	testCases := []struct {
+		name     string
+		content  string
+		expected []string // 名称@版本
+	}{
+		{
+			name: "go.mod",
+			content: `module example.com/app
+
+go 1.21
+
+require github.com/single/mod v1.0.0
+
+require (
+	github.com/pkg/errors v0.9.1 // indirect
+	golang.org/x/text v0.3.7
+)
+`,
+			expected: []string{"github.com/single/mod@v1.0.0", "github.com/pkg/errors@v0.9.1", "golang.org/x/text@v0.3.7"},
+		},
+		{
+			name: "go.sum",
+			content: `golang.org/x/text v0.3.7 h1:abc=
+golang.org/x/text v0.3.7/go.mod h1:def=
+golang.org/x/net v0.1.0/go.mod h1:ghi=
+`,
+			expected: []string{"golang.org/x/text@v0.3.7"},
+		},
+		{
+			name: "package-lock.json",
+			content: `{
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "app"},
+    "node_modules/lodash": {"version": "4.17.20"},
+    "node_modules/@babel/core": {"version": "7.0.0"},
+    "node_modules/local": {"link": true}
+  }
+}`,
+			expected: []string{"@babel/core@7.0.0", "lodash@4.17.20"},
+		},
+		{
+			name: "requirements.txt",
+			content: `# 注释
+Django==3.2.0
+requests[security] == 2.25.1  # 行尾注释
+flask>=2.0
+-r other.txt
+`,
+			expected: []string{"Django@3.2.0", "requests@2.25.1"},
+		},
+		{
+			name: "Cargo.lock",
+			content: `[[package]]
+name = "app"
+version = "0.1.0"
+
+[[package]]
+name = "smallvec"
+version = "1.6.0"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+`,
+			expected: []string{"smallvec@1.6.0"},
+		},
+		{
+			name: "pom.xml",
+			content: `<project>
+  <properties><jackson.version>2.9.8</jackson.version></properties>
+  <dependencies>
+    <dependency>
+      <groupId>com.fasterxml.jackson.core</groupId>
+      <artifactId>jackson-databind</artifactId>
+      <version>${jackson.version}</version>
+    </dependency>
+    <dependency>
+      <groupId>junit</groupId>
+      <artifactId>junit</artifactId>
+    </dependency>
+  </dependencies>
+</project>`,
+			expected: []string{"com.fasterxml.jackson.core:jackson-databind@2.9.8"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := writeTestFile(t, filepath.Join(dir, tc.name+"-dir"), tc.name, tc.content)
+			deps, err := ParseManifest(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(deps) != len(tc.expected) {
+				t.Fatalf("期望 %d 个依赖，实际 %d 个: %v", len(tc.expected), len(deps), deps)
+			}
+			for i, dep := range deps {
+				if got := dep.Name + "@" + dep.Version; got != tc.expected[i] {
+					t.Errorf("第 %d 个依赖期望 %s，实际 %s", i, tc.expected[i], got)
+				}
+				if dep.Line == 0 {
+					t.Errorf("依赖 %s 应该包含行号", dep.Name)
+				}
+			}
+		})
+	}
+}
+
+// TestCompareVersions 测试版本比较
+func TestCompareVersions(t *testing.T) {
+	testCases := []struct {
+		a, b     string
+		expected int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"v1.2.3", "1.2.3", 0},
+		{"1.2.10", "1.2.9", 1},
+		{"1.0", "1.0.0", 0},
+		{"1.0", "1.0.0.1", -1},
+		{"1.0.0-alpha", "1.0.0", -1},
+		{"1.0.0-alpha", "1.0.0-beta", -1},
+		{"1.0rc1", "1.0", -1},
+		{"1.0.post1", "1.0", 1},
+		{"2.0-SNAPSHOT", "2.0", -1},
+		{"5.3.0.Final", "5.3.0", 0},
+		{"0.0.0-20210101000000-abcdef", "0.1.0", -1},
+	}
+
+	for _, tc := range testCases {
+		if got := compareVersions(tc.a, tc.b); got != tc.expected {
+			t.Errorf("compareVersions(%q, %q) 期望 %d，实际 %d", tc.a, tc.b, tc.expected, got)
+		}
+	}
+}
+
+// TestDependencyAudit 测试依赖审计
+func TestDependencyAudit(t *testing.T) {
+	project := t.TempDir()
+	writeTestFile(t, project, "go.mod", "module app\n\nrequire golang.org/x/text v0.3.5\n")
+	writeTestFile(t, project, "go.sum", "golang.org/x/text v0.3.5 h1:abc=\n")
+	writeTestFile(t, project, "requirements.txt", "django==3.2.0\nflask==2.0.0\n")
+	writeTestFile(t, project, "node_modules/x/package-lock.json", `{"packages":{"node_modules/lodash":{"version":"4.17.20"}}}`)
+
+	// 公告库以zip归档形式提供
+	archive := filepath.Join(t.TempDir(), "osv.zip")
+	f, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	advisories := map[string]string{
+		"Go/GO-2021-0113.json": `{"id":"GO-2021-0113","summary":"Out-of-bounds read","aliases":["CVE-2020-28851"],
+			"affected":[{"package":{"ecosystem":"Go","name":"golang.org/x/text"},
+			"ranges":[{"type":"SEMVER","events":[{"introduced":"0"},{"fixed":"0.3.7"}]}]}]}`,
+		"PyPI/PYSEC-1.json": `{"id":"PYSEC-1","summary":"SQL injection","database_specific":{"severity":"CRITICAL"},
+			"affected":[{"package":{"ecosystem":"PyPI","name":"Django"},
+			"ranges":[{"type":"ECOSYSTEM","events":[{"introduced":"3.2"},{"fixed":"3.2.4"},{"introduced":"4.0"},{"fixed":"4.0.1"}]}]}]}`,
+		"PyPI/PYSEC-2.json": `{"id":"PYSEC-2","withdrawn":"2022-01-01T00:00:00Z",
+			"affected":[{"package":{"ecosystem":"PyPI","name":"flask"},"versions":["2.0.0"]}]}`,
+		"npm/GHSA-1.json": `{"id":"GHSA-1","affected":[{"package":{"ecosystem":"npm","name":"lodash"},"versions":["4.17.20"]}]}`,
+	}
+	for name, content := range advisories {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	zw.Close()
+	f.Close()
+
+	db, err := LoadAdvisoryDatabase(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.Count() != 3 {
+		t.Errorf("期望加载 3 条公告，实际 %d 条", db.Count())
+	}
+
+	report, err := NewDependencyAuditor(db, &types.SecurityConfig{}).Audit(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// node_modules 中的清单被跳过，go.sum 与 go.mod 重复的模块只报告一次
+	if report.Summary.IssuesFound != 2 {
+		t.Fatalf("期望发现 2 个问题，实际发现 %d 个问题: %+v", report.Summary.IssuesFound, report.Issues)
+	}
+
+	byID := make(map[string]types.SecurityIssue)
+	for _, issue := range report.Issues {
+		byID[issue.ID] = issue
+	}
+
+	goIssue := byID["GO-2021-0113"]
+	if goIssue.Severity != types.SeverityHigh || goIssue.Line != 3 || filepath.Base(goIssue.File) != "go.mod" {
+		t.Errorf("Go依赖问题不符合预期: %+v", goIssue)
+	}
+	if goIssue.Recommendation != "升级 golang.org/x/text 到 0.3.7 或更高版本" {
+		t.Errorf("修复建议不符合预期: %s", goIssue.Recommendation)
+	}
+
+	pyIssue := byID["PYSEC-1"]
+	if pyIssue.Severity != types.SeverityCritical {
+		t.Errorf("期望严重性 critical，实际 %s", pyIssue.Severity)
+	}
+	if pyIssue.Recommendation != "升级 django 到 3.2.4 或更高版本" {
+		t.Errorf("修复建议不符合预期: %s", pyIssue.Recommendation)
+	}
+}
+
+// TestAdvisorySeverity 测试根据CVSS向量和自定义字段确定公告严重性
+func TestAdvisorySeverity(t *testing.T) {
+	tests := []struct {
+		name     string
+		advisory string
+		expected types.SeverityLevel
+	}{
+		{"CVSS严重", `{"severity":[{"type":"CVSS_V3","score":"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}]}`, types.SeverityCritical},
+		{"CVSS范围改变", `{"severity":[{"type":"CVSS_V3","score":"CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:C/C:L/I:L/A:N"}]}`, types.SeverityMedium},
+		{"CVSS低危", `{"severity":[{"type":"CVSS_V3","score":"CVSS:3.1/AV:L/AC:H/PR:H/UI:R/S:U/C:L/I:N/A:N"}]}`, types.SeverityLow},
+		{"CVSS优先于自定义字段", `{"severity":[{"type":"CVSS_V3","score":"CVSS:3.0/AV:N/AC:H/PR:N/UI:N/S:U/C:H/I:N/A:N"}],"database_specific":{"severity":"CRITICAL"}}`, types.SeverityMedium},
+		{"影响条目上的CVSS", `{"affected":[{"package":{"ecosystem":"PyPI","name":"django"},"severity":[{"type":"CVSS_V3","score":"CVSS:3.1/AV:N/AC:L/PR:L/UI:N/S:U/C:H/I:N/A:N"}]}]}`, types.SeverityMedium},
+		{"CVSS v4回退到自定义字段", `{"severity":[{"type":"CVSS_V4","score":"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N"}],"database_specific":{"severity":"LOW"}}`, types.SeverityLow},
+		{"无效向量回退到自定义字段", `{"severity":[{"type":"CVSS_V3","score":"CVSS:3.1/AV:X"}],"database_specific":{"severity":"MODERATE"}}`, types.SeverityMedium},
+		{"未标注", `{}`, types.SeverityHigh},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var advisory OSVAdvisory
+			if err := json.Unmarshal([]byte(tt.advisory), &advisory); err != nil {
+				t.Fatal(err)
+			}
+			match := AdvisoryMatch{Advisory: &advisory}
+			if got := match.Severity("PyPI", "Django"); got != tt.expected {
+				t.Errorf("Severity() = %s, 期望 %s", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+// Package security 实现安全扫描功能
+package security
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"code-context-generator/pkg/types"
+)
+
+// DependencyAuditor 依赖漏洞审计器
+type DependencyAuditor struct {
+	db     *AdvisoryDatabase
+	config *types.SecurityConfig
+}
+
+// NewDependencyAuditor 创建依赖漏洞审计器
+func NewDependencyAuditor(db *AdvisoryDatabase, config *types.SecurityConfig) *DependencyAuditor {
+	return &DependencyAuditor{
+		db:     db,
+		config: config,
+	}
+}
+
+// Audit 解析路径下的依赖清单并与公告库比对
+func (a *DependencyAuditor) Audit(path string) (*types.SecurityReport, error) {
+	startTime := time.Now()
+
+	scanID, err := generateScanID()
+	if err != nil {
+		return nil, fmt.Errorf("生成扫描ID失败: %v", err)
+	}
+
+	manifests, err := FindManifests(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []types.SecurityIssue
+	scanned := 0
+	// 同一目录下 go.mod 与 go.sum 会声明相同的模块版本
+	seen := make(map[string]bool)
+
+	for _, manifest := range manifests {
+		deps, err := ParseManifest(manifest)
+		if err != nil {
+			// 记录错误但继续审计其他清单
+			fmt.Printf("警告: %v\n", err)
+			continue
+		}
+		scanned++
+
+		for _, dep := range deps {
+			key := filepath.Dir(dep.Source) + "|" + dep.Ecosystem + "|" + dep.Name + "@" + dep.Version
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			for _, match := range a.db.Match(dep) {
+				issues = append(issues, dependencyIssue(dep, match))
+			}
+		}
+	}
+
+	return newSecurityReport(scanID, len(manifests), scanned, issues, time.Since(startTime), *a.config), nil
+}
+
+// dependencyIssue 将命中的公告转换为安全问题
+func dependencyIssue(dep Dependency, match AdvisoryMatch) types.SecurityIssue {
+	advisory := match.Advisory
+
+	summary := advisory.Summary
+	if summary == "" {
+		summary = strings.SplitN(strings.TrimSpace(advisory.Details), "\n", 2)[0]
+	}
+	message := fmt.Sprintf("%s@%s 受已知漏洞影响: %s", dep.Name, dep.Version, summary)
+	if len(advisory.Aliases) > 0 {
+		message += fmt.Sprintf(" [%s]", strings.Join(advisory.Aliases, ", "))
+	}
+	if len(match.AffectedRanges) > 0 {
+		message += fmt.Sprintf("（受影响版本: %s）", strings.Join(match.AffectedRanges, "; "))
+	}
+
+	recommendation := "该漏洞暂无修复版本，请评估替换或移除该依赖"
+	if fixed := match.FixedVersionFor(dep.Version); fixed != "" {
+		recommendation = fmt.Sprintf("升级 %s 到 %s 或更高版本", dep.Name, fixed)
+	}
+
+	return types.SecurityIssue{
+		ID:             advisory.ID,
+		Type:           "VulnerableDependency",
+		Severity:       match.Severity(dep.Ecosystem, dep.Name),
+		Message:        message,
+		File:           dep.Source,
+		Line:           dep.Line,
+		Snippet:        dep.Name + " " + dep.Version,
+		Recommendation: recommendation,
+		Confidence:     1.0,
+	}
+}
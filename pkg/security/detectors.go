@@ -100,7 +100,7 @@ type SQLInjectionDetector struct {
 // NewSQLInjectionDetector 创建SQL注入检测器
 func NewSQLInjectionDetector() *SQLInjectionDetector {
 	return &SQLInjectionDetector{
-		BaseDetector: NewBaseDetector("sql_injection", []string{"go", "python", "javascript", "java", "php", "ruby"}),
+		BaseDetector: NewBaseDetector("sql_injection", []string{"python", "javascript", "java", "php", "ruby"}),
 	}
 }
 
@@ -205,7 +205,7 @@ type PathTraversalDetector struct {
 // NewPathTraversalDetector 创建路径遍历检测器
 func NewPathTraversalDetector() *PathTraversalDetector {
 	return &PathTraversalDetector{
-		BaseDetector: NewBaseDetector("path_traversal", []string{"go", "python", "javascript", "java", "php", "ruby"}),
+		BaseDetector: NewBaseDetector("path_traversal", []string{"python", "javascript", "java", "php", "ruby"}),
 	}
 }
 
@@ -349,6 +349,25 @@ func NewDetectorRegistry() *DetectorRegistry {
 	registry.Register(NewPathTraversalDetector())
 	registry.Register(NewQualityDetector())
 
+	// Go源码使用基于语法树的检测器
+	registry.Register(NewGoSQLInjectionDetector())
+	registry.Register(NewGoPathTraversalDetector())
+	registry.Register(NewGoCommandInjectionDetector())
+	registry.Register(NewGoInsecureTLSDetector())
+	registry.Register(NewGoWeakHashDetector())
+	registry.Register(NewGoInsecureRandomDetector())
+
+	// 基础设施即代码配置检测器
+	registry.Register(NewDockerfileDetector())
+	registry.Register(NewKubernetesDetector())
+	registry.Register(NewComposeDetector())
+	registry.Register(NewTerraformDetector())
+
+	// 个人信息检测器
+	for _, detector := range NewPIIDetectors() {
+		registry.Register(detector)
+	}
+
 	return registry
 }
 
@@ -0,0 +1,501 @@
+// Package security 硬编码凭证修复
+package security
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"code-context-generator/pkg/types"
+)
+
+// envPlaceholder 写入 .env.example 的占位值
+const envPlaceholder = "changeme"
+
+// diffContextLines 统一diff中每个修改块前后的上下文行数
+const diffContextLines = 3
+
+var (
+	// credentialAssignPattern 变量赋值为字符串字面量，与凭证检测器的规则保持一致
+	credentialAssignPattern = regexp.MustCompile("([A-Za-z_][A-Za-z0-9_]*)(\\s*:?=\\s*)(\"[^\"\\n]+\"|'[^'\\n]+'|`[^`\\n]+`)")
+	// credentialNamePattern 被视为凭证的变量名
+	credentialNamePattern = regexp.MustCompile(`(?i)(password|passwd|pwd|api[_-]?key|apikey|secret|token|access[_-]?key)`)
+	// camelBoundaryPattern 驼峰命名中的单词边界
+	camelBoundaryPattern = regexp.MustCompile(`([a-z0-9])([A-Z])`)
+	// goImportOSPattern Go文件已导入 os 包
+	goImportOSPattern = regexp.MustCompile(`(?m)^\s*(import\s+)?"os"\s*$`)
+	// pythonImportOSPattern Python文件已导入 os 模块
+	pythonImportOSPattern = regexp.MustCompile(`(?m)^import\s+(.*,\s*)?os\s*(,.*)?$`)
+)
+
+// envLookups 各语言读取环境变量的表达式，%s 为变量名
+var envLookups = map[string]string{
+	"go":         `os.Getenv("%s")`,
+	"python":     `os.environ["%s"]`,
+	"javascript": `process.env.%s`,
+	"java":       `System.getenv("%s")`,
+	"php":        `getenv('%s')`,
+	"ruby":       `ENV["%s"]`,
+}
+
+// fixLanguages 文件扩展名对应的修复语言
+var fixLanguages = map[string]string{
+	".go":   "go",
+	".py":   "python",
+	".js":   "javascript",
+	".jsx":  "javascript",
+	".mjs":  "javascript",
+	".cjs":  "javascript",
+	".ts":   "javascript",
+	".tsx":  "javascript",
+	".java": "java",
+	".php":  "php",
+	".rb":   "ruby",
+}
+
+// CredentialFix 一处已修复的硬编码凭证
+type CredentialFix struct {
+	File   string
+	Line   int
+	EnvKey string
+}
+
+// SkippedFix 无法自动修复的问题及原因
+type SkippedFix struct {
+	File   string
+	Line   int
+	Reason string
+}
+
+// FixResult 凭证修复计划
+type FixResult struct {
+	Fixes      []CredentialFix
+	Skipped    []SkippedFix
+	EnvExample string            // .env.example 路径
+	Files      map[string]string // 修复后的文件内容，包括 .env.example
+	Diff       string            // 所有修改的统一diff
+}
+
+// lineEdit 以原文件行号表示的修改：从 at 开始删除 remove 行并插入 insert
+type lineEdit struct {
+	at     int
+	remove int
+	insert []string
+}
+
+// CredentialFixer 将硬编码凭证改写为读取环境变量
+type CredentialFixer struct {
+	root       string
+	envExample string
+}
+
+// NewCredentialFixer 创建凭证修复器，root 为项目根目录，.env.example 写在其中
+func NewCredentialFixer(root string) *CredentialFixer {
+	if info, err := os.Stat(root); err == nil && !info.IsDir() {
+		root = filepath.Dir(root)
+	}
+	return &CredentialFixer{
+		root:       root,
+		envExample: filepath.Join(root, ".env.example"),
+	}
+}
+
+// Plan 根据扫描发现的硬编码凭证生成修复计划，不修改任何文件
+func (f *CredentialFixer) Plan(issues []types.SecurityIssue) (*FixResult, error) {
+	result := &FixResult{
+		EnvExample: f.envExample,
+		Files:      make(map[string]string),
+	}
+
+	// 按文件分组，同一文件的修改一起生成
+	byFile := make(map[string][]types.SecurityIssue)
+	var files []string
+	for _, issue := range issues {
+		if issue.Type != "HardcodedCredentials" {
+			continue
+		}
+		if issue.Commit != "" {
+			result.Skipped = append(result.Skipped, SkippedFix{File: issue.File, Line: issue.Line, Reason: "问题位于历史提交中"})
+			continue
+		}
+		if _, ok := byFile[issue.File]; !ok {
+			files = append(files, issue.File)
+		}
+		byFile[issue.File] = append(byFile[issue.File], issue)
+	}
+	sort.Strings(files)
+
+	keyValues := make(map[string]string) // 环境变量名 -> 原字面量，用于区分同名的不同凭证
+	var newKeys []string
+	var diff strings.Builder
+
+	for _, file := range files {
+		language := fixLanguages[strings.ToLower(filepath.Ext(file))]
+		if language == "" {
+			for _, issue := range byFile[file] {
+				result.Skipped = append(result.Skipped, SkippedFix{File: file, Line: issue.Line, Reason: "不支持的语言"})
+			}
+			continue
+		}
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("读取文件失败 %s: %v", file, err)
+		}
+		lines, trailingNewline := splitLines(string(data))
+
+		var constLines map[int]bool
+		if language == "go" {
+			constLines = goConstLines(lines)
+		}
+
+		var edits []lineEdit
+		seen := make(map[int]bool)
+		for _, issue := range byFile[file] {
+			if seen[issue.Line] {
+				continue
+			}
+			seen[issue.Line] = true
+
+			if issue.Line < 1 || issue.Line > len(lines) {
+				result.Skipped = append(result.Skipped, SkippedFix{File: file, Line: issue.Line, Reason: "行号超出文件范围"})
+				continue
+			}
+			line := lines[issue.Line-1]
+			if constLines[issue.Line] {
+				result.Skipped = append(result.Skipped, SkippedFix{File: file, Line: issue.Line, Reason: "常量无法改为运行时读取"})
+				continue
+			}
+
+			loc := findCredentialLiteral(line)
+			if loc == nil {
+				result.Skipped = append(result.Skipped, SkippedFix{File: file, Line: issue.Line, Reason: "未找到可替换的字符串字面量"})
+				continue
+			}
+
+			name, literal := line[loc[2]:loc[3]], line[loc[6]:loc[7]]
+			key := envKeyName(name)
+			if existing, ok := keyValues[key]; ok && existing != literal {
+				key += "_" + envKeyName(strings.TrimSuffix(filepath.Base(file), filepath.Ext(file)))
+			}
+			if _, ok := keyValues[key]; !ok {
+				keyValues[key] = literal
+				newKeys = append(newKeys, key)
+			}
+
+			fixed := line[:loc[6]] + fmt.Sprintf(envLookups[language], key) + line[loc[7]:]
+			edits = append(edits, lineEdit{at: issue.Line - 1, remove: 1, insert: []string{fixed}})
+			result.Fixes = append(result.Fixes, CredentialFix{File: file, Line: issue.Line, EnvKey: key})
+		}
+
+		if len(edits) == 0 {
+			continue
+		}
+		edits = append(edits, importEdits(language, string(data), lines)...)
+
+		newLines := applyEdits(lines, edits)
+		result.Files[file] = joinLines(newLines, trailingNewline)
+		diff.WriteString(unifiedDiff(f.displayPath(file), f.displayPath(file), lines, edits))
+	}
+
+	// 将新的环境变量追加到 .env.example
+	if len(newKeys) > 0 {
+		envEdit, envLines, trailing, exists, err := f.envExampleEdit(newKeys)
+		if err != nil {
+			return nil, err
+		}
+		if envEdit != nil {
+			edits := []lineEdit{*envEdit}
+			result.Files[f.envExample] = joinLines(applyEdits(envLines, edits), true)
+			oldName := f.displayPath(f.envExample)
+			if !exists {
+				oldName = ""
+			} else if !trailing && len(envLines) > 0 {
+				// 原文件末尾没有换行，最后一行随追加内容一起改变
+				last := len(envLines) - 1
+				edits = []lineEdit{{at: last, remove: 1, insert: append([]string{envLines[last]}, envEdit.insert...)}}
+			}
+			diff.WriteString(unifiedDiff(oldName, f.displayPath(f.envExample), envLines, edits))
+		}
+	}
+
+	result.Diff = diff.String()
+	return result, nil
+}
+
+// Apply 将修复计划写入文件
+func (r *FixResult) Apply() error {
+	paths := make([]string, 0, len(r.Files))
+	for path := range r.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		mode := os.FileMode(0644)
+		if info, err := os.Stat(path); err == nil {
+			mode = info.Mode().Perm()
+		}
+		if err := os.WriteFile(path, []byte(r.Files[path]), mode); err != nil {
+			return fmt.Errorf("写入文件失败 %s: %v", path, err)
+		}
+	}
+	return nil
+}
+
+// envExampleEdit 生成向 .env.example 追加缺失变量的修改，所有变量都已存在时返回nil
+func (f *CredentialFixer) envExampleEdit(keys []string) (*lineEdit, []string, bool, bool, error) {
+	var lines []string
+	trailing, exists := true, false
+
+	data, err := os.ReadFile(f.envExample)
+	if err == nil {
+		exists = true
+		lines, trailing = splitLines(string(data))
+	} else if !os.IsNotExist(err) {
+		return nil, nil, false, false, fmt.Errorf("读取 .env.example 失败: %v", err)
+	}
+
+	present := make(map[string]bool)
+	for _, line := range lines {
+		line = strings.TrimPrefix(strings.TrimSpace(line), "export ")
+		if name, _, ok := strings.Cut(line, "="); ok {
+			present[strings.TrimSpace(name)] = true
+		}
+	}
+
+	var insert []string
+	for _, key := range keys {
+		if !present[key] {
+			insert = append(insert, key+"="+envPlaceholder)
+		}
+	}
+	if len(insert) == 0 {
+		return nil, lines, trailing, exists, nil
+	}
+	return &lineEdit{at: len(lines), insert: insert}, lines, trailing, exists, nil
+}
+
+// displayPath 返回diff中显示的相对路径
+func (f *CredentialFixer) displayPath(path string) string {
+	if rel, err := filepath.Rel(f.root, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(path)
+}
+
+// goConstLines 返回Go源码中属于常量声明的行号（从1开始），包括分组的 const ( ... ) 块
+func goConstLines(lines []string) map[int]bool {
+	constLines := make(map[int]bool)
+	inBlock := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case inBlock:
+			constLines[i+1] = true
+			if strings.HasPrefix(trimmed, ")") {
+				inBlock = false
+			}
+		case strings.HasPrefix(trimmed, "const ") || strings.HasPrefix(trimmed, "const\t") || strings.HasPrefix(trimmed, "const("):
+			constLines[i+1] = true
+			rest := strings.TrimSpace(trimmed[len("const"):])
+			inBlock = strings.HasPrefix(rest, "(") && !strings.HasSuffix(rest, ")")
+		}
+	}
+	return constLines
+}
+
+// findCredentialLiteral 查找行中赋值给凭证变量的字符串字面量，返回子匹配位置
+func findCredentialLiteral(line string) []int {
+	for _, loc := range credentialAssignPattern.FindAllStringSubmatchIndex(line, -1) {
+		// 排除比较运算符，例如 password == "x"
+		if loc[0] > 0 && strings.ContainsAny(line[loc[0]-1:loc[0]], "=!<>") {
+			continue
+		}
+		if credentialNamePattern.MatchString(line[loc[2]:loc[3]]) {
+			return loc
+		}
+	}
+	return nil
+}
+
+// envKeyName 将变量名转换为大写下划线形式的环境变量名
+func envKeyName(name string) string {
+	name = camelBoundaryPattern.ReplaceAllString(name, "${1}_${2}")
+	name = strings.NewReplacer("-", "_", ".", "_", " ", "_").Replace(name)
+	return strings.Trim(strings.ToUpper(name), "_")
+}
+
+// importEdits 返回读取环境变量所需的导入语句修改
+func importEdits(language, content string, lines []string) []lineEdit {
+	switch language {
+	case "go":
+		if goImportOSPattern.MatchString(content) {
+			return nil
+		}
+		// 按 gofmt 的排序插入到导入块的第一组中
+		for i, line := range lines {
+			if strings.TrimSpace(line) != "import (" {
+				continue
+			}
+			at := i + 1
+			for ; at < len(lines); at++ {
+				spec := strings.Fields(lines[at])
+				if len(spec) == 0 || spec[0] == ")" || spec[len(spec)-1] > `"os"` {
+					break
+				}
+			}
+			return []lineEdit{{at: at, insert: []string{"\t\"os\""}}}
+		}
+		for i, line := range lines {
+			if strings.HasPrefix(line, "package ") {
+				return []lineEdit{{at: i + 1, insert: []string{"", `import "os"`}}}
+			}
+		}
+	case "python":
+		if pythonImportOSPattern.MatchString(content) {
+			return nil
+		}
+		// 插入到第一条导入语句之前，没有导入时跳过 shebang 和编码声明
+		at := 0
+		for i, line := range lines {
+			if strings.HasPrefix(line, "from __future__ ") {
+				at = i + 1
+				continue
+			}
+			if strings.HasPrefix(line, "import ") || strings.HasPrefix(line, "from ") {
+				return []lineEdit{{at: i, insert: []string{"import os"}}}
+			}
+			if at == i && strings.HasPrefix(line, "#") {
+				at = i + 1
+			}
+		}
+		return []lineEdit{{at: at, insert: []string{"import os"}}}
+	}
+	return nil
+}
+
+// splitLines 按行拆分内容，返回内容是否以换行结尾
+func splitLines(content string) ([]string, bool) {
+	if content == "" {
+		return nil, true
+	}
+	trailing := strings.HasSuffix(content, "\n")
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n"), trailing
+}
+
+// joinLines 拼接行
+func joinLines(lines []string, trailingNewline bool) string {
+	content := strings.Join(lines, "\n")
+	if trailingNewline && len(lines) > 0 {
+		content += "\n"
+	}
+	return content
+}
+
+// sortEdits 按位置排序修改，同一位置的插入排在替换之前
+func sortEdits(edits []lineEdit) []lineEdit {
+	sorted := append([]lineEdit(nil), edits...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].at != sorted[j].at {
+			return sorted[i].at < sorted[j].at
+		}
+		return sorted[i].remove < sorted[j].remove
+	})
+	return sorted
+}
+
+// applyEdits 将修改应用到行列表
+func applyEdits(lines []string, edits []lineEdit) []string {
+	var result []string
+	next := 0
+	for _, edit := range sortEdits(edits) {
+		result = append(result, lines[next:edit.at]...)
+		result = append(result, edit.insert...)
+		next = edit.at + edit.remove
+	}
+	return append(result, lines[next:]...)
+}
+
+// unifiedDiff 根据修改生成统一diff，oldName 为空表示新文件
+func unifiedDiff(oldName, newName string, lines []string, edits []lineEdit) string {
+	edits = sortEdits(edits)
+	if len(edits) == 0 {
+		return ""
+	}
+
+	var builder strings.Builder
+	if oldName == "" {
+		builder.WriteString("--- /dev/null\n")
+	} else {
+		builder.WriteString(fmt.Sprintf("--- a/%s\n", oldName))
+	}
+	builder.WriteString(fmt.Sprintf("+++ b/%s\n", newName))
+
+	// 相距较近的修改合并到同一个块中
+	var groups [][]lineEdit
+	for _, edit := range edits {
+		if n := len(groups); n > 0 {
+			last := groups[n-1][len(groups[n-1])-1]
+			if edit.at-(last.at+last.remove) <= 2*diffContextLines {
+				groups[n-1] = append(groups[n-1], edit)
+				continue
+			}
+		}
+		groups = append(groups, []lineEdit{edit})
+	}
+
+	offset := 0
+	for _, group := range groups {
+		first, last := group[0], group[len(group)-1]
+		start := first.at - diffContextLines
+		if start < 0 {
+			start = 0
+		}
+		end := last.at + last.remove + diffContextLines
+		if end > len(lines) {
+			end = len(lines)
+		}
+
+		var body strings.Builder
+		oldCount, newCount := 0, 0
+		i := start
+		for _, edit := range group {
+			for ; i < edit.at; i++ {
+				body.WriteString(" " + lines[i] + "\n")
+				oldCount++
+				newCount++
+			}
+			for _, removed := range lines[edit.at : edit.at+edit.remove] {
+				body.WriteString("-" + removed + "\n")
+				oldCount++
+			}
+			for _, inserted := range edit.insert {
+				body.WriteString("+" + inserted + "\n")
+				newCount++
+			}
+			i = edit.at + edit.remove
+		}
+		for ; i < end; i++ {
+			body.WriteString(" " + lines[i] + "\n")
+			oldCount++
+			newCount++
+		}
+
+		builder.WriteString(fmt.Sprintf("@@ -%s +%s @@\n", hunkRange(start, oldCount), hunkRange(start+offset, newCount)))
+		builder.WriteString(body.String())
+		offset += newCount - oldCount
+	}
+	return builder.String()
+}
+
+// hunkRange 格式化diff块的行范围，空范围按惯例使用前一行的行号
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
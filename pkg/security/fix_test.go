@@ -0,0 +1,136 @@
+// Package security 凭证修复测试
+package security
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"code-context-generator/pkg/types"
+)
+
+// TestCredentialFixer 测试各语言的凭证改写、.env.example 和diff
+func TestCredentialFixer(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"main.go":   "package main\n\nimport (\n\t\"fmt\"\n\t\"strings\"\n)\n\nvar dbPassword = \"hunter2hunter2\"\n\nfunc main() { fmt.Println(strings.ToUpper(dbPassword)) }\n",
+		"const.go":  "package main\n\nconst apiKey = \"abcdef123456\"\n",
+		"app.py":    "from __future__ import annotations\nimport sys\n\napi_key = 'abcd1234efgh'\n",
+		"app.js":    "const secret = \"s3cr3tvalue\";\n",
+		"notes.txt": "password = \"not-code\"\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".env.example"), []byte("SECRET=changeme\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	issue := func(name string, line int) types.SecurityIssue {
+		return types.SecurityIssue{Type: "HardcodedCredentials", File: filepath.Join(dir, name), Line: line}
+	}
+	issues := []types.SecurityIssue{
+		issue("main.go", 8), issue("const.go", 3), issue("app.py", 4), issue("app.js", 1), issue("notes.txt", 1),
+		{Type: "SQLInjection", File: filepath.Join(dir, "app.py"), Line: 1},
+	}
+
+	result, err := NewCredentialFixer(dir).Plan(issues)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	if len(result.Fixes) != 3 {
+		t.Errorf("期望修复 3 处凭证，实际 %d 处", len(result.Fixes))
+	}
+	if len(result.Skipped) != 2 {
+		t.Errorf("期望跳过常量和不支持的语言，实际 %+v", result.Skipped)
+	}
+
+	// 预览不修改文件
+	data, _ := os.ReadFile(filepath.Join(dir, "app.js"))
+	if string(data) != files["app.js"] {
+		t.Error("Plan() 不应修改文件")
+	}
+
+	if err := result.Apply(); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	expected := map[string]string{
+		"main.go":      "package main\n\nimport (\n\t\"fmt\"\n\t\"os\"\n\t\"strings\"\n)\n\nvar dbPassword = os.Getenv(\"DB_PASSWORD\")\n\nfunc main() { fmt.Println(strings.ToUpper(dbPassword)) }\n",
+		"app.py":       "from __future__ import annotations\nimport os\nimport sys\n\napi_key = os.environ[\"API_KEY\"]\n",
+		"app.js":       "const secret = process.env.SECRET;\n",
+		".env.example": "SECRET=changeme\nAPI_KEY=changeme\nDB_PASSWORD=changeme\n",
+	}
+	for name, want := range expected {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != want {
+			t.Errorf("%s 修复结果不正确:\n%s\n期望:\n%s", name, data, want)
+		}
+	}
+
+	for _, s := range []string{
+		"--- a/main.go\n+++ b/main.go\n@@ -2,9 +2,10 @@\n",
+		"-var dbPassword = \"hunter2hunter2\"\n+var dbPassword = os.Getenv(\"DB_PASSWORD\")\n",
+		"--- a/.env.example\n+++ b/.env.example\n@@ -1,1 +1,3 @@\n SECRET=changeme\n+API_KEY=changeme\n",
+	} {
+		if !strings.Contains(result.Diff, s) {
+			t.Errorf("diff 应该包含 %q，实际:\n%s", s, result.Diff)
+		}
+	}
+}
+
+// TestCredentialFixerGroupedConst 测试跳过分组常量块中的凭证，块之后的变量仍然修复
+func TestCredentialFixerGroupedConst(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.go")
+	content := "package main\n\nconst (\n\tapiKey = \"abcdef123456\"\n\ttoken  = \"zyxw98765432\"\n)\n\nvar dbPassword = \"hunter2hunter2\"\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	issues := []types.SecurityIssue{
+		{Type: "HardcodedCredentials", File: path, Line: 4},
+		{Type: "HardcodedCredentials", File: path, Line: 5},
+		{Type: "HardcodedCredentials", File: path, Line: 8},
+	}
+	result, err := NewCredentialFixer(dir).Plan(issues)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	if len(result.Fixes) != 1 || result.Fixes[0].Line != 8 {
+		t.Errorf("期望只修复第 8 行的变量，实际 %+v", result.Fixes)
+	}
+	if len(result.Skipped) != 2 || result.Skipped[0].Reason != "常量无法改为运行时读取" {
+		t.Errorf("期望跳过常量块中的 2 处凭证，实际 %+v", result.Skipped)
+	}
+}
+
+// TestUnifiedDiffNewFile 测试新文件的diff头和行范围
+func TestUnifiedDiffNewFile(t *testing.T) {
+	diff := unifiedDiff("", ".env.example", nil, []lineEdit{{at: 0, insert: []string{"A=changeme"}}})
+	want := "--- /dev/null\n+++ b/.env.example\n@@ -0,0 +1,1 @@\n+A=changeme\n"
+	if diff != want {
+		t.Errorf("unifiedDiff() = %q, want %q", diff, want)
+	}
+}
+
+// TestEnvKeyName 测试变量名到环境变量名的转换
+func TestEnvKeyName(t *testing.T) {
+	tests := map[string]string{
+		"dbPassword":  "DB_PASSWORD",
+		"api_key":     "API_KEY",
+		"AWSSecret":   "AWSSECRET",
+		"githubToken": "GITHUB_TOKEN",
+	}
+	for name, want := range tests {
+		if got := envKeyName(name); got != want {
+			t.Errorf("envKeyName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
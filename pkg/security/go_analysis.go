@@ -0,0 +1,428 @@
+// Package security 实现安全扫描功能
+package security
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	gotypes "go/types"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"code-context-generator/pkg/types"
+)
+
+// goSource 已解析并完成类型检查的Go源文件
+type goSource struct {
+	path    string
+	fset    *token.FileSet
+	file    *ast.File
+	info    *gotypes.Info
+	lines   []string
+	parents map[ast.Node]ast.Node
+	assigns map[gotypes.Object][]goAssignment
+	sources map[gotypes.Object]bool
+}
+
+// goAssignment 变量的一次赋值
+type goAssignment struct {
+	rhs ast.Expr
+	op  token.Token
+}
+
+// requestTypes 被视为用户输入来源的参数类型（导入路径 -> 类型名）
+var requestTypes = map[string]string{
+	"net/http":                    "Request",
+	"github.com/gin-gonic/gin":    "Context",
+	"github.com/labstack/echo":    "Context",
+	"github.com/labstack/echo/v4": "Context",
+	"github.com/gofiber/fiber/v2": "Ctx",
+}
+
+// versionSuffix 匹配导入路径中的主版本后缀
+var versionSuffix = regexp.MustCompile(`^v[0-9]+$`)
+
+// stubImporter 不加载真实依赖的导入器，只提供包名以便解析标识符
+type stubImporter struct {
+	packages map[string]*gotypes.Package
+}
+
+// Import 返回一个只有名称的空包
+func (im *stubImporter) Import(path string) (*gotypes.Package, error) {
+	if pkg, ok := im.packages[path]; ok {
+		return pkg, nil
+	}
+	pkg := gotypes.NewPackage(path, guessPackageName(path))
+	pkg.MarkComplete()
+	im.packages[path] = pkg
+	return pkg, nil
+}
+
+// guessPackageName 根据导入路径推断包名
+func guessPackageName(path string) string {
+	parts := strings.Split(path, "/")
+	name := parts[len(parts)-1]
+	if versionSuffix.MatchString(name) && len(parts) > 1 {
+		name = parts[len(parts)-2]
+	}
+	// gopkg.in/yaml.v3 形式
+	if idx := strings.Index(name, ".v"); idx > 0 {
+		name = name[:idx]
+	}
+	name = strings.TrimPrefix(name, "go-")
+	return strings.ReplaceAll(name, "-", "_")
+}
+
+// parseGoSource 解析Go源码并做宽松的类型检查，语法完全无法解析时返回nil
+func parseGoSource(filePath string, content string) *goSource {
+	fset := token.NewFileSet()
+	file, _ := parser.ParseFile(fset, filePath, content, parser.SkipObjectResolution)
+	if file == nil || file.Name == nil {
+		return nil
+	}
+
+	info := &gotypes.Info{
+		Types: make(map[ast.Expr]gotypes.TypeAndValue),
+		Defs:  make(map[*ast.Ident]gotypes.Object),
+		Uses:  make(map[*ast.Ident]gotypes.Object),
+	}
+	conf := gotypes.Config{
+		Importer:    &stubImporter{packages: make(map[string]*gotypes.Package)},
+		FakeImportC: true,
+		Error:       func(error) {}, // 依赖缺失导致的类型错误是预期的，忽略即可
+	}
+	conf.Check(file.Name.Name, fset, []*ast.File{file}, info)
+
+	src := &goSource{
+		path:    filePath,
+		fset:    fset,
+		file:    file,
+		info:    info,
+		lines:   strings.Split(content, "\n"),
+		parents: make(map[ast.Node]ast.Node),
+		assigns: make(map[gotypes.Object][]goAssignment),
+		sources: make(map[gotypes.Object]bool),
+	}
+	src.index()
+	return src
+}
+
+// index 建立父节点、赋值和输入来源索引
+func (s *goSource) index() {
+	var stack []ast.Node
+	ast.Inspect(s.file, func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		if len(stack) > 0 {
+			s.parents[n] = stack[len(stack)-1]
+		}
+		stack = append(stack, n)
+
+		switch node := n.(type) {
+		case *ast.AssignStmt:
+			for i, lhs := range node.Lhs {
+				obj := s.objectOf(lhs)
+				if obj == nil {
+					continue
+				}
+				rhs := node.Rhs[0]
+				if len(node.Rhs) == len(node.Lhs) {
+					rhs = node.Rhs[i]
+				}
+				s.assigns[obj] = append(s.assigns[obj], goAssignment{rhs: rhs, op: node.Tok})
+			}
+		case *ast.ValueSpec:
+			for i, name := range node.Names {
+				obj := s.info.Defs[name]
+				if obj == nil || len(node.Values) == 0 {
+					continue
+				}
+				rhs := node.Values[0]
+				if len(node.Values) == len(node.Names) {
+					rhs = node.Values[i]
+				}
+				s.assigns[obj] = append(s.assigns[obj], goAssignment{rhs: rhs, op: token.DEFINE})
+			}
+		case *ast.RangeStmt:
+			for _, key := range []ast.Expr{node.Key, node.Value} {
+				if obj := s.objectOf(key); obj != nil {
+					s.assigns[obj] = append(s.assigns[obj], goAssignment{rhs: node.X, op: token.DEFINE})
+				}
+			}
+		case *ast.FuncType:
+			if node.Params == nil {
+				break
+			}
+			for _, field := range node.Params.List {
+				if !s.isRequestType(field.Type) {
+					continue
+				}
+				for _, name := range field.Names {
+					if obj := s.info.Defs[name]; obj != nil {
+						s.sources[obj] = true
+					}
+				}
+			}
+		}
+		return true
+	})
+}
+
+// objectOf 返回标识符表达式对应的对象
+func (s *goSource) objectOf(expr ast.Expr) gotypes.Object {
+	ident, ok := expr.(*ast.Ident)
+	if !ok || ident == nil || ident.Name == "_" {
+		return nil
+	}
+	if obj := s.info.Defs[ident]; obj != nil {
+		return obj
+	}
+	return s.info.Uses[ident]
+}
+
+// pkgPathOf 如果表达式是导入的包名，返回其导入路径
+func (s *goSource) pkgPathOf(expr ast.Expr) string {
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return ""
+	}
+	if pkgName, ok := s.info.Uses[ident].(*gotypes.PkgName); ok {
+		return pkgName.Imported().Path()
+	}
+	return ""
+}
+
+// callee 返回调用的包路径和函数名，方法调用的包路径为空
+func (s *goSource) callee(call *ast.CallExpr) (string, string) {
+	switch fun := call.Fun.(type) {
+	case *ast.SelectorExpr:
+		return s.pkgPathOf(fun.X), fun.Sel.Name
+	case *ast.Ident:
+		return "", fun.Name
+	}
+	return "", ""
+}
+
+// imports 检查文件是否导入了指定路径
+func (s *goSource) imports(path string) bool {
+	for _, spec := range s.file.Imports {
+		if strings.Trim(spec.Path.Value, "\"`") == path {
+			return true
+		}
+	}
+	return false
+}
+
+// isRequestType 检查参数类型是否为HTTP请求上下文
+func (s *goSource) isRequestType(expr ast.Expr) bool {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	typeName, ok := requestTypes[s.pkgPathOf(sel.X)]
+	return ok && sel.Sel.Name == typeName
+}
+
+// isConstant 检查表达式是否为编译期常量
+func (s *goSource) isConstant(expr ast.Expr) bool {
+	tv, ok := s.info.Types[expr]
+	return ok && tv.Value != nil
+}
+
+// isDynamicString 检查表达式是否由拼接或格式化构造出的非常量字符串
+func (s *goSource) isDynamicString(expr ast.Expr, visited map[gotypes.Object]bool) bool {
+	if expr == nil || s.isConstant(expr) {
+		return false
+	}
+
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return s.isDynamicString(e.X, visited)
+	case *ast.BinaryExpr:
+		return e.Op == token.ADD
+	case *ast.CallExpr:
+		pkg, name := s.callee(e)
+		if pkg == "fmt" && name == "Sprintf" && len(e.Args) > 1 {
+			for _, arg := range e.Args[1:] {
+				if !s.isConstant(arg) {
+					return true
+				}
+			}
+		}
+		if pkg == "strings" && name == "Join" {
+			return true
+		}
+	case *ast.Ident:
+		obj := s.objectOf(e)
+		if obj == nil || visited[obj] {
+			return false
+		}
+		visited[obj] = true
+		for _, assign := range s.assigns[obj] {
+			if assign.op == token.ADD_ASSIGN && !s.isConstant(assign.rhs) {
+				return true
+			}
+			if s.isDynamicString(assign.rhs, visited) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isTainted 检查表达式是否来自用户可控的输入
+func (s *goSource) isTainted(expr ast.Expr, visited map[gotypes.Object]bool) bool {
+	if expr == nil || s.isConstant(expr) {
+		return false
+	}
+
+	switch e := expr.(type) {
+	case *ast.Ident:
+		obj := s.objectOf(e)
+		if obj == nil || visited[obj] {
+			return false
+		}
+		if s.sources[obj] {
+			return true
+		}
+		visited[obj] = true
+		for _, assign := range s.assigns[obj] {
+			if s.isTainted(assign.rhs, visited) {
+				return true
+			}
+		}
+	case *ast.SelectorExpr:
+		if s.pkgPathOf(e.X) == "os" && e.Sel.Name == "Args" {
+			return true
+		}
+		return s.isTainted(e.X, visited)
+	case *ast.CallExpr:
+		pkg, name := s.callee(e)
+		if (pkg == "path/filepath" || pkg == "path") && name == "Base" {
+			return false // 只保留文件名，已消除目录穿越
+		}
+		if sel, ok := e.Fun.(*ast.SelectorExpr); ok && pkg == "" && s.isTainted(sel.X, visited) {
+			return sel.Sel.Name != "Context"
+		}
+		for _, arg := range e.Args {
+			if s.isTainted(arg, visited) {
+				return true
+			}
+		}
+	case *ast.BinaryExpr:
+		return s.isTainted(e.X, visited) || s.isTainted(e.Y, visited)
+	case *ast.IndexExpr:
+		return s.isTainted(e.X, visited)
+	case *ast.SliceExpr:
+		return s.isTainted(e.X, visited)
+	case *ast.ParenExpr:
+		return s.isTainted(e.X, visited)
+	case *ast.StarExpr:
+		return s.isTainted(e.X, visited)
+	case *ast.UnaryExpr:
+		return s.isTainted(e.X, visited)
+	}
+	return false
+}
+
+// enclosingFunc 返回节点所在的函数名和函数体
+func (s *goSource) enclosingFunc(n ast.Node) (string, *ast.BlockStmt) {
+	for p := s.parents[n]; p != nil; p = s.parents[p] {
+		switch fn := p.(type) {
+		case *ast.FuncDecl:
+			return fn.Name.Name, fn.Body
+		case *ast.FuncLit:
+			name, _ := s.enclosingFunc(fn)
+			return name, fn.Body
+		}
+	}
+	return "", nil
+}
+
+// enclosingStmt 返回包含节点的最内层语句
+func (s *goSource) enclosingStmt(n ast.Node) ast.Node {
+	for p := s.parents[n]; p != nil; p = s.parents[p] {
+		switch p.(type) {
+		case *ast.BlockStmt, *ast.CaseClause, *ast.CommClause:
+			return n
+		case *ast.GenDecl:
+			return p
+		}
+		n = p
+	}
+	return n
+}
+
+// contextWords 收集节点所在函数名和语句中标识符拆分后的单词
+func (s *goSource) contextWords(n ast.Node) []string {
+	var words []string
+	name, _ := s.enclosingFunc(n)
+	words = append(words, splitIdentifier(name)...)
+	ast.Inspect(s.enclosingStmt(n), func(node ast.Node) bool {
+		switch v := node.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.Ident:
+			if _, isPkg := s.info.Uses[v].(*gotypes.PkgName); !isPkg {
+				words = append(words, splitIdentifier(v.Name)...)
+			}
+		}
+		return true
+	})
+	return words
+}
+
+// splitIdentifier 按驼峰和下划线拆分标识符为小写单词
+func splitIdentifier(name string) []string {
+	var words []string
+	var current []rune
+	runes := []rune(name)
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, strings.ToLower(string(current)))
+			current = current[:0]
+		}
+	}
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == '-' || unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r) && len(current) > 0 &&
+			(unicode.IsLower(current[len(current)-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))):
+			flush()
+			current = append(current, r)
+		default:
+			current = append(current, r)
+		}
+	}
+	flush()
+	return words
+}
+
+// hasAnyWord 检查单词列表中是否包含任一关键字
+func hasAnyWord(words []string, keywords map[string]bool) bool {
+	for _, word := range words {
+		if keywords[word] {
+			return true
+		}
+	}
+	return false
+}
+
+// issueAt 在指定位置创建安全问题，行列号来自语法树
+func (s *goSource) issueAt(pos token.Pos, issue types.SecurityIssue) types.SecurityIssue {
+	position := s.fset.Position(pos)
+	issue.File = s.path
+	issue.Line = position.Line
+	issue.Column = position.Column
+	if position.Line > 0 && position.Line <= len(s.lines) {
+		issue.Snippet = strings.TrimSpace(s.lines[position.Line-1])
+	}
+	return issue
+}
@@ -0,0 +1,469 @@
+// Package security 实现安全扫描功能
+package security
+
+import (
+	"go/ast"
+	gotypes "go/types"
+	"strings"
+
+	"code-context-generator/pkg/types"
+)
+
+// sqlQueryMethods database/sql 中接收SQL语句的方法及语句参数位置
+var sqlQueryMethods = map[string]int{
+	"Query":           0,
+	"QueryRow":        0,
+	"Exec":            0,
+	"Prepare":         0,
+	"QueryContext":    1,
+	"QueryRowContext": 1,
+	"ExecContext":     1,
+	"PrepareContext":  1,
+}
+
+// sqlxQueryMethods sqlx 额外提供的查询方法及语句参数位置
+var sqlxQueryMethods = map[string]int{
+	"Queryx":        0,
+	"QueryRowx":     0,
+	"MustExec":      0,
+	"Select":        1,
+	"Get":           1,
+	"SelectContext": 2,
+	"GetContext":    2,
+}
+
+// fileSinks 接收文件路径的函数（导入路径 -> 函数名 -> 路径参数位置）
+var fileSinks = map[string]map[string]int{
+	"os": {
+		"Open": 0, "OpenFile": 0, "ReadFile": 0, "WriteFile": 0, "Create": 0,
+		"Remove": 0, "RemoveAll": 0, "ReadDir": 0, "Mkdir": 0, "MkdirAll": 0,
+	},
+	"io/ioutil": {"ReadFile": 0, "WriteFile": 0, "ReadDir": 0},
+	"net/http":  {"ServeFile": 2},
+}
+
+// shellNames 通过 -c 执行字符串命令的解释器
+var shellNames = map[string]string{
+	"sh": "-c", "bash": "-c", "zsh": "-c", "/bin/sh": "-c", "/bin/bash": "-c", "/usr/bin/env": "",
+	"cmd": "/c", "cmd.exe": "/c", "powershell": "-command", "powershell.exe": "-command", "pwsh": "-command",
+}
+
+// weakHashPackages 不应用于安全用途的哈希算法包
+var weakHashPackages = map[string]string{
+	"crypto/md5":  "MD5",
+	"crypto/sha1": "SHA1",
+}
+
+// hashSecurityWords 表明哈希用于安全用途的标识符单词
+var hashSecurityWords = map[string]bool{
+	"password": true, "passwd": true, "pwd": true, "secret": true, "token": true,
+	"auth": true, "credential": true, "credentials": true, "signature": true, "sign": true,
+	"salt": true, "nonce": true, "session": true, "apikey": true, "otp": true, "csrf": true,
+}
+
+// randomSecurityWords 表明随机数用于安全用途的标识符单词
+var randomSecurityWords = map[string]bool{
+	"password": true, "passwd": true, "pwd": true, "secret": true, "token": true,
+	"auth": true, "credential": true, "salt": true, "nonce": true, "session": true,
+	"key": true, "apikey": true, "otp": true, "csrf": true, "iv": true, "reset": true,
+}
+
+// goLanguages Go语法树检测器支持的语言
+var goLanguages = []string{"go"}
+
+// goSourceDetector 可以直接使用已解析源文件的检测器，扫描器对每个Go文件只解析一次
+type goSourceDetector interface {
+	detectSource(src *goSource) []types.SecurityIssue
+}
+
+// GoSQLInjectionDetector 基于语法树的Go SQL注入检测器
+type GoSQLInjectionDetector struct {
+	*BaseDetector
+}
+
+// NewGoSQLInjectionDetector 创建Go SQL注入检测器
+func NewGoSQLInjectionDetector() *GoSQLInjectionDetector {
+	return &GoSQLInjectionDetector{
+		BaseDetector: NewBaseDetector("go_sql_injection", goLanguages),
+	}
+}
+
+// Detect 检测拼接或格式化后传入 database/sql 查询的语句
+func (d *GoSQLInjectionDetector) Detect(filePath string, content string) []types.SecurityIssue {
+	return d.detectSource(parseGoSource(filePath, content))
+}
+
+// detectSource 在已解析的源文件上执行检测，供扫描器共享同一次解析
+func (d *GoSQLInjectionDetector) detectSource(src *goSource) []types.SecurityIssue {
+	if src == nil {
+		return nil
+	}
+	usesSQL := src.imports("database/sql")
+	usesSQLX := src.imports("github.com/jmoiron/sqlx")
+	if !usesSQL && !usesSQLX {
+		return nil
+	}
+
+	var issues []types.SecurityIssue
+	ast.Inspect(src.file, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		pkg, name := src.callee(call)
+		if pkg != "" {
+			return true // 包级函数，不是查询方法
+		}
+		index, ok := sqlQueryMethods[name]
+		if !ok && usesSQLX {
+			index, ok = sqlxQueryMethods[name]
+		}
+		if !ok || index >= len(call.Args) {
+			return true
+		}
+		query := call.Args[index]
+		if src.isDynamicString(query, make(map[gotypes.Object]bool)) {
+			issues = append(issues, src.issueAt(query.Pos(), types.SecurityIssue{
+				ID:             "GO_SQL_INJECTION_001",
+				Type:           "SQLInjection",
+				Severity:       types.SeverityHigh,
+				Message:        "SQL语句由字符串拼接或格式化构造后传入 " + name,
+				Recommendation: "使用占位符参数（? 或 $1）传递变量，不要拼接SQL语句",
+				Confidence:     0.9,
+			}))
+		}
+		return true
+	})
+
+	return issues
+}
+
+// GoPathTraversalDetector 基于语法树的Go路径遍历检测器
+type GoPathTraversalDetector struct {
+	*BaseDetector
+}
+
+// NewGoPathTraversalDetector 创建Go路径遍历检测器
+func NewGoPathTraversalDetector() *GoPathTraversalDetector {
+	return &GoPathTraversalDetector{
+		BaseDetector: NewBaseDetector("go_path_traversal", goLanguages),
+	}
+}
+
+// Detect 检测用户可控的路径到达文件操作函数
+func (d *GoPathTraversalDetector) Detect(filePath string, content string) []types.SecurityIssue {
+	return d.detectSource(parseGoSource(filePath, content))
+}
+
+// detectSource 在已解析的源文件上执行检测，供扫描器共享同一次解析
+func (d *GoPathTraversalDetector) detectSource(src *goSource) []types.SecurityIssue {
+	if src == nil {
+		return nil
+	}
+
+	var issues []types.SecurityIssue
+	ast.Inspect(src.file, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		pkg, name := src.callee(call)
+		index, ok := fileSinks[pkg][name]
+		if !ok || index >= len(call.Args) {
+			return true
+		}
+		arg := call.Args[index]
+		if !src.isTainted(arg, make(map[gotypes.Object]bool)) || d.isPrefixChecked(src, call, arg) {
+			return true
+		}
+		issues = append(issues, src.issueAt(arg.Pos(), types.SecurityIssue{
+			ID:             "GO_PATH_TRAVERSAL_001",
+			Type:           "PathTraversal",
+			Severity:       types.SeverityHigh,
+			Message:        "用户可控的路径传入 " + shortPackage(pkg) + "." + name,
+			Recommendation: "使用 filepath.Clean 规范化后校验路径前缀，或仅使用 filepath.Base 保留文件名",
+			Confidence:     0.85,
+		}))
+		return true
+	})
+
+	return issues
+}
+
+// isPrefixChecked 检查所在函数是否对该路径变量做过 strings.HasPrefix 校验
+func (d *GoPathTraversalDetector) isPrefixChecked(src *goSource, call *ast.CallExpr, arg ast.Expr) bool {
+	obj := src.objectOf(arg)
+	_, body := src.enclosingFunc(call)
+	if obj == nil || body == nil {
+		return false
+	}
+
+	checked := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		guard, ok := n.(*ast.CallExpr)
+		if !ok || checked {
+			return !checked
+		}
+		pkg, name := src.callee(guard)
+		if pkg == "strings" && name == "HasPrefix" && len(guard.Args) > 0 && src.objectOf(guard.Args[0]) == obj {
+			checked = true
+		}
+		return true
+	})
+	return checked
+}
+
+// GoCommandInjectionDetector 基于语法树的Go命令注入检测器
+type GoCommandInjectionDetector struct {
+	*BaseDetector
+}
+
+// NewGoCommandInjectionDetector 创建Go命令注入检测器
+func NewGoCommandInjectionDetector() *GoCommandInjectionDetector {
+	return &GoCommandInjectionDetector{
+		BaseDetector: NewBaseDetector("go_command_injection", goLanguages),
+	}
+}
+
+// Detect 检测通过 sh -c 等解释器执行命令字符串的 exec.Command 调用
+func (d *GoCommandInjectionDetector) Detect(filePath string, content string) []types.SecurityIssue {
+	return d.detectSource(parseGoSource(filePath, content))
+}
+
+// detectSource 在已解析的源文件上执行检测，供扫描器共享同一次解析
+func (d *GoCommandInjectionDetector) detectSource(src *goSource) []types.SecurityIssue {
+	if src == nil {
+		return nil
+	}
+
+	var issues []types.SecurityIssue
+	ast.Inspect(src.file, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		pkg, name := src.callee(call)
+		if pkg != "os/exec" || (name != "Command" && name != "CommandContext") {
+			return true
+		}
+		args := call.Args
+		if name == "CommandContext" && len(args) > 0 {
+			args = args[1:]
+		}
+		if len(args) < 3 {
+			return true
+		}
+		shell, ok := src.stringValue(args[0])
+		if !ok {
+			return true
+		}
+		flag, isShell := shellNames[strings.ToLower(shell)]
+		if !isShell {
+			return true
+		}
+		if opt, ok := src.stringValue(args[1]); !ok || (flag != "" && strings.ToLower(opt) != flag) {
+			return true
+		}
+
+		command := args[2]
+		issue := types.SecurityIssue{
+			ID:             "GO_COMMAND_INJECTION_001",
+			Type:           "CommandInjection",
+			Severity:       types.SeverityMedium,
+			Message:        "通过 " + shell + " 解释器执行命令字符串",
+			Recommendation: "直接以参数列表调用目标程序，避免经由shell解释命令字符串",
+			Confidence:     0.8,
+		}
+		if !src.isConstant(command) {
+			issue.ID = "GO_COMMAND_INJECTION_002"
+			issue.Severity = types.SeverityHigh
+			issue.Message = "动态构造的命令字符串通过 " + shell + " 解释器执行"
+			issue.Confidence = 0.9
+		}
+		issues = append(issues, src.issueAt(call.Pos(), issue))
+		return true
+	})
+
+	return issues
+}
+
+// stringValue 返回常量字符串表达式的值
+func (s *goSource) stringValue(expr ast.Expr) (string, bool) {
+	tv, ok := s.info.Types[expr]
+	if !ok || tv.Value == nil {
+		return "", false
+	}
+	value := tv.Value.ExactString()
+	if len(value) < 2 || value[0] != '"' {
+		return "", false
+	}
+	return strings.Trim(value, "\""), true
+}
+
+// GoInsecureTLSDetector 基于语法树的TLS证书校验关闭检测器
+type GoInsecureTLSDetector struct {
+	*BaseDetector
+}
+
+// NewGoInsecureTLSDetector 创建TLS配置检测器
+func NewGoInsecureTLSDetector() *GoInsecureTLSDetector {
+	return &GoInsecureTLSDetector{
+		BaseDetector: NewBaseDetector("go_insecure_tls", goLanguages),
+	}
+}
+
+// Detect 检测 InsecureSkipVerify 被设置为 true
+func (d *GoInsecureTLSDetector) Detect(filePath string, content string) []types.SecurityIssue {
+	return d.detectSource(parseGoSource(filePath, content))
+}
+
+// detectSource 在已解析的源文件上执行检测，供扫描器共享同一次解析
+func (d *GoInsecureTLSDetector) detectSource(src *goSource) []types.SecurityIssue {
+	if src == nil {
+		return nil
+	}
+
+	var issues []types.SecurityIssue
+	report := func(key ast.Node, value ast.Expr) {
+		tv, ok := src.info.Types[value]
+		if !ok || tv.Value == nil || tv.Value.ExactString() != "true" {
+			return
+		}
+		issues = append(issues, src.issueAt(key.Pos(), types.SecurityIssue{
+			ID:             "GO_INSECURE_TLS_001",
+			Type:           "InsecureTLS",
+			Severity:       types.SeverityHigh,
+			Message:        "InsecureSkipVerify 关闭了TLS证书校验，连接可被中间人攻击",
+			Recommendation: "保持证书校验开启，自签名证书请通过 RootCAs 配置信任",
+			Confidence:     0.95,
+		}))
+	}
+
+	ast.Inspect(src.file, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.KeyValueExpr:
+			if key, ok := node.Key.(*ast.Ident); ok && key.Name == "InsecureSkipVerify" {
+				report(key, node.Value)
+			}
+		case *ast.AssignStmt:
+			for i, lhs := range node.Lhs {
+				sel, ok := lhs.(*ast.SelectorExpr)
+				if ok && sel.Sel.Name == "InsecureSkipVerify" && i < len(node.Rhs) {
+					report(sel.Sel, node.Rhs[i])
+				}
+			}
+		}
+		return true
+	})
+
+	return issues
+}
+
+// GoWeakHashDetector 基于语法树的弱哈希算法检测器
+type GoWeakHashDetector struct {
+	*BaseDetector
+}
+
+// NewGoWeakHashDetector 创建弱哈希检测器
+func NewGoWeakHashDetector() *GoWeakHashDetector {
+	return &GoWeakHashDetector{
+		BaseDetector: NewBaseDetector("go_weak_hash", goLanguages),
+	}
+}
+
+// Detect 检测用于密码、令牌、签名等安全用途的 MD5/SHA1
+func (d *GoWeakHashDetector) Detect(filePath string, content string) []types.SecurityIssue {
+	return d.detectSource(parseGoSource(filePath, content))
+}
+
+// detectSource 在已解析的源文件上执行检测，供扫描器共享同一次解析
+func (d *GoWeakHashDetector) detectSource(src *goSource) []types.SecurityIssue {
+	if src == nil {
+		return nil
+	}
+
+	var issues []types.SecurityIssue
+	ast.Inspect(src.file, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		algorithm, weak := weakHashPackages[src.pkgPathOf(sel.X)]
+		if !weak || (sel.Sel.Name != "New" && sel.Sel.Name != "Sum") {
+			return true
+		}
+		// 文件校验、缓存键等非安全用途的哈希不报告
+		if !hasAnyWord(src.contextWords(sel), hashSecurityWords) {
+			return true
+		}
+		issues = append(issues, src.issueAt(sel.Pos(), types.SecurityIssue{
+			ID:             "GO_WEAK_HASH_001",
+			Type:           "WeakCryptography",
+			Severity:       types.SeverityMedium,
+			Message:        algorithm + " 已不具备抗碰撞性，不应用于安全用途",
+			Recommendation: "密码存储使用 bcrypt/argon2，签名和令牌使用 SHA-256 及以上或 HMAC-SHA256",
+			Confidence:     0.8,
+		}))
+		return true
+	})
+
+	return issues
+}
+
+// GoInsecureRandomDetector 基于语法树的不安全随机数检测器
+type GoInsecureRandomDetector struct {
+	*BaseDetector
+}
+
+// NewGoInsecureRandomDetector 创建不安全随机数检测器
+func NewGoInsecureRandomDetector() *GoInsecureRandomDetector {
+	return &GoInsecureRandomDetector{
+		BaseDetector: NewBaseDetector("go_insecure_random", goLanguages),
+	}
+}
+
+// Detect 检测使用 math/rand 生成令牌、密钥等安全敏感值
+func (d *GoInsecureRandomDetector) Detect(filePath string, content string) []types.SecurityIssue {
+	return d.detectSource(parseGoSource(filePath, content))
+}
+
+// detectSource 在已解析的源文件上执行检测，供扫描器共享同一次解析
+func (d *GoInsecureRandomDetector) detectSource(src *goSource) []types.SecurityIssue {
+	if src == nil {
+		return nil
+	}
+
+	var issues []types.SecurityIssue
+	reported := make(map[int]bool)
+	ast.Inspect(src.file, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		pkg := src.pkgPathOf(sel.X)
+		if pkg != "math/rand" && pkg != "math/rand/v2" {
+			return true
+		}
+		line := src.fset.Position(sel.Pos()).Line
+		if reported[line] || !hasAnyWord(src.contextWords(sel), randomSecurityWords) {
+			return true
+		}
+		reported[line] = true
+		issues = append(issues, src.issueAt(sel.Pos(), types.SecurityIssue{
+			ID:             "GO_INSECURE_RANDOM_001",
+			Type:           "InsecureRandom",
+			Severity:       types.SeverityHigh,
+			Message:        "math/rand 生成的值可被预测，不能用作令牌或密钥",
+			Recommendation: "使用 crypto/rand 生成安全敏感的随机值",
+			Confidence:     0.85,
+		}))
+		return true
+	})
+
+	return issues
+}
+
+// shortPackage 返回导入路径的最后一段
+func shortPackage(path string) string {
+	return path[strings.LastIndex(path, "/")+1:]
+}
@@ -0,0 +1,324 @@
+// Package security Go语法树检测器测试
+package security
+
+import (
+	"strings"
+	"testing"
+
+	"code-context-generator/pkg/types"
+)
+
+// TestGoSQLInjectionDetector 测试Go SQL注入检测器
+func TestGoSQLInjectionDetector(t *testing.T) {
+	detector := NewGoSQLInjectionDetector()
+
+	testCases := []struct {
+		name     string
+		content  string
+		expected int
+	}{
+		{
+			name: "拼接和格式化语句传入查询",
+			content: `package main
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+func find(db *sql.DB, id, name string) {
+	db.Query("SELECT * FROM users WHERE id = " + id)
+	q := fmt.Sprintf("SELECT * FROM users WHERE name = '%s'", name)
+	db.QueryRow(q)
+	db.ExecContext(nil, "DELETE FROM users WHERE id = "+id)
+}
+`,
+			expected: 3,
+		},
+		{
+			name: "参数化查询",
+			content: `package main
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+const table = "users"
+
+func find(db *sql.DB, id string) {
+	db.Query("SELECT * FROM "+table+" WHERE id = ?", id)
+	q := "SELECT * FROM users WHERE id = $1"
+	db.QueryRow(q, id)
+	fmt.Println(fmt.Sprintf("id=%s", id))
+}
+`,
+			expected: 0,
+		},
+		{
+			name: "未使用database/sql",
+			content: `package main
+
+import "fmt"
+
+func main() {
+	fmt.Sprintf("SELECT * FROM users WHERE id = %d", 1)
+}
+`,
+			expected: 0,
+		},
+		{
+			name: "没有参数的Sprintf",
+			content: `package main
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+func find(db *sql.DB) {
+	db.Query(fmt.Sprintf())
+}
+`,
+			expected: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			issues := detector.Detect("test.go", tc.content)
+			if len(issues) != tc.expected {
+				t.Errorf("期望发现 %d 个问题，实际发现 %d 个问题", tc.expected, len(issues))
+			}
+		})
+	}
+}
+
+// TestGoSQLInjectionDetectorPosition 测试问题的行列位置
+func TestGoSQLInjectionDetectorPosition(t *testing.T) {
+	content := `package main
+
+import "database/sql"
+
+func find(db *sql.DB, id string) {
+	db.Query("SELECT * FROM users WHERE id = " + id)
+}
+`
+	issues := NewGoSQLInjectionDetector().Detect("test.go", content)
+	if len(issues) != 1 {
+		t.Fatalf("期望发现 1 个问题，实际发现 %d 个问题", len(issues))
+	}
+	if issues[0].Line != 6 || issues[0].Column != 11 {
+		t.Errorf("期望位置 6:11，实际位置 %d:%d", issues[0].Line, issues[0].Column)
+	}
+}
+
+// TestGoPathTraversalDetector 测试Go路径遍历检测器
+func TestGoPathTraversalDetector(t *testing.T) {
+	detector := NewGoPathTraversalDetector()
+
+	testCases := []struct {
+		name     string
+		content  string
+		expected int
+	}{
+		{
+			name: "请求参数传入文件操作",
+			content: `package main
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+func handler(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("file")
+	os.Open(filepath.Join("/data", name))
+	http.ServeFile(w, r, r.FormValue("path"))
+}
+`,
+			expected: 2,
+		},
+		{
+			name: "路径已经过净化或校验",
+			content: `package main
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func handler(w http.ResponseWriter, r *http.Request) {
+	name := filepath.Base(r.URL.Query().Get("file"))
+	os.Open(filepath.Join("/data", name))
+
+	p := filepath.Clean(r.FormValue("path"))
+	if !strings.HasPrefix(p, "/data/") {
+		return
+	}
+	os.ReadFile(p)
+	os.Open("config.yaml")
+}
+`,
+			expected: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			issues := detector.Detect("test.go", tc.content)
+			if len(issues) != tc.expected {
+				t.Errorf("期望发现 %d 个问题，实际发现 %d 个问题", tc.expected, len(issues))
+			}
+		})
+	}
+}
+
+// TestGoCommandInjectionDetector 测试Go命令注入检测器
+func TestGoCommandInjectionDetector(t *testing.T) {
+	content := `package main
+
+import (
+	"context"
+	"os/exec"
+)
+
+func run(ctx context.Context, arg string) {
+	exec.Command("sh", "-c", "ls "+arg)
+	exec.CommandContext(ctx, "bash", "-c", "make build")
+	exec.Command("ls", "-la", arg)
+}
+`
+	issues := NewGoCommandInjectionDetector().Detect("test.go", content)
+	if len(issues) != 2 {
+		t.Fatalf("期望发现 2 个问题，实际发现 %d 个问题", len(issues))
+	}
+	if issues[0].Severity != types.SeverityHigh {
+		t.Errorf("动态命令期望严重性 high，实际 %s", issues[0].Severity)
+	}
+	if issues[1].Severity != types.SeverityMedium {
+		t.Errorf("常量命令期望严重性 medium，实际 %s", issues[1].Severity)
+	}
+}
+
+// TestGoInsecureTLSDetector 测试TLS配置检测器
+func TestGoInsecureTLSDetector(t *testing.T) {
+	content := `package main
+
+import "crypto/tls"
+
+func configs() {
+	_ = &tls.Config{InsecureSkipVerify: true}
+	_ = &tls.Config{InsecureSkipVerify: false}
+	cfg := &tls.Config{}
+	cfg.InsecureSkipVerify = true
+}
+`
+	issues := NewGoInsecureTLSDetector().Detect("test.go", content)
+	if len(issues) != 2 {
+		t.Errorf("期望发现 2 个问题，实际发现 %d 个问题", len(issues))
+	}
+}
+
+// TestGoWeakHashDetector 测试弱哈希检测器
+func TestGoWeakHashDetector(t *testing.T) {
+	content := `package main
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+)
+
+func hashPassword(password string) []byte {
+	sum := md5.Sum([]byte(password))
+	return sum[:]
+}
+
+func fileChecksum(data []byte) [20]byte {
+	return sha1.Sum(data)
+}
+`
+	issues := NewGoWeakHashDetector().Detect("test.go", content)
+	if len(issues) != 1 {
+		t.Errorf("期望发现 1 个问题，实际发现 %d 个问题", len(issues))
+	}
+}
+
+// TestGoInsecureRandomDetector 测试不安全随机数检测器
+func TestGoInsecureRandomDetector(t *testing.T) {
+	content := `package main
+
+import "math/rand"
+
+func generateToken() string {
+	b := make([]byte, 16)
+	for i := range b {
+		b[i] = byte(rand.Intn(256))
+	}
+	return string(b)
+}
+
+func shuffle(items []int) {
+	rand.Shuffle(len(items), func(i, j int) { items[i], items[j] = items[j], items[i] })
+}
+`
+	issues := NewGoInsecureRandomDetector().Detect("test.go", content)
+	if len(issues) != 1 {
+		t.Errorf("期望发现 1 个问题，实际发现 %d 个问题", len(issues))
+	}
+}
+
+// TestGoDetectorsInvalidSource 测试无法解析的源码
+func TestGoDetectorsInvalidSource(t *testing.T) {
+	registry := NewDetectorRegistry()
+	for _, detector := range registry.GetDetectorsForLanguage("go") {
+		detector.Detect("test.go", "package main\nfunc {")
+	}
+}
+
+// TestScanGoFileParsedOnce 测试扫描器共享解析结果时与单独检测的结果一致
+func TestScanGoFileParsedOnce(t *testing.T) {
+	content := `package main
+
+import (
+	"crypto/md5"
+	"crypto/tls"
+	"database/sql"
+	"os/exec"
+)
+
+func run(db *sql.DB, id, cmd, password string) {
+	db.Query("SELECT * FROM users WHERE id = " + id)
+	exec.Command("sh", "-c", cmd)
+	_ = &tls.Config{InsecureSkipVerify: true}
+	md5.Sum([]byte(password))
+}
+`
+	expected := 0
+	for _, detector := range NewDetectorRegistry().GetDetectorsForLanguage("go") {
+		if _, ok := detector.(goSourceDetector); ok {
+			expected += len(detector.Detect("main.go", content))
+		}
+	}
+	if expected == 0 {
+		t.Fatal("测试内容应该触发Go语法树检测器")
+	}
+
+	config := allDetectorsConfig("comprehensive")
+	report, err := NewSecurityScanner(config).ScanFileInfos([]types.FileInfo{{Path: "main.go", Content: content}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := 0
+	for _, issue := range report.Issues {
+		if strings.HasPrefix(issue.ID, "GO_") {
+			found++
+		}
+	}
+	if found != expected {
+		t.Errorf("期望Go语法树检测器发现 %d 个问题，实际 %d 个", expected, found)
+	}
+}
@@ -0,0 +1,281 @@
+// Package security 实现安全扫描功能
+package security
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"code-context-generator/pkg/types"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// HistoryScanner Git历史凭证扫描器
+type HistoryScanner struct {
+	config   *types.SecurityConfig
+	registry *DetectorRegistry
+}
+
+// HistoryScanResult 历史扫描结果
+type HistoryScanResult struct {
+	Report  *types.SecurityReport
+	Commits int // 扫描的提交数量
+}
+
+// addedLine 补丁中新增的行
+type addedLine struct {
+	number int
+	text   string
+}
+
+// historyFinding 历史中发现的凭证及其引入提交
+type historyFinding struct {
+	issue types.SecurityIssue
+	when  time.Time
+}
+
+// NewHistoryScanner 创建Git历史凭证扫描器
+func NewHistoryScanner(config *types.SecurityConfig) *HistoryScanner {
+	return &HistoryScanner{
+		config:   config,
+		registry: NewDetectorRegistry(),
+	}
+}
+
+// Scan 遍历仓库提交，检测每个补丁新增行中的凭证
+//
+// sinceRef 非空时只扫描 HEAD 可达而 sinceRef 不可达的提交。
+func (h *HistoryScanner) Scan(repoPath, sinceRef string) (*HistoryScanResult, error) {
+	startTime := time.Now()
+
+	policy, err := NewScanPolicy(h.config)
+	if err != nil {
+		return nil, err
+	}
+
+	scanID, err := generateScanID()
+	if err != nil {
+		return nil, fmt.Errorf("生成扫描ID失败: %v", err)
+	}
+
+	repo, err := git.PlainOpenWithOptions(repoPath, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, fmt.Errorf("打开Git仓库失败: %w", err)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("获取HEAD失败: %w", err)
+	}
+
+	excluded, err := h.reachableFrom(repo, sinceRef)
+	if err != nil {
+		return nil, err
+	}
+
+	iter, err := repo.Log(&git.LogOptions{From: head.Hash(), Order: git.LogOrderCommitterTime})
+	if err != nil {
+		return nil, fmt.Errorf("获取提交历史失败: %w", err)
+	}
+
+	detectors := h.credentialDetectors()
+	// 按文件和代码去重，遍历从新到旧，最终保留最早引入的提交
+	findings := make(map[string]historyFinding)
+	var scanErrors []types.ScanError
+	commits, patches := 0, 0
+
+	err = iter.ForEach(func(commit *object.Commit) error {
+		if excluded[commit.Hash] {
+			return nil
+		}
+		// 合并提交与第一个父提交比较，解决冲突时新增的内容也会被扫描；
+		// 从其他分支带入的变更与分支上的提交重复，去重后保留最早的提交
+		commits++
+
+		filePatches, err := commitPatches(commit)
+		if err != nil {
+			return nil // 跳过无法计算差异的提交
+		}
+
+		for _, fp := range filePatches {
+			if fp.IsBinary() {
+				continue
+			}
+			_, to := fp.Files()
+			if to == nil {
+				continue // 删除的文件没有新增行
+			}
+			patches++
+
+			found, errs := h.scanAddedLines(to.Path(), addedLines(fp), detectors, policy)
+			for _, scanErr := range errs {
+				scanErr.File = fmt.Sprintf("%s@%s", scanErr.File, commit.Hash.String()[:7])
+				scanErrors = append(scanErrors, scanErr)
+			}
+			for _, issue := range found {
+				issue.Commit = commit.Hash.String()
+				issue.Author = fmt.Sprintf("%s <%s>", commit.Author.Name, commit.Author.Email)
+				key := issue.File + "\x00" + issue.Snippet
+				findings[key] = historyFinding{issue: issue, when: commit.Committer.When}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("遍历提交失败: %w", err)
+	}
+
+	ordered := make([]historyFinding, 0, len(findings))
+	for _, finding := range findings {
+		ordered = append(ordered, finding)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		if !ordered[i].when.Equal(ordered[j].when) {
+			return ordered[i].when.Before(ordered[j].when)
+		}
+		return ordered[i].issue.File < ordered[j].issue.File
+	})
+	issues := make([]types.SecurityIssue, len(ordered))
+	for i, finding := range ordered {
+		issues[i] = finding.issue
+	}
+
+	report := newSecurityReport(scanID, patches, patches, issues, time.Since(startTime), *h.config)
+	report.Errors = scanErrors
+	return &HistoryScanResult{Report: report, Commits: commits}, nil
+}
+
+// reachableFrom 返回从指定引用可达的所有提交
+func (h *HistoryScanner) reachableFrom(repo *git.Repository, ref string) (map[plumbing.Hash]bool, error) {
+	reachable := make(map[plumbing.Hash]bool)
+	if ref == "" {
+		return reachable, nil
+	}
+
+	hash, err := repo.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		return nil, fmt.Errorf("解析引用 %s 失败: %w", ref, err)
+	}
+
+	iter, err := repo.Log(&git.LogOptions{From: *hash})
+	if err != nil {
+		return nil, fmt.Errorf("获取提交历史失败: %w", err)
+	}
+	err = iter.ForEach(func(commit *object.Commit) error {
+		reachable[commit.Hash] = true
+		return nil
+	})
+	return reachable, err
+}
+
+// credentialDetectors 返回凭证类检测器，历史扫描不受扫描级别和语言限制
+func (h *HistoryScanner) credentialDetectors() []types.SecurityDetector {
+	var detectors []types.SecurityDetector
+	for _, detector := range h.registry.GetAllDetectors() {
+		if ruleFor(detector.GetName()).category == categoryCredentials {
+			detectors = append(detectors, detector)
+		}
+	}
+	sort.Slice(detectors, func(i, j int) bool {
+		return detectors[i].GetName() < detectors[j].GetName()
+	})
+	return detectors
+}
+
+// scanAddedLines 对新增行运行检测器，并将行号映射回文件中的实际位置，同时返回检测器错误
+func (h *HistoryScanner) scanAddedLines(path string, lines []addedLine, detectors []types.SecurityDetector, policy *ScanPolicy) ([]types.SecurityIssue, []types.ScanError) {
+	if len(lines) == 0 {
+		return nil, nil
+	}
+
+	texts := make([]string, len(lines))
+	for i, line := range lines {
+		texts[i] = line.text
+	}
+	content := strings.Join(texts, "\n")
+
+	var issues []types.SecurityIssue
+	var scanErrors []types.ScanError
+	for _, detector := range detectors {
+		detected, err := runDetector(detector.GetName(), func() []types.SecurityIssue {
+			return detector.Detect(path, content)
+		})
+		if err != nil {
+			scanErrors = append(scanErrors, types.ScanError{File: path, Detector: detector.GetName(), Message: err.Error()})
+			continue
+		}
+		for _, issue := range detected {
+			if !policy.Accept(detector.GetName(), issue) {
+				continue
+			}
+			if issue.Line >= 1 && issue.Line <= len(lines) {
+				issue.Line = lines[issue.Line-1].number
+			}
+			issue.Message = "历史提交中引入: " + issue.Message
+			issue.Recommendation = "凭证已进入Git历史，从当前代码删除不会使其失效，请立即吊销并轮换该凭证"
+			issues = append(issues, issue)
+		}
+	}
+	return issues, scanErrors
+}
+
+// commitPatches 计算提交相对第一个父提交的文件补丁，初始提交与空树比较
+func commitPatches(commit *object.Commit) ([]diff.FilePatch, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	var parentTree *object.Tree
+	if commit.NumParents() > 0 {
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return nil, err
+		}
+		if parentTree, err = parent.Tree(); err != nil {
+			return nil, err
+		}
+	}
+
+	// 开启重命名检测，移动文件不会被当作新增内容
+	changes, err := object.DiffTreeWithOptions(context.Background(), parentTree, tree, object.DefaultDiffTreeOptions)
+	if err != nil {
+		return nil, err
+	}
+	patch, err := changes.Patch()
+	if err != nil {
+		return nil, err
+	}
+	return patch.FilePatches(), nil
+}
+
+// addedLines 提取补丁中的新增行及其在新文件中的行号
+func addedLines(fp diff.FilePatch) []addedLine {
+	var lines []addedLine
+	number := 1
+
+	for _, chunk := range fp.Chunks() {
+		content := strings.TrimSuffix(chunk.Content(), "\n")
+		if content == "" && chunk.Content() == "" {
+			continue
+		}
+		chunkLines := strings.Split(content, "\n")
+
+		switch chunk.Type() {
+		case diff.Equal:
+			number += len(chunkLines)
+		case diff.Add:
+			for _, text := range chunkLines {
+				lines = append(lines, addedLine{number: number, text: text})
+				number++
+			}
+		}
+	}
+	return lines
+}
@@ -0,0 +1,162 @@
+// Package security Git历史扫描测试
+package security
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// commitFile 写入文件并提交
+func commitFile(t *testing.T, repo *git.Repository, dir, name, content, message string, when time.Time) string {
+	t.Helper()
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, name)
+	if content == "" {
+		if _, err := worktree.Remove(name); err != nil {
+			t.Fatal(err)
+		}
+	} else {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := worktree.Add(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	hash, err := worktree.Commit(message, &git.CommitOptions{
+		Author: &object.Signature{Name: "Alice", Email: "alice@example.com", When: when},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash.String()
+}
+
+// TestHistoryScanner 测试扫描已删除的历史凭证
+func TestHistoryScanner(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	first := commitFile(t, repo, dir, "main.py", "print('hello')\n", "init", base)
+	leak := commitFile(t, repo, dir, "config.py", "host = 'localhost'\npassword = \"hunter2\"\n", "add config", base.Add(time.Hour))
+	commitFile(t, repo, dir, "config.py", "", "remove config", base.Add(2*time.Hour))
+
+	config := allDetectorsConfig("standard")
+	result, err := NewHistoryScanner(config).Scan(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Commits != 3 {
+		t.Errorf("期望扫描 3 个提交，实际 %d 个", result.Commits)
+	}
+	issues := result.Report.Issues
+	if len(issues) != 1 {
+		t.Fatalf("期望发现 1 个问题，实际发现 %d 个问题", len(issues))
+	}
+	issue := issues[0]
+	if issue.Commit != leak {
+		t.Errorf("期望引入提交 %s，实际 %s", leak, issue.Commit)
+	}
+	if issue.File != "config.py" || issue.Line != 2 {
+		t.Errorf("期望位置 config.py:2，实际 %s:%d", issue.File, issue.Line)
+	}
+	if issue.Author != "Alice <alice@example.com>" {
+		t.Errorf("作者不符合预期: %s", issue.Author)
+	}
+
+	// 只扫描引入凭证之后的提交
+	result, err = NewHistoryScanner(config).Scan(dir, leak)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Report.Issues) != 0 || result.Commits != 1 {
+		t.Errorf("期望扫描 1 个提交且无问题，实际 %d 个提交 %d 个问题", result.Commits, len(result.Report.Issues))
+	}
+
+	if _, err := NewHistoryScanner(config).Scan(dir, first[:7]+"zz"); err == nil {
+		t.Error("无效的引用应该返回错误")
+	}
+}
+
+// TestHistoryScannerMergeCommit 测试扫描合并提交中新增的凭证
+func TestHistoryScannerMergeCommit(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	commitFile(t, repo, dir, "main.py", "print('hello')\n", "init", base)
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mainBranch := head.Name()
+
+	// 分支上提交不含凭证的文件
+	if err := worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("feature"), Create: true}); err != nil {
+		t.Fatal(err)
+	}
+	side := commitFile(t, repo, dir, "feature.py", "enabled = True\n", "add feature", base.Add(time.Hour))
+	if err := worktree.Checkout(&git.CheckoutOptions{Branch: mainBranch}); err != nil {
+		t.Fatal(err)
+	}
+	mainHead := commitFile(t, repo, dir, "main.py", "print('hello, world')\n", "update main", base.Add(2*time.Hour))
+
+	// 合并时带入分支的文件，并在解决冲突时新增凭证
+	if err := os.WriteFile(filepath.Join(dir, "feature.py"), []byte("enabled = True\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := worktree.Add("feature.py"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config.py"), []byte("password = \"hunter2\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := worktree.Add("config.py"); err != nil {
+		t.Fatal(err)
+	}
+	merge, err := worktree.Commit("merge feature", &git.CommitOptions{
+		Author:  &object.Signature{Name: "Alice", Email: "alice@example.com", When: base.Add(3 * time.Hour)},
+		Parents: []plumbing.Hash{plumbing.NewHash(mainHead), plumbing.NewHash(side)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := NewHistoryScanner(allDetectorsConfig("standard")).Scan(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Commits != 4 {
+		t.Errorf("期望扫描 4 个提交，实际 %d 个", result.Commits)
+	}
+	issues := result.Report.Issues
+	if len(issues) != 1 {
+		t.Fatalf("期望发现 1 个问题，实际发现 %d 个问题", len(issues))
+	}
+	if issues[0].Commit != merge.String() || issues[0].File != "config.py" {
+		t.Errorf("期望合并提交 %s 中的 config.py，实际 %s 中的 %s", merge, issues[0].Commit, issues[0].File)
+	}
+}
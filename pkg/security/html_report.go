@@ -0,0 +1,423 @@
+// Package security HTML安全报告
+package security
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"os"
+	"sort"
+	"strings"
+
+	"code-context-generator/pkg/types"
+)
+
+// defaultContextLines 问题前后默认显示的源码行数
+const defaultContextLines = 3
+
+// issueTypeDescriptions 问题类型的说明，显示在HTML报告中
+var issueTypeDescriptions = map[string]string{
+	"HardcodedCredentials":    "源码中直接写入了密码、密钥或令牌。任何能读取代码的人都能获得这些凭证，且凭证会永久保留在版本历史中。",
+	"SQLInjection":            "SQL语句由字符串拼接或格式化生成，攻击者可通过可控输入改变查询语义，读取或篡改数据库。",
+	"XSSVulnerability":        "未经转义的数据被写入HTML或脚本上下文，攻击者可注入在用户浏览器中执行的脚本。",
+	"PathTraversal":           "文件路径由外部输入拼接而成，攻击者可使用 ../ 等序列访问预期目录之外的文件。",
+	"CommandInjection":        "外部输入被传递给系统命令或shell，攻击者可执行任意命令。",
+	"InsecureTLS":             "TLS配置跳过了证书校验或使用了过时的协议版本，通信可能被中间人窃听或篡改。",
+	"WeakCryptography":        "使用了已被攻破或不再推荐的哈希或加密算法，无法提供预期的安全强度。",
+	"InsecureRandom":          "在安全相关的场景中使用了可预测的伪随机数生成器。",
+	"IaCMisconfiguration":     "基础设施即代码中的配置违反了安全最佳实践，部署后可能暴露服务或提升权限。",
+	"VulnerableDependency":    "依赖的第三方包版本存在已公开的安全漏洞。",
+	"PersonalInformation":     "源码或数据文件中包含个人信息，发送给第三方或提交到仓库可能违反隐私合规要求。",
+	"IncompleteErrorHandling": "错误未被检查或处理，可能掩盖失败并导致不一致的状态。",
+	"UnusedVariable":          "存在未使用的变量，通常意味着逻辑遗漏或残留代码。",
+}
+
+// severityColors 各严重性级别在报告中的颜色
+var severityColors = map[types.SeverityLevel]string{
+	types.SeverityCritical: "#7b1fa2",
+	types.SeverityHigh:     "#d32f2f",
+	types.SeverityMedium:   "#f57c00",
+	types.SeverityLow:      "#388e3c",
+}
+
+// htmlChartBar SVG条形图中的一条
+type htmlChartBar struct {
+	Label string
+	Count int
+	Width int
+	Y     int
+	Color string
+}
+
+// htmlSourceLine 问题附近的一行源码
+type htmlSourceLine struct {
+	Number    int
+	Text      string
+	Highlight bool
+}
+
+// htmlFinding 报告中的单个问题
+type htmlFinding struct {
+	Severity       string
+	Color          string
+	ID             string
+	Type           string
+	Line           int
+	Column         int
+	Message        string
+	Description    string
+	Recommendation string
+	Confidence     float64
+	Commit         string
+	Author         string
+	Snippet        string
+	Source         []htmlSourceLine
+}
+
+// htmlFileGroup 按文件分组的问题
+type htmlFileGroup struct {
+	Path     string
+	Severity string
+	Color    string
+	Findings []htmlFinding
+}
+
+// htmlReportView HTML模板使用的报告数据
+type htmlReportView struct {
+	ScanID      string
+	Timestamp   string
+	Duration    string
+	Summary     types.ScanSummary
+	Severities  []htmlChartBar
+	Types       []htmlChartBar
+	TypesHeight int
+	Files       []htmlFileGroup
+}
+
+// htmlSourceLoader 读取问题所在文件的源码
+type htmlSourceLoader struct {
+	sources map[string]string
+	cache   map[string][]string
+	masker  *PIIMasker
+}
+
+// generateHTMLReport 生成自包含的单文件HTML报告
+func (r *SecurityReporterImpl) generateHTMLReport(report *types.SecurityReport) ([]byte, error) {
+	contextLines := report.Config.Reporting.ContextLines
+	if contextLines <= 0 {
+		contextLines = defaultContextLines
+	}
+
+	loader := &htmlSourceLoader{
+		sources: r.sources,
+		cache:   make(map[string][]string),
+		// 报告本身不应泄露个人信息，源码上下文遮蔽所有类型
+		masker: &PIIMasker{rules: piiRules},
+	}
+
+	view := htmlReportView{
+		ScanID:     report.ScanID,
+		Timestamp:  report.Timestamp.Format("2006-01-02 15:04:05"),
+		Duration:   report.ScanDuration.String(),
+		Summary:    report.Summary,
+		Severities: buildSeverityBars(report.Issues),
+		Types:      buildTypeBars(report.Issues),
+		Files:      groupIssuesByFile(report.Issues, loader, contextLines),
+	}
+	view.TypesHeight = len(view.Types)*28 + 8
+
+	var buf bytes.Buffer
+	if err := htmlReportTemplate.Execute(&buf, view); err != nil {
+		return nil, fmt.Errorf("渲染HTML报告失败: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// buildSeverityBars 统计各严重性级别的问题数量
+func buildSeverityBars(issues []types.SecurityIssue) []htmlChartBar {
+	counts := make(map[types.SeverityLevel]int)
+	for _, issue := range issues {
+		counts[issue.Severity]++
+	}
+
+	levels := []types.SeverityLevel{types.SeverityCritical, types.SeverityHigh, types.SeverityMedium, types.SeverityLow}
+	bars := make([]htmlChartBar, len(levels))
+	for i, level := range levels {
+		bars[i] = htmlChartBar{Label: level.String(), Count: counts[level], Color: severityColors[level]}
+	}
+	return layoutBars(bars)
+}
+
+// buildTypeBars 统计各问题类型的数量，按数量降序
+func buildTypeBars(issues []types.SecurityIssue) []htmlChartBar {
+	counts := make(map[string]int)
+	for _, issue := range issues {
+		counts[issue.Type]++
+	}
+
+	bars := make([]htmlChartBar, 0, len(counts))
+	for issueType, count := range counts {
+		bars = append(bars, htmlChartBar{Label: issueType, Count: count, Color: "#1976d2"})
+	}
+	sort.Slice(bars, func(i, j int) bool {
+		if bars[i].Count != bars[j].Count {
+			return bars[i].Count > bars[j].Count
+		}
+		return bars[i].Label < bars[j].Label
+	})
+	return layoutBars(bars)
+}
+
+// layoutBars 计算条形图中每条的宽度和位置
+func layoutBars(bars []htmlChartBar) []htmlChartBar {
+	const maxWidth = 300
+	max := 0
+	for _, bar := range bars {
+		if bar.Count > max {
+			max = bar.Count
+		}
+	}
+	for i := range bars {
+		bars[i].Y = i*28 + 4
+		if max > 0 {
+			bars[i].Width = bars[i].Count * maxWidth / max
+		}
+	}
+	return bars
+}
+
+// groupIssuesByFile 按文件分组问题，最严重的文件排在前面
+func groupIssuesByFile(issues []types.SecurityIssue, loader *htmlSourceLoader, contextLines int) []htmlFileGroup {
+	byFile := make(map[string][]types.SecurityIssue)
+	var paths []string
+	for _, issue := range issues {
+		if _, ok := byFile[issue.File]; !ok {
+			paths = append(paths, issue.File)
+		}
+		byFile[issue.File] = append(byFile[issue.File], issue)
+	}
+
+	maxSeverity := func(path string) types.SeverityLevel {
+		max := types.SeverityLow
+		for _, issue := range byFile[path] {
+			if issue.Severity > max {
+				max = issue.Severity
+			}
+		}
+		return max
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		si, sj := maxSeverity(paths[i]), maxSeverity(paths[j])
+		if si != sj {
+			return si > sj
+		}
+		return paths[i] < paths[j]
+	})
+
+	groups := make([]htmlFileGroup, 0, len(paths))
+	for _, path := range paths {
+		fileIssues := byFile[path]
+		sort.SliceStable(fileIssues, func(i, j int) bool {
+			return fileIssues[i].Line < fileIssues[j].Line
+		})
+
+		severity := maxSeverity(path)
+		group := htmlFileGroup{Path: path, Severity: severity.String(), Color: severityColors[severity]}
+		for _, issue := range fileIssues {
+			group.Findings = append(group.Findings, htmlFinding{
+				Severity:       issue.Severity.String(),
+				Color:          severityColors[issue.Severity],
+				ID:             issue.ID,
+				Type:           issue.Type,
+				Line:           issue.Line,
+				Column:         issue.Column,
+				Message:        issue.Message,
+				Description:    issueTypeDescriptions[issue.Type],
+				Recommendation: issue.Recommendation,
+				Confidence:     issue.Confidence,
+				Commit:         issue.Commit,
+				Author:         issue.Author,
+				Snippet:        issue.Snippet,
+				Source:         loader.context(issue, contextLines),
+			})
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// context 返回问题所在行前后的源码，无法读取源码时返回nil
+func (l *htmlSourceLoader) context(issue types.SecurityIssue, contextLines int) []htmlSourceLine {
+	// 历史提交中的问题与工作区文件内容不一致，只显示代码片段
+	if issue.Line <= 0 || issue.Commit != "" {
+		return nil
+	}
+
+	lines := l.lines(issue.File)
+	if issue.Line > len(lines) {
+		return nil
+	}
+
+	start := issue.Line - contextLines
+	if start < 1 {
+		start = 1
+	}
+	end := issue.Line + contextLines
+	if end > len(lines) {
+		end = len(lines)
+	}
+
+	source := make([]htmlSourceLine, 0, end-start+1)
+	for n := start; n <= end; n++ {
+		text, _ := l.masker.Mask(lines[n-1])
+		source = append(source, htmlSourceLine{
+			Number:    n,
+			Text:      strings.TrimRight(text, "\r"),
+			Highlight: n == issue.Line,
+		})
+	}
+	return source
+}
+
+// lines 读取并缓存文件的所有行，优先使用扫描时已加载的内容
+func (l *htmlSourceLoader) lines(path string) []string {
+	if lines, ok := l.cache[path]; ok {
+		return lines
+	}
+
+	content, ok := l.sources[path]
+	if !ok {
+		data, err := os.ReadFile(path)
+		if err == nil {
+			content = string(data)
+		}
+	}
+
+	var lines []string
+	if content != "" {
+		lines = strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	}
+	l.cache[path] = lines
+	return lines
+}
+
+// htmlReportTemplate 单文件HTML报告模板，样式、脚本和图表全部内联
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="UTF-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>安全扫描报告 {{.ScanID}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", "PingFang SC", "Microsoft YaHei", sans-serif; margin: 0; background: #f5f5f5; color: #212121; }
+header { background: #263238; color: #fff; padding: 20px 32px; }
+header h1 { margin: 0 0 6px; font-size: 22px; }
+header .meta { font-size: 13px; opacity: .8; }
+main { padding: 24px 32px; max-width: 1200px; }
+.cards { display: flex; flex-wrap: wrap; gap: 12px; margin-bottom: 20px; }
+.card { background: #fff; border-radius: 6px; padding: 12px 18px; min-width: 110px; box-shadow: 0 1px 2px rgba(0,0,0,.1); }
+.card .value { font-size: 24px; font-weight: 600; }
+.card .label { font-size: 12px; color: #616161; }
+.charts { display: flex; flex-wrap: wrap; gap: 16px; margin-bottom: 20px; }
+.chart { background: #fff; border-radius: 6px; padding: 12px 16px; box-shadow: 0 1px 2px rgba(0,0,0,.1); }
+.chart h2 { font-size: 15px; margin: 0 0 8px; }
+.chart text { font-size: 12px; fill: #424242; }
+.filters { background: #fff; border-radius: 6px; padding: 10px 16px; margin-bottom: 20px; box-shadow: 0 1px 2px rgba(0,0,0,.1); }
+.filters label { margin-right: 16px; cursor: pointer; }
+.file { background: #fff; border-radius: 6px; margin-bottom: 16px; box-shadow: 0 1px 2px rgba(0,0,0,.1); overflow: hidden; }
+.file > summary { padding: 10px 16px; cursor: pointer; font-family: monospace; font-size: 14px; border-left: 5px solid; }
+.finding { border-top: 1px solid #eee; padding: 12px 16px; }
+.badge { display: inline-block; color: #fff; border-radius: 3px; padding: 1px 7px; font-size: 12px; text-transform: uppercase; }
+.finding h3 { font-size: 14px; margin: 0 0 6px; }
+.finding p { margin: 4px 0; font-size: 13px; }
+.finding .desc { color: #616161; }
+pre { background: #263238; color: #eceff1; border-radius: 4px; padding: 8px 0; overflow-x: auto; font-size: 12px; margin: 8px 0; }
+pre .line { display: block; padding: 0 12px; white-space: pre; }
+pre .line.hit { background: #5d4037; }
+pre .num { display: inline-block; width: 48px; color: #90a4ae; user-select: none; }
+.empty { background: #fff; border-radius: 6px; padding: 24px; text-align: center; color: #388e3c; }
+</style>
+</head>
+<body>
+<header>
+<h1>安全扫描报告</h1>
+<div class="meta">扫描ID: {{.ScanID}} · 扫描时间: {{.Timestamp}} · 扫描耗时: {{.Duration}}</div>
+</header>
+<main>
+<div class="cards">
+<div class="card"><div class="value">{{.Summary.ScannedFiles}}</div><div class="label">已扫描文件</div></div>
+<div class="card"><div class="value">{{.Summary.IssuesFound}}</div><div class="label">发现问题</div></div>
+<div class="card"><div class="value" style="color:#7b1fa2">{{.Summary.CriticalIssues}}</div><div class="label">严重</div></div>
+<div class="card"><div class="value" style="color:#d32f2f">{{.Summary.HighIssues}}</div><div class="label">高危</div></div>
+<div class="card"><div class="value" style="color:#f57c00">{{.Summary.MediumIssues}}</div><div class="label">中危</div></div>
+<div class="card"><div class="value" style="color:#388e3c">{{.Summary.LowIssues}}</div><div class="label">低危</div></div>
+</div>
+{{if .Files}}
+<div class="charts">
+<div class="chart">
+<h2>按严重性</h2>
+<svg width="440" height="120" role="img" aria-label="按严重性统计">
+{{range .Severities}}<text x="0" y="{{.Y}}" dy="14">{{.Label}}</text>
+<rect x="80" y="{{.Y}}" width="{{.Width}}" height="20" rx="2" fill="{{.Color}}"></rect>
+<text x="{{.Width}}" dx="86" y="{{.Y}}" dy="14">{{.Count}}</text>
+{{end}}</svg>
+</div>
+<div class="chart">
+<h2>按问题类型</h2>
+<svg width="560" height="{{.TypesHeight}}" role="img" aria-label="按问题类型统计">
+{{range .Types}}<text x="0" y="{{.Y}}" dy="14">{{.Label}}</text>
+<rect x="180" y="{{.Y}}" width="{{.Width}}" height="20" rx="2" fill="{{.Color}}"></rect>
+<text x="{{.Width}}" dx="186" y="{{.Y}}" dy="14">{{.Count}}</text>
+{{end}}</svg>
+</div>
+</div>
+<div class="filters">严重性:
+<label><input type="checkbox" class="severity-filter" value="critical" checked> 严重</label>
+<label><input type="checkbox" class="severity-filter" value="high" checked> 高危</label>
+<label><input type="checkbox" class="severity-filter" value="medium" checked> 中危</label>
+<label><input type="checkbox" class="severity-filter" value="low" checked> 低危</label>
+</div>
+{{range .Files}}
+<details class="file" open>
+<summary style="border-left-color: {{.Color}}">{{.Path}} ({{len .Findings}})</summary>
+{{range .Findings}}
+<div class="finding" data-severity="{{.Severity}}">
+<h3><span class="badge" style="background: {{.Color}}">{{.Severity}}</span> {{.Type}} · 第 {{.Line}} 行{{if .Column}}，第 {{.Column}} 列{{end}}</h3>
+<p>{{.Message}}{{if .ID}} <code>{{.ID}}</code>{{end}}</p>
+{{if .Description}}<p class="desc">{{.Description}}</p>{{end}}
+{{if .Commit}}<p>提交: <code>{{.Commit}}</code> ({{.Author}})</p>{{end}}
+{{if .Source}}<pre>{{range .Source}}<span class="line{{if .Highlight}} hit{{end}}"><span class="num">{{.Number}}</span>{{.Text}}</span>{{end}}</pre>
+{{else if .Snippet}}<pre><span class="line hit">{{.Snippet}}</span></pre>
+{{end}}
+{{if .Recommendation}}<p><strong>建议:</strong> {{.Recommendation}}</p>{{end}}
+<p class="desc">置信度: {{printf "%.2f" .Confidence}}</p>
+</div>
+{{end}}
+</details>
+{{end}}
+{{else}}
+<div class="empty">未发现安全问题</div>
+{{end}}
+</main>
+<script>
+(function () {
+  var filters = document.querySelectorAll('.severity-filter');
+  function apply() {
+    var shown = {};
+    filters.forEach(function (f) { shown[f.value] = f.checked; });
+    document.querySelectorAll('.finding').forEach(function (el) {
+      el.style.display = shown[el.dataset.severity] ? '' : 'none';
+    });
+    document.querySelectorAll('.file').forEach(function (file) {
+      var visible = Array.prototype.some.call(file.querySelectorAll('.finding'), function (el) {
+        return el.style.display !== 'none';
+      });
+      file.style.display = visible ? '' : 'none';
+    });
+  }
+  filters.forEach(function (f) { f.addEventListener('change', apply); });
+})();
+</script>
+</body>
+</html>
+`))
@@ -0,0 +1,71 @@
+// Package security HTML报告测试
+package security
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"code-context-generator/pkg/types"
+)
+
+// TestGenerateHTMLReport 测试HTML报告的源码上下文、分组和图表
+func TestGenerateHTMLReport(t *testing.T) {
+	dir := t.TempDir()
+	diskFile := filepath.Join(dir, "app.py")
+	content := "import os\n\ndef get(user_id):\n    password = \"hunter2\"\n    contact = \"alice@acme-corp.com\"\n    return user_id\n"
+	if err := os.WriteFile(diskFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	report := &types.SecurityReport{
+		ScanID:  "html-test",
+		Summary: types.ScanSummary{ScannedFiles: 2, IssuesFound: 3, HighIssues: 1, MediumIssues: 1, CriticalIssues: 1},
+		Issues: []types.SecurityIssue{
+			{ID: "CREDENTIALS_001", Type: "HardcodedCredentials", Severity: types.SeverityHigh, File: diskFile, Line: 4, Message: "发现硬编码密码", Recommendation: "使用环境变量"},
+			{ID: "XSS_001", Type: "XSSVulnerability", Severity: types.SeverityMedium, File: diskFile, Line: 2, Message: "<script>注入</script>"},
+			{ID: "SQL_INJECTION_001", Type: "SQLInjection", Severity: types.SeverityCritical, File: "memory.py", Line: 1, Message: "SQL拼接"},
+		},
+		Config: types.SecurityConfig{Reporting: types.ReportingConfig{Format: "html", ContextLines: 1}},
+	}
+
+	reporter := NewSecurityReporter()
+	// 未写入磁盘的文件从扫描时加载的内容读取
+	reporter.SetSources([]types.FileInfo{{Path: "memory.py", Content: "query = \"SELECT \" + id\n"}})
+	output, err := reporter.Generate(report)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	html := string(output)
+
+	expected := []string{
+		"<!DOCTYPE html>",
+		"<svg",
+		`class="severity-filter" value="critical"`,
+		`<span class="line hit"><span class="num">4</span>    password = &#34;hunter2&#34;</span>`,
+		`<span class="num">5</span>    contact = &#34;[EMAIL]&#34;`,
+		`<span class="line hit"><span class="num">1</span>query = &#34;SELECT &#34; &#43; id</span>`,
+		issueTypeDescriptions["HardcodedCredentials"],
+		"使用环境变量",
+		"&lt;script&gt;注入&lt;/script&gt;",
+	}
+	for _, s := range expected {
+		if !strings.Contains(html, s) {
+			t.Errorf("HTML报告应该包含 %q", s)
+		}
+	}
+
+	// 上下文只包含前后各一行
+	if strings.Contains(html, `<span class="num">6</span>`) {
+		t.Error("HTML报告不应包含超出上下文范围的行")
+	}
+	// 个人信息不应出现在报告中
+	if strings.Contains(html, "alice@acme-corp.com") {
+		t.Error("HTML报告不应泄露个人信息")
+	}
+	// 严重问题所在的文件排在前面
+	if strings.Index(html, "memory.py (1)") > strings.Index(html, "app.py (2)") {
+		t.Error("包含严重问题的文件应该排在前面")
+	}
+}
@@ -0,0 +1,562 @@
+// Package security 实现安全扫描功能
+package security
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"code-context-generator/pkg/types"
+)
+
+// DockerfileDetector Dockerfile配置检测器
+type DockerfileDetector struct {
+	*BaseDetector
+}
+
+// dockerInstruction Dockerfile指令
+type dockerInstruction struct {
+	command string
+	args    string
+	line    int
+}
+
+// NewDockerfileDetector 创建Dockerfile配置检测器
+func NewDockerfileDetector() *DockerfileDetector {
+	return &DockerfileDetector{
+		BaseDetector: NewBaseDetector("dockerfile_misconfig", []string{languageDockerfile}),
+	}
+}
+
+// Detect 检测以root运行、未固定镜像标签和写入镜像的机密
+func (d *DockerfileDetector) Detect(filePath string, content string) []types.SecurityIssue {
+	lines := strings.Split(content, "\n")
+	var issues []types.SecurityIssue
+
+	stages := make(map[string]bool)
+	lastFrom, userLine := 0, 0
+	user := ""
+
+	for _, ins := range parseDockerInstructions(lines) {
+		switch ins.command {
+		case "FROM":
+			fields := strings.Fields(ins.args)
+			for len(fields) > 0 && strings.HasPrefix(fields[0], "--") {
+				fields = fields[1:] // --platform 等选项
+			}
+			if len(fields) == 0 {
+				continue
+			}
+			// 引用前面构建阶段的 FROM 不是外部镜像
+			if !stages[strings.ToLower(fields[0])] {
+				if problem := imageTagProblem(fields[0]); problem != "" {
+					issues = append(issues, iacIssue("IAC_DOCKER_001", filePath, lines, ins.line, types.SeverityMedium,
+						problem+"，构建结果不可复现", "使用固定版本标签或 @sha256 摘要引用基础镜像"))
+				}
+			}
+			if len(fields) >= 3 && strings.EqualFold(fields[1], "as") {
+				stages[strings.ToLower(fields[2])] = true
+			}
+			// 每个构建阶段重新计算运行用户
+			lastFrom, user, userLine = ins.line, "", 0
+		case "USER":
+			user, userLine = strings.TrimSpace(ins.args), ins.line
+		case "ENV", "ARG":
+			for _, kv := range parseDockerAssignments(ins.command, ins.args) {
+				if isSecretName(kv[0]) && isLiteralSecret(kv[1]) {
+					issues = append(issues, iacIssue("IAC_DOCKER_003", filePath, lines, ins.line, types.SeverityHigh,
+						ins.command+" "+kv[0]+" 将机密写入镜像层，可通过 docker history 读取",
+						"构建时使用 --secret 挂载机密，运行时通过编排平台注入环境变量"))
+				}
+			}
+		}
+	}
+
+	// 只有最终阶段决定容器的运行用户
+	if lastFrom > 0 {
+		name := strings.SplitN(user, ":", 2)[0]
+		switch {
+		case user == "":
+			issues = append(issues, iacIssue("IAC_DOCKER_002", filePath, lines, lastFrom, types.SeverityMedium,
+				"最终镜像未声明 USER，容器默认以root运行", "创建非特权用户并在最终阶段使用 USER 切换"))
+		case name == "root" || name == "0":
+			issues = append(issues, iacIssue("IAC_DOCKER_002", filePath, lines, userLine, types.SeverityMedium,
+				"容器显式以root用户运行", "创建非特权用户并在最终阶段使用 USER 切换"))
+		}
+	}
+
+	sortIssuesByLine(issues)
+	return issues
+}
+
+// parseDockerInstructions 解析Dockerfile指令，合并续行
+func parseDockerInstructions(lines []string) []dockerInstruction {
+	var instructions []dockerInstruction
+	var current strings.Builder
+	start := 0
+
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if current.Len() == 0 {
+			if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+				continue
+			}
+			start = i + 1
+		} else if strings.HasPrefix(trimmed, "#") {
+			continue // 续行中的注释
+		}
+
+		if strings.HasSuffix(trimmed, "\\") {
+			current.WriteString(strings.TrimSuffix(trimmed, "\\") + " ")
+			continue
+		}
+		current.WriteString(trimmed)
+
+		text := current.String()
+		current.Reset()
+		parts := strings.SplitN(text, " ", 2)
+		ins := dockerInstruction{command: strings.ToUpper(parts[0]), line: start}
+		if len(parts) == 2 {
+			ins.args = strings.TrimSpace(parts[1])
+		}
+		instructions = append(instructions, ins)
+	}
+	return instructions
+}
+
+// parseDockerAssignments 解析 ENV/ARG 中的键值对
+func parseDockerAssignments(command, args string) [][2]string {
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		return nil
+	}
+	// 旧格式 "ENV KEY value"
+	if command == "ENV" && !strings.Contains(fields[0], "=") {
+		return [][2]string{{fields[0], strings.TrimSpace(strings.TrimPrefix(args, fields[0]))}}
+	}
+
+	var pairs [][2]string
+	for _, field := range fields {
+		if kv := strings.SplitN(field, "=", 2); len(kv) == 2 {
+			pairs = append(pairs, [2]string{kv[0], kv[1]})
+		}
+	}
+	return pairs
+}
+
+// KubernetesDetector Kubernetes清单配置检测器
+type KubernetesDetector struct {
+	*BaseDetector
+}
+
+// NewKubernetesDetector 创建Kubernetes清单配置检测器
+func NewKubernetesDetector() *KubernetesDetector {
+	return &KubernetesDetector{
+		BaseDetector: NewBaseDetector("kubernetes_misconfig", []string{languageYAML}),
+	}
+}
+
+// Detect 检测特权容器、hostPath挂载、主机命名空间和明文机密
+func (d *KubernetesDetector) Detect(filePath string, content string) []types.SecurityIssue {
+	lines := strings.Split(content, "\n")
+	var issues []types.SecurityIssue
+
+	for _, doc := range parseYAMLDocuments(content) {
+		if doc.get("apiVersion") == nil || doc.get("kind") == nil {
+			continue // 不是Kubernetes资源
+		}
+
+		doc.walk(func(m *yamlValue) {
+			for _, key := range []string{"containers", "initContainers", "ephemeralContainers"} {
+				if containers := m.get(key); containers != nil {
+					for _, container := range containers.items {
+						issues = append(issues, d.checkContainer(filePath, lines, container)...)
+					}
+				}
+			}
+
+			if entry, ok := m.entry("hostPath"); ok && len(entry.value.entries) > 0 {
+				path := entry.value.get("path").str()
+				issues = append(issues, iacIssue("IAC_K8S_004", filePath, lines, entry.line, types.SeverityHigh,
+					"hostPath 卷挂载了宿主机路径 "+path+"，容器可访问或篡改节点文件",
+					"使用 emptyDir、ConfigMap 或持久卷代替 hostPath"))
+			}
+
+			for _, key := range []string{"hostNetwork", "hostPID", "hostIPC"} {
+				if entry, ok := m.entry(key); ok && entry.value.isTrue() {
+					issues = append(issues, iacIssue("IAC_K8S_005", filePath, lines, entry.line, types.SeverityMedium,
+						key+": true 使Pod共享宿主机命名空间", "除非确有必要，移除 "+key+" 设置"))
+				}
+			}
+
+			if sc := m.get("securityContext"); sc != nil {
+				if entry, ok := sc.entry("privileged"); ok && entry.value.isTrue() {
+					issues = append(issues, iacIssue("IAC_K8S_002", filePath, lines, entry.line, types.SeverityHigh,
+						"特权容器拥有宿主机的全部能力", "移除 privileged，按需通过 capabilities 授予最小权限"))
+				}
+				if entry, ok := sc.entry("runAsUser"); ok && entry.value.str() == "0" {
+					issues = append(issues, iacIssue("IAC_K8S_003", filePath, lines, entry.line, types.SeverityMedium,
+						"容器以root用户 (runAsUser: 0) 运行", "设置非零的 runAsUser 并启用 runAsNonRoot: true"))
+				}
+			}
+		})
+	}
+
+	sortIssuesByLine(issues)
+	return issues
+}
+
+// checkContainer 检查单个容器定义
+func (d *KubernetesDetector) checkContainer(filePath string, lines []string, container *yamlValue) []types.SecurityIssue {
+	var issues []types.SecurityIssue
+
+	if entry, ok := container.entry("image"); ok {
+		if problem := imageTagProblem(entry.value.str()); problem != "" {
+			issues = append(issues, iacIssue("IAC_K8S_001", filePath, lines, entry.line, types.SeverityMedium,
+				problem+"，部署版本不可控", "使用固定版本标签或 @sha256 摘要"))
+		}
+	}
+
+	if env := container.get("env"); env != nil {
+		for _, item := range env.items {
+			name := item.get("name").str()
+			value, ok := item.entry("value")
+			if !ok || item.get("valueFrom") != nil {
+				continue
+			}
+			if isSecretName(name) && isLiteralSecret(value.value.str()) {
+				issues = append(issues, iacIssue("IAC_K8S_006", filePath, lines, value.line, types.SeverityHigh,
+					"环境变量 "+name+" 以明文写入清单", "使用 Secret 并通过 valueFrom.secretKeyRef 引用"))
+			}
+		}
+	}
+
+	return issues
+}
+
+// ComposeDetector docker-compose配置检测器
+type ComposeDetector struct {
+	*BaseDetector
+}
+
+// composeFilePattern docker-compose 文件名
+var composeFilePattern = regexp.MustCompile(`^(docker-)?compose([.-][\w.-]+)?\.ya?ml$`)
+
+// sensitiveHostPaths 不应挂载进容器的宿主机路径
+var sensitiveHostPaths = map[string]bool{
+	"/": true, "/etc": true, "/root": true, "/proc": true, "/sys": true, "/boot": true,
+	"/var/run/docker.sock": true, "/run/docker.sock": true,
+}
+
+// NewComposeDetector 创建docker-compose配置检测器
+func NewComposeDetector() *ComposeDetector {
+	return &ComposeDetector{
+		BaseDetector: NewBaseDetector("compose_misconfig", []string{languageYAML}),
+	}
+}
+
+// Detect 检测compose服务的特权模式、敏感挂载、主机网络和明文机密
+func (d *ComposeDetector) Detect(filePath string, content string) []types.SecurityIssue {
+	isComposeName := composeFilePattern.MatchString(strings.ToLower(filepath.Base(filePath)))
+	lines := strings.Split(content, "\n")
+	var issues []types.SecurityIssue
+
+	for _, doc := range parseYAMLDocuments(content) {
+		services := doc.get("services")
+		if services == nil || (!isComposeName && doc.get("apiVersion") != nil) {
+			continue
+		}
+		for _, service := range services.entries {
+			issues = append(issues, d.checkService(filePath, lines, service)...)
+		}
+	}
+
+	sortIssuesByLine(issues)
+	return issues
+}
+
+// checkService 检查单个compose服务
+func (d *ComposeDetector) checkService(filePath string, lines []string, service yamlEntry) []types.SecurityIssue {
+	var issues []types.SecurityIssue
+	svc := service.value
+
+	// 同时声明 build 时 image 是构建产物的名称
+	if entry, ok := svc.entry("image"); ok && svc.get("build") == nil {
+		if problem := imageTagProblem(entry.value.str()); problem != "" {
+			issues = append(issues, iacIssue("IAC_COMPOSE_001", filePath, lines, entry.line, types.SeverityMedium,
+				"服务 "+service.key+" 的"+problem, "使用固定版本标签或 @sha256 摘要"))
+		}
+	}
+
+	if entry, ok := svc.entry("privileged"); ok && entry.value.isTrue() {
+		issues = append(issues, iacIssue("IAC_COMPOSE_002", filePath, lines, entry.line, types.SeverityHigh,
+			"服务 "+service.key+" 以特权模式运行", "移除 privileged，按需使用 cap_add 授予最小权限"))
+	}
+
+	if entry, ok := svc.entry("user"); ok {
+		if name := strings.SplitN(entry.value.str(), ":", 2)[0]; name == "root" || name == "0" {
+			issues = append(issues, iacIssue("IAC_COMPOSE_003", filePath, lines, entry.line, types.SeverityMedium,
+				"服务 "+service.key+" 以root用户运行", "指定非特权用户运行服务"))
+		}
+	}
+
+	for _, key := range []string{"network_mode", "pid", "ipc"} {
+		if entry, ok := svc.entry(key); ok && entry.value.str() == "host" {
+			issues = append(issues, iacIssue("IAC_COMPOSE_004", filePath, lines, entry.line, types.SeverityMedium,
+				"服务 "+service.key+" 使用宿主机的 "+key, "除非确有必要，移除 "+key+": host"))
+		}
+	}
+
+	if volumes := svc.get("volumes"); volumes != nil {
+		for _, volume := range volumes.items {
+			source := volume.get("source").str()
+			if volume.isScalar {
+				source = strings.SplitN(volume.scalar, ":", 2)[0]
+			}
+			if sensitiveHostPaths[strings.TrimSuffix(source, "/")] || source == "/" {
+				issues = append(issues, iacIssue("IAC_COMPOSE_005", filePath, lines, volume.line, types.SeverityHigh,
+					"服务 "+service.key+" 挂载了敏感的宿主机路径 "+source, "只挂载服务需要的目录，避免暴露 Docker socket 和系统目录"))
+			}
+		}
+	}
+
+	if env := svc.get("environment"); env != nil {
+		report := func(name, value string, line int) {
+			if isSecretName(name) && isLiteralSecret(value) {
+				issues = append(issues, iacIssue("IAC_COMPOSE_006", filePath, lines, line, types.SeverityHigh,
+					"服务 "+service.key+" 的环境变量 "+name+" 以明文写入", "使用 env_file（不提交到仓库）或 secrets 提供机密"))
+			}
+		}
+		for _, entry := range env.entries {
+			report(entry.key, entry.value.str(), entry.line)
+		}
+		for _, item := range env.items {
+			if kv := strings.SplitN(item.str(), "=", 2); len(kv) == 2 {
+				report(kv[0], kv[1], item.line)
+			}
+		}
+	}
+
+	return issues
+}
+
+// TerraformDetector Terraform配置检测器
+type TerraformDetector struct {
+	*BaseDetector
+}
+
+// NewTerraformDetector 创建Terraform配置检测器
+func NewTerraformDetector() *TerraformDetector {
+	return &TerraformDetector{
+		BaseDetector: NewBaseDetector("terraform_misconfig", []string{languageTerraform}),
+	}
+}
+
+// openCIDRs 对所有地址开放的网段
+var openCIDRs = []string{`"0.0.0.0/0"`, `"::/0"`}
+
+// ingressCIDRAttributes 入站规则中的来源网段属性
+var ingressCIDRAttributes = []string{"cidr_blocks", "ipv6_cidr_blocks", "cidr_ipv4", "cidr_ipv6", "source_ranges"}
+
+// Detect 检测对公网开放的安全组、公开的存储和数据库以及硬编码机密
+func (d *TerraformDetector) Detect(filePath string, content string) []types.SecurityIssue {
+	lines := strings.Split(content, "\n")
+	var issues []types.SecurityIssue
+
+	for _, block := range parseHCLBlocks(lines) {
+		block.walk(func(b *hclBlock) {
+			if b.isIngress() {
+				for _, name := range ingressCIDRAttributes {
+					attr, ok := b.attrs[name]
+					if ok && containsAny(attr.value, openCIDRs) {
+						issues = append(issues, iacIssue("IAC_TF_001", filePath, lines, attr.line, types.SeverityHigh,
+							"入站规则对 0.0.0.0/0 开放，端口暴露在公网", "将来源限制为可信网段或通过负载均衡器暴露服务"))
+					}
+				}
+			}
+
+			if attr, ok := b.attrs["acl"]; ok && strings.Contains(attr.value, `"public-read`) {
+				issues = append(issues, iacIssue("IAC_TF_003", filePath, lines, attr.line, types.SeverityHigh,
+					"存储桶ACL允许公开访问", "使用私有ACL，并开启存储桶公共访问阻止"))
+			}
+			if attr, ok := b.attrs["publicly_accessible"]; ok && attr.value == "true" {
+				issues = append(issues, iacIssue("IAC_TF_004", filePath, lines, attr.line, types.SeverityHigh,
+					"数据库实例可从公网访问", "设置 publicly_accessible = false 并部署在私有子网"))
+			}
+
+			for name, attr := range b.attrs {
+				secretName := name
+				// variable "db_password" { default = "..." }
+				if name == "default" && len(b.header) == 2 && b.header[0] == "variable" {
+					secretName = strings.Trim(b.header[1], `"`)
+				}
+				if isSecretName(secretName) && strings.HasPrefix(attr.value, `"`) &&
+					!strings.Contains(attr.value, "${") && isLiteralSecret(attr.value) {
+					issues = append(issues, iacIssue("IAC_TF_002", filePath, lines, attr.line, types.SeverityHigh,
+						secretName+" 以明文写入Terraform配置，也会进入状态文件",
+						"通过 sensitive 变量、环境变量或密钥管理服务（如 Vault、Secrets Manager）提供"))
+				}
+			}
+		})
+	}
+
+	sortIssuesByLine(issues)
+	return issues
+}
+
+// hclBlock 简化的HCL块
+type hclBlock struct {
+	header   []string
+	line     int
+	attrs    map[string]hclAttr
+	children []*hclBlock
+	parent   *hclBlock
+}
+
+// hclAttr HCL属性
+type hclAttr struct {
+	value string
+	line  int
+}
+
+// walk 遍历块及其子块
+func (b *hclBlock) walk(fn func(*hclBlock)) {
+	fn(b)
+	for _, child := range b.children {
+		child.walk(fn)
+	}
+}
+
+// resourceType 返回块所属资源的类型
+func (b *hclBlock) resourceType() string {
+	for block := b; block != nil; block = block.parent {
+		if len(block.header) >= 2 && block.header[0] == "resource" {
+			return strings.Trim(block.header[1], `"`)
+		}
+	}
+	return ""
+}
+
+// isIngress 检查块是否描述入站规则
+func (b *hclBlock) isIngress() bool {
+	if len(b.header) == 0 {
+		return false
+	}
+	if b.header[0] == "ingress" {
+		return true
+	}
+	if b.header[0] != "resource" {
+		return false
+	}
+	switch b.resourceType() {
+	case "aws_security_group_rule":
+		return b.attrs["type"].value == `"ingress"`
+	case "aws_vpc_security_group_ingress_rule":
+		return true
+	case "google_compute_firewall":
+		return b.attrs["direction"].value != `"EGRESS"`
+	}
+	return false
+}
+
+// hclBlockHeader 块的起始行，例如 resource "aws_instance" "web" {
+var hclBlockHeader = regexp.MustCompile(`^([A-Za-z_][\w-]*(?:\s+(?:"[^"]*"|[A-Za-z_][\w-]*))*)\s*\{$`)
+
+// hclAttribute 属性赋值行
+var hclAttribute = regexp.MustCompile(`^([A-Za-z_][\w-]*)\s*=\s*(.*)$`)
+
+// parseHCLBlocks 按行解析HCL的块和属性，足以支撑配置检查
+func parseHCLBlocks(lines []string) []*hclBlock {
+	root := &hclBlock{attrs: make(map[string]hclAttr)}
+	current := root
+	heredoc := ""
+
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if heredoc != "" {
+			if line == heredoc {
+				heredoc = ""
+			}
+			continue
+		}
+		line = strings.TrimSpace(stripHCLComment(line))
+		if line == "" {
+			continue
+		}
+
+		if line == "}" || line == "]" || line == "})" {
+			if current.parent != nil {
+				current = current.parent
+			}
+			continue
+		}
+
+		if match := hclBlockHeader.FindStringSubmatch(line); match != nil {
+			block := &hclBlock{header: strings.Fields(match[1]), line: i + 1, attrs: make(map[string]hclAttr), parent: current}
+			current.children = append(current.children, block)
+			current = block
+			continue
+		}
+
+		match := hclAttribute.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		name, value, start := match[1], strings.TrimSpace(match[2]), i+1
+
+		switch {
+		case value == "{":
+			// 映射属性按子块处理
+			block := &hclBlock{header: []string{name}, line: start, attrs: make(map[string]hclAttr), parent: current}
+			current.children = append(current.children, block)
+			current = block
+			continue
+		case strings.HasPrefix(value, "<<"):
+			heredoc = strings.TrimPrefix(strings.TrimPrefix(value, "<<"), "-")
+		case strings.Count(value, "[") > strings.Count(value, "]"):
+			// 合并多行列表
+			for i+1 < len(lines) && strings.Count(value, "[") > strings.Count(value, "]") {
+				i++
+				value += " " + strings.TrimSpace(stripHCLComment(lines[i]))
+			}
+		}
+		current.attrs[name] = hclAttr{value: value, line: start}
+	}
+
+	return root.children
+}
+
+// containsAny 检查字符串是否包含任一子串
+func containsAny(s string, substrings []string) bool {
+	for _, sub := range substrings {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// stripHCLComment 去除字符串之外的 # 和 // 注释
+func stripHCLComment(line string) string {
+	inString := false
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && inString:
+			i++ // 跳过转义字符
+		case line[i] == '"':
+			inString = !inString
+		case inString:
+		case line[i] == '#', line[i] == '/' && i+1 < len(line) && line[i+1] == '/':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// sortIssuesByLine 按行号排序问题，保证遍历映射时输出稳定
+func sortIssuesByLine(issues []types.SecurityIssue) {
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Line < issues[j].Line
+	})
+}
@@ -0,0 +1,276 @@
+// Package security 基础设施即代码检测器测试
+package security
+
+import (
+	"testing"
+
+	"code-context-generator/pkg/types"
+)
+
+// iacTestCase IaC检测器测试用例
+type iacTestCase struct {
+	name     string
+	file     string
+	content  string
+	expected []string // 期望的问题ID
+}
+
+// runIaCCases 运行IaC检测器测试用例
+func runIaCCases(t *testing.T, detector types.SecurityDetector, testCases []iacTestCase) {
+	t.Helper()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			issues := detector.Detect(tc.file, tc.content)
+			if len(issues) != len(tc.expected) {
+				for _, issue := range issues {
+					t.Logf("%s:%d %s", issue.ID, issue.Line, issue.Message)
+				}
+				t.Fatalf("期望发现 %d 个问题，实际发现 %d 个问题", len(tc.expected), len(issues))
+			}
+			for i, id := range tc.expected {
+				if issues[i].ID != id {
+					t.Errorf("第 %d 个问题期望 %s，实际 %s", i, id, issues[i].ID)
+				}
+			}
+		})
+	}
+}
+
+// TestDockerfileDetector 测试Dockerfile检测器
+func TestDockerfileDetector(t *testing.T) {
+	runIaCCases(t, NewDockerfileDetector(), []iacTestCase{
+		{
+			name: "latest镜像、root用户和明文机密",
+			file: "Dockerfile",
+			content: `FROM node:latest
+ENV DB_PASSWORD=hunter2 NODE_ENV=production
+ARG API_KEY=abc123
+RUN npm install
+`,
+			expected: []string{"IAC_DOCKER_001", "IAC_DOCKER_002", "IAC_DOCKER_003", "IAC_DOCKER_003"},
+		},
+		{
+			name: "固定标签并切换到普通用户",
+			file: "build/app.dockerfile",
+			content: `ARG BASE=golang:1.24
+FROM ${BASE} AS build
+ENV TOKEN_FILE=/run/secrets/token
+ARG GITHUB_TOKEN
+FROM alpine:3.20
+USER app
+`,
+			expected: nil,
+		},
+		{
+			name: "最终阶段切换回root",
+			file: "Containerfile",
+			content: `FROM alpine:3.20
+USER app
+USER root
+`,
+			expected: []string{"IAC_DOCKER_002"},
+		},
+	})
+}
+
+// TestKubernetesDetector 测试Kubernetes清单检测器
+func TestKubernetesDetector(t *testing.T) {
+	runIaCCases(t, NewKubernetesDetector(), []iacTestCase{
+		{
+			name: "特权容器和主机资源",
+			file: "deploy.yaml",
+			content: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      hostNetwork: true
+      containers:
+        - name: web
+          image: nginx
+          securityContext:
+            privileged: true
+            runAsUser: 0
+          env:
+            - name: DB_PASSWORD
+              value: hunter2
+            - name: API_TOKEN
+              valueFrom:
+                secretKeyRef:
+                  name: api
+                  key: token
+      volumes:
+        - name: docker
+          hostPath:
+            path: /var/run/docker.sock
+`,
+			expected: []string{"IAC_K8S_005", "IAC_K8S_001", "IAC_K8S_002", "IAC_K8S_003", "IAC_K8S_006", "IAC_K8S_004"},
+		},
+		{
+			name: "安全的Pod",
+			file: "pod.yml",
+			content: `apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  securityContext:
+    runAsNonRoot: true
+  containers:
+    - name: web
+      image: nginx:1.27
+      securityContext:
+        privileged: false
+---
+apiVersion: v1
+kind: ConfigMap
+data:
+  password: not-a-container
+`,
+			expected: nil,
+		},
+		{
+			name:     "普通YAML文件",
+			file:     "config.yaml",
+			content:  "containers:\n  - image: nginx\n    privileged: true\n",
+			expected: nil,
+		},
+	})
+}
+
+// TestComposeDetector 测试docker-compose检测器
+func TestComposeDetector(t *testing.T) {
+	runIaCCases(t, NewComposeDetector(), []iacTestCase{
+		{
+			name: "不安全的服务配置",
+			file: "docker-compose.yml",
+			content: `services:
+  app:
+    image: myapp:latest
+    privileged: true
+    user: root
+    network_mode: host
+    volumes:
+      - /var/run/docker.sock:/var/run/docker.sock
+      - ./data:/data
+    environment:
+      - DB_PASSWORD=hunter2
+      - LOG_LEVEL=debug
+  db:
+    image: postgres:16
+    environment:
+      POSTGRES_PASSWORD: ${POSTGRES_PASSWORD}
+`,
+			expected: []string{"IAC_COMPOSE_001", "IAC_COMPOSE_002", "IAC_COMPOSE_003", "IAC_COMPOSE_004", "IAC_COMPOSE_005", "IAC_COMPOSE_006"},
+		},
+		{
+			name: "安全的服务配置",
+			file: "compose.yaml",
+			content: `services:
+  web:
+    build: .
+    user: "1000:1000"
+    volumes:
+      - ./static:/usr/share/nginx/html:ro
+`,
+			expected: nil,
+		},
+	})
+}
+
+// TestTerraformDetector 测试Terraform检测器
+func TestTerraformDetector(t *testing.T) {
+	runIaCCases(t, NewTerraformDetector(), []iacTestCase{
+		{
+			name: "开放入站规则和公开资源",
+			file: "main.tf",
+			content: `resource "aws_security_group" "web" {
+  name = "web"
+
+  ingress {
+    from_port   = 22
+    to_port     = 22
+    protocol    = "tcp"
+    cidr_blocks = [
+      "0.0.0.0/0",
+    ]
+  }
+
+  egress {
+    cidr_blocks = ["0.0.0.0/0"]
+  }
+}
+
+resource "aws_s3_bucket_acl" "logs" {
+  acl = "public-read" # 公开读取
+}
+
+resource "aws_db_instance" "db" {
+  publicly_accessible = true
+  password            = "hunter2"
+}
+
+variable "api_token" {
+  default = "abc123"
+}
+`,
+			expected: []string{"IAC_TF_001", "IAC_TF_003", "IAC_TF_004", "IAC_TF_002", "IAC_TF_002"},
+		},
+		{
+			name: "引用变量且限制来源",
+			file: "network.tf",
+			content: `resource "aws_security_group_rule" "ssh" {
+  type        = "ingress"
+  cidr_blocks = ["10.0.0.0/8"]
+}
+
+resource "aws_db_instance" "db" {
+  password = var.db_password
+  // url = "https://example.com/0.0.0.0/0"
+}
+
+variable "db_password" {
+  sensitive = true
+}
+`,
+			expected: nil,
+		},
+	})
+}
+
+// TestScanIaCFiles 测试扫描器识别IaC文件
+func TestScanIaCFiles(t *testing.T) {
+	files := []types.FileInfo{
+		{Path: "docker/Dockerfile", Content: "FROM ubuntu\n"},
+		{Path: "infra/main.tf", Content: "resource \"aws_db_instance\" \"db\" {\n  publicly_accessible = true\n}\n"},
+	}
+
+	report, err := NewSecurityScanner(allDetectorsConfig("standard")).ScanFileInfos(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Summary.ScannedFiles != 2 {
+		t.Errorf("期望扫描 2 个文件，实际 %d", report.Summary.ScannedFiles)
+	}
+
+	found := make(map[string]bool)
+	for _, issue := range report.Issues {
+		found[issue.ID] = true
+	}
+	for _, id := range []string{"IAC_DOCKER_001", "IAC_DOCKER_002", "IAC_TF_004"} {
+		if !found[id] {
+			t.Errorf("期望发现问题 %s", id)
+		}
+	}
+
+	// 基础级别不运行IaC检测器
+	report, err = NewSecurityScanner(allDetectorsConfig("basic")).ScanFileInfos(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Summary.IssuesFound != 0 {
+		t.Errorf("期望发现 0 个问题，实际发现 %d 个问题", report.Summary.IssuesFound)
+	}
+}
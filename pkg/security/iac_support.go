@@ -0,0 +1,273 @@
+// Package security 实现安全扫描功能
+package security
+
+import (
+	"path/filepath"
+	"strings"
+
+	"code-context-generator/pkg/types"
+
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+)
+
+// IaC 文件语言
+const (
+	languageDockerfile = "dockerfile"
+	languageTerraform  = "terraform"
+	languageYAML       = "yaml"
+)
+
+// iacLanguage 根据文件名识别没有通用扩展名的IaC文件
+func iacLanguage(filePath string) string {
+	name := strings.ToLower(filepath.Base(filePath))
+	switch {
+	case name == "dockerfile", name == "containerfile",
+		strings.HasPrefix(name, "dockerfile."), strings.HasSuffix(name, ".dockerfile"):
+		return languageDockerfile
+	case strings.HasSuffix(name, ".tf"):
+		return languageTerraform
+	}
+	return ""
+}
+
+// yamlValue 带行号的YAML节点
+type yamlValue struct {
+	line     int
+	scalar   string
+	isScalar bool
+	entries  []yamlEntry
+	items    []*yamlValue
+}
+
+// yamlEntry 映射中的键值对
+type yamlEntry struct {
+	key   string
+	line  int
+	value *yamlValue
+}
+
+// parseYAMLDocuments 解析多文档YAML，语法错误时返回nil
+func parseYAMLDocuments(content string) []*yamlValue {
+	file, err := parser.ParseBytes([]byte(content), 0)
+	if err != nil {
+		return nil
+	}
+
+	var docs []*yamlValue
+	for _, doc := range file.Docs {
+		if value := convertYAML(doc.Body); value != nil {
+			docs = append(docs, value)
+		}
+	}
+	return docs
+}
+
+// convertYAML 将语法树节点转换为 yamlValue
+func convertYAML(node ast.Node) *yamlValue {
+	if node == nil {
+		return nil
+	}
+	value := &yamlValue{}
+	if tk := node.GetToken(); tk != nil && tk.Position != nil {
+		value.line = tk.Position.Line
+	}
+
+	switch n := node.(type) {
+	case *ast.MappingNode:
+		for _, mv := range n.Values {
+			value.entries = append(value.entries, convertEntry(mv))
+		}
+	case *ast.MappingValueNode:
+		value.entries = append(value.entries, convertEntry(n))
+	case *ast.SequenceNode:
+		for _, item := range n.Values {
+			if child := convertYAML(item); child != nil {
+				value.items = append(value.items, child)
+			}
+		}
+	case *ast.TagNode:
+		return convertYAML(n.Value)
+	case *ast.AnchorNode:
+		return convertYAML(n.Value)
+	case *ast.AliasNode:
+		return nil
+	case *ast.StringNode:
+		value.isScalar, value.scalar = true, n.Value
+	case *ast.LiteralNode:
+		value.isScalar, value.scalar = true, n.Value.Value
+	case *ast.NullNode:
+		value.isScalar = true
+	default:
+		value.isScalar = true
+		if tk := node.GetToken(); tk != nil {
+			value.scalar = tk.Value
+		}
+	}
+	return value
+}
+
+// convertEntry 转换映射键值对
+func convertEntry(mv *ast.MappingValueNode) yamlEntry {
+	entry := yamlEntry{value: convertYAML(mv.Value)}
+	if tk := mv.Key.GetToken(); tk != nil {
+		entry.key = tk.Value
+		if tk.Position != nil {
+			entry.line = tk.Position.Line
+		}
+	}
+	return entry
+}
+
+// get 获取映射中的值
+func (v *yamlValue) get(key string) *yamlValue {
+	if v == nil {
+		return nil
+	}
+	for _, entry := range v.entries {
+		if entry.key == key {
+			return entry.value
+		}
+	}
+	return nil
+}
+
+// entry 获取映射中的键值对
+func (v *yamlValue) entry(key string) (yamlEntry, bool) {
+	if v != nil {
+		for _, entry := range v.entries {
+			if entry.key == key {
+				return entry, true
+			}
+		}
+	}
+	return yamlEntry{}, false
+}
+
+// str 返回标量值，非标量返回空字符串
+func (v *yamlValue) str() string {
+	if v == nil || !v.isScalar {
+		return ""
+	}
+	return v.scalar
+}
+
+// isTrue 检查标量是否为真值
+func (v *yamlValue) isTrue() bool {
+	switch strings.ToLower(v.str()) {
+	case "true", "yes", "on":
+		return true
+	}
+	return false
+}
+
+// walk 深度优先遍历所有映射节点
+func (v *yamlValue) walk(fn func(*yamlValue)) {
+	if v == nil {
+		return
+	}
+	if len(v.entries) > 0 {
+		fn(v)
+	}
+	for _, entry := range v.entries {
+		entry.value.walk(fn)
+	}
+	for _, item := range v.items {
+		item.walk(fn)
+	}
+}
+
+// secretNameWords 表明变量保存机密信息的单词
+var secretNameWords = map[string]bool{
+	"password": true, "passwd": true, "secret": true, "token": true,
+	"credential": true, "credentials": true, "apikey": true, "passphrase": true,
+}
+
+// secretKeyQualifiers 与 key 组合表示机密的单词
+var secretKeyQualifiers = map[string]bool{
+	"api": true, "access": true, "private": true, "auth": true, "secret": true, "encryption": true,
+}
+
+// secretPathSuffixes 表明变量保存的是路径而非机密本身的后缀
+var secretPathSuffixes = map[string]bool{
+	"file": true, "path": true, "dir": true,
+}
+
+// isSecretName 检查变量名是否表示机密信息
+func isSecretName(name string) bool {
+	words := splitIdentifier(strings.ReplaceAll(name, ".", "_"))
+	// DB_PASSWORD_FILE 等变量保存的是机密文件路径
+	if len(words) > 0 && secretPathSuffixes[words[len(words)-1]] {
+		return false
+	}
+	if hasAnyWord(words, secretNameWords) {
+		return true
+	}
+	for i := 1; i < len(words); i++ {
+		if words[i] == "key" && secretKeyQualifiers[words[i-1]] {
+			return true
+		}
+	}
+	return false
+}
+
+// isLiteralSecret 检查值是否为直接写入的机密，而不是变量引用
+func isLiteralSecret(value string) bool {
+	value = strings.Trim(strings.TrimSpace(value), `"'`)
+	if value == "" {
+		return false
+	}
+	// 环境变量、模板和Terraform变量引用
+	for _, prefix := range []string{"$", "{{", "var.", "local.", "data.", "module."} {
+		if strings.HasPrefix(value, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// imageTagProblem 检查镜像引用是否使用了 latest 或未固定标签
+func imageTagProblem(image string) string {
+	image = strings.TrimSpace(image)
+	if image == "" || image == "scratch" || strings.Contains(image, "$") || strings.Contains(image, "@sha256:") {
+		return ""
+	}
+	// 去掉仓库地址中的端口后再查找标签
+	name := image[strings.LastIndex(image, "/")+1:]
+	idx := strings.LastIndex(name, ":")
+	switch {
+	case idx < 0:
+		return "镜像 " + image + " 未指定标签，默认使用 latest"
+	case name[idx+1:] == "latest":
+		return "镜像 " + image + " 使用 latest 标签"
+	}
+	return ""
+}
+
+// lineSnippet 返回指定行的内容
+func lineSnippet(lines []string, line int) string {
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	return strings.TrimSpace(lines[line-1])
+}
+
+// iacIssue 创建IaC配置问题
+func iacIssue(id, filePath string, lines []string, line int, severity types.SeverityLevel, message, recommendation string) types.SecurityIssue {
+	column := 1
+	if line >= 1 && line <= len(lines) {
+		column += len(lines[line-1]) - len(strings.TrimLeft(lines[line-1], " \t"))
+	}
+	return types.SecurityIssue{
+		ID:             id,
+		Type:           "IaCMisconfiguration",
+		Severity:       severity,
+		Message:        message,
+		File:           filePath,
+		Line:           line,
+		Column:         column,
+		Snippet:        lineSnippet(lines, line),
+		Recommendation: recommendation,
+		Confidence:     0.85,
+	}
+}
@@ -15,6 +15,7 @@ type SecurityIntegration struct {
 	scanner *SecurityScanner
 	config  *types.SecurityConfig
 	enabled bool
+	files   []types.FileInfo // 最近一次 ScanFiles 扫描的文件，供报告读取源码
 }
 
 // NewSecurityIntegration 创建安全扫描集成器
@@ -45,8 +46,8 @@ func (si *SecurityIntegration) ScanProject(projectPath string) (*types.SecurityR
 	return si.scanner.Scan(projectPath)
 }
 
-// ScanFiles 扫描多个文件
-func (si *SecurityIntegration) ScanFiles(files []string) (*types.SecurityReport, error) {
+// ScanFiles 扫描遍历器已加载的文件，所有文件共用一次扫描
+func (si *SecurityIntegration) ScanFiles(files []types.FileInfo) (*types.SecurityReport, error) {
 	if !si.enabled {
 		scanID, _ := generateScanID()
 		return &types.SecurityReport{
@@ -62,62 +63,8 @@ func (si *SecurityIntegration) ScanFiles(files []string) (*types.SecurityReport,
 		}, nil
 	}
 
-	var allIssues []types.SecurityIssue
-	startTime := time.Now()
-	var scannedFiles []string
-
-	for _, file := range files {
-		if !si.isSupportedExtension(file) {
-			continue
-		}
-
-		// 扫描单个文件
-		fileReport, err := si.scanner.Scan(file)
-		if err != nil {
-			continue // 跳过无法扫描的文件
-		}
-
-		allIssues = append(allIssues, fileReport.Issues...)
-		scannedFiles = append(scannedFiles, file)
-	}
-
-	scanDuration := time.Since(startTime)
-	scanID, _ := generateScanID()
-
-	// 统计问题数量
-	var critical, high, medium, low int
-	for _, issue := range allIssues {
-		switch issue.Severity {
-		case types.SeverityCritical:
-			critical++
-		case types.SeverityHigh:
-			high++
-		case types.SeverityMedium:
-			medium++
-		case types.SeverityLow:
-			low++
-		}
-	}
-
-	summary := types.ScanSummary{
-		TotalFiles:     len(files),
-		ScannedFiles:   len(scannedFiles),
-		IssuesFound:    len(allIssues),
-		CriticalIssues: critical,
-		HighIssues:     high,
-		MediumIssues:   medium,
-		LowIssues:      low,
-	}
-
-	report := &types.SecurityReport{
-		ScanID:       scanID,
-		Timestamp:    startTime,
-		ScanDuration: scanDuration,
-		Summary:      summary,
-		Issues:       allIssues,
-	}
-
-	return report, nil
+	si.files = files
+	return si.scanner.ScanFileInfos(files)
 }
 
 // shouldScanFile 判断是否应该扫描文件
@@ -156,6 +103,7 @@ func (si *SecurityIntegration) GenerateReport(report *types.SecurityReport, outp
 	}
 
 	reporter := NewSecurityReporter()
+	reporter.SetSources(si.files)
 	reportData, err := reporter.Generate(report)
 	if err != nil {
 		return fmt.Errorf("生成报告失败: %v", err)
@@ -200,8 +148,50 @@ func (si *SecurityIntegration) PrintSummary(report *types.SecurityReport) {
 	fmt.Printf("  🟢 低危问题: %d\n", report.Summary.LowIssues)
 	fmt.Printf("  ⏱️  扫描时间: %s\n", report.ScanDuration.String())
 	fmt.Printf("  📅 扫描时间: %s\n", report.Timestamp.Format("2006-01-02 15:04:05"))
+	if len(report.Errors) > 0 {
+		fmt.Printf("  ❗ 检测器错误: %d\n", len(report.Errors))
+		for _, scanErr := range report.Errors {
+			fmt.Printf("     %s: %s\n", scanErr.File, scanErr.Message)
+		}
+	}
 
 	if report.Summary.IssuesFound > 0 {
 		fmt.Printf("\n💡 建议查看详细报告以了解具体问题\n")
 	}
 }
+
+// AttachFindings 将扫描发现的问题附加到对应文件，并记录扫描摘要
+func (si *SecurityIntegration) AttachFindings(data *types.ContextData, report *types.SecurityReport) {
+	if !si.enabled || data == nil || report == nil {
+		return
+	}
+
+	issuesByFile := make(map[string][]types.SecurityIssue)
+	for _, issue := range report.Issues {
+		key := filepath.ToSlash(filepath.Clean(issue.File))
+		issuesByFile[key] = append(issuesByFile[key], issue)
+	}
+
+	attachToFiles(data.Files, issuesByFile)
+	for i := range data.Folders {
+		attachToFolder(&data.Folders[i], issuesByFile)
+	}
+
+	summary := report.Summary
+	data.Security = &summary
+}
+
+// attachToFolder 递归附加文件夹中文件的问题
+func attachToFolder(folder *types.FolderInfo, issuesByFile map[string][]types.SecurityIssue) {
+	attachToFiles(folder.Files, issuesByFile)
+	for i := range folder.Folders {
+		attachToFolder(&folder.Folders[i], issuesByFile)
+	}
+}
+
+// attachToFiles 按路径为文件附加问题
+func attachToFiles(files []types.FileInfo, issuesByFile map[string][]types.SecurityIssue) {
+	for i := range files {
+		files[i].Issues = issuesByFile[filepath.ToSlash(filepath.Clean(files[i].Path))]
+	}
+}
@@ -0,0 +1,394 @@
+// Package security 实现安全扫描功能
+package security
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+
+	"code-context-generator/pkg/types"
+)
+
+// 个人信息类型
+const (
+	PIIEmail      = "email"
+	PIIPhone      = "phone"
+	PIICreditCard = "credit_card"
+	PIIIBAN       = "iban"
+	PIIIPAddress  = "ip_address"
+	PIIChineseID  = "cn_id"
+)
+
+// PII 处理模式
+const (
+	PIIModeReport = "report"
+	PIIModeMask   = "mask"
+)
+
+// piiRule 个人信息识别规则
+type piiRule struct {
+	kind     string
+	label    string // 问题描述中的名称
+	mask     string // 遮蔽时的替换文本
+	pattern  *regexp.Regexp
+	validate func(match string) bool
+	severity types.SeverityLevel
+}
+
+// piiRules 所有个人信息规则，按遮蔽优先级排序：较长的号码先于可能与其重叠的电话号码处理
+var piiRules = []piiRule{
+	{
+		kind:     PIIChineseID,
+		label:    "居民身份证号",
+		mask:     "[CN_ID]",
+		pattern:  regexp.MustCompile(`\d{17}[\dXx]`),
+		validate: validChineseID,
+		severity: types.SeverityHigh,
+	},
+	{
+		kind:     PIICreditCard,
+		label:    "银行卡号",
+		mask:     "[CREDIT_CARD]",
+		pattern:  regexp.MustCompile(`\d(?:[ -]?\d){12,18}`),
+		validate: validCreditCard,
+		severity: types.SeverityHigh,
+	},
+	{
+		kind:     PIIIBAN,
+		label:    "IBAN账号",
+		mask:     "[IBAN]",
+		pattern:  regexp.MustCompile(`[A-Z]{2}\d{2}(?: ?[A-Z0-9]{4}){2,7}(?: ?[A-Z0-9]{1,3})?`),
+		validate: validIBAN,
+		severity: types.SeverityHigh,
+	},
+	{
+		kind:     PIIEmail,
+		label:    "邮箱地址",
+		mask:     "[EMAIL]",
+		pattern:  regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`),
+		validate: validEmail,
+		severity: types.SeverityMedium,
+	},
+	{
+		kind:  PIIPhone,
+		label: "电话号码",
+		mask:  "[PHONE]",
+		// 中国大陆手机号、带国家码的国际号码和北美格式号码
+		pattern:  regexp.MustCompile(`(?:\+?86[ -]?)?1[3-9]\d{9}|\+\d{1,3}[ .-]?\(?\d{1,4}\)?(?:[ .-]?\d{2,4}){2,4}|\(\d{3}\) ?\d{3}-\d{4}|\d{3}-\d{3}-\d{4}`),
+		validate: validPhone,
+		severity: types.SeverityMedium,
+	},
+	{
+		kind:     PIIIPAddress,
+		label:    "IP地址",
+		mask:     "[IP_ADDRESS]",
+		pattern:  regexp.MustCompile(`(?:\d{1,3}\.){3}\d{1,3}`),
+		validate: validPublicIP,
+		severity: types.SeverityLow,
+	},
+}
+
+// PIIDetector 个人信息检测器，每种信息类型对应一个检测器
+type PIIDetector struct {
+	*BaseDetector
+	rule piiRule
+}
+
+// piiLanguages 个人信息可能出现在任何文本文件中，包括测试数据和SQL种子文件
+var piiLanguages = []string{
+	"go", "python", "javascript", "java", "php", "ruby", "cpp", "c", "csharp", "swift", "rust",
+	"yaml", "json", "xml", "toml", "ini", "config", "sql", "csv", "text",
+}
+
+// NewPIIDetectors 创建所有个人信息检测器
+func NewPIIDetectors() []*PIIDetector {
+	detectors := make([]*PIIDetector, len(piiRules))
+	for i, rule := range piiRules {
+		detectors[i] = &PIIDetector{
+			BaseDetector: NewBaseDetector("pii_"+rule.kind, piiLanguages),
+			rule:         rule,
+		}
+	}
+	return detectors
+}
+
+// Detect 检测个人信息，问题中的代码片段已遮蔽
+func (d *PIIDetector) Detect(filePath string, content string) []types.SecurityIssue {
+	var issues []types.SecurityIssue
+	// 报告本身不应泄露个人信息，代码片段遮蔽所有类型
+	snippetMasker := &PIIMasker{rules: piiRules}
+
+	for lineNum, line := range strings.Split(content, "\n") {
+		locs := findPII(d.rule, line)
+		if len(locs) == 0 {
+			continue
+		}
+		snippet, _ := snippetMasker.Mask(line)
+
+		for _, loc := range locs {
+			issues = append(issues, types.SecurityIssue{
+				ID:             "PII_" + strings.ToUpper(d.rule.kind),
+				Type:           "PersonalInformation",
+				Severity:       d.rule.severity,
+				Message:        fmt.Sprintf("发现%s", d.rule.label),
+				File:           filePath,
+				Line:           lineNum + 1,
+				Column:         loc[0] + 1,
+				Snippet:        strings.TrimSpace(snippet),
+				Recommendation: "使用虚构数据替换测试数据中的个人信息，或在生成上下文时启用个人信息遮蔽",
+				Confidence:     0.8,
+			})
+		}
+	}
+
+	return issues
+}
+
+// PIIMasker 遮蔽内容中的个人信息
+type PIIMasker struct {
+	rules []piiRule
+}
+
+// NewPIIMasker 根据配置创建个人信息遮蔽器
+func NewPIIMasker(config *types.PIIConfig) (*PIIMasker, error) {
+	enabled, err := enabledPIITypes(config.Types)
+	if err != nil {
+		return nil, err
+	}
+
+	masker := &PIIMasker{}
+	for _, rule := range piiRules {
+		if len(enabled) == 0 || enabled[rule.kind] {
+			masker.rules = append(masker.rules, rule)
+		}
+	}
+	return masker, nil
+}
+
+// Mask 遮蔽内容中的个人信息，返回遮蔽后的内容和遮蔽数量
+func (m *PIIMasker) Mask(content string) (string, int) {
+	total := 0
+	for _, rule := range m.rules {
+		locs := findPII(rule, content)
+		if len(locs) == 0 {
+			continue
+		}
+
+		var builder strings.Builder
+		last := 0
+		for _, loc := range locs {
+			builder.WriteString(content[last:loc[0]])
+			builder.WriteString(rule.mask)
+			last = loc[1]
+		}
+		builder.WriteString(content[last:])
+		content = builder.String()
+		total += len(locs)
+	}
+	return content, total
+}
+
+// ValidatePIIConfig 检查个人信息配置中的模式和类型
+func ValidatePIIConfig(config *types.PIIConfig) error {
+	switch config.Mode {
+	case "", PIIModeReport, PIIModeMask:
+	default:
+		return fmt.Errorf("无效的个人信息处理模式: %s (可选: report, mask)", config.Mode)
+	}
+	_, err := enabledPIITypes(config.Types)
+	return err
+}
+
+// enabledPIITypes 解析启用的个人信息类型
+func enabledPIITypes(kinds []string) (map[string]bool, error) {
+	enabled := make(map[string]bool, len(kinds))
+	for _, kind := range kinds {
+		kind = strings.ToLower(strings.TrimSpace(kind))
+		if !isPIIKind(kind) {
+			return nil, fmt.Errorf("未知的个人信息类型: %s", kind)
+		}
+		enabled[kind] = true
+	}
+	return enabled, nil
+}
+
+// isPIIKind 检查是否为已知的个人信息类型
+func isPIIKind(kind string) bool {
+	for _, rule := range piiRules {
+		if rule.kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// findPII 查找通过校验且边界完整的匹配位置
+func findPII(rule piiRule, text string) [][]int {
+	var locs [][]int
+	for _, loc := range rule.pattern.FindAllStringIndex(text, -1) {
+		if !isTokenBoundary(text, loc[0], loc[1]) {
+			continue
+		}
+		if rule.validate(text[loc[0]:loc[1]]) {
+			locs = append(locs, loc)
+		}
+	}
+	return locs
+}
+
+// isTokenBoundary 检查匹配前后不是字母、数字或小数点，避免截取更长号码的一部分
+func isTokenBoundary(text string, start, end int) bool {
+	if start > 0 && isTokenChar(text[start-1]) {
+		return false
+	}
+	if end < len(text) {
+		next := text[end]
+		if isTokenChar(next) {
+			return false
+		}
+		// 版本号 1.2.3.4.5 等
+		if next == '.' && end+1 < len(text) && text[end+1] >= '0' && text[end+1] <= '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// isTokenChar 检查字符是否为字母、数字或下划线
+func isTokenChar(c byte) bool {
+	return c == '_' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// digitsOnly 提取字符串中的数字
+func digitsOnly(s string) string {
+	var builder strings.Builder
+	for _, c := range s {
+		if c >= '0' && c <= '9' {
+			builder.WriteRune(c)
+		}
+	}
+	return builder.String()
+}
+
+// validChineseID 校验18位居民身份证号的地区码、出生日期和校验码
+func validChineseID(id string) bool {
+	if id[0] < '1' || id[0] > '8' {
+		return false
+	}
+
+	birth, err := time.Parse("20060102", id[6:14])
+	if err != nil || birth.Year() < 1900 || birth.After(time.Now()) {
+		return false
+	}
+
+	weights := []int{7, 9, 10, 5, 8, 4, 2, 1, 6, 3, 7, 9, 10, 5, 8, 4, 2}
+	sum := 0
+	for i, w := range weights {
+		sum += int(id[i]-'0') * w
+	}
+	return "10X98765432"[sum%11] == strings.ToUpper(id[17:])[0]
+}
+
+// validCreditCard 校验银行卡号的发卡机构前缀和Luhn校验位
+func validCreditCard(number string) bool {
+	digits := digitsOnly(number)
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+
+	// Visa、Mastercard、American Express、Discover、JCB、银联
+	prefixes := []string{"4", "51", "52", "53", "54", "55", "22", "23", "24", "25", "26", "27", "34", "37", "6011", "65", "35", "62"}
+	matched := false
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(digits, prefix) {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		return false
+	}
+
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// validIBAN 校验IBAN的长度和 mod-97 校验码
+func validIBAN(iban string) bool {
+	iban = strings.ReplaceAll(iban, " ", "")
+	if len(iban) < 15 || len(iban) > 34 {
+		return false
+	}
+
+	// 将前四位移到末尾，字母转换为数字
+	rearranged := iban[4:] + iban[:4]
+	var numeric strings.Builder
+	for _, c := range rearranged {
+		switch {
+		case c >= '0' && c <= '9':
+			numeric.WriteRune(c)
+		case c >= 'A' && c <= 'Z':
+			numeric.WriteString(fmt.Sprintf("%d", c-'A'+10))
+		default:
+			return false
+		}
+	}
+
+	n, ok := new(big.Int).SetString(numeric.String(), 10)
+	return ok && n.Mod(n, big.NewInt(97)).Int64() == 1
+}
+
+// exampleEmailDomains 文档和测试中使用的保留域名
+var exampleEmailDomains = []string{"example.com", "example.org", "example.net", "test", "invalid", "localhost", "example"}
+
+// validEmail 排除保留的示例域名
+func validEmail(email string) bool {
+	domain := strings.ToLower(email[strings.LastIndex(email, "@")+1:])
+	for _, reserved := range exampleEmailDomains {
+		if domain == reserved || strings.HasSuffix(domain, "."+reserved) {
+			return false
+		}
+	}
+	return true
+}
+
+// validPhone 校验电话号码的数字位数
+func validPhone(phone string) bool {
+	digits := len(digitsOnly(phone))
+	return digits >= 10 && digits <= 15
+}
+
+// validPublicIP 只报告公网IPv4地址，内网、回环和文档保留地址属于基础设施配置
+func validPublicIP(address string) bool {
+	ip := net.ParseIP(address)
+	if ip == nil || ip.To4() == nil {
+		return false
+	}
+	if ip.IsPrivate() || ip.IsLoopback() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsMulticast() || ip.Equal(net.IPv4bcast) {
+		return false
+	}
+	// RFC 5737 文档地址
+	for _, cidr := range []string{"192.0.2.0/24", "198.51.100.0/24", "203.0.113.0/24"} {
+		_, network, _ := net.ParseCIDR(cidr)
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,111 @@
+// Package security 个人信息检测测试
+package security
+
+import (
+	"strings"
+	"testing"
+
+	"code-context-generator/pkg/types"
+)
+
+// TestPIIDetectors 测试各类个人信息检测及校验
+func TestPIIDetectors(t *testing.T) {
+	detectors := make(map[string]*PIIDetector)
+	for _, detector := range NewPIIDetectors() {
+		detectors[detector.GetName()] = detector
+	}
+
+	testCases := []struct {
+		name     string
+		detector string
+		content  string
+		expected int
+	}{
+		{"邮箱", "pii_email", "contact: zhang.wei@corp-mail.cn", 1},
+		{"示例域名邮箱", "pii_email", "author: dev@example.com, test@localhost", 0},
+		{"手机号", "pii_phone", "phone = '13812345678'\nmobile: +86 13912345678", 2},
+		{"北美电话", "pii_phone", "call (415) 555-2671 or 415-555-2671", 2},
+		{"时间戳不是手机号", "pii_phone", "id = 17123456789012", 0},
+		{"通过Luhn校验的卡号", "pii_credit_card", "card: 4111 1111 1111 1111, 5500-0000-0000-0004", 2},
+		{"未通过Luhn校验的卡号", "pii_credit_card", "card: 4111111111111112", 0},
+		{"IBAN", "pii_iban", "iban: GB82WEST12345698765432, DE89 3704 0044 0532 0130 00", 2},
+		{"校验码错误的IBAN", "pii_iban", "iban: GB82WEST12345698765433", 0},
+		{"公网IP", "pii_ip_address", "client 8.8.8.8 connected", 1},
+		{"内网和文档地址", "pii_ip_address", "bind 127.0.0.1, 10.0.0.1, 192.168.1.1, 203.0.113.5", 0},
+		{"版本号不是IP", "pii_ip_address", "version 8.8.8.8.1", 0},
+		{"身份证号", "pii_cn_id", "id_card: 11010519491231002X", 1},
+		{"校验码错误的身份证号", "pii_cn_id", "id_card: 110105194912310021", 0},
+		{"出生日期无效的身份证号", "pii_cn_id", "id_card: 110105194913310028", 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			issues := detectors[tc.detector].Detect("seed.sql", tc.content)
+			if len(issues) != tc.expected {
+				t.Errorf("期望发现 %d 个问题，实际发现 %d 个问题", tc.expected, len(issues))
+			}
+		})
+	}
+}
+
+// TestPIIDetectorSnippet 测试报告中的代码片段不泄露个人信息
+func TestPIIDetectorSnippet(t *testing.T) {
+	line := "INSERT INTO users VALUES ('zhang.wei@corp-mail.cn', '13812345678');"
+	for _, detector := range NewPIIDetectors() {
+		for _, issue := range detector.Detect("seed.sql", line) {
+			if strings.Contains(issue.Snippet, "corp-mail") || strings.Contains(issue.Snippet, "13812345678") {
+				t.Errorf("%s 的代码片段未遮蔽: %s", detector.GetName(), issue.Snippet)
+			}
+		}
+	}
+}
+
+// TestPIIMasker 测试按类型遮蔽个人信息
+func TestPIIMasker(t *testing.T) {
+	content := "email zhang.wei@corp-mail.cn card 4111-1111-1111-1111 id 11010519491231002X ip 8.8.8.8"
+
+	masker, err := NewPIIMasker(&types.PIIConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	masked, count := masker.Mask(content)
+	expected := "email [EMAIL] card [CREDIT_CARD] id [CN_ID] ip [IP_ADDRESS]"
+	if masked != expected || count != 4 {
+		t.Errorf("遮蔽结果不符合预期: %q (%d)", masked, count)
+	}
+
+	masker, err = NewPIIMasker(&types.PIIConfig{Types: []string{"email"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if masked, count = masker.Mask(content); count != 1 || !strings.Contains(masked, "8.8.8.8") {
+		t.Errorf("只应遮蔽邮箱: %q (%d)", masked, count)
+	}
+
+	if _, err := NewPIIMasker(&types.PIIConfig{Types: []string{"ssn"}}); err == nil {
+		t.Error("未知类型应该返回错误")
+	}
+	if err := ValidatePIIConfig(&types.PIIConfig{Mode: "drop"}); err == nil {
+		t.Error("无效模式应该返回错误")
+	}
+}
+
+// TestPIIPolicy 测试个人信息检测的开关和类型过滤
+func TestPIIPolicy(t *testing.T) {
+	config := allDetectorsConfig("standard")
+	config.Detectors.PII = true
+	config.PII.Types = []string{"email"}
+
+	policy, err := NewScanPolicy(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !policy.IsEnabled("pii_email") || policy.IsEnabled("pii_phone") {
+		t.Error("只应启用邮箱检测")
+	}
+
+	config.Detectors.PII = false
+	if policy, _ = NewScanPolicy(config); policy.IsEnabled("pii_email") {
+		t.Error("关闭个人信息检测后不应启用")
+	}
+}
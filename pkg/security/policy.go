@@ -0,0 +1,132 @@
+// Package security 实现安全扫描功能
+package security
+
+import (
+	"strings"
+
+	"code-context-generator/pkg/types"
+)
+
+// 检测器类别，对应 DetectorConfig 中的开关
+const (
+	categoryCredentials   = "credentials"
+	categorySQLInjection  = "sql_injection"
+	categoryXSS           = "xss"
+	categoryPathTraversal = "path_traversal"
+	categoryQuality       = "quality"
+	categoryPII           = "pii"
+	categoryVulnerability = "vulnerability" // 没有独立开关的漏洞类检测器
+)
+
+// detectorRule 检测器的类别与最低启用扫描级别
+type detectorRule struct {
+	category string
+	level    types.ScanLevel
+}
+
+// detectorRules 已知检测器的策略规则，未列出的检测器按标准级别的漏洞类处理
+var detectorRules = map[string]detectorRule{
+	"hardcoded_credentials": {categoryCredentials, types.ScanLevelBasic},
+	"go_sql_injection":      {categorySQLInjection, types.ScanLevelBasic},
+	"go_command_injection":  {categoryVulnerability, types.ScanLevelBasic},
+	"go_insecure_tls":       {categoryVulnerability, types.ScanLevelBasic},
+	"sql_injection":         {categorySQLInjection, types.ScanLevelStandard},
+	"xss_vulnerabilities":   {categoryXSS, types.ScanLevelStandard},
+	"path_traversal":        {categoryPathTraversal, types.ScanLevelStandard},
+	"go_path_traversal":     {categoryPathTraversal, types.ScanLevelStandard},
+	"go_weak_hash":          {categoryVulnerability, types.ScanLevelStandard},
+	"go_insecure_random":    {categoryVulnerability, types.ScanLevelStandard},
+	"dockerfile_misconfig":  {categoryVulnerability, types.ScanLevelStandard},
+	"kubernetes_misconfig":  {categoryVulnerability, types.ScanLevelStandard},
+	"compose_misconfig":     {categoryVulnerability, types.ScanLevelStandard},
+	"terraform_misconfig":   {categoryVulnerability, types.ScanLevelStandard},
+	"code_quality":          {categoryQuality, types.ScanLevelComprehensive},
+	"pii_" + PIIEmail:       {categoryPII, types.ScanLevelStandard},
+	"pii_" + PIIPhone:       {categoryPII, types.ScanLevelStandard},
+	"pii_" + PIICreditCard:  {categoryPII, types.ScanLevelStandard},
+	"pii_" + PIIIBAN:        {categoryPII, types.ScanLevelStandard},
+	"pii_" + PIIIPAddress:   {categoryPII, types.ScanLevelStandard},
+	"pii_" + PIIChineseID:   {categoryPII, types.ScanLevelStandard},
+}
+
+// ScanPolicy 扫描策略，决定运行哪些检测器以及保留哪些问题
+type ScanPolicy struct {
+	level      types.ScanLevel
+	enabled    map[string]bool
+	thresholds map[string]types.SeverityLevel
+	piiTypes   map[string]bool // 启用的个人信息类型，为空表示全部
+}
+
+// NewScanPolicy 根据安全配置创建扫描策略
+func NewScanPolicy(config *types.SecurityConfig) (*ScanPolicy, error) {
+	level, err := types.ParseScanLevel(config.ScanLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	piiTypes, err := enabledPIITypes(config.PII.Types)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ScanPolicy{
+		level: level,
+		enabled: map[string]bool{
+			categoryCredentials:   config.Detectors.Credentials,
+			categorySQLInjection:  config.Detectors.SQLInjection,
+			categoryXSS:           config.Detectors.XSS,
+			categoryPathTraversal: config.Detectors.PathTraversal,
+			categoryQuality:       config.Detectors.Quality,
+			categoryPII:           config.Detectors.PII,
+			categoryVulnerability: true,
+		},
+		thresholds: map[string]types.SeverityLevel{
+			categoryCredentials:   config.Credentials.SeverityThreshold,
+			categorySQLInjection:  config.Vulnerability.SeverityThreshold,
+			categoryXSS:           config.Vulnerability.SeverityThreshold,
+			categoryPathTraversal: config.Vulnerability.SeverityThreshold,
+			categoryQuality:       config.Quality.SeverityThreshold,
+			categoryPII:           config.PII.SeverityThreshold,
+			categoryVulnerability: config.Vulnerability.SeverityThreshold,
+		},
+		piiTypes: piiTypes,
+	}, nil
+}
+
+// Level 返回策略的扫描级别
+func (p *ScanPolicy) Level() types.ScanLevel {
+	return p.level
+}
+
+// IsEnabled 检查检测器在当前策略下是否运行
+func (p *ScanPolicy) IsEnabled(detectorName string) bool {
+	rule := ruleFor(detectorName)
+	if rule.category == categoryPII && len(p.piiTypes) > 0 && !p.piiTypes[strings.TrimPrefix(detectorName, "pii_")] {
+		return false
+	}
+	return rule.level <= p.level && p.enabled[rule.category]
+}
+
+// Filter 过滤检测器
+func (p *ScanPolicy) Filter(detectors []types.SecurityDetector) []types.SecurityDetector {
+	var active []types.SecurityDetector
+	for _, detector := range detectors {
+		if p.IsEnabled(detector.GetName()) {
+			active = append(active, detector)
+		}
+	}
+	return active
+}
+
+// Accept 检查问题是否达到检测器类别的最低严重性
+func (p *ScanPolicy) Accept(detectorName string, issue types.SecurityIssue) bool {
+	return issue.Severity >= p.thresholds[ruleFor(detectorName).category]
+}
+
+// ruleFor 获取检测器的策略规则
+func ruleFor(detectorName string) detectorRule {
+	if rule, ok := detectorRules[detectorName]; ok {
+		return rule
+	}
+	return detectorRule{categoryVulnerability, types.ScanLevelStandard}
+}
@@ -0,0 +1,113 @@
+// Package security 扫描策略测试
+package security
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"code-context-generator/pkg/types"
+)
+
+// allDetectorsConfig 创建启用全部检测器的配置
+func allDetectorsConfig(level string) *types.SecurityConfig {
+	return &types.SecurityConfig{
+		Enabled:   true,
+		ScanLevel: level,
+		Detectors: types.DetectorConfig{
+			Credentials:   true,
+			SQLInjection:  true,
+			XSS:           true,
+			PathTraversal: true,
+			Quality:       true,
+		},
+	}
+}
+
+// TestScanPolicyLevels 测试扫描级别对应的检测器集合
+func TestScanPolicyLevels(t *testing.T) {
+	testCases := []struct {
+		level    string
+		detector string
+		expected bool
+	}{
+		{"basic", "hardcoded_credentials", true},
+		{"basic", "go_sql_injection", true},
+		{"basic", "xss_vulnerabilities", false},
+		{"basic", "code_quality", false},
+		{"standard", "xss_vulnerabilities", true},
+		{"standard", "go_insecure_random", true},
+		{"standard", "code_quality", false},
+		{"comprehensive", "code_quality", true},
+		{"", "path_traversal", true},
+	}
+
+	for _, tc := range testCases {
+		policy, err := NewScanPolicy(allDetectorsConfig(tc.level))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := policy.IsEnabled(tc.detector); got != tc.expected {
+			t.Errorf("级别 %q 下检测器 %s: 期望 %v，实际 %v", tc.level, tc.detector, tc.expected, got)
+		}
+	}
+}
+
+// TestScanPolicyToggles 测试检测器开关
+func TestScanPolicyToggles(t *testing.T) {
+	config := allDetectorsConfig("comprehensive")
+	config.Detectors.XSS = false
+	config.Detectors.SQLInjection = false
+
+	policy, err := NewScanPolicy(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"xss_vulnerabilities", "sql_injection", "go_sql_injection"} {
+		if policy.IsEnabled(name) {
+			t.Errorf("检测器 %s 已被关闭，不应运行", name)
+		}
+	}
+	if !policy.IsEnabled("hardcoded_credentials") {
+		t.Error("硬编码凭证检测器应该运行")
+	}
+}
+
+// TestScanPolicyInvalidLevel 测试无效的扫描级别
+func TestScanPolicyInvalidLevel(t *testing.T) {
+	if _, err := NewScanPolicy(allDetectorsConfig("paranoid")); err == nil {
+		t.Error("无效的扫描级别应该返回错误")
+	}
+}
+
+// TestScannerSeverityThreshold 测试最低严重性过滤
+func TestScannerSeverityThreshold(t *testing.T) {
+	tempDir := t.TempDir()
+	content := `
+password = "secret123"
+var unusedVar = "test"
+`
+	if err := os.WriteFile(filepath.Join(tempDir, "test.py"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := allDetectorsConfig("comprehensive")
+	report, err := NewSecurityScanner(config).Scan(tempDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Summary.LowIssues == 0 {
+		t.Fatal("未设置阈值时应该报告低危问题")
+	}
+
+	config.Quality.SeverityThreshold = types.SeverityMedium
+	config.Credentials.SeverityThreshold = types.SeverityCritical
+	report, err = NewSecurityScanner(config).Scan(tempDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Summary.IssuesFound != 0 {
+		t.Errorf("期望阈值过滤全部问题，实际发现 %d 个问题", report.Summary.IssuesFound)
+	}
+}
@@ -4,11 +4,14 @@ package security
 import (
 	"crypto/rand"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"code-context-generator/pkg/types"
@@ -30,40 +33,84 @@ func NewSecurityScanner(config *types.SecurityConfig) *SecurityScanner {
 
 // Scan 执行安全扫描
 func (s *SecurityScanner) Scan(path string) (*types.SecurityReport, error) {
-	startTime := time.Now()
-
-	// 生成扫描ID
-	scanID, err := generateScanID()
-	if err != nil {
-		return nil, fmt.Errorf("生成扫描ID失败: %v", err)
-	}
-
 	// 检查路径是否存在
 	fileInfo, err := os.Stat(path)
 	if err != nil {
 		return nil, fmt.Errorf("路径不存在: %v", err)
 	}
 
-	var files []string
+	var paths []string
 	if fileInfo.IsDir() {
 		// 扫描目录
-		files, err = s.scanDirectory(path)
+		paths, err = s.scanDirectory(path)
 		if err != nil {
 			return nil, fmt.Errorf("扫描目录失败: %v", err)
 		}
 	} else {
 		// 扫描单个文件
-		files = []string{path}
+		paths = []string{path}
+	}
+
+	// 内容留空，由扫描worker读取
+	files := make([]types.FileInfo, len(paths))
+	for i, p := range paths {
+		files[i] = types.FileInfo{Path: p}
+	}
+
+	report, err := s.ScanFileInfos(files)
+	if err != nil {
+		return nil, err
+	}
+	report.Root = path
+	if !fileInfo.IsDir() {
+		report.Root = filepath.Dir(path)
+	}
+	return report, nil
+}
+
+// ScanFileInfos 扫描已加载的文件，复用 FileInfo.Content 避免重复读取
+func (s *SecurityScanner) ScanFileInfos(files []types.FileInfo) (*types.SecurityReport, error) {
+	startTime := time.Now()
+
+	// 根据扫描级别和检测器开关确定扫描策略
+	policy, err := NewScanPolicy(s.config)
+	if err != nil {
+		return nil, err
+	}
+
+	// 生成扫描ID
+	scanID, err := generateScanID()
+	if err != nil {
+		return nil, fmt.Errorf("生成扫描ID失败: %v", err)
+	}
+
+	// 过滤重复、不支持和被排除的文件
+	var targets []types.FileInfo
+	seen := make(map[string]bool, len(files))
+	for _, file := range files {
+		if seen[file.Path] || file.IsDir || file.IsBinary || s.isExcluded(file.Path) || !s.isSupportedFile(file.Path) {
+			continue
+		}
+		seen[file.Path] = true
+		targets = append(targets, file)
 	}
 
+	var memBefore, memAfter runtime.MemStats
+	runtime.ReadMemStats(&memBefore)
+
 	// 执行扫描
-	issues := s.scanFiles(files)
+	issues, scanErrors, scanned, timings := s.scanFiles(targets, policy)
+
+	runtime.ReadMemStats(&memAfter)
 
 	// 计算扫描时间
 	scanDuration := time.Since(startTime)
 
 	// 生成报告
-	report := s.generateReport(scanID, files, issues, scanDuration)
+	report := s.generateReport(scanID, len(files), scanned, issues, scanDuration)
+	report.Statistics.MemoryUsage = int64(memAfter.TotalAlloc - memBefore.TotalAlloc)
+	report.Statistics.DetectorTimings = timings
+	report.Errors = scanErrors
 
 	return report, nil
 }
@@ -124,6 +171,11 @@ func (s *SecurityScanner) isExcluded(filePath string) bool {
 
 // isSupportedFile 检查文件是否支持
 func (s *SecurityScanner) isSupportedFile(filePath string) bool {
+	// Dockerfile 等没有通用扩展名的IaC文件
+	if iacLanguage(filePath) != "" {
+		return true
+	}
+
 	ext := strings.ToLower(filepath.Ext(filePath))
 
 	supportedExtensions := map[string]bool{
@@ -148,42 +200,147 @@ func (s *SecurityScanner) isSupportedFile(filePath string) bool {
 		".ini":   true,
 		".cfg":   true,
 		".conf":  true,
+		".sql":   true,
+		".csv":   true,
+		".tsv":   true,
+		".txt":   true,
 	}
 
 	return supportedExtensions[ext]
 }
 
-// scanFiles 扫描文件
-func (s *SecurityScanner) scanFiles(files []string) []types.SecurityIssue {
+// scanResult 单个文件的扫描结果
+type scanResult struct {
+	issues  []types.SecurityIssue
+	errors  []types.ScanError
+	scanned bool
+	timings map[string]time.Duration
+}
+
+// runDetector 执行单个检测器，检测器panic时返回错误而不是终止整个进程
+func runDetector(name string, detect func() []types.SecurityIssue) (issues []types.SecurityIssue, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			issues, err = nil, fmt.Errorf("检测器 %s 执行异常: %v", name, r)
+		}
+	}()
+	return detect(), nil
+}
+
+// workerCount 返回扫描worker数量
+func (s *SecurityScanner) workerCount(files int) int {
+	workers := s.config.MaxWorkers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > files {
+		workers = files
+	}
+	return workers
+}
+
+// scanFiles 使用有界worker池并发扫描文件，返回问题、检测器错误、已扫描文件数和各检测器累计耗时
+func (s *SecurityScanner) scanFiles(files []types.FileInfo, policy *ScanPolicy) ([]types.SecurityIssue, []types.ScanError, int, map[string]time.Duration) {
+	results := make([]scanResult, len(files))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for i := 0; i < s.workerCount(len(files)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				results[index] = s.scanFile(files[index], policy)
+			}
+		}()
+	}
+	for i := range files {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	// 按输入顺序合并结果，保证报告稳定
 	var allIssues []types.SecurityIssue
+	var allErrors []types.ScanError
+	scanned := 0
+	timings := make(map[string]time.Duration)
+	for _, result := range results {
+		if !result.scanned {
+			continue
+		}
+		scanned++
+		allIssues = append(allIssues, result.issues...)
+		allErrors = append(allErrors, result.errors...)
+		for name, elapsed := range result.timings {
+			timings[name] += elapsed
+		}
+	}
 
-	for _, filePath := range files {
-		// 读取文件内容
-		content, err := ioutil.ReadFile(filePath)
+	return allIssues, allErrors, scanned, timings
+}
+
+// scanFile 扫描单个文件
+func (s *SecurityScanner) scanFile(file types.FileInfo, policy *ScanPolicy) scanResult {
+	content := file.Content
+	if content == "" {
+		// 未加载内容时从磁盘读取
+		data, err := os.ReadFile(file.Path)
 		if err != nil {
 			// 记录错误但继续扫描其他文件
-			fmt.Printf("警告: 无法读取文件 %s: %v\n", filePath, err)
-			continue
+			fmt.Printf("警告: 无法读取文件 %s: %v\n", file.Path, err)
+			return scanResult{}
 		}
+		content = string(data)
+	}
 
-		// 获取文件语言
-		language := s.getFileLanguage(filePath)
+	// 获取适用于该语言且被策略启用的检测器
+	language := s.getFileLanguage(file.Path)
+	detectors := policy.Filter(s.registry.GetDetectorsForLanguage(language))
 
-		// 获取适用于该语言的检测器
-		detectors := s.registry.GetDetectorsForLanguage(language)
+	result := scanResult{
+		scanned: true,
+		timings: make(map[string]time.Duration, len(detectors)),
+	}
 
-		// 执行检测
-		for _, detector := range detectors {
-			issues := detector.Detect(filePath, string(content))
-			allIssues = append(allIssues, issues...)
+	// 执行检测，丢弃低于类别最低严重性的问题
+	// Go语法树检测器共享同一次解析和类型检查，耗时计入第一个使用它的检测器
+	var src *goSource
+	parsed := false
+	for _, detector := range detectors {
+		start := time.Now()
+		issues, err := runDetector(detector.GetName(), func() []types.SecurityIssue {
+			if goDetector, ok := detector.(goSourceDetector); ok {
+				if !parsed {
+					parsed = true
+					src = parseGoSource(file.Path, content)
+				}
+				return goDetector.detectSource(src)
+			}
+			return detector.Detect(file.Path, content)
+		})
+		result.timings[detector.GetName()] += time.Since(start)
+		if err != nil {
+			result.errors = append(result.errors, types.ScanError{File: file.Path, Detector: detector.GetName(), Message: err.Error()})
+			continue
+		}
+
+		for _, issue := range issues {
+			if policy.Accept(detector.GetName(), issue) {
+				result.issues = append(result.issues, issue)
+			}
 		}
 	}
 
-	return allIssues
+	return result
 }
 
 // getFileLanguage 获取文件语言
 func (s *SecurityScanner) getFileLanguage(filePath string) string {
+	if lang := iacLanguage(filePath); lang != "" {
+		return lang
+	}
+
 	ext := strings.ToLower(filepath.Ext(filePath))
 
 	languageMap := map[string]string{
@@ -208,6 +365,10 @@ func (s *SecurityScanner) getFileLanguage(filePath string) string {
 		".ini":   "ini",
 		".cfg":   "config",
 		".conf":  "config",
+		".sql":   "sql",
+		".csv":   "csv",
+		".tsv":   "csv",
+		".txt":   "text",
 	}
 
 	if lang, exists := languageMap[ext]; exists {
@@ -218,7 +379,12 @@ func (s *SecurityScanner) getFileLanguage(filePath string) string {
 }
 
 // generateReport 生成安全报告
-func (s *SecurityScanner) generateReport(scanID string, files []string, issues []types.SecurityIssue, duration time.Duration) *types.SecurityReport {
+func (s *SecurityScanner) generateReport(scanID string, totalFiles, scannedFiles int, issues []types.SecurityIssue, duration time.Duration) *types.SecurityReport {
+	return newSecurityReport(scanID, totalFiles, scannedFiles, issues, duration, *s.config)
+}
+
+// newSecurityReport 根据扫描结果构建安全报告
+func newSecurityReport(scanID string, totalFiles, scannedFiles int, issues []types.SecurityIssue, duration time.Duration, config types.SecurityConfig) *types.SecurityReport {
 	// 统计问题数量
 	var critical, high, medium, low int
 	for _, issue := range issues {
@@ -235,26 +401,11 @@ func (s *SecurityScanner) generateReport(scanID string, files []string, issues [
 	}
 
 	// 计算统计信息
-	totalFiles := len(files)
-	scannedFiles := len(files)
 	totalIssues := len(issues)
 
 	// 计算性能统计
 	filesPerSec := float64(scannedFiles) / duration.Seconds()
 
-	// 如果没有发现严重问题，将一些高危问题升级为严重问题（用于测试）
-	if critical == 0 && high > 0 {
-		// 将第一个高危问题升级为严重问题
-		for i := range issues {
-			if issues[i].Severity == types.SeverityHigh {
-				issues[i].Severity = types.SeverityCritical
-				critical++
-				high--
-				break
-			}
-		}
-	}
-
 	summary := types.ScanSummary{
 		TotalFiles:     totalFiles,
 		ScannedFiles:   scannedFiles,
@@ -267,9 +418,10 @@ func (s *SecurityScanner) generateReport(scanID string, files []string, issues [
 
 	statistics := types.ScanStatistics{
 		TotalTime:   duration,
-		AverageTime: duration / time.Duration(scannedFiles),
 		FilesPerSec: filesPerSec,
-		MemoryUsage: 0, // 暂时不实现内存统计
+	}
+	if scannedFiles > 0 {
+		statistics.AverageTime = duration / time.Duration(scannedFiles)
 	}
 
 	report := &types.SecurityReport{
@@ -279,7 +431,7 @@ func (s *SecurityScanner) generateReport(scanID string, files []string, issues [
 		Summary:      summary,
 		Issues:       issues,
 		Statistics:   statistics,
-		Config:       *s.config,
+		Config:       config,
 	}
 
 	return report
@@ -306,18 +458,43 @@ func generateScanID() (string, error) {
 }
 
 // SecurityReporterImpl 安全报告器实现
-type SecurityReporterImpl struct{}
+type SecurityReporterImpl struct {
+	sources map[string]string // HTML报告使用的已加载文件内容，按路径索引
+}
 
 // NewSecurityReporter 创建安全报告器
 func NewSecurityReporter() *SecurityReporterImpl {
 	return &SecurityReporterImpl{}
 }
 
+// SetSources 设置已加载的文件内容，HTML报告优先从中读取源码上下文
+func (r *SecurityReporterImpl) SetSources(files []types.FileInfo) {
+	r.sources = make(map[string]string, len(files))
+	for _, file := range files {
+		r.sources[file.Path] = file.Content
+	}
+}
+
 // Generate 生成报告
 func (r *SecurityReporterImpl) Generate(report *types.SecurityReport) ([]byte, error) {
-	// 这里实现报告生成逻辑
-	// 暂时返回简单文本报告
-	return r.generateTextReport(report), nil
+	format := report.Config.Reporting.Format
+	if format == "" {
+		format = report.Config.ReportFormat
+	}
+
+	switch strings.ToLower(format) {
+	case "html":
+		return r.generateHTMLReport(report)
+	case "json":
+		// JSON报告可用于 security compare 对比
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("序列化报告失败: %v", err)
+		}
+		return data, nil
+	default:
+		return r.generateTextReport(report), nil
+	}
 }
 
 // generateTextReport 生成文本报告
@@ -346,6 +523,18 @@ func (r *SecurityReporterImpl) generateTextReport(report *types.SecurityReport)
 	builder.WriteString(fmt.Sprintf("总扫描时间: %v\n", report.Statistics.TotalTime))
 	builder.WriteString(fmt.Sprintf("平均文件扫描时间: %v\n", report.Statistics.AverageTime))
 	builder.WriteString(fmt.Sprintf("文件扫描速度: %.2f 文件/秒\n", report.Statistics.FilesPerSec))
+	builder.WriteString(fmt.Sprintf("内存分配: %.2f MB\n", float64(report.Statistics.MemoryUsage)/1024/1024))
+	if len(report.Statistics.DetectorTimings) > 0 {
+		builder.WriteString("检测器耗时:\n")
+		names := make([]string, 0, len(report.Statistics.DetectorTimings))
+		for name := range report.Statistics.DetectorTimings {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			builder.WriteString(fmt.Sprintf("  %s: %v\n", name, report.Statistics.DetectorTimings[name]))
+		}
+	}
 	builder.WriteString("\n")
 
 	// 问题详情
@@ -354,6 +543,9 @@ func (r *SecurityReporterImpl) generateTextReport(report *types.SecurityReport)
 		for i, issue := range report.Issues {
 			builder.WriteString(fmt.Sprintf("%d. [%s] %s\n", i+1, issue.Severity.String(), issue.Type))
 			builder.WriteString(fmt.Sprintf("   文件: %s:%d:%d\n", issue.File, issue.Line, issue.Column))
+			if issue.Commit != "" {
+				builder.WriteString(fmt.Sprintf("   提交: %s (%s)\n", issue.Commit, issue.Author))
+			}
 			builder.WriteString(fmt.Sprintf("   描述: %s\n", issue.Message))
 			builder.WriteString(fmt.Sprintf("   代码: %s\n", issue.Snippet))
 			builder.WriteString(fmt.Sprintf("   建议: %s\n", issue.Recommendation))
@@ -365,6 +557,14 @@ func (r *SecurityReporterImpl) generateTextReport(report *types.SecurityReport)
 		builder.WriteString("未发现安全问题\n")
 	}
 
+	// 检测器错误
+	if len(report.Errors) > 0 {
+		builder.WriteString("\n=== 扫描错误 ===\n")
+		for _, scanErr := range report.Errors {
+			builder.WriteString(fmt.Sprintf("%s: %s\n", scanErr.File, scanErr.Message))
+		}
+	}
+
 	return []byte(builder.String())
 }
 
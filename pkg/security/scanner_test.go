@@ -0,0 +1,117 @@
+// Package security 并发扫描测试
+package security
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"code-context-generator/pkg/types"
+)
+
+// TestScanFileInfos 测试扫描已加载内容的文件
+func TestScanFileInfos(t *testing.T) {
+	var files []types.FileInfo
+	for i := 0; i < 20; i++ {
+		files = append(files, types.FileInfo{
+			// 路径不存在，只能使用已加载的内容
+			Path:    fmt.Sprintf("missing/file%02d.py", i),
+			Content: fmt.Sprintf("password = \"secret%d\"\n", i),
+		})
+	}
+	files = append(files, files[0])
+	files = append(files, types.FileInfo{Path: "missing/image.png", Content: "password = \"x\""})
+
+	config := allDetectorsConfig("standard")
+	config.MaxWorkers = 4
+	report, err := NewSecurityScanner(config).ScanFileInfos(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Summary.TotalFiles != 22 {
+		t.Errorf("期望总文件数 22，实际 %d", report.Summary.TotalFiles)
+	}
+	if report.Summary.ScannedFiles != 20 {
+		t.Errorf("期望扫描 20 个文件，实际 %d", report.Summary.ScannedFiles)
+	}
+	if report.Summary.IssuesFound != 20 {
+		t.Errorf("期望发现 20 个问题，实际发现 %d 个问题", report.Summary.IssuesFound)
+	}
+
+	// 结果应按输入顺序排列
+	for i, issue := range report.Issues {
+		expected := fmt.Sprintf("missing/file%02d.py", i)
+		if issue.File != expected {
+			t.Errorf("第 %d 个问题期望来自 %s，实际 %s", i, expected, issue.File)
+		}
+	}
+
+	if _, ok := report.Statistics.DetectorTimings["hardcoded_credentials"]; !ok {
+		t.Error("应该记录硬编码凭证检测器的耗时")
+	}
+	if report.Statistics.MemoryUsage <= 0 {
+		t.Error("应该记录扫描期间的内存分配")
+	}
+}
+
+// TestSecurityIntegrationScanFiles 测试集成器使用同一扫描ID
+func TestSecurityIntegrationScanFiles(t *testing.T) {
+	config := allDetectorsConfig("standard")
+	integration := NewSecurityIntegration(config)
+
+	report, err := integration.ScanFiles([]types.FileInfo{
+		{Path: "a.py", Content: "password = \"one\""},
+		{Path: "b.py", Content: "password = \"two\""},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.ScanID == "" {
+		t.Error("报告应该包含扫描ID")
+	}
+	if report.Summary.IssuesFound != 2 {
+		t.Errorf("期望发现 2 个问题，实际发现 %d 个问题", report.Summary.IssuesFound)
+	}
+}
+
+// panicDetector 执行时panic的检测器
+type panicDetector struct {
+	*BaseDetector
+}
+
+// Detect 总是panic
+func (d *panicDetector) Detect(filePath string, content string) []types.SecurityIssue {
+	panic("boom")
+}
+
+// TestScanRecoversDetectorPanic 测试检测器panic被记录为扫描错误
+func TestScanRecoversDetectorPanic(t *testing.T) {
+	config := allDetectorsConfig("standard")
+	config.MaxWorkers = 2
+	scanner := NewSecurityScanner(config)
+	scanner.registry.Register(&panicDetector{NewBaseDetector("hardcoded_credentials", []string{"python"})})
+
+	files := []types.FileInfo{
+		{Path: "missing/a.py", Content: "password = \"secret\"\n"},
+		{Path: "missing/b.py", Content: "x = 1\n"},
+	}
+	report, err := scanner.ScanFileInfos(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Summary.ScannedFiles != 2 {
+		t.Errorf("期望扫描 2 个文件，实际 %d", report.Summary.ScannedFiles)
+	}
+	if len(report.Errors) != 2 {
+		t.Fatalf("期望记录 2 个扫描错误，实际 %d 个", len(report.Errors))
+	}
+	if report.Errors[0].File != "missing/a.py" || report.Errors[0].Detector != "hardcoded_credentials" || !strings.Contains(report.Errors[0].Message, "boom") {
+		t.Errorf("扫描错误 = %+v", report.Errors[0])
+	}
+
+	text := string(NewSecurityReporter().generateTextReport(report))
+	if !strings.Contains(text, "=== 扫描错误 ===") {
+		t.Error("文本报告应该包含扫描错误")
+	}
+}
@@ -332,8 +332,13 @@ func main() {
 		t.Error("应该发现安全问题")
 	}
 
-	if report.Summary.CriticalIssues == 0 {
-		t.Error("应该发现严重问题")
+	// 严重性应与检测器输出一致，不应被升级
+	if report.Summary.CriticalIssues != 0 {
+		t.Errorf("期望 0 个严重问题，实际 %d 个", report.Summary.CriticalIssues)
+	}
+
+	if report.Summary.HighIssues == 0 {
+		t.Error("应该发现高危问题")
 	}
 
 	// 验证报告内容
@@ -384,3 +389,38 @@ func TestSeverityLevelString(t *testing.T) {
 		}
 	}
 }
+
+// TestAttachFindings 测试将问题附加到上下文数据中的文件
+func TestAttachFindings(t *testing.T) {
+	integration := NewSecurityIntegration(&types.SecurityConfig{Enabled: true})
+	data := &types.ContextData{
+		Files: []types.FileInfo{{Path: "main.go"}, {Path: "util.go"}},
+		Folders: []types.FolderInfo{{
+			Path:  "conf",
+			Files: []types.FileInfo{{Path: filepath.Join("conf", "app.py")}},
+		}},
+	}
+	report := &types.SecurityReport{
+		Summary: types.ScanSummary{IssuesFound: 3},
+		Issues: []types.SecurityIssue{
+			{ID: "XSS_001", File: "main.go", Line: 3},
+			{ID: "XSS_001", File: "./main.go", Line: 7},
+			{ID: "CREDENTIALS_001", File: "conf/app.py", Line: 1},
+		},
+	}
+
+	integration.AttachFindings(data, report)
+
+	if len(data.Files[0].Issues) != 2 {
+		t.Errorf("期望 main.go 有 2 个问题，实际 %d 个", len(data.Files[0].Issues))
+	}
+	if len(data.Files[1].Issues) != 0 {
+		t.Errorf("期望 util.go 没有问题，实际 %d 个", len(data.Files[1].Issues))
+	}
+	if len(data.Folders[0].Files[0].Issues) != 1 {
+		t.Errorf("期望 conf/app.py 有 1 个问题，实际 %d 个", len(data.Folders[0].Files[0].Issues))
+	}
+	if data.Security == nil || data.Security.IssuesFound != 3 {
+		t.Errorf("应该记录扫描摘要，实际 %+v", data.Security)
+	}
+}
@@ -0,0 +1,196 @@
+// Package security 实现安全扫描功能
+package security
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"code-context-generator/pkg/types"
+)
+
+const (
+	// WebhookSignatureHeader 请求体HMAC-SHA256签名的请求头，值为 sha256=<hex>
+	WebhookSignatureHeader = "X-CGen-Signature-256"
+	// WebhookEventHeader 事件类型请求头
+	WebhookEventHeader = "X-CGen-Event"
+
+	webhookEvent          = "security_scan"
+	defaultWebhookTimeout = 10 * time.Second
+	defaultWebhookBackoff = time.Second
+	maxWebhookErrorBody   = 512
+)
+
+// WebhookPayload 推送到Webhook的扫描结果
+type WebhookPayload struct {
+	Event          string            `json:"event"`
+	ScanID         string            `json:"scan_id"`
+	Timestamp      time.Time         `json:"timestamp"`
+	BaselineScanID string            `json:"baseline_scan_id,omitempty"` // 用于判断新增问题的上一次扫描
+	Summary        types.ScanSummary `json:"summary"`
+	NewIssues      []WebhookIssue    `json:"new_issues"`
+}
+
+// WebhookIssue 推送的问题，不包含可能含有凭证的代码片段
+type WebhookIssue struct {
+	Fingerprint    string `json:"fingerprint"`
+	ID             string `json:"id"`
+	Type           string `json:"type"`
+	Severity       string `json:"severity"`
+	File           string `json:"file"`
+	Line           int    `json:"line"`
+	Message        string `json:"message"`
+	Recommendation string `json:"recommendation,omitempty"`
+	Commit         string `json:"commit,omitempty"`
+}
+
+// WebhookResult 推送结果
+type WebhookResult struct {
+	StatusCode int
+	Attempts   int
+	DryRun     bool
+	Body       []byte      // 发送的请求体
+	Headers    http.Header // 发送的请求头
+}
+
+// BuildWebhookPayload 生成推送内容，只包含相对上一次扫描新增的严重和高危问题
+//
+// previous 为nil时，报告中所有严重和高危问题都视为新增。
+func BuildWebhookPayload(report, previous *types.SecurityReport) *WebhookPayload {
+	payload := &WebhookPayload{
+		Event:     webhookEvent,
+		ScanID:    report.ScanID,
+		Timestamp: report.Timestamp,
+		Summary:   report.Summary,
+		NewIssues: []WebhookIssue{},
+	}
+
+	issues := report.Issues
+	if previous != nil {
+		payload.BaselineScanID = previous.ScanID
+		issues = CompareReports(previous, report).Added
+	}
+
+	for _, issue := range issues {
+		if issue.Severity < types.SeverityHigh {
+			continue
+		}
+		payload.NewIssues = append(payload.NewIssues, WebhookIssue{
+			Fingerprint:    IssueFingerprint(issue, report.Root),
+			ID:             issue.ID,
+			Type:           issue.Type,
+			Severity:       issue.Severity.String(),
+			File:           issue.File,
+			Line:           issue.Line,
+			Message:        issue.Message,
+			Recommendation: issue.Recommendation,
+			Commit:         issue.Commit,
+		})
+	}
+	return payload
+}
+
+// SignWebhookPayload 计算请求体的HMAC-SHA256签名
+func SignWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookNotifier Webhook推送器
+type WebhookNotifier struct {
+	config  *types.WebhookConfig
+	client  *http.Client
+	backoff time.Duration // 第一次重试前的等待时间，之后每次翻倍
+}
+
+// NewWebhookNotifier 创建Webhook推送器
+func NewWebhookNotifier(config *types.WebhookConfig) *WebhookNotifier {
+	timeout := defaultWebhookTimeout
+	if config.Timeout > 0 {
+		timeout = time.Duration(config.Timeout) * time.Second
+	}
+	return &WebhookNotifier{
+		config:  config,
+		client:  &http.Client{Timeout: timeout},
+		backoff: defaultWebhookBackoff,
+	}
+}
+
+// Send 发送扫描结果，网络错误、429和5xx响应会按配置重试
+func (n *WebhookNotifier) Send(payload *WebhookPayload) (*WebhookResult, error) {
+	if n.config.URL == "" {
+		return nil, fmt.Errorf("未配置Webhook地址")
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("序列化Webhook内容失败: %v", err)
+	}
+
+	headers := http.Header{}
+	headers.Set("Content-Type", "application/json")
+	headers.Set("User-Agent", "c-gen")
+	headers.Set(WebhookEventHeader, webhookEvent)
+	for key, value := range n.config.Headers {
+		headers.Set(key, value)
+	}
+	if n.config.SecretEnv != "" {
+		secret := os.Getenv(n.config.SecretEnv)
+		if secret == "" {
+			return nil, fmt.Errorf("环境变量 %s 未设置，无法签名Webhook请求", n.config.SecretEnv)
+		}
+		headers.Set(WebhookSignatureHeader, SignWebhookPayload(secret, body))
+	}
+
+	result := &WebhookResult{Body: body, Headers: headers, DryRun: n.config.DryRun}
+	if n.config.DryRun {
+		return result, nil
+	}
+
+	wait := n.backoff
+	for {
+		result.Attempts++
+		status, retryable, err := n.post(body, headers)
+		result.StatusCode = status
+		if err == nil {
+			return result, nil
+		}
+		if !retryable || result.Attempts > n.config.MaxRetries {
+			return result, fmt.Errorf("Webhook推送失败（已尝试 %d 次）: %v", result.Attempts, err)
+		}
+		time.Sleep(wait)
+		wait *= 2
+	}
+}
+
+// post 发送一次请求，返回状态码以及失败时是否可以重试
+func (n *WebhookNotifier) post(body []byte, headers http.Header) (int, bool, error) {
+	req, err := http.NewRequest(http.MethodPost, n.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, false, fmt.Errorf("创建请求失败: %v", err)
+	}
+	req.Header = headers.Clone()
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return 0, true, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(io.Discard, resp.Body)
+		return resp.StatusCode, false, nil
+	}
+
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, maxWebhookErrorBody))
+	retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return resp.StatusCode, retryable, fmt.Errorf("服务器返回 %d: %s", resp.StatusCode, bytes.TrimSpace(message))
+}
@@ -0,0 +1,155 @@
+// Package security Webhook推送测试
+package security
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"code-context-generator/pkg/types"
+)
+
+// webhookTestReports 返回上一次和本次扫描的报告
+func webhookTestReports() (*types.SecurityReport, *types.SecurityReport) {
+	previous := &types.SecurityReport{
+		ScanID: "previous",
+		Issues: []types.SecurityIssue{
+			{ID: "CREDENTIALS_001", File: "a.py", Snippet: `password = "one"`, Severity: types.SeverityHigh},
+		},
+	}
+	report := &types.SecurityReport{
+		ScanID:    "current",
+		Timestamp: time.Date(2024, 5, 1, 2, 0, 0, 0, time.UTC),
+		Summary:   types.ScanSummary{IssuesFound: 4, CriticalIssues: 1, HighIssues: 2, MediumIssues: 1},
+		Issues: []types.SecurityIssue{
+			{ID: "CREDENTIALS_001", File: "a.py", Line: 3, Snippet: `password = "one"`, Severity: types.SeverityHigh},
+			{ID: "CREDENTIALS_001", File: "b.py", Line: 1, Snippet: `token = "secret-token"`, Severity: types.SeverityHigh, Message: "硬编码令牌"},
+			{ID: "SQL_INJECTION_001", File: "d.py", Line: 2, Snippet: "query + id", Severity: types.SeverityCritical},
+			{ID: "XSS_001", File: "c.js", Line: 5, Snippet: "el.innerHTML = x", Severity: types.SeverityMedium},
+		},
+	}
+	return previous, report
+}
+
+// TestBuildWebhookPayload 测试只推送新增的严重和高危问题
+func TestBuildWebhookPayload(t *testing.T) {
+	previous, report := webhookTestReports()
+
+	payload := BuildWebhookPayload(report, previous)
+	if payload.BaselineScanID != "previous" || payload.Summary.IssuesFound != 4 {
+		t.Errorf("摘要信息错误: %+v", payload)
+	}
+	if len(payload.NewIssues) != 2 {
+		t.Fatalf("期望 2 个新增问题，实际 %+v", payload.NewIssues)
+	}
+	if payload.NewIssues[0].File != "b.py" || payload.NewIssues[1].Severity != "critical" {
+		t.Errorf("新增问题错误: %+v", payload.NewIssues)
+	}
+
+	// 没有上一次扫描时，所有严重和高危问题都视为新增
+	if payload := BuildWebhookPayload(report, nil); len(payload.NewIssues) != 3 {
+		t.Errorf("期望 3 个问题，实际 %d 个", len(payload.NewIssues))
+	}
+}
+
+// TestWebhookNotifierSend 测试签名、请求头和失败重试
+func TestWebhookNotifierSend(t *testing.T) {
+	_, report := webhookTestReports()
+	t.Setenv("CGEN_TEST_WEBHOOK_SECRET", "s3cret")
+
+	var attempts int32
+	var received []byte
+	var signature, custom string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		received, _ = io.ReadAll(r.Body)
+		signature = r.Header.Get(WebhookSignatureHeader)
+		custom = r.Header.Get("X-Team")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier(&types.WebhookConfig{
+		URL:        server.URL,
+		SecretEnv:  "CGEN_TEST_WEBHOOK_SECRET",
+		Headers:    map[string]string{"X-Team": "security"},
+		MaxRetries: 2,
+	})
+	notifier.backoff = time.Millisecond
+
+	result, err := notifier.Send(BuildWebhookPayload(report, nil))
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if result.Attempts != 3 || result.StatusCode != http.StatusAccepted {
+		t.Errorf("期望第 3 次成功并返回 202，实际尝试 %d 次、状态 %d", result.Attempts, result.StatusCode)
+	}
+	if signature != SignWebhookPayload("s3cret", received) {
+		t.Errorf("签名不匹配: %s", signature)
+	}
+	if custom != "security" {
+		t.Errorf("缺少自定义请求头，实际 %q", custom)
+	}
+
+	var payload WebhookPayload
+	if err := json.Unmarshal(received, &payload); err != nil {
+		t.Fatalf("请求体不是有效JSON: %v", err)
+	}
+	if payload.Event != "security_scan" || len(payload.NewIssues) != 3 {
+		t.Errorf("请求体错误: %+v", payload)
+	}
+	if strings.Contains(string(received), "secret-token") {
+		t.Error("推送内容不应包含代码片段")
+	}
+}
+
+// TestWebhookNotifierFailures 测试客户端错误不重试、重试耗尽和试运行
+func TestWebhookNotifierFailures(t *testing.T) {
+	_, report := webhookTestReports()
+	payload := BuildWebhookPayload(report, nil)
+
+	var attempts int32
+	status := http.StatusBadRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		http.Error(w, "rejected", status)
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier(&types.WebhookConfig{URL: server.URL, MaxRetries: 3})
+	notifier.backoff = time.Millisecond
+
+	result, err := notifier.Send(payload)
+	if err == nil || result.Attempts != 1 || !strings.Contains(err.Error(), "rejected") {
+		t.Errorf("400 响应不应重试，实际尝试 %d 次，错误 %v", result.Attempts, err)
+	}
+
+	atomic.StoreInt32(&attempts, 0)
+	status = http.StatusInternalServerError
+	if result, err := notifier.Send(payload); err == nil || result.Attempts != 4 {
+		t.Errorf("500 响应应重试 3 次后失败，实际尝试 %d 次", result.Attempts)
+	}
+
+	atomic.StoreInt32(&attempts, 0)
+	dryRun := NewWebhookNotifier(&types.WebhookConfig{URL: server.URL, DryRun: true})
+	result, err = dryRun.Send(payload)
+	if err != nil || !result.DryRun || len(result.Body) == 0 {
+		t.Errorf("试运行应返回请求内容: %+v, %v", result, err)
+	}
+	if atomic.LoadInt32(&attempts) != 0 {
+		t.Error("试运行不应发送请求")
+	}
+
+	missingSecret := NewWebhookNotifier(&types.WebhookConfig{URL: server.URL, SecretEnv: "CGEN_TEST_MISSING_SECRET"})
+	if _, err := missingSecret.Send(payload); err == nil {
+		t.Error("签名密钥缺失时应返回错误")
+	}
+}
@@ -0,0 +1,41 @@
+// Package types 分块输出相关类型定义
+package types
+
+import "time"
+
+// ChunkFile 分块中的文件，超出分块限制的文件按行拆分为多个片段
+type ChunkFile struct {
+	Path   string `json:"path" yaml:"path" xml:"path,attr" toml:"path"`
+	Part   int    `json:"part,omitempty" yaml:"part,omitempty" xml:"part,attr,omitempty" toml:"part,omitempty"`     // 片段序号，从1开始，未拆分时为0
+	Parts  int    `json:"parts,omitempty" yaml:"parts,omitempty" xml:"parts,attr,omitempty" toml:"parts,omitempty"` // 片段总数，未拆分时为0
+	Size   int64  `json:"size" yaml:"size" xml:"size,attr" toml:"size"`
+	Tokens int    `json:"tokens" yaml:"tokens" xml:"tokens,attr" toml:"tokens"`
+}
+
+// ChunkEntry 分块索引中的一项
+type ChunkEntry struct {
+	Index  int         `json:"index" yaml:"index" xml:"index,attr" toml:"index"`
+	File   string      `json:"file" yaml:"file" xml:"file,attr" toml:"file"`
+	Size   int64       `json:"size" yaml:"size" xml:"size,attr" toml:"size"`
+	Tokens int         `json:"tokens" yaml:"tokens" xml:"tokens,attr" toml:"tokens"`
+	Files  []ChunkFile `json:"files" yaml:"files" xml:"entry" toml:"files"`
+}
+
+// ChunkInfo 每个分块携带的头信息，包含所有分块的文件索引
+type ChunkInfo struct {
+	Index    int          `json:"index" yaml:"index" xml:"index,attr" toml:"index"`
+	Total    int          `json:"total" yaml:"total" xml:"total,attr" toml:"total"`
+	Manifest string       `json:"manifest" yaml:"manifest" xml:"manifest,attr" toml:"manifest"`
+	Chunks   []ChunkEntry `json:"chunks" yaml:"chunks" xml:"chunk" toml:"chunks"`
+}
+
+// ChunkManifest 与分块文件一同写出的清单
+type ChunkManifest struct {
+	Format      string       `json:"format"`
+	GeneratedAt time.Time    `json:"generated_at"`
+	MaxTokens   int          `json:"max_tokens,omitempty"`
+	MaxBytes    int64        `json:"max_bytes,omitempty"`
+	FileCount   int          `json:"file_count"`
+	TotalSize   int64        `json:"total_size"`
+	Chunks      []ChunkEntry `json:"chunks"`
+}
@@ -0,0 +1,56 @@
+// Package types 许可证相关类型定义
+package types
+
+// LicenseConfig 许可证检测配置
+type LicenseConfig struct {
+	Enabled        bool   `yaml:"enabled"`
+	ProjectLicense string `yaml:"project_license"` // 项目许可证的SPDX表达式，为空时从根目录识别
+}
+
+// 许可证类别，按限制程度从低到高排列
+const (
+	LicenseCategoryPublicDomain    = "public-domain"
+	LicenseCategoryPermissive      = "permissive"
+	LicenseCategoryWeakCopyleft    = "weak-copyleft"
+	LicenseCategoryStrongCopyleft  = "strong-copyleft"
+	LicenseCategoryNetworkCopyleft = "network-copyleft"
+	LicenseCategoryUnknown         = "unknown"
+)
+
+// 许可证声明的来源
+const (
+	LicenseSourceFile     = "license_file"
+	LicenseSourceHeader   = "spdx_header"
+	LicenseSourceManifest = "manifest"
+)
+
+// LicenseFinding 检测到的许可证声明
+type LicenseFinding struct {
+	Path     string `json:"path" yaml:"path" xml:"path,attr" toml:"path"`
+	License  string `json:"license" yaml:"license" xml:"license,attr" toml:"license"` // SPDX表达式，无法识别时为 NOASSERTION
+	Source   string `json:"source" yaml:"source" xml:"source,attr" toml:"source"`
+	Category string `json:"category" yaml:"category" xml:"category,attr" toml:"category"`
+}
+
+// DirectoryLicense 目录中声明的许可证，作用于整个子目录
+type DirectoryLicense struct {
+	Path     string   `json:"path" yaml:"path" xml:"path,attr" toml:"path"`
+	Licenses []string `json:"licenses" yaml:"licenses" xml:"license" toml:"licenses"`
+}
+
+// LicenseConflict 与所在目录许可证不兼容的声明
+type LicenseConflict struct {
+	Path      string `json:"path" yaml:"path" xml:"path,attr" toml:"path"`
+	License   string `json:"license" yaml:"license" xml:"license,attr" toml:"license"`
+	Governing string `json:"governing_license" yaml:"governing_license" xml:"governing_license,attr" toml:"governing_license"`
+	Message   string `json:"message" yaml:"message" xml:",chardata" toml:"message"`
+}
+
+// LicenseReport 许可证合规摘要
+type LicenseReport struct {
+	ProjectLicense     string             `json:"project_license" yaml:"project_license" xml:"project_license" toml:"project_license"`
+	Directories        []DirectoryLicense `json:"directories" yaml:"directories" xml:"directories>directory" toml:"directories"`
+	Conflicts          []LicenseConflict  `json:"conflicts" yaml:"conflicts" xml:"conflicts>conflict" toml:"conflicts"`
+	FilesWithoutHeader []string           `json:"files_without_header" yaml:"files_without_header" xml:"files_without_header>file" toml:"files_without_header"`
+	Findings           []LicenseFinding   `json:"findings" yaml:"findings" xml:"findings>finding" toml:"findings"`
+}
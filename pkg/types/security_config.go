@@ -2,6 +2,9 @@
 package types
 
 import (
+	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
 )
 
@@ -11,8 +14,15 @@ type SecurityConfig struct {
 	FailOnCritical bool     `yaml:"fail_on_critical"`
 	ScanLevel      string   `yaml:"scan_level"`
 	ReportFormat   string   `yaml:"report_format"`
+	MaxWorkers     int      `yaml:"max_workers"`
+	AdvisoryDB     string   `yaml:"advisory_db"`
+	HistoryDir     string   `yaml:"history_dir"`
 	
 	Detectors      DetectorConfig    `yaml:"detectors"`
+	Credentials    HardcodedCredentialsConfig `yaml:"credentials"`
+	Vulnerability  VulnerabilityConfig        `yaml:"vulnerability"`
+	Quality        QualityConfig              `yaml:"quality"`
+	PII            PIIConfig                  `yaml:"pii"`
 	Exclusions     ExclusionConfig   `yaml:"exclusions"`
 	Reporting      ReportingConfig   `yaml:"reporting"`
 }
@@ -26,6 +36,34 @@ const (
 	ScanLevelComprehensive          // 全面扫描
 )
 
+// ParseScanLevel 解析扫描级别字符串，空字符串视为标准扫描
+func ParseScanLevel(level string) (ScanLevel, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "basic":
+		return ScanLevelBasic, nil
+	case "", "standard":
+		return ScanLevelStandard, nil
+	case "comprehensive":
+		return ScanLevelComprehensive, nil
+	default:
+		return ScanLevelStandard, fmt.Errorf("无效的扫描级别: %s (可选: basic, standard, comprehensive)", level)
+	}
+}
+
+// String 返回扫描级别的字符串表示
+func (l ScanLevel) String() string {
+	switch l {
+	case ScanLevelBasic:
+		return "basic"
+	case ScanLevelStandard:
+		return "standard"
+	case ScanLevelComprehensive:
+		return "comprehensive"
+	default:
+		return "unknown"
+	}
+}
+
 // DetectorConfig 检测器配置
 type DetectorConfig struct {
 	Credentials    bool `yaml:"credentials"`
@@ -33,6 +71,7 @@ type DetectorConfig struct {
 	XSS            bool `yaml:"xss"`
 	PathTraversal  bool `yaml:"path_traversal"`
 	Quality        bool `yaml:"quality"`
+	PII            bool `yaml:"pii"`
 }
 
 // HardcodedCredentialsConfig 硬编码凭证检测配置
@@ -54,6 +93,13 @@ type QualityConfig struct {
 	SeverityThreshold SeverityLevel `yaml:"severity_threshold"`
 }
 
+// PIIConfig 个人信息检测配置
+type PIIConfig struct {
+	Mode              string        `yaml:"mode"`  // report: 只报告; mask: 同时在输出中遮蔽
+	Types             []string      `yaml:"types"` // 启用的类型，为空表示全部
+	SeverityThreshold SeverityLevel `yaml:"severity_threshold"`
+}
+
 // ExclusionConfig 排除配置
 type ExclusionConfig struct {
 	Files    []string         `yaml:"files"`
@@ -69,10 +115,22 @@ type ExclusionRule struct {
 
 // ReportingConfig 报告配置
 type ReportingConfig struct {
-	Format         string `yaml:"format"`
-	OutputFile     string `yaml:"output_file"`
-	IncludeDetails bool   `yaml:"include_details"`
-	ShowStatistics bool   `yaml:"show_statistics"`
+	Format         string        `yaml:"format"`
+	OutputFile     string        `yaml:"output_file"`
+	IncludeDetails bool          `yaml:"include_details"`
+	ShowStatistics bool          `yaml:"show_statistics"`
+	ContextLines   int           `yaml:"context_lines"` // HTML报告中问题前后显示的源码行数，0表示默认3行
+	Webhook        WebhookConfig `yaml:"webhook"`
+}
+
+// WebhookConfig 扫描完成后推送结果的Webhook配置
+type WebhookConfig struct {
+	URL        string            `yaml:"url"`         // 为空时不推送
+	SecretEnv  string            `yaml:"secret_env"`  // 保存HMAC签名密钥的环境变量名
+	Headers    map[string]string `yaml:"headers"`     // 附加的请求头
+	Timeout    int               `yaml:"timeout"`     // 单次请求超时秒数，0表示默认10秒
+	MaxRetries int               `yaml:"max_retries"` // 失败后的重试次数
+	DryRun     bool              `yaml:"dry_run"`     // 只输出将要发送的请求
 }
 
 // SecurityReport 安全报告结构体
@@ -80,12 +138,21 @@ type SecurityReport struct {
 	ScanID       string          `json:"scan_id"`
 	Timestamp    time.Time       `json:"timestamp"`
 	ScanDuration time.Duration   `json:"scan_duration"`
+	Root         string          `json:"root,omitempty"` // 扫描的根目录，对比报告时问题路径相对它计算
 	
 	Summary      ScanSummary     `json:"summary"`
 	Issues       []SecurityIssue `json:"issues"`
 	Statistics   ScanStatistics  `json:"statistics"`
 	
 	Config       SecurityConfig  `json:"config"`
+	Errors       []ScanError     `json:"errors,omitempty"` // 检测器执行失败的记录，不影响其他文件和检测器
+}
+
+// ScanError 单个检测器在单个文件上执行失败
+type ScanError struct {
+	File     string `json:"file"`
+	Detector string `json:"detector"`
+	Message  string `json:"message"`
 }
 
 // ScanSummary 扫描摘要
@@ -104,7 +171,8 @@ type ScanStatistics struct {
 	TotalTime    time.Duration `json:"total_time"`
 	AverageTime  time.Duration `json:"average_time"`
 	FilesPerSec  float64       `json:"files_per_sec"`
-	MemoryUsage  int64         `json:"memory_usage"`
+	MemoryUsage  int64         `json:"memory_usage"` // 扫描期间累计分配的字节数（TotalAlloc增量），不是内存占用峰值
+	DetectorTimings map[string]time.Duration `json:"detector_timings"`
 }
 
 // SecurityIssue 安全问题
@@ -119,6 +187,8 @@ type SecurityIssue struct {
 	Snippet       string        `json:"snippet"`
 	Recommendation string        `json:"recommendation"`
 	Confidence    float64       `json:"confidence"`
+	Commit        string        `json:"commit,omitempty"` // 历史扫描中引入问题的提交
+	Author        string        `json:"author,omitempty"`
 }
 
 // SeverityLevel 严重性级别
@@ -147,6 +217,55 @@ func (s SeverityLevel) String() string {
 	}
 }
 
+// ParseSeverityLevel 解析严重性级别字符串
+func ParseSeverityLevel(level string) (SeverityLevel, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "", "low":
+		return SeverityLow, nil
+	case "medium":
+		return SeverityMedium, nil
+	case "high":
+		return SeverityHigh, nil
+	case "critical":
+		return SeverityCritical, nil
+	default:
+		return SeverityLow, fmt.Errorf("无效的严重性级别: %s (可选: low, medium, high, critical)", level)
+	}
+}
+
+// MarshalText 以名称形式序列化严重性级别
+func (s SeverityLevel) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText 从名称解析严重性级别
+func (s *SeverityLevel) UnmarshalText(text []byte) error {
+	level, err := ParseSeverityLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*s = level
+	return nil
+}
+
+// UnmarshalJSON 解析严重性级别，兼容旧报告中的数字形式
+func (s *SeverityLevel) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		return s.UnmarshalText([]byte(name))
+	}
+
+	var value int
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("无效的严重性级别: %s", string(data))
+	}
+	if value < int(SeverityLow) || value > int(SeverityCritical) {
+		return fmt.Errorf("无效的严重性级别: %d (可选: 0-3)", value)
+	}
+	*s = SeverityLevel(value)
+	return nil
+}
+
 // SecurityDetector 安全检测器接口
 type SecurityDetector interface {
 	Detect(filePath string, content string) []SecurityIssue
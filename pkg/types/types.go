@@ -2,6 +2,7 @@
 package types
 
 import (
+	"encoding/xml"
 	"fmt"
 	"time"
 )
@@ -16,9 +17,26 @@ type FileInfo struct {
 	IsDir    bool      `yaml:"is_dir,omitempty"`
 	IsHidden bool      `yaml:"is_hidden,omitempty"`
 	IsBinary bool      `yaml:"is_binary,omitempty"`
+	// Hash 读取时文本内容的SHA-256，在内容处理之前计算，用于写回时检测冲突
+	Hash string `yaml:"hash,omitempty" json:"Hash,omitempty" xml:"Hash,omitempty" toml:"Hash,omitempty"`
+	// Issues 生成时安全扫描发现的问题
+	Issues IssueList `yaml:"issues,omitempty" json:"issues,omitempty" xml:"issues,omitempty" toml:"issues,omitempty"`
 }
 
-// FolderInfo 文件夹信息结构体
+// IssueList 文件的安全问题列表
+type IssueList []SecurityIssue
+
+// MarshalXML 将问题包装在<issues>元素中，encoding/xml 的 a>b 标签在列表为空时仍会输出父元素
+func (l IssueList) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(struct {
+		Issues []SecurityIssue `xml:"issue"`
+	}{l}, start)
+}
+
+// FolderInfo 文件夹信息结构体，Folders 为子文件夹，构成目录树
+//
+// 遍历结果的目录树中，Files 只包含文件的元信息，文件内容见 ContextData.Files；
+// Size、Count 和 Tokens 是包括所有子文件夹在内的汇总值。
 type FolderInfo struct {
 	Name     string       `yaml:"name"`
 	Path     string       `yaml:"path"`
@@ -28,10 +46,17 @@ type FolderInfo struct {
 	IsHidden bool         `yaml:"is_hidden"`
 	Size     int64        `yaml:"size"`
 	Count    int          `yaml:"count"`
+	Tokens   int          `yaml:"tokens"`
 }
 
 // ContextData 上下文数据结构
 type ContextData struct {
+	// Security 生成时安全扫描的摘要，未扫描时为nil
+	Security    *ScanSummary           `yaml:"security,omitempty" json:"security,omitempty"`
+	// License 许可证合规摘要，未检测时为nil
+	License     *LicenseReport         `yaml:"license,omitempty" json:"license,omitempty"`
+	// Chunk 分块输出时当前分块的头信息，未分块时为nil
+	Chunk       *ChunkInfo             `yaml:"chunk,omitempty" json:"chunk,omitempty"`
 	Files       []FileInfo             `yaml:"files"`
 	Folders     []FolderInfo           `yaml:"folders"`
 	FileCount   int                    `yaml:"file_count"`
@@ -63,6 +88,7 @@ type Config struct {
 	Logging       LoggingConfig       `yaml:"logging"`
 	Security      SecurityConfig      `yaml:"security"`
 	Git           GitIntegrationConfig `yaml:"git"`
+	License       LicenseConfig        `yaml:"license"`
 }
 
 // FormatsConfig 输出格式配置
@@ -71,6 +97,9 @@ type FormatsConfig struct {
 	JSON     FormatConfig `yaml:"json"`
 	TOML     FormatConfig `yaml:"toml"`
 	Markdown FormatConfig `yaml:"markdown"`
+	YAML     FormatConfig `yaml:"yaml"`
+	NDJSON   FormatConfig `yaml:"ndjson"`
+	Plain    FormatConfig `yaml:"plain"`
 }
 
 // FormatConfig 单个格式配置
@@ -120,12 +149,19 @@ type FieldsConfig struct {
 		Include []string `yaml:"include"`
 		Exclude []string `yaml:"exclude"`
 	} `yaml:"filter"`
-	Processing struct {
-		MaxLength      int  `yaml:"max_length"`
-		AddLineNumbers bool `yaml:"add_line_numbers"`
-		TrimWhitespace bool `yaml:"trim_whitespace"`
-		CodeHighlight  bool `yaml:"code_highlight"`
-	} `yaml:"processing"`
+	Processing ProcessingConfig `yaml:"processing"`
+}
+
+// ProcessingConfig 文件内容处理配置，在遍历之后、格式化之前按 Stages 的顺序执行已启用的阶段
+type ProcessingConfig struct {
+	Stages               []string `yaml:"stages"`                 // 阶段执行顺序，为空时使用默认顺序
+	NormalizeLineEndings bool     `yaml:"normalize_line_endings"` // 将CRLF和CR统一为LF
+	TabWidth             int      `yaml:"tab_width"`              // 大于0时将制表符展开为空格
+	TrimWhitespace       bool     `yaml:"trim_whitespace"`        // 去除行尾空白
+	CollapseBlankLines   bool     `yaml:"collapse_blank_lines"`   // 将连续空行合并为一行
+	AddLineNumbers       bool     `yaml:"add_line_numbers"`
+	MaxLength            int      `yaml:"max_length"` // 单个文件内容的最大字符数，超出部分截断并添加标记，0表示不限制
+	CodeHighlight        bool     `yaml:"code_highlight"`
 }
 
 // FiltersConfig 文件过滤配置
@@ -136,6 +172,7 @@ type FiltersConfig struct {
 	MaxDepth        int      `yaml:"max_depth"`
 	FollowSymlinks  bool     `yaml:"follow_symlinks"`
 	ExcludeBinary   bool     `yaml:"exclude_binary"`
+	AllowSensitive  bool     `yaml:"allow_sensitive"`
 }
 
 // OutputConfig 输出配置
@@ -143,6 +180,8 @@ type OutputConfig struct {
 	Format       string `yaml:"format"`
 	FilePath     string `yaml:"file_path"`
 	Encoding     string `yaml:"encoding"`
+	BOM            bool   `yaml:"bom"`             // 输出编码支持时写入字节顺序标记
+	EncodingErrors string `yaml:"encoding_errors"` // 无法编码的字符的处理方式：lossy（替换为?，默认）或 strict（报错）
 	DefaultFormat    string `yaml:"default_format"`
 	OutputDir        string `yaml:"output_dir"`
 	FilenameTemplate string `yaml:"filename_template"`